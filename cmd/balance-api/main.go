@@ -1,58 +1,180 @@
 package main
 
 import (
+	"context"
 	"flag"
+	"fmt"
 	"gn-indexer/internal/api"
 	"gn-indexer/internal/config"
+	"gn-indexer/internal/domain"
+	"gn-indexer/internal/graphql"
+	"gn-indexer/internal/health"
+	"gn-indexer/internal/logging"
+	"gn-indexer/internal/migrate"
+	"gn-indexer/internal/queue"
 	"gn-indexer/internal/repository"
-	"log"
+	"gn-indexer/internal/service"
+	"gn-indexer/internal/tracing"
+	"log/slog"
+	"os"
 
 	"github.com/joho/godotenv"
 )
 
-func init() {
-	if err := godotenv.Load(); err != nil {
-		log.Println("no .env file found, continuing...")
+func main() {
+	// Configuration from the defaults, the -config file, the environment (.env included) and flags, in that order
+	envErr := godotenv.Load()
+	cfg := config.DefaultBalanceAPIConfig()
+	cfg.RegisterFlags(flag.CommandLine)
+	if err := config.Load(cfg, flag.CommandLine, os.Args[1:]); err != nil {
+		logging.Fatal("invalid configuration", "error", err)
 	}
-}
+	if err := logging.Setup(&cfg.Log); err != nil {
+		logging.Fatal("invalid log configuration", "error", err)
+	}
+	if envErr != nil {
+		slog.Info("no .env file found, continuing...")
+	}
+	slog.Info("effective configuration", config.LogAttrs(cfg)...)
 
-func main() {
-	// Parse command line flags
-	var (
-		port = flag.String("port", "8080", "server port")
-		host = flag.String("host", "127.0.0.1", "server host")
-	)
-	flag.Parse()
+	// Database connection
+	gormDb, err := cfg.Database.Connect()
+	if err != nil {
+		logging.Fatal("failed to connect to database", "error", err)
+	}
 
-	// Load environment variables
-	if err := godotenv.Load(); err != nil {
-		log.Println("no .env file found, continuing...")
+	// Refuse to run against a schema older or newer than the migrations built into this binary
+	if err := migrate.CheckSchema(context.Background(), gormDb); err != nil {
+		logging.Fatal("schema version check failed", "error", err)
 	}
 
-	// Database connection
-	dbConfig := config.NewDatabaseConfig()
-	gormDb, err := dbConfig.Connect()
+	// Spans are exported per OTEL_TRACES_EXPORTER and continue across the queue and HTTP calls
+	shutdownTracing, err := tracing.Setup(context.Background(), &cfg.Tracing, "balance-api")
+	if err != nil {
+		logging.Fatal("failed to set up tracing", "error", err)
+	}
+	defer shutdownTracing(context.Background())
+	if err := gormDb.Use(tracing.GormPlugin{}); err != nil {
+		logging.Fatal("failed to instrument database", "error", err)
+	}
+
+	// Indexed data is read from the replicas, API keys, webhooks and snapshot jobs stay on the primary the API writes to
+	replicas, err := cfg.Database.UseReplicas(gormDb,
+		&domain.Block{}, &domain.Transaction{}, &domain.TxEvent{}, &domain.TxEventAttr{},
+		&domain.Token{}, &domain.Balance{}, &domain.Allowance{}, &domain.Transfer{}, &domain.BalanceHistory{},
+		&domain.NFTOwner{}, &domain.NFTTransfer{},
+	)
 	if err != nil {
-		log.Fatal("failed to connect to database:", err)
+		logging.Fatal("failed to connect to read replicas", "error", err)
 	}
 
 	// Create repositories
 	balanceRepo := repository.NewBalanceRepository(gormDb)
 	tokenRepo := repository.NewTokenRepository(gormDb)
 	transferRepo := repository.NewTransferRepository(gormDb)
+	allowanceRepo := repository.NewAllowanceRepository(gormDb)
+	nftRepo := repository.NewNFTRepository(gormDb)
+	balanceHistoryRepo := repository.NewBalanceHistoryRepository(gormDb)
+	blockRepo := repository.NewBlockRepository(gormDb)
+	snapshotJobRepo := repository.NewSnapshotJobRepository(gormDb)
+	webhookRepo := repository.NewWebhookRepository(gormDb)
+	transactionRepo := repository.NewTransactionRepository(gormDb)
+	eventRepo := repository.NewEventRepository(gormDb)
+	eventAttrRepo := repository.NewEventAttrRepository(gormDb)
+	apiKeyRepo := repository.NewAPIKeyRepository(gormDb)
+
+	// Snapshot jobs export whole holder lists in the background, they read through their own pool
+	// without the statement timeout meant for API queries
+	snapshotDb := gormDb
+	if cfg.Database.StatementTimeout > 0 && !cfg.Database.IsSQLite() {
+		snapshotDatabase := cfg.Database
+		snapshotDatabase.StatementTimeout = 0
+		snapshotDatabase.MaxOpenConns, snapshotDatabase.MaxIdleConns = cfg.SnapshotWorkers, cfg.SnapshotWorkers
+		if snapshotDb, err = snapshotDatabase.Connect(); err != nil {
+			logging.Fatal("failed to connect to database for snapshot jobs", "error", err)
+		}
+		if err := snapshotDb.Use(tracing.GormPlugin{}); err != nil {
+			logging.Fatal("failed to instrument database", "error", err)
+		}
+		if _, err := snapshotDatabase.UseReplicas(snapshotDb, &domain.Balance{}, &domain.BalanceHistory{}); err != nil {
+			logging.Fatal("failed to connect to read replicas for snapshot jobs", "error", err)
+		}
+	}
+
+	// Create services
+	snapshotService := service.NewSnapshotService(repository.NewBalanceRepository(snapshotDb), repository.NewBalanceHistoryRepository(snapshotDb), snapshotJobRepo, cfg.SnapshotDir)
+	go func() {
+		if err := snapshotService.Start(context.Background(), cfg.SnapshotWorkers, cfg.SnapshotRetention); err != nil {
+			slog.Error("snapshot workers stopped", "error", err)
+		}
+	}()
+
+	// API keys identify partners, their usage is flushed to the database in the background
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo)
+	go func() {
+		if err := apiKeyService.Start(context.Background()); err != nil {
+			slog.Error("api key usage flush stopped", "error", err)
+		}
+	}()
+	auth := api.AuthConfig{
+		Keys:       apiKeyService,
+		Required:   cfg.APIKeysRequired,
+		AdminToken: cfg.AdminToken,
+	}
+
+	// Balance responses are cached until the event processor reports a change or they get too old
+	responseCache, err := api.NewResponseCacheFromConfig(&cfg.Cache)
+	if err != nil {
+		logging.Fatal("failed to create response cache", "error", err)
+	}
+
+	// Relay notifications published by the event processor to the cache and stream subscribers
+	streamHub := api.NewStreamHub()
+	listener := queue.NewListenerFor(&cfg.Database, queue.NotificationChannel, func(notification *domain.Notification) {
+		responseCache.Invalidate(notification)
+		streamHub.Broadcast(notification)
+	})
+	if listener != nil {
+		go func() {
+			if err := listener.Start(context.Background()); err != nil {
+				slog.Error("notification listener stopped", "error", err)
+			}
+		}()
+	} else {
+		slog.Warn("SQLite has no notification channel, stream endpoints stay silent and cached responses expire after max staleness",
+			"max_staleness", cfg.Cache.MaxStaleness)
+	}
+
+	graphqlHandler, err := graphql.NewHandler(graphql.Repositories{
+		Blocks:       blockRepo,
+		Transactions: transactionRepo,
+		Events:       eventRepo,
+		EventAttrs:   eventAttrRepo,
+		Transfers:    transferRepo,
+		Tokens:       tokenRepo,
+		Balances:     balanceRepo,
+	})
+	if err != nil {
+		logging.Fatal("failed to build graphql schema", "error", err)
+	}
+
+	// Readiness follows the database and the replica lag, the indexed height is reported for monitoring
+	checks := []health.Check{health.DatabaseCheck(gormDb)}
+	for i, replica := range replicas {
+		checks = append(checks, health.ReplicaCheck(fmt.Sprintf("replica-%d", i+1), replica))
+	}
+	healthChecker := health.NewChecker("balance-api", &cfg.Health, health.BlockProgress(blockRepo), checks...)
 
 	// Create and start API server
-	server := api.NewServer(balanceRepo, tokenRepo, transferRepo)
+	server := api.NewServer(balanceRepo, tokenRepo, transferRepo, allowanceRepo, nftRepo, balanceHistoryRepo, blockRepo, transactionRepo, eventRepo, eventAttrRepo, snapshotService, streamHub, cfg.StreamAllowedOrigins, webhookRepo, graphqlHandler, responseCache, healthChecker, auth)
 
-	addr := *host + ":" + *port
-	log.Printf("Starting GN Indexer Balance API on %s", addr)
-	log.Printf("Available endpoints:")
-	log.Printf("  GET /health")
-	log.Printf("  GET /tokens/balances?address={address}")
-	log.Printf("  GET /tokens/{tokenPath}/balances?address={address}")
-	log.Printf("  GET /tokens/transfer-history?address={address}")
+	addr := cfg.Host + ":" + cfg.Port
+	slog.Info("starting GN Indexer Balance API", "addr", addr, "docs", "/openapi.json")
+	if auth.Required {
+		slog.Info("API keys are required, send them in the header or the api_key query parameter", "header", api.APIKeyHeader)
+	}
 
 	if err := server.Run(addr); err != nil {
-		log.Fatal("failed to start server:", err)
+		logging.Fatal("failed to start server", "error", err)
 	}
 }
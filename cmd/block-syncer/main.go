@@ -5,12 +5,16 @@ import (
 	"flag"
 	"gn-indexer/internal/client"
 	"gn-indexer/internal/config"
+	"gn-indexer/internal/health"
+	"gn-indexer/internal/logging"
+	"gn-indexer/internal/migrate"
 	"gn-indexer/internal/producer"
 	"gn-indexer/internal/queue"
 	"gn-indexer/internal/repository"
 	"gn-indexer/internal/service"
+	"gn-indexer/internal/tracing"
 	"gn-indexer/internal/types"
-	"log"
+	"log/slog"
 	"os"
 	"os/signal"
 	"syscall"
@@ -18,59 +22,78 @@ import (
 	"github.com/joho/godotenv"
 )
 
-func init() {
-	if err := godotenv.Load(); err != nil {
-		log.Println("no .env file found, continuing...")
-	}
-}
-
 func main() {
-	// GraphQL endpoints from environment variables
-	gqlEndpoint := os.Getenv("GRAPHQL_ENDPOINT")
-	wsEndpoint := os.Getenv("GRAPHQL_WS_ENDPOINT")
-
 	// flag: command line standardization
 	var (
 		fromHeight = flag.Int("from", 0, "from block height")
 		toHeight   = flag.Int("to", 0, "to block height")
 		realtime   = flag.Bool("realtime", false, "start realtime sync")
 		integrity  = flag.Bool("integrity", false, "check and fix data integrity from height 1")
+		normalize  = flag.Bool("normalize-addresses", false, "rewrite stored addresses to lowercase bech32 and exit")
 	)
-	flag.Parse()
 
-	// database connection
-	connConfig := config.NewDatabaseConfig()
-	gormDb, err := connConfig.Connect()
+	// Configuration from the defaults, the -config file, the environment (.env included) and flags, in that order
+	envErr := godotenv.Load()
+	cfg := config.DefaultBlockSyncerConfig()
+	cfg.RegisterFlags(flag.CommandLine)
+	if err := config.Load(cfg, flag.CommandLine, os.Args[1:]); err != nil {
+		logging.Fatal("invalid configuration", "error", err)
+	}
+	if err := logging.Setup(&cfg.Log); err != nil {
+		logging.Fatal("invalid log configuration", "error", err)
+	}
+	if envErr != nil {
+		slog.Info("no .env file found, continuing...")
+	}
+	slog.Info("effective configuration", config.LogAttrs(cfg)...)
+
+	// database connection, writers stay on the primary and never use the read replicas
+	gormDb, err := cfg.Database.Connect()
 	if err != nil {
-		log.Fatal(err)
+		logging.Fatal("failed to connect to database", "error", err)
 	}
 
-	ctx := context.Background()
+	// Refuse to run against a schema older or newer than the migrations built into this binary
+	if err := migrate.CheckSchema(context.Background(), gormDb); err != nil {
+		logging.Fatal("schema version check failed", "error", err)
+	}
 
-	// Load queue configuration for SQS
-	queueConfig := &queue.QueueConfig{
-		QueueName:          getEnv("SQS_QUEUE_NAME", "gn-token-events"),
-		EndpointURL:        getEnv("LOCALSTACK_URL", "http://localhost:4566"),
-		Region:             getEnv("AWS_DEFAULT_REGION", "ap-northeast-2"),
-		AccessKeyID:        getEnv("AWS_ACCESS_KEY_ID", "test"),
-		SecretAccessKey:    getEnv("AWS_SECRET_ACCESS_KEY", "test"),
-		MaxReceiveMessages: 10,
-		VisibilityTimeout:  30,
+	// Spans are exported per OTEL_TRACES_EXPORTER and continue across the queue and HTTP calls
+	shutdownTracing, err := tracing.Setup(context.Background(), &cfg.Tracing, "block-syncer")
+	if err != nil {
+		logging.Fatal("failed to set up tracing", "error", err)
 	}
+	defer shutdownTracing(context.Background())
+	if err := gormDb.Use(tracing.GormPlugin{}); err != nil {
+		logging.Fatal("failed to instrument database", "error", err)
+	}
+
+	ctx := context.Background()
 
 	// create SQS queue
-	eventQueue, err := queue.NewSQSQueue(queueConfig)
+	eventQueue, err := queue.NewSQSQueue(queue.NewQueueConfig(&cfg.Queue, 10))
 	if err != nil {
-		log.Fatalf("failed to create SQS queue: %v", err)
+		logging.Fatal("failed to create SQS queue", "error", err)
 	}
 	defer eventQueue.Close()
 
+	// Metrics, and health reporting the database, the queue and the sync progress
+	if cfg.MetricsAddr != "" {
+		checker := health.NewChecker("block-syncer", &cfg.Health, health.MetricsProgress,
+			health.DatabaseCheck(gormDb), health.QueueCheck(eventQueue))
+		go func() {
+			if err := health.Serve(ctx, cfg.MetricsAddr, checker); err != nil {
+				slog.Error("health server stopped", "error", err)
+			}
+		}()
+	}
+
 	// http client
-	cliBlocks := client.NewGraphQLClient[types.BlocksDataArr](gqlEndpoint)
-	cliTxs := client.NewGraphQLClient[types.TxsData](gqlEndpoint)
+	cliBlocks := client.NewGraphQLClient[types.BlocksDataArr](cfg.GraphQL.Endpoint, cfg.GraphQL.Timeout)
+	cliTxs := client.NewGraphQLClient[types.TxsData](cfg.GraphQL.Endpoint, cfg.GraphQL.Timeout)
 
 	// websocket client
-	subClient := client.NewSubscriptionClient(wsEndpoint)
+	subClient := client.NewSubscriptionClient(cfg.GraphQL.WSEndpoint, cfg.GraphQL.ReconnectDelay)
 
 	// create repositories directly
 	blockRepo := repository.NewBlockRepository(gormDb)
@@ -79,13 +102,16 @@ func main() {
 	eventAttrRepo := repository.NewEventAttrRepository(gormDb)
 	transferRepo := repository.NewTransferRepository(gormDb)
 	tokenRepo := repository.NewTokenRepository(gormDb)
+	nftRepo := repository.NewNFTRepository(gormDb)
 
 	// create event storage service for parsing and queuing events
 	eventStorageService := service.NewEventStorageService(
+		repository.NewTransactor(gormDb),
 		eventRepo,
 		eventAttrRepo,
 		transferRepo,
 		tokenRepo,
+		nftRepo,
 		eventQueue,
 	)
 
@@ -97,11 +123,12 @@ func main() {
 		blockRepo,
 		transactionRepo,
 		eventStorageService, // Add event storage service
+		cfg.Sync.TxIndexLimit,
 	)
 
 	if *realtime {
 		// Real-time synchronization
-		log.Println("starting realtime sync mode")
+		slog.Info("starting realtime sync mode")
 
 		ctx, cancel := context.WithCancel(ctx)
 		defer cancel()
@@ -111,39 +138,52 @@ func main() {
 		signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
 		// Create and start realtime sync service
-		realtimeService := service.NewRealtimeSyncService(syncer, subClient)
+		realtimeService := service.NewRealtimeSyncService(syncer, subClient, &cfg.Sync)
 
 		// Start realtime sync in a goroutine
 		go func() {
 			if err := realtimeService.Start(ctx); err != nil {
-				log.Printf("realtime sync failed: %v", err)
+				slog.Error("realtime sync failed", "error", err)
 			}
 		}()
 
 		// Wait for signal
 		sig := <-sigChan
-		log.Printf("received signal %v, shutting down gracefully...", sig)
+		slog.Info("shutting down gracefully", "signal", sig.String())
 
 		// Cancel context to stop all operations
 		cancel()
 
 		// Close WebSocket connection
 		if err := subClient.Close(); err != nil {
-			log.Printf("error closing websocket: %v", err)
+			slog.Error("error closing websocket", "error", err)
 		}
 
-		log.Println("shutdown completed")
+		slog.Info("shutdown completed")
+	} else if *normalize {
+		// One-off pass fixing addresses stored before ingestion validated them
+		slog.Info("starting address normalization")
+
+		normalizationSvc := service.NewAddressNormalizationService(repository.NewAddressRepository(gormDb))
+
+		result, err := normalizationSvc.NormalizeAddresses(ctx)
+		if err != nil {
+			logging.Fatal("address normalization failed", "error", err)
+		}
+
+		slog.Info("address normalization completed", "renamed", result.Renamed, "malformed", len(result.Malformed))
+		return
 	} else if *integrity {
 		// Data integrity check and fix (from height 1)
-		log.Println("starting data integrity check and fix from height 1...")
+		slog.Info("starting data integrity check and fix from height 1")
 
-		dataIntegritySvc := service.NewDataIntegrityService(syncer, subClient)
+		dataIntegritySvc := service.NewDataIntegrityService(syncer, subClient, cfg.Sync.ChunkSize)
 
 		if err := dataIntegritySvc.CheckAndFixDataIntegrity(ctx); err != nil {
-			log.Fatalf("data integrity check and fix failed: %v", err)
+			logging.Fatal("data integrity check and fix failed", "error", err)
 		}
 
-		log.Println("data integrity check and fix completed successfully")
+		slog.Info("data integrity check and fix completed")
 		return
 	} else if *fromHeight > 0 || *toHeight > 0 {
 		// Specific range synchronization
@@ -156,38 +196,25 @@ func main() {
 
 		// Add validation check
 		if *fromHeight > *toHeight {
-			log.Fatalf("invalid range: from height (%d) cannot be greater than to height (%d)", *fromHeight, *toHeight)
+			logging.Fatal("invalid range: from height cannot be greater than to height", "from", *fromHeight, "to", *toHeight)
 		}
 
-		log.Printf("starting one-time sync from height %d to %d", *fromHeight, *toHeight)
+		slog.Info("starting one-time sync", "from", *fromHeight, "to", *toHeight)
 
 		if err := syncer.SyncRange(ctx, *fromHeight, *toHeight); err != nil {
-			log.Fatalf("failed to sync range: %v", err)
+			logging.Fatal("failed to sync range", "error", err)
 		}
 
-		log.Println("sync completed successfully")
+		slog.Info("sync completed")
 	} else {
 		// Default behavior: sync from height 1 to 1000
-		log.Println("no flags specified, starting default sync from height 1 to 1000...")
+		slog.Info("no flags specified, starting default sync", "from", 1, "to", 1000)
 
 		if err := syncer.SyncRange(ctx, 1, 1000); err != nil {
-			log.Fatalf("failed to sync default range: %v", err)
+			logging.Fatal("failed to sync default range", "error", err)
 		}
 
-		log.Println("default sync completed successfully")
-		log.Println("")
-		log.Println("Usage:")
-		log.Println("  --integrity: Check and fix data integrity from height 1")
-		log.Println("  --realtime: Start realtime sync mode")
-		log.Println("  --from <height> --to <height>: Sync specific range (from defaults to 1, to defaults to 1000)")
-		log.Println("  No flags: Sync from height 1 to 1000 (default behavior)")
-	}
-}
-
-// getEnv gets environment variable with fallback
-func getEnv(key, fallback string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+		slog.Info("default sync completed")
+		flag.Usage()
 	}
-	return fallback
 }
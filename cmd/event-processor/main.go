@@ -3,11 +3,17 @@ package main
 import (
 	"context"
 	"flag"
+	"gn-indexer/internal/api"
+	"gn-indexer/internal/client"
 	"gn-indexer/internal/config"
+	"gn-indexer/internal/health"
+	"gn-indexer/internal/logging"
+	"gn-indexer/internal/migrate"
 	"gn-indexer/internal/queue"
 	"gn-indexer/internal/repository"
 	"gn-indexer/internal/service"
-	"log"
+	"gn-indexer/internal/tracing"
+	"log/slog"
 	"os"
 	"os/signal"
 	"syscall"
@@ -15,70 +21,114 @@ import (
 	"github.com/joho/godotenv"
 )
 
-func init() {
-	if err := godotenv.Load(); err != nil {
-		log.Println("no .env file found, continuing...")
-	}
-}
-
 func main() {
 	// flag: command line standardization
-	var (
-		batchSize = flag.Int("batch", 10, "batch size for processing events")
-		manual    = flag.Bool("manual", false, "manual batch processing mode")
-	)
-	flag.Parse()
-
-	// database connection
-	dbConfig := config.NewDatabaseConfig()
-	gormDb, err := dbConfig.Connect()
+	manual := flag.Bool("manual", false, "manual batch processing mode")
+
+	// Configuration from the defaults, the -config file, the environment (.env included) and flags, in that order
+	envErr := godotenv.Load()
+	cfg := config.DefaultEventProcessorConfig()
+	cfg.RegisterFlags(flag.CommandLine)
+	if err := config.Load(cfg, flag.CommandLine, os.Args[1:]); err != nil {
+		logging.Fatal("invalid configuration", "error", err)
+	}
+	if err := logging.Setup(&cfg.Log); err != nil {
+		logging.Fatal("invalid log configuration", "error", err)
+	}
+	if envErr != nil {
+		slog.Info("no .env file found, continuing...")
+	}
+	slog.Info("effective configuration", config.LogAttrs(cfg)...)
+
+	// database connection, writers stay on the primary and never use the read replicas
+	gormDb, err := cfg.Database.Connect()
 	if err != nil {
-		log.Fatal(err)
+		logging.Fatal("failed to connect to database", "error", err)
 	}
 
-	ctx := context.Background()
+	// Refuse to run against a schema older or newer than the migrations built into this binary
+	if err := migrate.CheckSchema(context.Background(), gormDb); err != nil {
+		logging.Fatal("schema version check failed", "error", err)
+	}
 
-	// Load queue configuration
-	queueConfig := &queue.QueueConfig{
-		QueueName:          getEnv("SQS_QUEUE_NAME", "gn-token-events"),
-		EndpointURL:        getEnv("LOCALSTACK_URL", "http://localhost:4566"),
-		Region:             getEnv("AWS_DEFAULT_REGION", "ap-northeast-2"),
-		AccessKeyID:        getEnv("AWS_ACCESS_KEY_ID", "test"),
-		SecretAccessKey:    getEnv("AWS_SECRET_ACCESS_KEY", "test"),
-		MaxReceiveMessages: *batchSize,
-		VisibilityTimeout:  30,
+	// Spans are exported per OTEL_TRACES_EXPORTER and continue across the queue and HTTP calls
+	shutdownTracing, err := tracing.Setup(context.Background(), &cfg.Tracing, "event-processor")
+	if err != nil {
+		logging.Fatal("failed to set up tracing", "error", err)
 	}
+	defer shutdownTracing(context.Background())
+	if err := gormDb.Use(tracing.GormPlugin{}); err != nil {
+		logging.Fatal("failed to instrument database", "error", err)
+	}
+
+	ctx := context.Background()
 
 	// create repositories directly
 	balanceRepo := repository.NewBalanceRepository(gormDb)
 	tokenRepo := repository.NewTokenRepository(gormDb)
+	allowanceRepo := repository.NewAllowanceRepository(gormDb)
+	balanceHistoryRepo := repository.NewBalanceHistoryRepository(gormDb)
+	webhookRepo := repository.NewWebhookRepository(gormDb)
+	nftRepo := repository.NewNFTRepository(gormDb)
 
 	// create queue
-	eventQueue, err := queue.NewSQSQueue(queueConfig)
+	eventQueue, err := queue.NewSQSQueue(queue.NewQueueConfig(&cfg.Queue, cfg.BatchSize))
 	if err != nil {
-		log.Fatalf("failed to create SQS queue: %v", err)
+		logging.Fatal("failed to create SQS queue", "error", err)
 	}
 	defer eventQueue.Close()
 
-	// create services
-	balanceService := service.NewBalanceService(balanceRepo, tokenRepo)
-	eventProcessor := service.NewEventProcessorService(eventQueue, balanceService)
+	// Metrics, and health reporting the database, the queue and the sync progress
+	if cfg.MetricsAddr != "" {
+		checker := health.NewChecker("event-processor", &cfg.Health, health.QueueProgress(eventQueue),
+			health.DatabaseCheck(gormDb), health.QueueCheck(eventQueue))
+		go func() {
+			if err := health.Serve(ctx, cfg.MetricsAddr, checker); err != nil {
+				slog.Error("health server stopped", "error", err)
+			}
+		}()
+	}
+
+	// balance and transfer notifications for the balance-api stream endpoints and response cache
+	var publishers []service.Publisher
+	if notifyPublisher := queue.NewNotifyPublisherFor(&cfg.Database, gormDb, queue.NotificationChannel); notifyPublisher != nil {
+		publishers = append(publishers, notifyPublisher)
+	} else {
+		slog.Warn("SQLite has no notification channel, balance-api streams and cache invalidation are disabled")
+	}
+
+	// create services, webhook deliveries are queued in the transaction of the balance change
+	webhookService := service.NewWebhookService(webhookRepo, service.NewWebhookClient(cfg.WebhookTimeout))
+
+	// A shared Redis response cache is invalidated right after balances are committed
+	if cfg.Cache.Backend == config.CacheBackendRedis {
+		responseCache, err := api.NewResponseCacheFromConfig(&cfg.Cache)
+		if err != nil {
+			logging.Fatal("failed to create response cache", "error", err)
+		}
+		publishers = append(publishers, responseCache)
+	}
+
+	balanceService := service.NewBalanceService(repository.NewTransactor(gormDb), balanceRepo, tokenRepo, balanceHistoryRepo, webhookService, publishers...)
+	allowanceService := service.NewAllowanceService(allowanceRepo)
+	nftService := service.NewNFTService(nftRepo)
+	eventProcessor := service.NewEventProcessorService(eventQueue, balanceService, allowanceService, nftService)
 
 	if *manual {
 		// Manual batch processing mode - process one batch and exit
-		log.Printf("starting manual batch processing mode with batch size: %d", *batchSize)
+		slog.Info("starting manual batch processing mode", "batch_size", cfg.BatchSize)
 
 		// Process exactly one batch
-		processedCount, err := eventProcessor.ProcessSingleBatch(ctx, *batchSize)
+		processedCount, err := eventProcessor.ProcessSingleBatch(ctx, cfg.BatchSize)
 		if err != nil {
-			log.Fatalf("manual batch processing failed: %v", err)
+			logging.Fatal("manual batch processing failed", "error", err)
 		}
 
-		log.Printf("manual batch processing completed successfully. Processed %d events", processedCount)
+		slog.Info("manual batch processing completed", "processed", processedCount)
 		return
 	} else {
 		// Continuous event processing mode (default) - keep processing until interrupted
-		log.Printf("starting continuous event processing mode with batch size: %d", *batchSize)
+		slog.Info("starting continuous event processing mode", "batch_size", cfg.BatchSize)
 
 		ctx, cancel := context.WithCancel(ctx)
 		defer cancel()
@@ -90,35 +140,57 @@ func main() {
 		// Start event processing in a goroutine
 		go func() {
 			if err := eventProcessor.Start(ctx); err != nil {
-				log.Printf("event processing failed: %v", err)
+				slog.Error("event processing failed", "error", err)
+			}
+		}()
+
+		// Resolve token metadata in the background
+		metadataService := service.NewTokenMetadataService(tokenRepo, metadataResolvers(cfg)...)
+		go func() {
+			if err := metadataService.Start(ctx, cfg.MetadataInterval); err != nil && ctx.Err() == nil {
+				slog.Error("token metadata refresh failed", "error", err)
+			}
+		}()
+
+		// Deliver queued webhooks in the background
+		go func() {
+			if err := webhookService.Start(ctx); err != nil && ctx.Err() == nil {
+				slog.Error("webhook delivery failed", "error", err)
 			}
 		}()
 
 		// Wait for signal
 		sig := <-sigChan
-		log.Printf("received signal %v, shutting down gracefully...", sig)
+		slog.Info("shutting down gracefully", "signal", sig.String())
 
 		// Cancel context to stop all operations
 		cancel()
 
 		// Close queue connection
 		if err := eventQueue.Close(); err != nil {
-			log.Printf("error closing queue: %v", err)
+			slog.Error("error closing queue", "error", err)
 		}
 
-		log.Println("shutdown completed")
-		log.Println("")
-		log.Println("Usage:")
-		log.Println("  --manual: Manual batch processing mode (process one batch and exit)")
-		log.Println("  --batch <size>: Set batch size (default: 10)")
-		log.Println("  No flags: Continuous event processing mode (default behavior)")
+		slog.Info("shutdown completed")
 	}
 }
 
-// getEnv gets environment variable with fallback
-func getEnv(key, fallback string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+// metadataResolvers builds token metadata resolvers, realm queries first and the static file filling what they miss
+func metadataResolvers(cfg *config.EventProcessorConfig) []service.MetadataResolver {
+	var resolvers []service.MetadataResolver
+
+	if endpoint := cfg.GnoRPCEndpoint; endpoint != "" {
+		resolvers = append(resolvers, service.NewRPCMetadataResolver(client.NewGnoRPCClient(endpoint, cfg.GnoRPCTimeout)))
 	}
-	return fallback
+
+	if path := cfg.TokenMetadataFile; path != "" {
+		staticResolver, err := service.LoadStaticMetadataResolver(path)
+		if err != nil {
+			slog.Error("failed to load token metadata file", "path", path, "error", err)
+		} else {
+			resolvers = append(resolvers, staticResolver)
+		}
+	}
+
+	return resolvers
 }
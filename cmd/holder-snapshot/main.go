@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"gn-indexer/internal/config"
+	"gn-indexer/internal/domain"
+	"gn-indexer/internal/logging"
+	"gn-indexer/internal/migrate"
+	"gn-indexer/internal/repository"
+	"gn-indexer/internal/service"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	// flag: command line standardization
+	var (
+		tokenPath   = flag.String("token", "", "token path to export (required)")
+		height      = flag.Int64("height", 0, "export balances at this block height (default: current balances)")
+		atTime      = flag.String("time", "", "export balances at the last block before this RFC3339 time")
+		format      = flag.String("format", service.SnapshotFormatCSV, "output format: csv, jsonl or parquet")
+		minAmount   = flag.String("min-amount", "", "skip holders below this raw amount")
+		exclude     = flag.String("exclude", "", "comma separated addresses to leave out")
+		excludeFile = flag.String("exclude-file", "", "file with one address to leave out per line")
+		output      = flag.String("out", "", "output file (default: stdout)")
+	)
+
+	// Configuration from the defaults, the -config file, the environment (.env included) and flags, in that order
+	envErr := godotenv.Load()
+	cfg := config.DefaultHolderSnapshotConfig()
+	if err := config.Load(cfg, flag.CommandLine, os.Args[1:]); err != nil {
+		logging.Fatal("invalid configuration", "error", err)
+	}
+	if err := logging.Setup(&cfg.Log); err != nil {
+		logging.Fatal("invalid log configuration", "error", err)
+	}
+	if envErr != nil {
+		slog.Info("no .env file found, continuing...")
+	}
+	slog.Info("effective configuration", config.LogAttrs(cfg)...)
+
+	if *tokenPath == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if !service.ValidSnapshotFormat(*format) {
+		logging.Fatal(service.ErrInvalidSnapshotFormat.Error(), "format", *format)
+	}
+
+	// database connection
+	gormDb, err := cfg.Database.Connect()
+	if err != nil {
+		logging.Fatal("failed to connect to database", "error", err)
+	}
+
+	// Refuse to run against a schema older or newer than the migrations built into this binary
+	if err := migrate.CheckSchema(context.Background(), gormDb); err != nil {
+		logging.Fatal("schema version check failed", "error", err)
+	}
+
+	ctx := context.Background()
+
+	// create repositories directly
+	balanceRepo := repository.NewBalanceRepository(gormDb)
+	balanceHistoryRepo := repository.NewBalanceHistoryRepository(gormDb)
+	blockRepo := repository.NewBlockRepository(gormDb)
+
+	req := service.SnapshotRequest{
+		TokenPath: *tokenPath,
+		Format:    *format,
+	}
+
+	switch {
+	case *height > 0 && *atTime != "":
+		logging.Fatal("-height and -time cannot be combined")
+	case *height > 0:
+		req.AtHeight = height
+	case *atTime != "":
+		at, err := time.Parse(time.RFC3339, *atTime)
+		if err != nil {
+			logging.Fatal("invalid -time", "error", err)
+		}
+		resolved, err := blockRepo.GetHeightAtTime(ctx, at)
+		if err != nil {
+			logging.Fatal("failed to resolve -time", "error", err)
+		}
+		req.AtHeight = &resolved
+	}
+
+	if *minAmount != "" {
+		req.MinAmount, err = domain.NewU64FromString(*minAmount)
+		if err != nil {
+			logging.Fatal("invalid -min-amount", "error", err)
+		}
+	}
+
+	req.Exclude, err = loadExcludeList(*exclude, *excludeFile)
+	if err != nil {
+		logging.Fatal("failed to load exclude list", "error", err)
+	}
+
+	var out io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			logging.Fatal("failed to create output file", "error", err)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	snapshotService := service.NewSnapshotService(balanceRepo, balanceHistoryRepo, nil, "")
+	rowCount, err := snapshotService.Export(ctx, req, out)
+	if err != nil {
+		logging.Fatal("snapshot failed", "error", err)
+	}
+
+	slog.Info("snapshot exported", "holders", rowCount, "token", *tokenPath)
+}
+
+// loadExcludeList merges the -exclude list and the -exclude-file entries, ignoring blank lines and # comments
+func loadExcludeList(list, path string) ([]string, error) {
+	var addresses []string
+	for _, address := range strings.Split(list, ",") {
+		if address = strings.TrimSpace(address); address != "" {
+			addresses = append(addresses, address)
+		}
+	}
+
+	if path == "" {
+		return addresses, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		addresses = append(addresses, line)
+	}
+	return addresses, scanner.Err()
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"gn-indexer/internal/config"
+	"gn-indexer/internal/logging"
+	"gn-indexer/internal/migrate"
+	"log/slog"
+	"os"
+	"strconv"
+
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: migrate [flags] up | down [steps] | status | force VERSION\n")
+		flag.PrintDefaults()
+	}
+
+	// Configuration from the defaults, the -config file, the environment (.env included) and flags, in that order
+	envErr := godotenv.Load()
+	cfg := config.DefaultMigrateConfig()
+	if err := config.Load(cfg, flag.CommandLine, os.Args[1:]); err != nil {
+		logging.Fatal("invalid configuration", "error", err)
+	}
+	if err := logging.Setup(&cfg.Log); err != nil {
+		logging.Fatal("invalid log configuration", "error", err)
+	}
+	if envErr != nil {
+		slog.Info("no .env file found, continuing...")
+	}
+
+	args := flag.Args()
+	if len(args) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// database connection
+	gormDb, err := cfg.Database.Connect()
+	if err != nil {
+		logging.Fatal("failed to connect to database", "error", err)
+	}
+	sqlDb, err := gormDb.DB()
+	if err != nil {
+		logging.Fatal("failed to get database handle", "error", err)
+	}
+	defer sqlDb.Close()
+
+	migrator, err := migrate.ForDatabase(gormDb)
+	if err != nil {
+		logging.Fatal("failed to load migrations", "error", err)
+	}
+
+	ctx := context.Background()
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			logging.Fatal("migrate up failed", "applied", len(applied), "error", err)
+		}
+		slog.Info("schema is up to date", "version", migrator.Latest(), "applied", len(applied))
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				logging.Fatal("invalid number of steps", "steps", args[1])
+			}
+		}
+		reverted, err := migrator.Down(ctx, steps)
+		if err != nil {
+			logging.Fatal("migrate down failed", "reverted", len(reverted), "error", err)
+		}
+		slog.Info("reverted migrations", "reverted", len(reverted))
+	case "status":
+		status, err := migrator.Status(ctx)
+		if err != nil {
+			logging.Fatal("failed to read schema version", "error", err)
+		}
+		fmt.Printf("version %d of %d", status.Current, status.Latest)
+		if status.Dirty {
+			fmt.Print(" (dirty)")
+		}
+		fmt.Println()
+		for _, migration := range status.Pending {
+			fmt.Printf("pending %04d_%s\n", migration.Version, migration.Name)
+		}
+	case "force":
+		if len(args) < 2 {
+			flag.Usage()
+			os.Exit(2)
+		}
+		version, err := strconv.Atoi(args[1])
+		if err != nil {
+			logging.Fatal("invalid version", "version", args[1])
+		}
+		if err := migrator.Force(ctx, version); err != nil {
+			logging.Fatal("migrate force failed", "error", err)
+		}
+		slog.Info("schema version forced", "version", version)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
@@ -0,0 +1,22 @@
+package db
+
+import (
+	"embed"
+	"io/fs"
+)
+
+// files holds the numbered NNNN_name.up.sql and NNNN_name.down.sql migrations,
+// written for PostgreSQL with the same versions translated for SQLite in migrations/sqlite
+//
+//go:embed migrations/*.sql migrations/sqlite/*.sql
+var files embed.FS
+
+// Migrations returns the embedded migrations of a gorm driver ("postgres" or "sqlite"), one pair of up and down files per version
+func Migrations(driver string) fs.FS {
+	dir := "migrations"
+	if driver == "sqlite" {
+		dir = "migrations/sqlite"
+	}
+	migrations, _ := fs.Sub(files, dir) // only fails for an invalid path
+	return migrations
+}
@@ -0,0 +1,5 @@
+SET search_path = indexer, public;
+
+DROP INDEX IF EXISTS idx_allowances_token;
+DROP INDEX IF EXISTS idx_allowances_spender;
+DROP TABLE IF EXISTS allowances;
@@ -0,0 +1,5 @@
+SET search_path = indexer, public;
+
+ALTER TABLE tokens DROP COLUMN IF EXISTS metadata_updated_at;
+ALTER TABLE tokens DROP COLUMN IF EXISTS total_supply;
+ALTER TABLE tokens DROP COLUMN IF EXISTS name;
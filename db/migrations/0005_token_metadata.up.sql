@@ -0,0 +1,5 @@
+SET search_path = indexer, public;
+
+ALTER TABLE tokens ADD COLUMN IF NOT EXISTS name TEXT;
+ALTER TABLE tokens ADD COLUMN IF NOT EXISTS total_supply u64;
+ALTER TABLE tokens ADD COLUMN IF NOT EXISTS metadata_updated_at TIMESTAMPTZ;
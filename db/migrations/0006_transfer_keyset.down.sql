@@ -0,0 +1,5 @@
+SET search_path = indexer, public;
+
+DROP INDEX IF EXISTS idx_transfers_to_keyset;
+DROP INDEX IF EXISTS idx_transfers_from_keyset;
+DROP INDEX IF EXISTS idx_transfers_keyset;
@@ -0,0 +1,3 @@
+SET search_path = indexer, public;
+
+DROP INDEX IF EXISTS idx_balances_token_amount;
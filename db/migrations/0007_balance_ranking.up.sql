@@ -0,0 +1,3 @@
+SET search_path = indexer, public;
+
+CREATE INDEX IF NOT EXISTS idx_balances_token_amount ON balances(token_path, amount DESC, address);
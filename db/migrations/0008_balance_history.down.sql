@@ -0,0 +1,3 @@
+SET search_path = indexer, public;
+
+DROP TABLE IF EXISTS balance_history;
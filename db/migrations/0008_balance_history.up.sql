@@ -0,0 +1,44 @@
+SET search_path = indexer, public;
+
+-- Rows store the signed change of an event instead of the running balance,
+-- a sum of changes does not depend on the order events were indexed in
+CREATE TABLE IF NOT EXISTS balance_history (
+    id BIGSERIAL PRIMARY KEY,
+    address TEXT NOT NULL,
+    token_path TEXT NOT NULL REFERENCES tokens(token_path) ON DELETE RESTRICT,
+    delta NUMERIC NOT NULL,          -- signed change of the balance made by the event
+    tx_hash TEXT NOT NULL,
+    event_index INT NOT NULL,
+    block_height BIGINT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (token_path, address, tx_hash, event_index)
+);
+
+CREATE INDEX IF NOT EXISTS idx_balance_history_address ON balance_history(address, token_path, block_height DESC, event_index DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_balance_history_token ON balance_history(token_path, block_height DESC, event_index DESC, id DESC);
+
+-- Backfill from existing transfers, self transfers do not change a balance.
+-- Like the live balance, a running balance that would go negative is clamped to 0, and each row stores
+-- the change actually applied: the clamped balance is the running sum minus its lowest negative prefix.
+INSERT INTO balance_history (address, token_path, delta, tx_hash, event_index, block_height)
+SELECT address, token_path, balance - COALESCE(LAG(balance) OVER w, 0), tx_hash, event_index, block_height
+FROM (
+    SELECT sums.*, running - LEAST(MIN(running) OVER w, 0) AS balance
+    FROM (
+        SELECT deltas.*, SUM(delta) OVER w AS running
+        FROM (
+            SELECT id, to_address AS address, token_path, amount::NUMERIC AS delta, tx_hash, event_index, block_height
+            FROM transfers
+            WHERE COALESCE(to_address, '') <> '' AND to_address IS DISTINCT FROM from_address
+            UNION ALL
+            SELECT id, from_address AS address, token_path, -amount::NUMERIC AS delta, tx_hash, event_index, block_height
+            FROM transfers
+            WHERE COALESCE(from_address, '') <> '' AND from_address IS DISTINCT FROM to_address
+        ) deltas
+        WINDOW w AS (PARTITION BY token_path, address ORDER BY block_height, event_index, id ROWS UNBOUNDED PRECEDING)
+    ) sums
+    WINDOW w AS (PARTITION BY token_path, address ORDER BY block_height, event_index, id ROWS UNBOUNDED PRECEDING)
+) balances
+WINDOW w AS (PARTITION BY token_path, address ORDER BY block_height, event_index, id)
+ORDER BY block_height, event_index, id
+ON CONFLICT (token_path, address, tx_hash, event_index) DO NOTHING;
@@ -0,0 +1,20 @@
+SET search_path = indexer, public;
+
+CREATE TABLE IF NOT EXISTS snapshot_jobs (
+    id BIGSERIAL PRIMARY KEY,
+    token_path TEXT NOT NULL,
+    api_key_id BIGINT,               -- submitting API key, NULL without keys (api_keys follows in 0011)
+    at_height BIGINT,                -- NULL exports current balances
+    format TEXT NOT NULL,            -- csv, jsonl, parquet
+    min_amount u64,
+    exclude JSONB,                   -- excluded addresses
+    status TEXT NOT NULL,            -- pending, running, done, failed, expired
+    row_count BIGINT NOT NULL DEFAULT 0,
+    file_path TEXT,
+    error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    finished_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_snapshot_jobs_status ON snapshot_jobs(status, id);
+CREATE INDEX IF NOT EXISTS idx_snapshot_jobs_api_key ON snapshot_jobs(api_key_id);
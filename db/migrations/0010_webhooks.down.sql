@@ -0,0 +1,4 @@
+SET search_path = indexer, public;
+
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhooks;
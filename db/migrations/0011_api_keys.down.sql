@@ -0,0 +1,4 @@
+SET search_path = indexer, public;
+
+DROP TABLE IF EXISTS api_key_usage;
+DROP TABLE IF EXISTS api_keys;
@@ -0,0 +1,21 @@
+SET search_path = indexer, public;
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    id BIGSERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    key_prefix TEXT NOT NULL,        -- first characters, shown in listings
+    key_hash TEXT NOT NULL UNIQUE,   -- hex SHA-256 of the key, the key itself is returned once
+    rate_limit DOUBLE PRECISION NOT NULL,
+    burst INT NOT NULL,
+    daily_quota BIGINT NOT NULL DEFAULT 0, -- 0 means unlimited
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    revoked_at TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS api_key_usage (
+    api_key_id BIGINT NOT NULL REFERENCES api_keys(id) ON DELETE CASCADE,
+    day DATE NOT NULL,               -- UTC
+    requests BIGINT NOT NULL DEFAULT 0,
+    rejected BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (api_key_id, day)
+);
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS app_state;
+DROP TABLE IF EXISTS balances;
+DROP TABLE IF EXISTS transfers;
+DROP TABLE IF EXISTS tokens;
+DROP TABLE IF EXISTS tx_event_attrs;
+DROP TABLE IF EXISTS tx_events;
+DROP TABLE IF EXISTS transactions;
+DROP TABLE IF EXISTS blocks;
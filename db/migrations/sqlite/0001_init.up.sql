@@ -0,0 +1,82 @@
+-- SQLite translation of ../0001_init.up.sql, keep both in step.
+-- Amounts (u64 on PostgreSQL) are TEXT zero padded to 20 digits, so comparing the text compares the numbers.
+
+CREATE TABLE IF NOT EXISTS blocks (
+    hash TEXT PRIMARY KEY,
+    height BIGINT UNIQUE NOT NULL,
+    last_block_hash TEXT,
+    time DATETIME NOT NULL,
+    total_txs BIGINT,
+    num_txs BIGINT
+);
+
+CREATE TABLE IF NOT EXISTS transactions (
+    hash          TEXT PRIMARY KEY,
+    block_height  BIGINT NOT NULL REFERENCES blocks(height) ON DELETE CASCADE,
+    tx_index      INT NOT NULL,                -- GraphQL index ↔ tx_index mapping
+    success       BOOLEAN NOT NULL DEFAULT FALSE,
+    gas_wanted    BIGINT,
+    gas_used      BIGINT,
+    gas_fee       TEXT,
+    memo          TEXT,
+    content_raw   TEXT,
+    messages_json TEXT,
+    response_json TEXT,
+    UNIQUE (block_height, tx_index)
+);
+
+CREATE TABLE IF NOT EXISTS tx_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    tx_hash TEXT NOT NULL REFERENCES transactions(hash) ON DELETE CASCADE,
+    event_index INT NOT NULL,
+    type TEXT NOT NULL,
+    func TEXT,
+    pkg_path TEXT,
+    UNIQUE (tx_hash, event_index)
+);
+
+CREATE TABLE IF NOT EXISTS tx_event_attrs (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    event_id BIGINT NOT NULL REFERENCES tx_events(id) ON DELETE CASCADE,
+    attr_index INT NOT NULL,
+    key TEXT NOT NULL,
+    value TEXT,
+    UNIQUE (event_id, attr_index)
+);
+
+CREATE TABLE IF NOT EXISTS tokens (
+    token_path TEXT PRIMARY KEY,
+    symbol TEXT,
+    decimals INT,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS transfers (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    tx_hash TEXT NOT NULL REFERENCES transactions(hash) ON DELETE CASCADE,
+    event_index INT NOT NULL,
+    token_path TEXT NOT NULL REFERENCES tokens(token_path) ON DELETE RESTRICT,
+    from_address TEXT,
+    to_address TEXT,
+    amount TEXT NOT NULL,
+    block_height BIGINT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (tx_hash, event_index)
+);
+
+CREATE TABLE IF NOT EXISTS balances (
+    address TEXT NOT NULL,
+    token_path TEXT NOT NULL REFERENCES tokens(token_path) ON DELETE RESTRICT,
+    amount TEXT NOT NULL,
+    last_tx_hash TEXT,
+    last_block_h BIGINT,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (address, token_path)
+);
+
+CREATE TABLE IF NOT EXISTS app_state (
+    component TEXT PRIMARY KEY,   -- 'block_sync' | 'event_consumer'
+    last_block_h BIGINT,
+    last_tx_hash TEXT,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_allowances_token;
+DROP INDEX IF EXISTS idx_allowances_spender;
+DROP TABLE IF EXISTS allowances;
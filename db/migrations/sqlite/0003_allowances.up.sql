@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS allowances (
+    owner TEXT NOT NULL,
+    spender TEXT NOT NULL,
+    token_path TEXT NOT NULL REFERENCES tokens(token_path) ON DELETE RESTRICT,
+    amount TEXT NOT NULL,
+    last_tx_hash TEXT,
+    last_block_h BIGINT,
+    last_event_index INT NOT NULL DEFAULT 0, -- older (height, index) approvals are ignored
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (owner, spender, token_path)
+);
+
+CREATE INDEX IF NOT EXISTS idx_allowances_spender ON allowances(spender);
+CREATE INDEX IF NOT EXISTS idx_allowances_token ON allowances(token_path);
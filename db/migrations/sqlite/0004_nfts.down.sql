@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_nft_owners_owner;
+DROP INDEX IF EXISTS idx_nft_transfers_collection;
+DROP INDEX IF EXISTS idx_nft_transfers_token;
+DROP TABLE IF EXISTS nft_owners;
+DROP TABLE IF EXISTS nft_transfers;
@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS nft_transfers (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    tx_hash TEXT NOT NULL REFERENCES transactions(hash) ON DELETE CASCADE,
+    event_index INT NOT NULL,
+    collection_path TEXT NOT NULL,
+    token_id TEXT NOT NULL,
+    from_address TEXT,
+    to_address TEXT,
+    block_height BIGINT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (tx_hash, event_index)
+);
+
+CREATE TABLE IF NOT EXISTS nft_owners (
+    collection_path TEXT NOT NULL,
+    token_id TEXT NOT NULL,
+    owner TEXT NOT NULL,             -- empty after burn
+    last_tx_hash TEXT,
+    last_block_h BIGINT,
+    last_event_index INT NOT NULL DEFAULT 0, -- older (height, index) events are ignored
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (collection_path, token_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_nft_transfers_token ON nft_transfers(collection_path, token_id, block_height);
+CREATE INDEX IF NOT EXISTS idx_nft_transfers_collection ON nft_transfers(collection_path, block_height DESC);
+CREATE INDEX IF NOT EXISTS idx_nft_owners_owner ON nft_owners(owner);
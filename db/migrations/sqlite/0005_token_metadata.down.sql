@@ -0,0 +1,3 @@
+ALTER TABLE tokens DROP COLUMN metadata_updated_at;
+ALTER TABLE tokens DROP COLUMN total_supply;
+ALTER TABLE tokens DROP COLUMN name;
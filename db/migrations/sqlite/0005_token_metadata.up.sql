@@ -0,0 +1,3 @@
+ALTER TABLE tokens ADD COLUMN name TEXT;
+ALTER TABLE tokens ADD COLUMN total_supply TEXT;
+ALTER TABLE tokens ADD COLUMN metadata_updated_at DATETIME;
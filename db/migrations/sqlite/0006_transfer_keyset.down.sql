@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_transfers_to_keyset;
+DROP INDEX IF EXISTS idx_transfers_from_keyset;
+DROP INDEX IF EXISTS idx_transfers_keyset;
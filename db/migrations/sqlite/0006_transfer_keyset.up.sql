@@ -0,0 +1,3 @@
+CREATE INDEX IF NOT EXISTS idx_transfers_keyset ON transfers(block_height DESC, event_index DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_transfers_from_keyset ON transfers(from_address, block_height DESC, event_index DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_transfers_to_keyset ON transfers(to_address, block_height DESC, event_index DESC, id DESC);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_balances_token_amount;
@@ -0,0 +1,18 @@
+-- Rows store the signed change of an event instead of the running balance,
+-- a sum of changes does not depend on the order events were indexed in
+CREATE TABLE IF NOT EXISTS balance_history (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    address TEXT NOT NULL,
+    token_path TEXT NOT NULL REFERENCES tokens(token_path) ON DELETE RESTRICT,
+    delta INTEGER NOT NULL,          -- signed change of the balance made by the event
+    tx_hash TEXT NOT NULL,
+    event_index INT NOT NULL,
+    block_height BIGINT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (token_path, address, tx_hash, event_index)
+);
+
+CREATE INDEX IF NOT EXISTS idx_balance_history_address ON balance_history(address, token_path, block_height DESC, event_index DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_balance_history_token ON balance_history(token_path, block_height DESC, event_index DESC, id DESC);
+
+-- Unlike PostgreSQL there is no backfill from transfers, SQLite cannot sum the amounts exactly: reindex an existing database instead
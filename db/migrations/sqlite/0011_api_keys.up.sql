@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS api_keys (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    key_prefix TEXT NOT NULL,        -- first characters, shown in listings
+    key_hash TEXT NOT NULL UNIQUE,   -- hex SHA-256 of the key, the key itself is returned once
+    rate_limit REAL NOT NULL,
+    burst INT NOT NULL,
+    daily_quota BIGINT NOT NULL DEFAULT 0, -- 0 means unlimited
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    revoked_at DATETIME
+);
+
+CREATE TABLE IF NOT EXISTS api_key_usage (
+    api_key_id BIGINT NOT NULL REFERENCES api_keys(id) ON DELETE CASCADE,
+    day DATE NOT NULL,               -- UTC
+    requests BIGINT NOT NULL DEFAULT 0,
+    rejected BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (api_key_id, day)
+);
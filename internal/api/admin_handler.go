@@ -0,0 +1,168 @@
+package api
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gn-indexer/internal/domain"
+	"gn-indexer/internal/repository"
+	"gn-indexer/internal/service"
+	"gn-indexer/internal/types"
+	"net/http"
+	"strconv"
+)
+
+// AdminHandler handles API key administration requests
+type AdminHandler struct {
+	apiKeyService *service.APIKeyService
+}
+
+// NewAdminHandler creates a new admin handler
+func NewAdminHandler(apiKeyService *service.APIKeyService) *AdminHandler {
+	return &AdminHandler{
+		apiKeyService: apiKeyService,
+	}
+}
+
+// CreateAPIKey handles POST /admin/keys
+func (h *AdminHandler) CreateAPIKey(c *gin.Context) {
+	var body types.APIKeyRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		writeError(c, http.StatusBadRequest, ErrCodeInvalidRequest, "invalid request body: "+err.Error())
+		return
+	}
+
+	secret, key, err := h.apiKeyService.Issue(c.Request.Context(), service.APIKeyRequest{
+		Name:       body.Name,
+		RateLimit:  body.RateLimit,
+		Burst:      body.Burst,
+		DailyQuota: body.DailyQuota,
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidAPIKeyRequest) {
+			writeError(c, http.StatusBadRequest, ErrCodeInvalidRequest, err.Error())
+			return
+		}
+		writeError(c, http.StatusInternalServerError, ErrCodeInternal, "failed to issue api key: "+err.Error())
+		return
+	}
+
+	// The key is only shown once, only its hash is stored
+	response := toAPIKeyResponse(key)
+	response.Key = secret
+	c.JSON(http.StatusCreated, response)
+}
+
+// GetAPIKeys handles GET /admin/keys
+func (h *AdminHandler) GetAPIKeys(c *gin.Context) {
+	keys, err := h.apiKeyService.List(c.Request.Context())
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, ErrCodeInternal, "failed to get api keys: "+err.Error())
+		return
+	}
+
+	responses := make([]types.APIKeyResponse, 0, len(keys))
+	for _, key := range keys {
+		responses = append(responses, toAPIKeyResponse(key))
+	}
+
+	c.JSON(http.StatusOK, types.APIKeyListResponse{Keys: responses})
+}
+
+// GetAPIKey handles GET /admin/keys/:id
+func (h *AdminHandler) GetAPIKey(c *gin.Context) {
+	id, ok := apiKeyID(c)
+	if !ok {
+		return
+	}
+
+	key, err := h.apiKeyService.Get(c.Request.Context(), id)
+	if err != nil {
+		writeAPIKeyError(c, id, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toAPIKeyResponse(key))
+}
+
+// RevokeAPIKey handles DELETE /admin/keys/:id, the key is kept for its usage history
+func (h *AdminHandler) RevokeAPIKey(c *gin.Context) {
+	id, ok := apiKeyID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.apiKeyService.Revoke(c.Request.Context(), id); err != nil {
+		writeAPIKeyError(c, id, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetAPIKeyUsage handles GET /admin/keys/:id/usage?days={days}
+func (h *AdminHandler) GetAPIKeyUsage(c *gin.Context) {
+	id, ok := apiKeyID(c)
+	if !ok {
+		return
+	}
+
+	days, err := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if err != nil || days < 1 || days > 366 {
+		days = 30
+	}
+
+	if _, err := h.apiKeyService.Get(c.Request.Context(), id); err != nil {
+		writeAPIKeyError(c, id, err)
+		return
+	}
+
+	usage, err := h.apiKeyService.GetUsage(c.Request.Context(), id, days)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, ErrCodeInternal, "failed to get api key usage: "+err.Error())
+		return
+	}
+
+	records := make([]types.APIKeyUsageRecord, 0, len(usage))
+	for _, u := range usage {
+		records = append(records, types.APIKeyUsageRecord{
+			Day:      u.Day.Format("2006-01-02"),
+			Requests: u.Requests,
+			Rejected: u.Rejected,
+		})
+	}
+
+	c.JSON(http.StatusOK, types.APIKeyUsageResponse{KeyID: id, Usage: records})
+}
+
+// apiKeyID parses the :id path parameter, writing a 400 response when it is invalid
+func apiKeyID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		writeError(c, http.StatusBadRequest, ErrCodeInvalidRequest, "invalid api key id")
+		return 0, false
+	}
+	return id, true
+}
+
+// writeAPIKeyError maps repository errors to responses
+func writeAPIKeyError(c *gin.Context, id int64, err error) {
+	if errors.Is(err, repository.ErrAPIKeyNotFound) {
+		writeErrorDetails(c, http.StatusNotFound, ErrCodeNotFound, "api key not found", map[string]interface{}{"id": id})
+		return
+	}
+	writeError(c, http.StatusInternalServerError, ErrCodeInternal, "failed to get api key: "+err.Error())
+}
+
+// toAPIKeyResponse converts a key into its API representation without the secret
+func toAPIKeyResponse(key *domain.APIKey) types.APIKeyResponse {
+	return types.APIKeyResponse{
+		ID:         key.ID,
+		Name:       key.Name,
+		KeyPrefix:  key.KeyPrefix,
+		RateLimit:  key.RateLimit,
+		Burst:      key.Burst,
+		DailyQuota: key.DailyQuota,
+		CreatedAt:  key.CreatedAt,
+		RevokedAt:  key.RevokedAt,
+	}
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"gn-indexer/internal/repository"
+	"gn-indexer/internal/types"
+	"net/http"
+)
+
+// AllowanceHandler handles allowance-related API requests
+type AllowanceHandler struct {
+	allowanceRepo repository.AllowanceRepository
+}
+
+// NewAllowanceHandler creates a new allowance handler
+func NewAllowanceHandler(allowanceRepo repository.AllowanceRepository) *AllowanceHandler {
+	return &AllowanceHandler{
+		allowanceRepo: allowanceRepo,
+	}
+}
+
+// GetAllowances handles GET /tokens/allowances?owner={address}&spender={address}&tokenPath={tokenPath}
+// owner lists allowances granted by an address, spender lists allowances received by it.
+// Optional: tokenPath narrows down to one token, page and limit select the page
+func (h *AllowanceHandler) GetAllowances(c *gin.Context) {
+	page, limit := parsePage(c)
+	query := repository.AllowanceQuery{
+		Owner:     c.Query("owner"),
+		Spender:   c.Query("spender"),
+		TokenPath: c.Query("tokenPath"),
+		Offset:    (page - 1) * limit,
+		Limit:     limit,
+	}
+	if query.Owner == "" && query.Spender == "" {
+		writeError(c, http.StatusBadRequest, ErrCodeInvalidRequest, "owner or spender parameter is required")
+		return
+	}
+
+	allowances, total, err := h.allowanceRepo.ListAllowances(c.Request.Context(), query)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, ErrCodeInternal, "failed to get allowances: "+err.Error())
+		return
+	}
+
+	records := make([]types.AllowanceRecord, 0, len(allowances))
+	for _, allowance := range allowances {
+		amount := int64(0)
+		if allowance.Amount != nil {
+			amount = allowance.Amount.Int64()
+		}
+		records = append(records, types.AllowanceRecord{
+			Owner:     allowance.Owner,
+			Spender:   allowance.Spender,
+			TokenPath: allowance.TokenPath,
+			Amount:    amount,
+		})
+	}
+
+	c.JSON(http.StatusOK, types.AllowanceResponse{
+		Allowances: records,
+		Pagination: &types.Pagination{Page: page, Limit: limit, Total: total},
+	})
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"gn-indexer/internal/domain"
+	"gn-indexer/internal/repository"
+	"gn-indexer/internal/types"
+)
+
+// formatDecimal is the query value that enables decimal-formatted amounts
+const formatDecimal = "decimal"
+
+// amountFormatter renders raw amounts with token decimals, caching token lookups per request
+type amountFormatter struct {
+	ctx       context.Context
+	tokenRepo repository.TokenRepository
+	tokens    map[string]*domain.Token
+}
+
+// newAmountFormatter returns a formatter when ?format=decimal is set, nil for raw output
+// ok is false when the format value is not supported
+func newAmountFormatter(c *gin.Context, tokenRepo repository.TokenRepository) (formatter *amountFormatter, ok bool) {
+	switch c.Query("format") {
+	case "", "raw":
+		return nil, true
+	case formatDecimal:
+		return &amountFormatter{
+			ctx:       c.Request.Context(),
+			tokenRepo: tokenRepo,
+			tokens:    make(map[string]*domain.Token),
+		}, true
+	default:
+		return nil, false
+	}
+}
+
+// Format returns the decimal fields for an amount, nil formatter returns empty fields
+func (f *amountFormatter) Format(tokenPath string, amount *domain.U64) types.DecimalAmount {
+	if f == nil {
+		return types.DecimalAmount{}
+	}
+
+	token := f.token(tokenPath)
+	decimals := token.Decimals
+
+	formatted := "0"
+	if amount != nil {
+		formatted = amount.FormatDecimal(decimals)
+	}
+
+	return types.DecimalAmount{
+		AmountDecimal: formatted,
+		Symbol:        token.Symbol,
+		Decimals:      &decimals,
+	}
+}
+
+// token loads token metadata once per path, unknown tokens are treated as 0 decimals
+func (f *amountFormatter) token(tokenPath string) *domain.Token {
+	if token, ok := f.tokens[tokenPath]; ok {
+		return token
+	}
+
+	token, err := f.tokenRepo.GetByPath(f.ctx, tokenPath)
+	if err != nil {
+		logger.WarnContext(f.ctx, "token not found, using raw amount", "token", tokenPath, "error", err)
+		token = &domain.Token{Path: tokenPath}
+	}
+	f.tokens[tokenPath] = token
+	return token
+}
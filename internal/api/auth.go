@@ -0,0 +1,170 @@
+package api
+
+import (
+	"crypto/subtle"
+	"errors"
+	"gn-indexer/internal/domain"
+	"gn-indexer/internal/service"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyHeader carries the API key, clients that cannot set headers (browser WebSockets) use the api_key query parameter
+const APIKeyHeader = "X-API-Key"
+
+// publicPaths are served without an API key
+var publicPaths = map[string]bool{
+	"/health":       true,
+	"/healthz":      true,
+	"/readyz":       true,
+	"/openapi.json": true,
+	"/metrics":      true,
+}
+
+// AuthConfig configures API key authentication and the admin endpoints issuing keys
+type AuthConfig struct {
+	Keys       *service.APIKeyService
+	Required   bool   // reject requests without a valid key, otherwise the API stays open
+	AdminToken string // bearer token of the /admin endpoints, empty disables them
+}
+
+// tokenBucket refills rate tokens per second up to burst, each request takes one
+type tokenBucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// take removes a token if one is available, otherwise returns how long until the next one
+func (b *tokenBucket) take(now time.Time) (bool, time.Duration) {
+	b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	return false, time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+}
+
+// APIKeyAuth authenticates requests by API key and applies the per-key rate limit and daily quota
+type APIKeyAuth struct {
+	keys *service.APIKeyService
+
+	mu      sync.Mutex
+	buckets map[int64]*tokenBucket
+}
+
+// NewAPIKeyAuth creates a new API key authenticator
+func NewAPIKeyAuth(keys *service.APIKeyService) *APIKeyAuth {
+	return &APIKeyAuth{keys: keys, buckets: make(map[int64]*tokenBucket)}
+}
+
+// Middleware rejects requests without a valid key (401), over the rate limit or over the quota (429).
+// Public paths and the admin endpoints, which have their own token, are skipped.
+func (a *APIKeyAuth) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		path := c.Request.URL.Path
+		if publicPaths[path] || strings.HasPrefix(path, "/admin/") {
+			c.Next()
+			return
+		}
+
+		secret := c.GetHeader(APIKeyHeader)
+		if secret == "" {
+			// read the URL directly, c.Query would cache the raw query before RequestValidator normalizes it
+			secret = c.Request.URL.Query().Get("api_key")
+		}
+		key, err := a.keys.Authenticate(c.Request.Context(), secret)
+		if err != nil {
+			if errors.Is(err, service.ErrInvalidAPIKey) {
+				c.Header("WWW-Authenticate", `ApiKey header="`+APIKeyHeader+`"`)
+				writeError(c, http.StatusUnauthorized, ErrCodeUnauthorized, "missing or invalid API key")
+				return
+			}
+			writeError(c, http.StatusInternalServerError, ErrCodeInternal, "failed to authenticate: "+err.Error())
+			return
+		}
+
+		if ok, wait := a.take(key); !ok {
+			a.keys.RecordRejected(c.Request.Context(), key)
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			writeErrorDetails(c, http.StatusTooManyRequests, ErrCodeRateLimited, "rate limit exceeded", map[string]interface{}{
+				"limit": key.RateLimit,
+				"burst": key.Burst,
+			})
+			return
+		}
+
+		remaining, err := a.keys.ConsumeQuota(c.Request.Context(), key)
+		if err != nil {
+			if errors.Is(err, service.ErrQuotaExceeded) {
+				c.Header("Retry-After", strconv.Itoa(int(time.Until(nextUTCDay()).Seconds())+1))
+				writeErrorDetails(c, http.StatusTooManyRequests, ErrCodeQuotaExceeded, "daily quota exceeded", map[string]interface{}{
+					"quota": key.DailyQuota,
+				})
+				return
+			}
+			writeError(c, http.StatusInternalServerError, ErrCodeInternal, "failed to check quota: "+err.Error())
+			return
+		}
+		if remaining >= 0 {
+			c.Header("X-Quota-Remaining", strconv.FormatInt(remaining, 10))
+		}
+
+		c.Set("apiKey", key)
+		c.Next()
+	}
+}
+
+// requestOwner returns the ID of the API key of the request, webhooks and snapshot jobs are only visible to the key that created them.
+// It is nil when the API runs without API keys.
+func requestOwner(c *gin.Context) *int64 {
+	if key, ok := c.Get("apiKey"); ok {
+		if apiKey, ok := key.(*domain.APIKey); ok {
+			return &apiKey.ID
+		}
+	}
+	return nil
+}
+
+// take applies the token bucket of a key
+func (a *APIKeyAuth) take(key *domain.APIKey) (bool, time.Duration) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := time.Now()
+	bucket, ok := a.buckets[key.ID]
+	if !ok {
+		bucket = &tokenBucket{rate: key.RateLimit, burst: float64(key.Burst), tokens: float64(key.Burst), last: now}
+		a.buckets[key.ID] = bucket
+	}
+	return bucket.take(now)
+}
+
+// nextUTCDay returns the start of the next UTC day, when daily quotas reset
+func nextUTCDay() time.Time {
+	return time.Now().UTC().Truncate(24 * time.Hour).Add(24 * time.Hour)
+}
+
+// requireAdmin checks the bearer token of the admin endpoints
+func requireAdmin(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" {
+			writeError(c, http.StatusForbidden, ErrCodeForbidden, "admin API is disabled, set ADMIN_TOKEN to enable it")
+			return
+		}
+		given := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			writeError(c, http.StatusUnauthorized, ErrCodeUnauthorized, "missing or invalid admin token")
+			return
+		}
+		c.Next()
+	}
+}
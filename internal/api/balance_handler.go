@@ -1,14 +1,15 @@
 package api
 
 import (
+	"fmt"
 	"github.com/gin-gonic/gin"
+	"gn-indexer/internal/domain"
 	"gn-indexer/internal/repository"
 	"gn-indexer/internal/types"
 	"net/http"
-	"net/url"
-	"regexp"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // BalanceHandler handles balance-related API requests
@@ -16,6 +17,8 @@ type BalanceHandler struct {
 	balanceRepo  repository.BalanceRepository
 	tokenRepo    repository.TokenRepository
 	transferRepo repository.TransferRepository
+	historyRepo  repository.BalanceHistoryRepository
+	blockRepo    repository.BlockRepository
 }
 
 // NewBalanceHandler creates a new balance handler
@@ -23,56 +26,46 @@ func NewBalanceHandler(
 	balanceRepo repository.BalanceRepository,
 	tokenRepo repository.TokenRepository,
 	transferRepo repository.TransferRepository,
+	historyRepo repository.BalanceHistoryRepository,
+	blockRepo repository.BlockRepository,
 ) *BalanceHandler {
 	return &BalanceHandler{
 		balanceRepo:  balanceRepo,
 		tokenRepo:    tokenRepo,
 		transferRepo: transferRepo,
+		historyRepo:  historyRepo,
+		blockRepo:    blockRepo,
 	}
 }
 
 // GetBalancesByAddress handles GET /tokens/balances?address={address}
+// Optional: sort (amount|address), min_amount, exclude_zero, page, limit and at_height or at_time
 func (h *BalanceHandler) GetBalancesByAddress(c *gin.Context) {
-	address := c.Query("address")
-
-	if address == "" {
-		// Get all balances for all addresses
-		balances, err := h.balanceRepo.GetAllBalances(c.Request.Context())
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "failed to get all balances: " + err.Error(),
-			})
-			return
-		}
-
-		responseBalances := make([]types.TokenBalance, 0, len(balances))
-		for _, balance := range balances {
-			amount := int64(0)
-			if balance.Amount != nil {
-				amount = balance.Amount.Int64()
-			}
-			responseBalances = append(responseBalances, types.TokenBalance{
-				TokenPath: balance.TokenPath,
-				Amount:    amount,
-			})
-		}
+	formatter, ok := newAmountFormatter(c, h.tokenRepo)
+	if !ok {
+		writeError(c, http.StatusBadRequest, ErrCodeInvalidRequest, "invalid format, expected raw or decimal")
+		return
+	}
 
-		response := types.BalanceResponse{
-			Balances: responseBalances,
-		}
+	query, pagination, err := parseBalanceQuery(c)
+	if err != nil {
+		writeError(c, http.StatusBadRequest, ErrCodeInvalidRequest, err.Error())
+		return
+	}
+	query.Address = c.Query("address")
 
-		c.JSON(http.StatusOK, response)
+	atHeight, err := h.parseAtHeight(c)
+	if err != nil {
+		writeError(c, http.StatusBadRequest, ErrCodeInvalidRequest, err.Error())
 		return
 	}
 
-	// Get balances for specific address
-	balances, err := h.balanceRepo.GetBalancesByAddress(c.Request.Context(), address)
+	balances, total, err := h.listBalances(c, query, atHeight)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "failed to get balances: " + err.Error(),
-		})
+		writeError(c, http.StatusInternalServerError, ErrCodeInternal, "failed to get balances: "+err.Error())
 		return
 	}
+	pagination.Total = total
 
 	responseBalances := make([]types.TokenBalance, 0, len(balances))
 	for _, balance := range balances {
@@ -81,97 +74,205 @@ func (h *BalanceHandler) GetBalancesByAddress(c *gin.Context) {
 			amount = balance.Amount.Int64()
 		}
 		responseBalances = append(responseBalances, types.TokenBalance{
-			TokenPath: balance.TokenPath,
-			Amount:    amount,
+			TokenPath:     balance.TokenPath,
+			Amount:        amount,
+			DecimalAmount: formatter.Format(balance.TokenPath, balance.Amount),
 		})
 	}
 
 	response := types.BalanceResponse{
-		Balances: responseBalances,
+		Balances:   responseBalances,
+		Pagination: pagination,
+		AtHeight:   atHeight,
 	}
 
 	c.JSON(http.StatusOK, response)
 }
 
-// precompile once at package level (optional but nice)
-var reTokenBalances = regexp.MustCompile(`^/tokens/(.+)/balances$`)
+// parseBalanceQuery reads the paging, sorting and filtering parameters of the balance endpoints
+func parseBalanceQuery(c *gin.Context) (repository.BalanceQuery, *types.Pagination, error) {
+	page, limit := parsePage(c)
+	query := repository.BalanceQuery{
+		Sort:   c.DefaultQuery("sort", repository.BalanceSortAmount),
+		Offset: (page - 1) * limit,
+		Limit:  limit,
+	}
+	pagination := &types.Pagination{Page: page, Limit: limit}
 
-// GetBalancesByTokenAndAddress handles GET /tokens/{tokenPath}/balances?address={address}
-func (h *BalanceHandler) GetBalancesByTokenAndAddress(c *gin.Context) {
-	raw := c.Param("tokenPath")
+	if query.Sort != repository.BalanceSortAmount && query.Sort != repository.BalanceSortAddress {
+		return query, nil, fmt.Errorf("invalid sort, expected amount or address")
+	}
+
+	if value := c.Query("min_amount"); value != "" {
+		minAmount, err := domain.NewU64FromString(value)
+		if err != nil || minAmount.Sign() < 0 {
+			return query, nil, fmt.Errorf("invalid min_amount")
+		}
+		query.MinAmount = minAmount
+	}
+
+	if value := c.Query("exclude_zero"); value != "" {
+		excludeZero, err := strconv.ParseBool(value)
+		if err != nil {
+			return query, nil, fmt.Errorf("invalid exclude_zero, expected true or false")
+		}
+		query.ExcludeZero = excludeZero
+	}
+
+	return query, pagination, nil
+}
 
-	// Example raw path: /tokens/gno.land/r/demo/wugnot/balances
-	if raw == "" {
-		path := c.Request.URL.Path
-		// Normalize backslashes if any (defensive)
-		path = strings.ReplaceAll(path, `\`, `/`)
+// parsePage reads the page (from 1) and limit (default 50, at most 500) of a page/limit endpoint
+func parsePage(c *gin.Context) (int, int) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 || limit > 500 {
+		limit = 50
+	}
+	return page, limit
+}
 
-		if m := reTokenBalances.FindStringSubmatch(path); len(m) == 2 {
-			raw = m[1]
+// parseAtHeight resolves at_height or at_time to a block height, nil means the current balances
+func (h *BalanceHandler) parseAtHeight(c *gin.Context) (*int64, error) {
+	atHeight, atTime := c.Query("at_height"), c.Query("at_time")
+	if atHeight == "" && atTime == "" {
+		return nil, nil
+	}
+	if atHeight != "" && atTime != "" {
+		return nil, fmt.Errorf("at_height and at_time cannot be combined")
+	}
+	if h.historyRepo == nil {
+		return nil, fmt.Errorf("historical balances are not available")
+	}
+
+	if atHeight != "" {
+		height, err := strconv.ParseInt(atHeight, 10, 64)
+		if err != nil || height < 0 {
+			return nil, fmt.Errorf("invalid at_height")
 		}
+		return &height, nil
 	}
 
-	// URL-decode in case client sent %2F etc.
-	tokenPath, err := url.PathUnescape(raw)
+	at, err := time.Parse(time.RFC3339, atTime)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid tokenPath: " + err.Error()})
-		return
+		return nil, fmt.Errorf("invalid at_time, expected RFC3339")
 	}
-	tokenPath = strings.Trim(tokenPath, "/")
-	if tokenPath == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "tokenPath parameter is required"})
-		return
+	height, err := h.blockRepo.GetHeightAtTime(c.Request.Context(), at)
+	if err != nil {
+		return nil, err
 	}
+	return &height, nil
+}
+
+// listBalances reads current balances, or historical ones when atHeight is set
+func (h *BalanceHandler) listBalances(c *gin.Context, query repository.BalanceQuery, atHeight *int64) ([]*domain.Balance, int64, error) {
+	if atHeight != nil {
+		return h.historyRepo.ListBalancesAt(c.Request.Context(), *atHeight, query)
+	}
+	return h.balanceRepo.ListBalances(c.Request.Context(), query)
+}
 
-	// (Optional) sanity check for allowed characters
-	if !regexp.MustCompile(`^[A-Za-z0-9._\-/]+$`).MatchString(tokenPath) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid tokenPath format"})
+// GetBalancesByTokenAndAddress handles GET /tokens/{tokenPath}/balances?address={address}
+// tokenPath is injected by the server's route fallback because realm paths contain '/'
+func (h *BalanceHandler) GetBalancesByTokenAndAddress(c *gin.Context) {
+	tokenPath := strings.Trim(c.Param("tokenPath"), "/")
+	if tokenPath == "" {
+		writeError(c, http.StatusBadRequest, ErrCodeInvalidParameter, "tokenPath parameter is required")
 		return
 	}
 
 	// Read optional address query
 	address := c.Query("address")
 
-	// Case A: no address → all balances for the token
+	formatter, ok := newAmountFormatter(c, h.tokenRepo)
+	if !ok {
+		writeError(c, http.StatusBadRequest, ErrCodeInvalidRequest, "invalid format, expected raw or decimal")
+		return
+	}
+
+	atHeight, err := h.parseAtHeight(c)
+	if err != nil {
+		writeError(c, http.StatusBadRequest, ErrCodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Case A: no address → ranked page of holders for the token
 	if address == "" {
-		balances, err := h.balanceRepo.GetBalancesByTokenAndAddress(c.Request.Context(), tokenPath)
+		query, pagination, err := parseBalanceQuery(c)
+		if err != nil {
+			writeError(c, http.StatusBadRequest, ErrCodeInvalidRequest, err.Error())
+			return
+		}
+		query.TokenPath = tokenPath
+
+		balances, total, err := h.listBalances(c, query, atHeight)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get balances: " + err.Error()})
+			writeError(c, http.StatusInternalServerError, ErrCodeInternal, "failed to get balances: "+err.Error())
 			return
 		}
+		pagination.Total = total
 
 		accountBalances := make([]types.AccountBalance, 0, len(balances))
-		for _, balance := range balances {
+		for i, balance := range balances {
 			amount := int64(0)
 			if balance.Amount != nil {
 				amount = balance.Amount.Int64()
 			}
-			accountBalances = append(accountBalances, types.AccountBalance{
-				Address:   balance.Address,
-				TokenPath: balance.TokenPath, // or tokenPath to normalize
-				Amount:    amount,
-			})
+			accountBalance := types.AccountBalance{
+				Address:       balance.Address,
+				TokenPath:     balance.TokenPath, // or tokenPath to normalize
+				Amount:        amount,
+				DecimalAmount: formatter.Format(balance.TokenPath, balance.Amount),
+			}
+			if query.Sort == repository.BalanceSortAmount {
+				accountBalance.Rank = query.Offset + i + 1
+			}
+			accountBalances = append(accountBalances, accountBalance)
 		}
 
 		response := types.AccountBalanceResponse{
 			AccountBalances: accountBalances,
+			Pagination:      pagination,
+			AtHeight:        atHeight,
+		}
+
+		// The holder summary reflects current balances only
+		if atHeight == nil {
+			summary, err := h.balanceRepo.GetTokenHolderSummary(c.Request.Context(), tokenPath)
+			if err != nil {
+				writeError(c, http.StatusInternalServerError, ErrCodeInternal, "failed to get holder summary: "+err.Error())
+				return
+			}
+			response.Summary = &types.HolderSummary{
+				HolderCount:   summary.HolderCount,
+				TotalSupply:   summary.TotalSupply.String(),
+				DecimalAmount: formatter.Format(tokenPath, summary.TotalSupply),
+			}
 		}
 		c.JSON(http.StatusOK, response)
 		return
 	}
 
 	// Case B: address provided → single balance for (tokenPath, address)
-	balance, err := h.balanceRepo.GetBalance(c.Request.Context(), tokenPath, address)
+	var balance *domain.Balance
+	if atHeight != nil {
+		balance, err = h.historyRepo.GetBalanceAt(c.Request.Context(), tokenPath, address, *atHeight)
+	} else {
+		balance, err = h.balanceRepo.GetBalance(c.Request.Context(), tokenPath, address)
+	}
 	if err != nil {
 		if err == repository.ErrBalanceNotFound {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error":     "balance not found",
+			writeErrorDetails(c, http.StatusNotFound, ErrCodeNotFound, "balance not found", map[string]interface{}{
 				"tokenPath": tokenPath,
 				"address":   address,
 			})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get balance: " + err.Error()})
+		writeError(c, http.StatusInternalServerError, ErrCodeInternal, "failed to get balance: "+err.Error())
 		return
 	}
 
@@ -182,90 +283,135 @@ func (h *BalanceHandler) GetBalancesByTokenAndAddress(c *gin.Context) {
 
 	response := types.AccountBalanceResponse{
 		AccountBalances: []types.AccountBalance{{
-			Address:   balance.Address,
-			TokenPath: balance.TokenPath, // or tokenPath to normalize
-			Amount:    amount,
+			Address:       balance.Address,
+			TokenPath:     balance.TokenPath, // or tokenPath to normalize
+			Amount:        amount,
+			DecimalAmount: formatter.Format(balance.TokenPath, balance.Amount),
 		}},
+		AtHeight: atHeight,
 	}
 	c.JSON(http.StatusOK, response)
 }
 
 // GetTransferHistory handles GET /tokens/transfer-history?address={address}
+// Optional filters: token_path, direction (in|out), counterparty, from_height, to_height,
+// from_time, to_time (RFC3339), min_amount, max_amount, cursor and limit
 func (h *BalanceHandler) GetTransferHistory(c *gin.Context) {
-	address := c.Query("address")
-
-	if address == "" {
-		// Get all transfer history
-		transfers, err := h.transferRepo.GetAll(c.Request.Context())
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "failed to get all transfer history: " + err.Error(),
-			})
-			return
-		}
-
-		responseTransfers := make([]types.TransferRecord, 0, len(transfers))
-		for _, transfer := range transfers {
-			amount := int64(0)
-			if transfer.Amount != nil {
-				amount = transfer.Amount.Int64()
-			}
-			responseTransfers = append(responseTransfers, types.TransferRecord{
-				FromAddress: transfer.FromAddress,
-				ToAddress:   transfer.ToAddress,
-				TokenPath:   transfer.TokenPath,
-				Amount:      amount,
-			})
-		}
-
-		response := types.TransferHistoryResponse{
-			Transfers: responseTransfers,
-		}
-
-		c.JSON(http.StatusOK, response)
+	formatter, ok := newAmountFormatter(c, h.tokenRepo)
+	if !ok {
+		writeError(c, http.StatusBadRequest, ErrCodeInvalidRequest, "invalid format, expected raw or decimal")
 		return
 	}
 
-	// Get page and limit parameters
-	pageStr := c.DefaultQuery("page", "1")
-	limitStr := c.DefaultQuery("limit", "20")
-
-	page, err := strconv.Atoi(pageStr)
-	if err != nil || page < 1 {
-		page = 1
+	filter, err := parseTransferFilter(c)
+	if err != nil {
+		writeError(c, http.StatusBadRequest, ErrCodeInvalidRequest, err.Error())
+		return
 	}
 
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil || limit < 1 || limit > 100 {
-		limit = 20
-	}
+	// Fetch one extra row to know whether another page exists
+	pageSize := filter.Limit
+	filter.Limit = pageSize + 1
 
-	// Transfer history for specific address
-	transfers, err := h.transferRepo.GetByAddress(c.Request.Context(), address)
+	transfers, err := h.transferRepo.List(c.Request.Context(), filter)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "failed to get transfer history: " + err.Error(),
-		})
+		writeError(c, http.StatusInternalServerError, ErrCodeInternal, "failed to get transfer history: "+err.Error())
 		return
 	}
 
-	responseTransfers := make([]types.TransferRecord, 0, len(transfers))
+	response := types.TransferHistoryResponse{}
+	if len(transfers) > pageSize {
+		transfers = transfers[:pageSize]
+		response.NextCursor, response.Next = nextPage(c, repository.NewTransferCursor(&transfers[len(transfers)-1]).Encode())
+	}
+
+	response.Transfers = toTransferRecords(transfers, formatter)
+
+	c.JSON(http.StatusOK, response)
+}
+
+// toTransferRecords converts transfers into their API representation
+func toTransferRecords(transfers []domain.Transfer, formatter *amountFormatter) []types.TransferRecord {
+	records := make([]types.TransferRecord, 0, len(transfers))
 	for _, transfer := range transfers {
 		amount := int64(0)
 		if transfer.Amount != nil {
 			amount = transfer.Amount.Int64()
 		}
-		responseTransfers = append(responseTransfers, types.TransferRecord{
-			FromAddress: transfer.FromAddress,
-			ToAddress:   transfer.ToAddress,
-			TokenPath:   transfer.TokenPath,
-			Amount:      amount,
+		records = append(records, types.TransferRecord{
+			FromAddress:   transfer.FromAddress,
+			ToAddress:     transfer.ToAddress,
+			TokenPath:     transfer.TokenPath,
+			Amount:        amount,
+			TxHash:        transfer.TxHash,
+			BlockHeight:   transfer.BlockHeight,
+			DecimalAmount: formatter.Format(transfer.TokenPath, transfer.Amount),
 		})
 	}
+	return records
+}
 
-	response := types.TransferHistoryResponse{
-		Transfers: responseTransfers,
+// parseTransferFilter builds a transfer filter from the query string
+func parseTransferFilter(c *gin.Context) (repository.TransferFilter, error) {
+	filter := repository.TransferFilter{
+		Address:      c.Query("address"),
+		Direction:    c.Query("direction"),
+		Counterparty: c.Query("counterparty"),
+		TokenPath:    c.Query("token_path"),
 	}
 
-	c.JSON(http.StatusOK, response)
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 20
+	}
+	filter.Limit = limit
+
+	switch filter.Direction {
+	case "", repository.TransferDirectionIn, repository.TransferDirectionOut:
+	default:
+		return filter, fmt.Errorf("invalid direction, expected in or out")
+	}
+	if filter.Address == "" && (filter.Direction != "" || filter.Counterparty != "") {
+		return filter, fmt.Errorf("direction and counterparty require the address parameter")
+	}
+
+	for name, target := range map[string]*int64{"from_height": &filter.FromHeight, "to_height": &filter.ToHeight} {
+		if value := c.Query(name); value != "" {
+			height, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || height < 0 {
+				return filter, fmt.Errorf("invalid %s", name)
+			}
+			*target = height
+		}
+	}
+
+	for name, target := range map[string]**time.Time{"from_time": &filter.FromTime, "to_time": &filter.ToTime} {
+		if value := c.Query(name); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return filter, fmt.Errorf("invalid %s, expected RFC3339", name)
+			}
+			*target = &t
+		}
+	}
+
+	for name, target := range map[string]**domain.U64{"min_amount": &filter.MinAmount, "max_amount": &filter.MaxAmount} {
+		if value := c.Query(name); value != "" {
+			amount, err := domain.NewU64FromString(value)
+			if err != nil || amount.Sign() < 0 {
+				return filter, fmt.Errorf("invalid %s", name)
+			}
+			*target = amount
+		}
+	}
+
+	if cursor := c.Query("cursor"); cursor != "" {
+		decoded, err := repository.DecodeTransferCursor(cursor)
+		if err != nil {
+			return filter, fmt.Errorf("invalid cursor")
+		}
+		filter.Cursor = decoded
+	}
+
+	return filter, nil
 }
@@ -0,0 +1,36 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"gn-indexer/internal/types"
+)
+
+// Error codes of the error envelope, documented in openapi.json
+const (
+	ErrCodeInvalidParameter = "INVALID_PARAMETER" // a parameter or body field does not match the OpenAPI document
+	ErrCodeInvalidRequest   = "INVALID_REQUEST"   // the request is well-formed but cannot be served as asked
+	ErrCodeUnauthorized     = "UNAUTHORIZED"      // missing or invalid API key or admin token
+	ErrCodeForbidden        = "FORBIDDEN"
+	ErrCodeNotFound         = "NOT_FOUND"
+	ErrCodeConflict         = "CONFLICT"
+	ErrCodeRateLimited      = "RATE_LIMITED"   // the key's token bucket is empty, retry after Retry-After
+	ErrCodeQuotaExceeded    = "QUOTA_EXCEEDED" // the key's daily quota is used up until the next UTC day
+	ErrCodeUnavailable      = "UNAVAILABLE"    // the server cannot take more work now, retry after Retry-After
+	ErrCodeInternal         = "INTERNAL_ERROR"
+)
+
+// writeError aborts the request with the error envelope
+func writeError(c *gin.Context, status int, code, message string) {
+	writeErrorDetails(c, status, code, message, nil)
+}
+
+// writeErrorDetails aborts the request with the error envelope and extra context such as the missing resource
+func writeErrorDetails(c *gin.Context, status int, code, message string, details map[string]interface{}) {
+	c.AbortWithStatusJSON(status, types.ErrorResponse{
+		Error: types.ErrorBody{
+			Code:    code,
+			Message: message,
+			Details: details,
+		},
+	})
+}
@@ -0,0 +1,374 @@
+package api
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gn-indexer/internal/domain"
+	"gn-indexer/internal/repository"
+	"gn-indexer/internal/types"
+	"net/http"
+	"strconv"
+)
+
+// ExplorerHandler handles block, transaction and event lookups
+type ExplorerHandler struct {
+	blockRepo     repository.BlockRepository
+	txRepo        repository.TransactionRepository
+	eventRepo     repository.EventRepository
+	eventAttrRepo repository.EventAttrRepository
+	transferRepo  repository.TransferRepository
+	tokenRepo     repository.TokenRepository
+}
+
+// NewExplorerHandler creates a new explorer handler
+func NewExplorerHandler(
+	blockRepo repository.BlockRepository,
+	txRepo repository.TransactionRepository,
+	eventRepo repository.EventRepository,
+	eventAttrRepo repository.EventAttrRepository,
+	transferRepo repository.TransferRepository,
+	tokenRepo repository.TokenRepository,
+) *ExplorerHandler {
+	return &ExplorerHandler{
+		blockRepo:     blockRepo,
+		txRepo:        txRepo,
+		eventRepo:     eventRepo,
+		eventAttrRepo: eventAttrRepo,
+		transferRepo:  transferRepo,
+		tokenRepo:     tokenRepo,
+	}
+}
+
+// GetBlocks handles GET /blocks?cursor={height}&limit={limit}, newest first
+func (h *ExplorerHandler) GetBlocks(c *gin.Context) {
+	limit := explorerLimit(c)
+
+	var before int64
+	if cursor := c.Query("cursor"); cursor != "" {
+		height, err := strconv.ParseInt(cursor, 10, 64)
+		if err != nil || height < 1 {
+			writeError(c, http.StatusBadRequest, ErrCodeInvalidRequest, "invalid cursor")
+			return
+		}
+		before = height
+	}
+
+	// Fetch one extra row to know whether another page exists
+	blocks, err := h.blockRepo.ListBlocks(c.Request.Context(), before, limit+1)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, ErrCodeInternal, "failed to get blocks: "+err.Error())
+		return
+	}
+
+	response := types.BlockListResponse{}
+	if len(blocks) > limit {
+		blocks = blocks[:limit]
+		response.NextCursor, response.Next = nextPage(c, strconv.Itoa(blocks[limit-1].Height))
+	}
+
+	response.Blocks = make([]types.BlockRecord, 0, len(blocks))
+	for i := range blocks {
+		response.Blocks = append(response.Blocks, toBlockRecord(&blocks[i]))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetLatestBlock handles GET /blocks/latest
+func (h *ExplorerHandler) GetLatestBlock(c *gin.Context) {
+	blocks, err := h.blockRepo.ListBlocks(c.Request.Context(), 0, 1)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, ErrCodeInternal, "failed to get latest block: "+err.Error())
+		return
+	}
+	if len(blocks) == 0 {
+		writeError(c, http.StatusNotFound, ErrCodeNotFound, "no blocks indexed yet")
+		return
+	}
+
+	c.JSON(http.StatusOK, toBlockRecord(&blocks[0]))
+}
+
+// GetBlock handles GET /blocks/:height
+func (h *ExplorerHandler) GetBlock(c *gin.Context) {
+	height, ok := blockHeight(c)
+	if !ok {
+		return
+	}
+
+	block, err := h.blockRepo.GetBlockByHeight(c.Request.Context(), height)
+	if err != nil {
+		if errors.Is(err, repository.ErrBlockNotFound) {
+			writeErrorDetails(c, http.StatusNotFound, ErrCodeNotFound, "block not found", map[string]interface{}{"height": height})
+			return
+		}
+		writeError(c, http.StatusInternalServerError, ErrCodeInternal, "failed to get block: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, toBlockRecord(block))
+}
+
+// GetBlockTransactions handles GET /blocks/:height/txs
+func (h *ExplorerHandler) GetBlockTransactions(c *gin.Context) {
+	height, ok := blockHeight(c)
+	if !ok {
+		return
+	}
+
+	txs, err := h.txRepo.GetTransactionsByBlockHeight(c.Request.Context(), height)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, ErrCodeInternal, "failed to get transactions: "+err.Error())
+		return
+	}
+
+	response := types.TransactionListResponse{Transactions: make([]types.TransactionRecord, 0, len(txs))}
+	for i := range txs {
+		response.Transactions = append(response.Transactions, toTransactionRecord(&txs[i]))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetTransactions handles GET /txs?block_height={height}&success={bool}&cursor={cursor}&limit={limit}, newest first
+func (h *ExplorerHandler) GetTransactions(c *gin.Context) {
+	filter := repository.TransactionFilter{Limit: explorerLimit(c) + 1}
+
+	if value := c.Query("block_height"); value != "" {
+		height, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || height < 1 {
+			writeError(c, http.StatusBadRequest, ErrCodeInvalidRequest, "invalid block_height")
+			return
+		}
+		filter.BlockHeight = height
+	}
+	if value := c.Query("success"); value != "" {
+		success, err := strconv.ParseBool(value)
+		if err != nil {
+			writeError(c, http.StatusBadRequest, ErrCodeInvalidRequest, "invalid success, expected true or false")
+			return
+		}
+		filter.Success = &success
+	}
+	if cursor := c.Query("cursor"); cursor != "" {
+		decoded, err := repository.DecodeTransactionCursor(cursor)
+		if err != nil {
+			writeError(c, http.StatusBadRequest, ErrCodeInvalidRequest, "invalid cursor")
+			return
+		}
+		filter.Cursor = decoded
+	}
+
+	txs, err := h.txRepo.List(c.Request.Context(), filter)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, ErrCodeInternal, "failed to get transactions: "+err.Error())
+		return
+	}
+
+	response := types.TransactionListResponse{}
+	if pageSize := filter.Limit - 1; len(txs) > pageSize {
+		txs = txs[:pageSize]
+		response.NextCursor, response.Next = nextPage(c, repository.NewTransactionCursor(&txs[pageSize-1]).Encode())
+	}
+
+	response.Transactions = make([]types.TransactionRecord, 0, len(txs))
+	for i := range txs {
+		response.Transactions = append(response.Transactions, toTransactionRecord(&txs[i]))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetTransaction handles GET /txs/:hash with decoded messages, gas fee and events
+func (h *ExplorerHandler) GetTransaction(c *gin.Context) {
+	hash := c.Param("hash")
+
+	tx, err := h.txRepo.GetTransactionByHash(c.Request.Context(), hash)
+	if err != nil {
+		writeTransactionError(c, hash, err)
+		return
+	}
+
+	events, err := h.eventRepo.GetByTxHash(c.Request.Context(), hash)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, ErrCodeInternal, "failed to get events: "+err.Error())
+		return
+	}
+
+	eventRecords, err := h.toEventRecords(c, events)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, ErrCodeInternal, "failed to get event attrs: "+err.Error())
+		return
+	}
+
+	response := types.TransactionDetailResponse{
+		TransactionRecord: toTransactionRecord(tx),
+		GasFee:            tx.GasFee,
+		Messages:          []domain.TransactionMessage{},
+		Events:            eventRecords,
+	}
+	if tx.Messages != nil {
+		response.Messages = *tx.Messages
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetTransactionTransfers handles GET /txs/:hash/transfers
+// Optional: format=decimal
+func (h *ExplorerHandler) GetTransactionTransfers(c *gin.Context) {
+	formatter, ok := newAmountFormatter(c, h.tokenRepo)
+	if !ok {
+		writeError(c, http.StatusBadRequest, ErrCodeInvalidRequest, "invalid format, expected raw or decimal")
+		return
+	}
+
+	hash := c.Param("hash")
+	if _, err := h.txRepo.GetTransactionByHash(c.Request.Context(), hash); err != nil {
+		writeTransactionError(c, hash, err)
+		return
+	}
+
+	transfers, err := h.transferRepo.GetByTxHash(c.Request.Context(), hash)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, ErrCodeInternal, "failed to get transfers: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, types.TransferHistoryResponse{Transfers: toTransferRecords(transfers, formatter)})
+}
+
+// GetEvents handles GET /events?type={type}&func={func}&pkg_path={pkgPath}&cursor={id}&limit={limit}, newest first
+func (h *ExplorerHandler) GetEvents(c *gin.Context) {
+	filter := repository.EventFilter{
+		Type:    c.Query("type"),
+		Func:    c.Query("func"),
+		PkgPath: c.Query("pkg_path"),
+		Limit:   explorerLimit(c) + 1,
+	}
+
+	if cursor := c.Query("cursor"); cursor != "" {
+		id, err := strconv.ParseInt(cursor, 10, 64)
+		if err != nil || id < 1 {
+			writeError(c, http.StatusBadRequest, ErrCodeInvalidRequest, "invalid cursor")
+			return
+		}
+		filter.BeforeID = id
+	}
+
+	events, err := h.eventRepo.List(c.Request.Context(), filter)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, ErrCodeInternal, "failed to get events: "+err.Error())
+		return
+	}
+
+	response := types.EventListResponse{}
+	if pageSize := filter.Limit - 1; len(events) > pageSize {
+		events = events[:pageSize]
+		response.NextCursor, response.Next = nextPage(c, strconv.FormatInt(events[pageSize-1].ID, 10))
+	}
+
+	response.Events, err = h.toEventRecords(c, events)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, ErrCodeInternal, "failed to get event attrs: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// toEventRecords converts events into their API representation, loading all attributes in one query
+func (h *ExplorerHandler) toEventRecords(c *gin.Context, events []domain.TxEvent) ([]types.EventRecord, error) {
+	ids := make([]int64, 0, len(events))
+	for _, event := range events {
+		ids = append(ids, event.ID)
+	}
+
+	attrs, err := h.eventAttrRepo.GetByEventIDs(c.Request.Context(), ids)
+	if err != nil {
+		return nil, err
+	}
+
+	attrsByEvent := make(map[int64][]types.EventAttrRecord)
+	for _, attr := range attrs {
+		attrsByEvent[attr.EventID] = append(attrsByEvent[attr.EventID], types.EventAttrRecord{Key: attr.Key, Value: attr.Value})
+	}
+
+	records := make([]types.EventRecord, 0, len(events))
+	for _, event := range events {
+		eventAttrs := attrsByEvent[event.ID]
+		if eventAttrs == nil {
+			eventAttrs = []types.EventAttrRecord{}
+		}
+		records = append(records, types.EventRecord{
+			ID:      event.ID,
+			TxHash:  event.TxHash,
+			Index:   event.EventIndex,
+			Type:    event.Type,
+			Func:    event.Func,
+			PkgPath: event.PkgPath,
+			Attrs:   eventAttrs,
+		})
+	}
+	return records, nil
+}
+
+// explorerLimit reads the page size, default 20 and at most 100
+func explorerLimit(c *gin.Context) int {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 20
+	}
+	return limit
+}
+
+// nextPage returns the cursor and the link of the following page
+func nextPage(c *gin.Context, cursor string) (string, string) {
+	nextQuery := c.Request.URL.Query()
+	nextQuery.Set("cursor", cursor)
+	return cursor, c.Request.URL.Path + "?" + nextQuery.Encode()
+}
+
+// blockHeight parses the :height path parameter, writing a 400 response when it is invalid
+func blockHeight(c *gin.Context) (int, bool) {
+	height, err := strconv.Atoi(c.Param("height"))
+	if err != nil || height < 1 {
+		writeError(c, http.StatusBadRequest, ErrCodeInvalidRequest, "invalid block height")
+		return 0, false
+	}
+	return height, true
+}
+
+// writeTransactionError maps repository errors to responses
+func writeTransactionError(c *gin.Context, hash string, err error) {
+	if errors.Is(err, repository.ErrTransactionNotFound) {
+		writeErrorDetails(c, http.StatusNotFound, ErrCodeNotFound, "transaction not found", map[string]interface{}{"hash": hash})
+		return
+	}
+	writeError(c, http.StatusInternalServerError, ErrCodeInternal, "failed to get transaction: "+err.Error())
+}
+
+// toBlockRecord converts a block into its API representation
+func toBlockRecord(block *domain.Block) types.BlockRecord {
+	return types.BlockRecord{
+		Height:        block.Height,
+		Hash:          block.Hash,
+		LastBlockHash: block.LastBlockHash,
+		Time:          block.Time,
+		NumTxs:        block.NumTxs,
+		TotalTxs:      block.TotalTxs,
+	}
+}
+
+// toTransactionRecord converts a transaction into its API representation
+func toTransactionRecord(tx *domain.Transaction) types.TransactionRecord {
+	return types.TransactionRecord{
+		Hash:        tx.Hash,
+		BlockHeight: tx.BlockHeight,
+		Index:       tx.Index,
+		Success:     tx.Success,
+		GasWanted:   tx.GasWanted,
+		GasUsed:     tx.GasUsed,
+		Memo:        tx.Memo,
+	}
+}
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"gn-indexer/internal/domain"
+	"gn-indexer/internal/repository"
+	"gn-indexer/internal/types"
+	"net/http"
+	"strconv"
+)
+
+// NFTHandler handles GRC721-related API requests
+type NFTHandler struct {
+	nftRepo repository.NFTRepository
+}
+
+// NewNFTHandler creates a new NFT handler
+func NewNFTHandler(nftRepo repository.NFTRepository) *NFTHandler {
+	return &NFTHandler{
+		nftRepo: nftRepo,
+	}
+}
+
+// GetNFTsByOwner handles GET /nfts?owner={address}
+func (h *NFTHandler) GetNFTsByOwner(c *gin.Context) {
+	owner := c.Query("owner")
+	if owner == "" {
+		writeError(c, http.StatusBadRequest, ErrCodeInvalidRequest, "owner parameter is required")
+		return
+	}
+
+	owners, err := h.nftRepo.GetByOwner(c.Request.Context(), owner)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, ErrCodeInternal, "failed to get nfts: "+err.Error())
+		return
+	}
+
+	records := make([]types.NFTRecord, 0, len(owners))
+	for _, o := range owners {
+		records = append(records, types.NFTRecord{
+			CollectionPath: o.CollectionPath,
+			TokenID:        o.TokenID,
+			Owner:          o.Owner,
+			LastBlockH:     o.LastBlockH,
+		})
+	}
+
+	c.JSON(http.StatusOK, types.NFTOwnershipResponse{NFTs: records})
+}
+
+// GetProvenance handles GET /nfts/provenance?collection={collectionPath}&tokenId={tokenId}
+func (h *NFTHandler) GetProvenance(c *gin.Context) {
+	collection := c.Query("collection")
+	tokenID := c.Query("tokenId")
+	if collection == "" || tokenID == "" {
+		writeError(c, http.StatusBadRequest, ErrCodeInvalidRequest, "collection and tokenId parameters are required")
+		return
+	}
+
+	transfers, err := h.nftRepo.GetTransfersByToken(c.Request.Context(), collection, tokenID)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, ErrCodeInternal, "failed to get provenance: "+err.Error())
+		return
+	}
+
+	if len(transfers) == 0 {
+		writeErrorDetails(c, http.StatusNotFound, ErrCodeNotFound, "nft not found", map[string]interface{}{
+			"collection": collection,
+			"tokenId":    tokenID,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NFTTransferResponse{Transfers: toNFTTransferRecords(transfers)})
+}
+
+// GetCollectionTransfers handles GET /nfts/transfers?collection={collectionPath}&limit={limit}
+func (h *NFTHandler) GetCollectionTransfers(c *gin.Context) {
+	collection := c.Query("collection")
+	if collection == "" {
+		writeError(c, http.StatusBadRequest, ErrCodeInvalidRequest, "collection parameter is required")
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 || limit > 500 {
+		limit = 50
+	}
+
+	transfers, err := h.nftRepo.GetTransfersByCollection(c.Request.Context(), collection, limit)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, ErrCodeInternal, "failed to get collection transfers: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NFTTransferResponse{Transfers: toNFTTransferRecords(transfers)})
+}
+
+// toNFTTransferRecords converts domain transfers into response records
+func toNFTTransferRecords(transfers []domain.NFTTransfer) []types.NFTTransferRecord {
+	records := make([]types.NFTTransferRecord, 0, len(transfers))
+	for _, t := range transfers {
+		records = append(records, types.NFTTransferRecord{
+			CollectionPath: t.CollectionPath,
+			TokenID:        t.TokenID,
+			FromAddress:    t.FromAddress,
+			ToAddress:      t.ToAddress,
+			TxHash:         t.TxHash,
+			BlockHeight:    t.BlockHeight,
+		})
+	}
+	return records
+}
@@ -0,0 +1,397 @@
+package api
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gn-indexer/internal/domain"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// openAPIDocument is the API description served at /openapi.json and used for request validation
+//
+//go:embed openapi.json
+var openAPIDocument []byte
+
+// openAPISpec is the subset of an OpenAPI 3 document needed to validate requests
+type openAPISpec struct {
+	Paths      map[string]map[string]openAPIOperation `json:"paths"`
+	Components struct {
+		Parameters map[string]openAPIParameter `json:"parameters"`
+		Schemas    map[string]openAPISchema    `json:"schemas"`
+	} `json:"components"`
+}
+
+type openAPIOperation struct {
+	Parameters  []openAPIParameter `json:"parameters"`
+	RequestBody *struct {
+		Content map[string]struct {
+			Schema openAPISchema `json:"schema"`
+		} `json:"content"`
+	} `json:"requestBody"`
+}
+
+type openAPIParameter struct {
+	Ref      string        `json:"$ref"`
+	Name     string        `json:"name"`
+	In       string        `json:"in"`
+	Required bool          `json:"required"`
+	Schema   openAPISchema `json:"schema"`
+}
+
+type openAPISchema struct {
+	Ref        string                   `json:"$ref"`
+	Type       string                   `json:"type"`
+	Format     string                   `json:"format"`
+	Enum       []string                 `json:"enum"`
+	Pattern    string                   `json:"pattern"`
+	Minimum    *int64                   `json:"minimum"`
+	Maximum    *int64                   `json:"maximum"`
+	Required   []string                 `json:"required"`
+	Properties map[string]openAPISchema `json:"properties"`
+	Items      *openAPISchema           `json:"items"`
+}
+
+// valueRule is a compiled parameter or body field schema
+type valueRule struct {
+	typ     string
+	format  string
+	enum    []string
+	pattern *regexp.Regexp
+	minimum *int64
+	maximum *int64
+	items   *valueRule
+}
+
+type paramRule struct {
+	name     string
+	in       string
+	required bool
+	rule     *valueRule
+}
+
+type bodyRule struct {
+	required   []string
+	properties map[string]*valueRule
+}
+
+type operationRules struct {
+	params []paramRule
+	body   *bodyRule
+}
+
+// RequestValidator checks path, query and JSON body values against the OpenAPI document
+type RequestValidator struct {
+	operations map[string]*operationRules // keyed by "METHOD /openapi/{path}"
+}
+
+// NewRequestValidator compiles the validation rules of an OpenAPI document
+func NewRequestValidator(document []byte) (*RequestValidator, error) {
+	var spec openAPISpec
+	if err := json.Unmarshal(document, &spec); err != nil {
+		return nil, fmt.Errorf("parse openapi document: %w", err)
+	}
+
+	v := &RequestValidator{operations: make(map[string]*operationRules)}
+	for path, methods := range spec.Paths {
+		for method, operation := range methods {
+			rules := &operationRules{}
+
+			for _, param := range operation.Parameters {
+				if param.Ref != "" {
+					resolved, ok := spec.Components.Parameters[strings.TrimPrefix(param.Ref, "#/components/parameters/")]
+					if !ok {
+						return nil, fmt.Errorf("%s %s: unknown parameter %s", method, path, param.Ref)
+					}
+					param = resolved
+				}
+				rule, err := compileRule(param.Schema)
+				if err != nil {
+					return nil, fmt.Errorf("%s %s: parameter %s: %w", method, path, param.Name, err)
+				}
+				rules.params = append(rules.params, paramRule{name: param.Name, in: param.In, required: param.Required, rule: rule})
+			}
+
+			if operation.RequestBody != nil {
+				schema := operation.RequestBody.Content["application/json"].Schema
+				if schema.Ref != "" {
+					schema = spec.Components.Schemas[strings.TrimPrefix(schema.Ref, "#/components/schemas/")]
+				}
+				body := &bodyRule{required: schema.Required, properties: make(map[string]*valueRule)}
+				for name, property := range schema.Properties {
+					rule, err := compileRule(property)
+					if err != nil {
+						return nil, fmt.Errorf("%s %s: body field %s: %w", method, path, name, err)
+					}
+					body.properties[name] = rule
+				}
+				rules.body = body
+			}
+
+			v.operations[strings.ToUpper(method)+" "+path] = rules
+		}
+	}
+	return v, nil
+}
+
+// compileRule precompiles the pattern of a schema
+func compileRule(schema openAPISchema) (*valueRule, error) {
+	rule := &valueRule{
+		typ:     schema.Type,
+		format:  schema.Format,
+		enum:    schema.Enum,
+		minimum: schema.Minimum,
+		maximum: schema.Maximum,
+	}
+	if schema.Pattern != "" {
+		pattern, err := regexp.Compile(schema.Pattern)
+		if err != nil {
+			return nil, err
+		}
+		rule.pattern = pattern
+	}
+	if schema.Items != nil {
+		items, err := compileRule(*schema.Items)
+		if err != nil {
+			return nil, err
+		}
+		rule.items = items
+	}
+	return rule, nil
+}
+
+// ginPathParam matches gin path parameters such as :id
+var ginPathParam = regexp.MustCompile(`:([A-Za-z0-9_]+)`)
+
+// Middleware validates requests of documented routes, undocumented routes pass through
+func (v *RequestValidator) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.FullPath() == "" {
+			c.Next()
+			return
+		}
+		path := ginPathParam.ReplaceAllString(c.FullPath(), "{$1}")
+		if !v.Validate(c, c.Request.Method+" "+path) {
+			return
+		}
+		c.Next()
+	}
+}
+
+// Validate checks a request against an operation, writing the error response when it is invalid.
+// Query values with a canonical form, such as addresses, are rewritten so handlers read the normalized value;
+// it must run before the handler first reads the query.
+func (v *RequestValidator) Validate(c *gin.Context, operation string) bool {
+	rules, ok := v.operations[operation]
+	if !ok {
+		return true
+	}
+
+	query := c.Request.URL.Query()
+	normalized := false
+	for _, param := range rules.params {
+		var value string
+		var present bool
+		switch param.in {
+		case "path":
+			value = c.Param(param.name)
+			present = value != ""
+		case "query":
+			if values, ok := query[param.name]; ok && len(values) > 0 {
+				value, present = values[0], true
+			}
+		default:
+			continue
+		}
+
+		if !present || value == "" {
+			if param.required {
+				writeErrorDetails(c, http.StatusBadRequest, ErrCodeInvalidParameter, param.name+" parameter is required",
+					map[string]interface{}{"parameter": param.name})
+				return false
+			}
+			continue
+		}
+
+		if err := param.rule.check(value); err != nil {
+			writeErrorDetails(c, http.StatusBadRequest, ErrCodeInvalidParameter, "invalid "+param.name+": "+err.Error(),
+				map[string]interface{}{"parameter": param.name})
+			return false
+		}
+		if param.in == "query" {
+			if canonical := param.rule.normalize(value); canonical != value {
+				query.Set(param.name, canonical)
+				normalized = true
+			}
+		}
+	}
+	if normalized {
+		c.Request.URL.RawQuery = query.Encode()
+	}
+
+	if rules.body != nil {
+		if field, err := v.validateBody(c, rules.body); err != nil {
+			details := map[string]interface{}{}
+			if field != "" {
+				details["field"] = field
+			}
+			writeErrorDetails(c, http.StatusBadRequest, ErrCodeInvalidParameter, err.Error(), details)
+			return false
+		}
+	}
+	return true
+}
+
+// validateBody checks the JSON body and restores it for the handler
+func (v *RequestValidator) validateBody(c *gin.Context, rules *bodyRule) (string, error) {
+	raw, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read request body: %w", err)
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(raw))
+
+	var body map[string]interface{}
+	if err := json.Unmarshal(raw, &body); err != nil {
+		return "", fmt.Errorf("invalid request body: expected a JSON object")
+	}
+
+	for _, name := range rules.required {
+		if value, ok := body[name]; !ok || value == nil || value == "" {
+			return name, fmt.Errorf("%s is required", name)
+		}
+	}
+	for name, rule := range rules.properties {
+		value, ok := body[name]
+		if !ok || value == nil {
+			continue
+		}
+		if err := rule.checkJSON(value); err != nil {
+			return name, fmt.Errorf("invalid %s: %s", name, err)
+		}
+	}
+	return "", nil
+}
+
+// check validates a path or query string value
+func (r *valueRule) check(value string) error {
+	switch r.typ {
+	case "integer":
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("expected an integer")
+		}
+		return r.checkRange(n)
+	case "boolean":
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("expected true or false")
+		}
+		return nil
+	default:
+		return r.checkString(value)
+	}
+}
+
+// checkJSON validates a decoded JSON body value
+func (r *valueRule) checkJSON(value interface{}) error {
+	switch r.typ {
+	case "integer":
+		n, ok := value.(float64)
+		if !ok || n != float64(int64(n)) {
+			return fmt.Errorf("expected an integer")
+		}
+		return r.checkRange(int64(n))
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("expected true or false")
+		}
+		return nil
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("expected an array")
+		}
+		if r.items == nil {
+			return nil
+		}
+		for i, item := range items {
+			if err := r.items.checkJSON(item); err != nil {
+				return fmt.Errorf("item %d: %s", i, err)
+			}
+		}
+		return nil
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("expected a string")
+		}
+		if s == "" {
+			return nil
+		}
+		return r.checkString(s)
+	default:
+		return nil
+	}
+}
+
+func (r *valueRule) checkRange(n int64) error {
+	if r.minimum != nil && n < *r.minimum {
+		return fmt.Errorf("must be at least %d", *r.minimum)
+	}
+	if r.maximum != nil && n > *r.maximum {
+		return fmt.Errorf("must be at most %d", *r.maximum)
+	}
+	return nil
+}
+
+func (r *valueRule) checkString(value string) error {
+	if len(r.enum) > 0 {
+		for _, allowed := range r.enum {
+			if value == allowed {
+				return nil
+			}
+		}
+		return fmt.Errorf("expected one of %s", strings.Join(r.enum, ", "))
+	}
+
+	switch r.format {
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, value); err != nil {
+			return fmt.Errorf("expected an RFC3339 time")
+		}
+	case "uri":
+		endpoint, err := url.Parse(value)
+		if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+			return fmt.Errorf("expected an absolute http or https URL")
+		}
+	case "gno-address":
+		if _, err := domain.ParseAddress(value); err != nil {
+			return err
+		}
+	}
+
+	if r.pattern != nil && !r.pattern.MatchString(value) {
+		return fmt.Errorf("does not match %s", r.pattern.String())
+	}
+	return nil
+}
+
+// normalize returns the canonical form of a valid value
+func (r *valueRule) normalize(value string) string {
+	if r.format == "gno-address" {
+		return domain.NormalizeAddress(value)
+	}
+	return value
+}
+
+// ServeOpenAPI handles GET /openapi.json
+func ServeOpenAPI(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json", openAPIDocument)
+}
@@ -0,0 +1,404 @@
+package api
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"gn-indexer/internal/config"
+	"gn-indexer/internal/domain"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// CachedResponse is a stored 200 response of a cached endpoint
+type CachedResponse struct {
+	ContentType string `json:"contentType"`
+	ETag        string `json:"etag"`
+	Body        []byte `json:"body"`
+}
+
+// CacheStore keeps cached responses tagged by the addresses and tokens they depend on
+type CacheStore interface {
+	Get(ctx context.Context, key string) (*CachedResponse, bool, error)
+	Set(ctx context.Context, key string, response *CachedResponse, tags []string, ttl time.Duration) error
+	Invalidate(ctx context.Context, tags ...string) error
+}
+
+// Cache tags, a balance change of (address, token) drops every entry carrying one of its tags
+const (
+	cacheTagAll     = "all"
+	cacheTagAddress = "address:"
+	cacheTagToken   = "token:"
+)
+
+// ResponseCache serves repeated balance queries from a CacheStore.
+// Entries are dropped when the event processor reports a balance change and expire after maxStaleness
+// in any case, which bounds staleness when a notification is missed.
+type ResponseCache struct {
+	store        CacheStore
+	maxStaleness time.Duration
+}
+
+// NewResponseCache creates a new response cache
+func NewResponseCache(store CacheStore, maxStaleness time.Duration) *ResponseCache {
+	return &ResponseCache{store: store, maxStaleness: maxStaleness}
+}
+
+// NewResponseCacheFromConfig creates the configured cache, nil when caching is disabled
+func NewResponseCacheFromConfig(cfg *config.CacheConfig) (*ResponseCache, error) {
+	switch cfg.Backend {
+	case config.CacheBackendNone:
+		return nil, nil
+	case config.CacheBackendMemory:
+		return NewResponseCache(NewLRUCacheStore(cfg.Size), cfg.MaxStaleness), nil
+	case config.CacheBackendRedis:
+		if cfg.RedisURL == "" {
+			return nil, fmt.Errorf("REDIS_URL is required for the redis cache backend")
+		}
+		store, err := NewRedisCacheStore(cfg.RedisURL)
+		if err != nil {
+			return nil, err
+		}
+		return NewResponseCache(store, cfg.MaxStaleness), nil
+	default:
+		return nil, fmt.Errorf("unknown cache backend %q", cfg.Backend)
+	}
+}
+
+// Handle wraps a GET handler with the cache, a nil cache leaves the handler as is.
+// Responses carry an ETag and a matching If-None-Match is answered with 304.
+func (rc *ResponseCache) Handle(handler gin.HandlerFunc) gin.HandlerFunc {
+	if rc == nil {
+		return handler
+	}
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		key := cacheKey(c)
+
+		cached, ok, err := rc.store.Get(ctx, key)
+		if err != nil {
+			logger.WarnContext(ctx, "cache get failed", "key", key, "error", err)
+		}
+		if ok {
+			c.Header("X-Cache", "HIT")
+			writeCachedResponse(c, cached)
+			return
+		}
+
+		// Buffer the handler output so the ETag can be set before anything is sent
+		writer := &bufferedWriter{ResponseWriter: c.Writer, status: http.StatusOK}
+		c.Writer = writer
+		handler(c)
+		c.Writer = writer.ResponseWriter
+
+		if writer.status != http.StatusOK {
+			c.Writer.WriteHeader(writer.status)
+			_, _ = c.Writer.Write(writer.body.Bytes())
+			return
+		}
+
+		response := &CachedResponse{
+			ContentType: writer.Header().Get("Content-Type"),
+			ETag:        etagOf(writer.body.Bytes()),
+			Body:        writer.body.Bytes(),
+		}
+		if err := rc.store.Set(ctx, key, response, cacheTags(c), rc.maxStaleness); err != nil {
+			logger.WarnContext(ctx, "cache set failed", "key", key, "error", err)
+		}
+		c.Header("X-Cache", "MISS")
+		writeCachedResponse(c, response)
+	}
+}
+
+// Publish drops the entries affected by a notification, so the cache can be registered as a BalanceService publisher
+func (rc *ResponseCache) Publish(ctx context.Context, notification *domain.Notification) error {
+	if rc == nil {
+		return nil
+	}
+	tags := []string{cacheTagAll}
+	if notification.TokenPath != "" {
+		tags = append(tags, cacheTagToken+notification.TokenPath)
+	}
+	for _, address := range []string{notification.Address, notification.FromAddress, notification.ToAddress} {
+		if address != "" {
+			tags = append(tags, cacheTagAddress+address)
+		}
+	}
+	if err := rc.store.Invalidate(ctx, tags...); err != nil {
+		return fmt.Errorf("invalidate cache: %w", err)
+	}
+	return nil
+}
+
+// Invalidate is Publish for notification handlers without a context, failures are logged
+func (rc *ResponseCache) Invalidate(notification *domain.Notification) {
+	if err := rc.Publish(context.Background(), notification); err != nil {
+		logger.Warn("cache invalidation failed", "error", err)
+	}
+}
+
+// cacheKey identifies a request by path and its query in canonical order, without the API key
+func cacheKey(c *gin.Context) string {
+	query := c.Request.URL.Query()
+	query.Del("api_key")
+	return c.Request.URL.Path + "?" + query.Encode()
+}
+
+// cacheTags returns the tags of a balance query, the address is the narrowest dependency
+func cacheTags(c *gin.Context) []string {
+	if address := c.Query("address"); address != "" {
+		return []string{cacheTagAddress + address}
+	}
+	if tokenPath := strings.Trim(c.Param("tokenPath"), "/"); tokenPath != "" {
+		return []string{cacheTagToken + tokenPath}
+	}
+	return []string{cacheTagAll}
+}
+
+// etagOf returns a strong ETag for a response body
+func etagOf(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// writeCachedResponse writes a response, or 304 when the client already holds it
+func writeCachedResponse(c *gin.Context, response *CachedResponse) {
+	c.Header("ETag", response.ETag)
+	if etagMatches(c.GetHeader("If-None-Match"), response.ETag) {
+		c.Status(http.StatusNotModified)
+		c.Writer.WriteHeaderNow()
+		return
+	}
+	c.Data(http.StatusOK, response.ContentType, response.Body)
+}
+
+// etagMatches checks an If-None-Match header, which may list several tags or "*"
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// bufferedWriter holds the handler output until the cache decides what to send
+type bufferedWriter struct {
+	gin.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *bufferedWriter) WriteHeader(status int) {
+	w.status = status
+}
+
+func (w *bufferedWriter) WriteHeaderNow() {}
+
+func (w *bufferedWriter) Write(data []byte) (int, error) {
+	return w.body.Write(data)
+}
+
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+func (w *bufferedWriter) Status() int {
+	return w.status
+}
+
+func (w *bufferedWriter) Written() bool {
+	return w.body.Len() > 0
+}
+
+// lruEntry is an in-memory cache entry
+type lruEntry struct {
+	key       string
+	response  *CachedResponse
+	tags      []string
+	expiresAt time.Time
+}
+
+// LRUCacheStore is an in-process CacheStore evicting the least recently used entry beyond its capacity
+type LRUCacheStore struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List               // front is the most recently used
+	entries  map[string]*list.Element // key -> element holding *lruEntry
+	tags     map[string]map[string]struct{}
+}
+
+// NewLRUCacheStore creates an in-memory store holding up to capacity responses
+func NewLRUCacheStore(capacity int) *LRUCacheStore {
+	return &LRUCacheStore{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+		tags:     make(map[string]map[string]struct{}),
+	}
+}
+
+// Get returns an unexpired response and marks it as recently used
+func (s *LRUCacheStore) Get(ctx context.Context, key string) (*CachedResponse, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	element, ok := s.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	entry := element.Value.(*lruEntry)
+	if time.Now().After(entry.expiresAt) {
+		s.remove(element)
+		return nil, false, nil
+	}
+	s.order.MoveToFront(element)
+	return entry.response, true, nil
+}
+
+// Set stores a response, evicting the least recently used entries when full
+func (s *LRUCacheStore) Set(ctx context.Context, key string, response *CachedResponse, tags []string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if element, ok := s.entries[key]; ok {
+		s.remove(element)
+	}
+	entry := &lruEntry{key: key, response: response, tags: tags, expiresAt: time.Now().Add(ttl)}
+	s.entries[key] = s.order.PushFront(entry)
+	for _, tag := range tags {
+		if s.tags[tag] == nil {
+			s.tags[tag] = make(map[string]struct{})
+		}
+		s.tags[tag][key] = struct{}{}
+	}
+
+	for s.order.Len() > s.capacity {
+		s.remove(s.order.Back())
+	}
+	return nil
+}
+
+// Invalidate drops every entry carrying one of the tags
+func (s *LRUCacheStore) Invalidate(ctx context.Context, tags ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, tag := range tags {
+		for key := range s.tags[tag] {
+			if element, ok := s.entries[key]; ok {
+				s.remove(element)
+			}
+		}
+	}
+	return nil
+}
+
+// Len returns the number of stored entries
+func (s *LRUCacheStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.order.Len()
+}
+
+// remove deletes an entry and its tag references, the caller holds the lock
+func (s *LRUCacheStore) remove(element *list.Element) {
+	entry := s.order.Remove(element).(*lruEntry)
+	delete(s.entries, entry.key)
+	for _, tag := range entry.tags {
+		delete(s.tags[tag], entry.key)
+		if len(s.tags[tag]) == 0 {
+			delete(s.tags, tag)
+		}
+	}
+}
+
+// redisKeyPrefix namespaces cache keys in a shared Redis
+const redisKeyPrefix = "gn-indexer:cache:"
+
+// RedisCacheStore is a CacheStore shared by every API instance, tags are Redis sets of entry keys
+type RedisCacheStore struct {
+	client *redis.Client
+}
+
+// NewRedisCacheStore creates a store on a Redis URL such as redis://localhost:6379/0
+func NewRedisCacheStore(redisURL string) (*RedisCacheStore, error) {
+	options, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse redis url: %w", err)
+	}
+	return &RedisCacheStore{client: redis.NewClient(options)}, nil
+}
+
+// Get returns a stored response, expiry is left to Redis
+func (s *RedisCacheStore) Get(ctx context.Context, key string) (*CachedResponse, bool, error) {
+	raw, err := s.client.Get(ctx, redisKeyPrefix+"entry:"+key).Bytes()
+	if err == redis.Nil {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("redis get: %w", err)
+	}
+
+	var response CachedResponse
+	if err := json.Unmarshal(raw, &response); err != nil {
+		return nil, false, fmt.Errorf("decode cached response: %w", err)
+	}
+	return &response, true, nil
+}
+
+// Set stores a response with a TTL and records it under its tags
+func (s *RedisCacheStore) Set(ctx context.Context, key string, response *CachedResponse, tags []string, ttl time.Duration) error {
+	raw, err := json.Marshal(response)
+	if err != nil {
+		return fmt.Errorf("encode cached response: %w", err)
+	}
+
+	pipe := s.client.TxPipeline()
+	pipe.Set(ctx, redisKeyPrefix+"entry:"+key, raw, ttl)
+	for _, tag := range tags {
+		tagKey := redisKeyPrefix + "tag:" + tag
+		pipe.SAdd(ctx, tagKey, key)
+		// Tag sets outlive their entries only by one TTL
+		pipe.Expire(ctx, tagKey, ttl)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("redis set: %w", err)
+	}
+	return nil
+}
+
+// Invalidate deletes every entry recorded under the tags
+func (s *RedisCacheStore) Invalidate(ctx context.Context, tags ...string) error {
+	for _, tag := range tags {
+		tagKey := redisKeyPrefix + "tag:" + tag
+		keys, err := s.client.SMembers(ctx, tagKey).Result()
+		if err != nil {
+			return fmt.Errorf("redis smembers: %w", err)
+		}
+
+		toDelete := []string{tagKey}
+		for _, key := range keys {
+			toDelete = append(toDelete, redisKeyPrefix+"entry:"+key)
+		}
+		if err := s.client.Del(ctx, toDelete...).Err(); err != nil {
+			return fmt.Errorf("redis del: %w", err)
+		}
+	}
+	return nil
+}
+
+// Close closes the Redis connection
+func (s *RedisCacheStore) Close() error {
+	return s.client.Close()
+}
@@ -1,8 +1,13 @@
 package api
 
 import (
+	"gn-indexer/internal/config"
+	"gn-indexer/internal/health"
+	"gn-indexer/internal/logging"
+	"gn-indexer/internal/metrics"
 	"gn-indexer/internal/repository"
-	"log"
+	"gn-indexer/internal/service"
+	"gn-indexer/internal/tracing"
 	"net/http"
 	"regexp"
 	"strings"
@@ -10,10 +15,26 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// logger is shared by the handlers and middlewares of the package
+var logger = logging.For("api")
+
 // Server represents the HTTP API server
 type Server struct {
-	router         *gin.Engine
-	balanceHandler *BalanceHandler
+	router           *gin.Engine
+	validator        *RequestValidator
+	balanceHandler   *BalanceHandler
+	allowanceHandler *AllowanceHandler
+	nftHandler       *NFTHandler
+	tokenHandler     *TokenHandler
+	snapshotHandler  *SnapshotHandler
+	streamHandler    *StreamHandler
+	webhookHandler   *WebhookHandler
+	explorerHandler  *ExplorerHandler
+	adminHandler     *AdminHandler
+	graphqlHandler   http.Handler
+	responseCache    *ResponseCache
+	healthChecker    *health.Checker
+	auth             AuthConfig
 }
 
 // NewServer creates a new API server
@@ -21,18 +42,75 @@ func NewServer(
 	balanceRepo repository.BalanceRepository,
 	tokenRepo repository.TokenRepository,
 	transferRepo repository.TransferRepository,
+	allowanceRepo repository.AllowanceRepository,
+	nftRepo repository.NFTRepository,
+	balanceHistoryRepo repository.BalanceHistoryRepository,
+	blockRepo repository.BlockRepository,
+	txRepo repository.TransactionRepository,
+	eventRepo repository.EventRepository,
+	eventAttrRepo repository.EventAttrRepository,
+	snapshotService *service.SnapshotService,
+	streamHub *StreamHub,
+	streamOrigins []string,
+	webhookRepo repository.WebhookRepository,
+	graphqlHandler http.Handler,
+	responseCache *ResponseCache,
+	healthChecker *health.Checker,
+	auth AuthConfig,
 ) *Server {
 	// Set Gin to release mode for production
 	gin.SetMode(gin.ReleaseMode)
 
 	router := gin.Default()
 
+	// Request latency per route, rejected requests included
+	router.Use(metrics.Middleware())
+	router.Use(tracing.Middleware())
+
+	// Partners authenticate with API keys, checked before validation
+	if auth.Required {
+		router.Use(NewAPIKeyAuth(auth.Keys).Middleware())
+	}
+
+	// The embedded document is fixed at build time, an invalid one is a programming error
+	validator, err := NewRequestValidator(openAPIDocument)
+	if err != nil {
+		panic(err)
+	}
+	router.Use(validator.Middleware())
+
 	// Create handlers
-	balanceHandler := NewBalanceHandler(balanceRepo, tokenRepo, transferRepo)
+	balanceHandler := NewBalanceHandler(balanceRepo, tokenRepo, transferRepo, balanceHistoryRepo, blockRepo)
+	allowanceHandler := NewAllowanceHandler(allowanceRepo)
+	nftHandler := NewNFTHandler(nftRepo)
+	tokenHandler := NewTokenHandler(tokenRepo)
+	snapshotHandler := NewSnapshotHandler(snapshotService, blockRepo)
+	streamHandler := NewStreamHandler(streamHub, streamOrigins)
+	webhookHandler := NewWebhookHandler(webhookRepo)
+	explorerHandler := NewExplorerHandler(blockRepo, txRepo, eventRepo, eventAttrRepo, transferRepo, tokenRepo)
+	adminHandler := NewAdminHandler(auth.Keys)
+
+	// Without dependency checks the API reports ready as long as it serves requests
+	if healthChecker == nil {
+		healthChecker = health.NewChecker("balance-api", config.NewHealthConfig(), nil)
+	}
 
 	server := &Server{
-		router:         router,
-		balanceHandler: balanceHandler,
+		router:           router,
+		validator:        validator,
+		balanceHandler:   balanceHandler,
+		allowanceHandler: allowanceHandler,
+		nftHandler:       nftHandler,
+		tokenHandler:     tokenHandler,
+		snapshotHandler:  snapshotHandler,
+		streamHandler:    streamHandler,
+		webhookHandler:   webhookHandler,
+		explorerHandler:  explorerHandler,
+		adminHandler:     adminHandler,
+		graphqlHandler:   graphqlHandler,
+		responseCache:    responseCache,
+		healthChecker:    healthChecker,
+		auth:             auth,
 	}
 
 	// Setup routes
@@ -47,10 +125,67 @@ func (s *Server) setupRoutes() {
 	s.router.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{"status": "ok", "service": "gn-indexer-api"})
 	})
+	s.router.GET("/healthz", gin.WrapF(s.healthChecker.ServeHealthz))
+	s.router.GET("/readyz", gin.WrapF(s.healthChecker.ServeReadyz))
+
+	// API description, also drives request validation
+	s.router.GET("/openapi.json", ServeOpenAPI)
+
+	// Prometheus metrics
+	s.router.GET("/metrics", gin.WrapH(metrics.Handler()))
 
 	// Concrete routes under /tokens
-	s.router.GET("/tokens/balances", s.balanceHandler.GetBalancesByAddress)
+	s.router.GET("/tokens", s.tokenHandler.GetTokens)
+	s.router.GET("/tokens/metadata", s.tokenHandler.GetTokenMetadata)
+	s.router.GET("/tokens/balances", s.responseCache.Handle(s.balanceHandler.GetBalancesByAddress))
 	s.router.GET("/tokens/transfer-history", s.balanceHandler.GetTransferHistory)
+	s.router.GET("/tokens/allowances", s.allowanceHandler.GetAllowances)
+
+	// GRC721 routes (collection paths contain '/', so they are passed as query params)
+	s.router.GET("/nfts", s.nftHandler.GetNFTsByOwner)
+	s.router.GET("/nfts/provenance", s.nftHandler.GetProvenance)
+	s.router.GET("/nfts/transfers", s.nftHandler.GetCollectionTransfers)
+
+	// Token holder snapshot jobs
+	s.router.POST("/snapshots", s.snapshotHandler.CreateSnapshot)
+	s.router.GET("/snapshots/:id", s.snapshotHandler.GetSnapshot)
+	s.router.GET("/snapshots/:id/download", s.snapshotHandler.DownloadSnapshot)
+
+	// Real-time transfer and balance notifications
+	s.router.GET("/stream/ws", s.streamHandler.ServeWebSocket)
+	s.router.GET("/stream/sse", s.streamHandler.ServeSSE)
+
+	// Webhook management and delivery log
+	s.router.POST("/webhooks", s.webhookHandler.CreateWebhook)
+	s.router.GET("/webhooks", s.webhookHandler.GetWebhooks)
+	s.router.GET("/webhooks/:id", s.webhookHandler.GetWebhook)
+	s.router.DELETE("/webhooks/:id", s.webhookHandler.DeleteWebhook)
+	s.router.GET("/webhooks/:id/deliveries", s.webhookHandler.GetDeliveries)
+
+	// Block, transaction and event explorer
+	s.router.GET("/blocks", s.explorerHandler.GetBlocks)
+	s.router.GET("/blocks/latest", s.explorerHandler.GetLatestBlock)
+	s.router.GET("/blocks/:height", s.explorerHandler.GetBlock)
+	s.router.GET("/blocks/:height/txs", s.explorerHandler.GetBlockTransactions)
+	s.router.GET("/txs", s.explorerHandler.GetTransactions)
+	s.router.GET("/txs/:hash", s.explorerHandler.GetTransaction)
+	s.router.GET("/txs/:hash/transfers", s.explorerHandler.GetTransactionTransfers)
+	s.router.GET("/events", s.explorerHandler.GetEvents)
+
+	// GraphQL over blocks, transactions, events, transfers, tokens and balances
+	s.router.POST("/graphql", gin.WrapH(s.graphqlHandler))
+
+	// API key administration, guarded by the admin token instead of an API key
+	adminToken := s.auth.AdminToken
+	if s.auth.Keys == nil {
+		adminToken = ""
+	}
+	admin := s.router.Group("/admin", requireAdmin(adminToken))
+	admin.POST("/keys", s.adminHandler.CreateAPIKey)
+	admin.GET("/keys", s.adminHandler.GetAPIKeys)
+	admin.GET("/keys/:id", s.adminHandler.GetAPIKey)
+	admin.DELETE("/keys/:id", s.adminHandler.RevokeAPIKey)
+	admin.GET("/keys/:id/usage", s.adminHandler.GetAPIKeyUsage)
 
 	// /tokens/:tokenPath/balances handling
 	s.router.NoRoute(s.tokenRouteFallback())
@@ -58,7 +193,7 @@ func (s *Server) setupRoutes() {
 
 // Run starts the HTTP server
 func (s *Server) Run(addr string) error {
-	log.Printf("Starting API server on %s", addr)
+	logger.Info("starting API server", "addr", addr)
 	return s.router.Run(addr)
 }
 
@@ -67,38 +202,28 @@ func (s *Server) GetRouter() *gin.Engine {
 	return s.router
 }
 
-// tokenRouteFallback tries to recover tokenPath from a raw path like
-func (s *Server) tokenRouteFallback() gin.HandlerFunc {
-	// Precompile regex once
-	var re = regexp.MustCompile(`^/tokens/(.+)/balances$`)
+// tokenBalancesRoute matches /tokens/{tokenPath}/balances, tokenPath may contain '/'
+var tokenBalancesRoute = regexp.MustCompile(`^/tokens/(.+)/balances$`)
 
+// tokenRouteFallback serves /tokens/{tokenPath}/balances, which gin cannot route because
+// a catch-all under /tokens would conflict with the static /tokens/* routes
+func (s *Server) tokenRouteFallback() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		path := c.Request.URL.Path
-
 		// Normalize backslashes to forward slashes (in case client sent '\')
-		path = strings.ReplaceAll(path, `\`, `/`)
-
-		// Try to match /tokens/{tokenPath}/balances
-		m := re.FindStringSubmatch(path)
-		if len(m) == 2 {
-			tokenPath := strings.Trim(m[1], "/")
-			if tokenPath != "" {
-				// Inject tokenPath so the original handler can read c.Param("tokenPath")
-				c.Params = append(c.Params, gin.Param{Key: "tokenPath", Value: tokenPath})
-
-				// Call the existing handler
-				s.balanceHandler.GetBalancesByTokenAndAddress(c)
+		path := strings.ReplaceAll(c.Request.URL.Path, `\`, `/`)
+
+		if m := tokenBalancesRoute.FindStringSubmatch(path); len(m) == 2 && c.Request.Method == http.MethodGet {
+			// Inject tokenPath so the handler and the validator can read c.Param("tokenPath")
+			c.Params = append(c.Params, gin.Param{Key: "tokenPath", Value: strings.Trim(m[1], "/")})
+			if !s.validator.Validate(c, "GET /tokens/{tokenPath}/balances") {
 				return
 			}
-			c.JSON(http.StatusBadRequest, gin.H{"error": "tokenPath parameter is required"})
+			s.responseCache.Handle(s.balanceHandler.GetBalancesByTokenAndAddress)(c)
 			return
 		}
 
-		// Not our pattern → keep 404
-		c.JSON(http.StatusNotFound, gin.H{
-			"error":  "route not found",
-			"path":   c.Request.URL.Path,
-			"detail": "check path or query params",
+		writeErrorDetails(c, http.StatusNotFound, ErrCodeNotFound, "route not found", map[string]interface{}{
+			"path": c.Request.URL.Path,
 		})
 	}
 }
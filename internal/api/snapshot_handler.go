@@ -0,0 +1,172 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gn-indexer/internal/domain"
+	"gn-indexer/internal/repository"
+	"gn-indexer/internal/service"
+	"gn-indexer/internal/types"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// SnapshotHandler handles token holder snapshot jobs
+type SnapshotHandler struct {
+	snapshotService *service.SnapshotService
+	blockRepo       repository.BlockRepository
+}
+
+// NewSnapshotHandler creates a new snapshot handler
+func NewSnapshotHandler(snapshotService *service.SnapshotService, blockRepo repository.BlockRepository) *SnapshotHandler {
+	return &SnapshotHandler{
+		snapshotService: snapshotService,
+		blockRepo:       blockRepo,
+	}
+}
+
+// CreateSnapshot handles POST /snapshots
+func (h *SnapshotHandler) CreateSnapshot(c *gin.Context) {
+	var body types.SnapshotJobRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		writeError(c, http.StatusBadRequest, ErrCodeInvalidRequest, "invalid request body: "+err.Error())
+		return
+	}
+
+	req, err := h.toSnapshotRequest(c, &body)
+	if err != nil {
+		writeError(c, http.StatusBadRequest, ErrCodeInvalidRequest, err.Error())
+		return
+	}
+
+	job, err := h.snapshotService.Submit(c.Request.Context(), req)
+	if errors.Is(err, service.ErrSnapshotQueueFull) {
+		c.Header("Retry-After", "60")
+		writeError(c, http.StatusServiceUnavailable, ErrCodeUnavailable, "too many snapshot jobs are queued, retry later")
+		return
+	}
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, ErrCodeInternal, "failed to submit snapshot: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusAccepted, toSnapshotJobResponse(job))
+}
+
+// GetSnapshot handles GET /snapshots/:id
+func (h *SnapshotHandler) GetSnapshot(c *gin.Context) {
+	job, ok := h.getJob(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, toSnapshotJobResponse(job))
+}
+
+// DownloadSnapshot handles GET /snapshots/:id/download
+func (h *SnapshotHandler) DownloadSnapshot(c *gin.Context) {
+	job, ok := h.getJob(c)
+	if !ok {
+		return
+	}
+
+	if job.Status == domain.SnapshotStatusExpired {
+		writeErrorDetails(c, http.StatusGone, ErrCodeNotFound, "snapshot file has expired", map[string]interface{}{"id": job.ID})
+		return
+	}
+	if job.Status != domain.SnapshotStatusDone {
+		writeErrorDetails(c, http.StatusConflict, ErrCodeConflict, "snapshot is not ready", map[string]interface{}{"status": job.Status})
+		return
+	}
+
+	c.FileAttachment(job.FilePath, filepath.Base(job.FilePath))
+}
+
+// getJob loads the job named by the :id path parameter, writing the error response when it fails
+func (h *SnapshotHandler) getJob(c *gin.Context) (*domain.SnapshotJob, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		writeError(c, http.StatusBadRequest, ErrCodeInvalidRequest, "invalid snapshot id")
+		return nil, false
+	}
+
+	job, err := h.snapshotService.GetJob(c.Request.Context(), requestOwner(c), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrSnapshotJobNotFound) {
+			writeErrorDetails(c, http.StatusNotFound, ErrCodeNotFound, "snapshot not found", map[string]interface{}{"id": id})
+			return nil, false
+		}
+		writeError(c, http.StatusInternalServerError, ErrCodeInternal, "failed to get snapshot: "+err.Error())
+		return nil, false
+	}
+
+	return job, true
+}
+
+// toSnapshotRequest validates a job request body and resolves at_time to a height
+func (h *SnapshotHandler) toSnapshotRequest(c *gin.Context, body *types.SnapshotJobRequest) (service.SnapshotRequest, error) {
+	req := service.SnapshotRequest{
+		TokenPath: body.TokenPath,
+		AtHeight:  body.AtHeight,
+		Exclude:   body.Exclude,
+		Format:    body.Format,
+		APIKeyID:  requestOwner(c),
+	}
+
+	if req.TokenPath == "" {
+		return req, fmt.Errorf("tokenPath is required")
+	}
+	if req.Format == "" {
+		req.Format = service.SnapshotFormatCSV
+	}
+	if !service.ValidSnapshotFormat(req.Format) {
+		return req, service.ErrInvalidSnapshotFormat
+	}
+
+	if body.MinAmount != "" {
+		minAmount, err := domain.NewU64FromString(body.MinAmount)
+		if err != nil || minAmount.Sign() < 0 {
+			return req, fmt.Errorf("invalid minAmount")
+		}
+		req.MinAmount = minAmount
+	}
+
+	if body.AtTime != "" {
+		if body.AtHeight != nil {
+			return req, fmt.Errorf("atHeight and atTime cannot be combined")
+		}
+		at, err := time.Parse(time.RFC3339, body.AtTime)
+		if err != nil {
+			return req, fmt.Errorf("invalid atTime, expected RFC3339")
+		}
+		height, err := h.blockRepo.GetHeightAtTime(c.Request.Context(), at)
+		if err != nil {
+			return req, err
+		}
+		req.AtHeight = &height
+	}
+
+	return req, nil
+}
+
+// toSnapshotJobResponse converts a job into its API representation
+func toSnapshotJobResponse(job *domain.SnapshotJob) types.SnapshotJobResponse {
+	response := types.SnapshotJobResponse{
+		ID:         job.ID,
+		TokenPath:  job.TokenPath,
+		AtHeight:   job.AtHeight,
+		Format:     job.Format,
+		Status:     job.Status,
+		RowCount:   job.RowCount,
+		Error:      job.Error,
+		CreatedAt:  job.CreatedAt,
+		FinishedAt: job.FinishedAt,
+	}
+	if job.Status == domain.SnapshotStatusDone {
+		response.DownloadURL = "/snapshots/" + strconv.FormatInt(job.ID, 10) + "/download"
+	}
+	return response
+}
@@ -0,0 +1,202 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"gn-indexer/internal/domain"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// subscriberBuffer is how many notifications a slow subscriber may lag behind before messages are dropped
+	subscriberBuffer = 64
+	// streamPingInterval keeps idle WebSocket and SSE connections open through proxies
+	streamPingInterval = 30 * time.Second
+)
+
+// StreamFilter selects the notifications a subscriber receives, empty fields match everything
+type StreamFilter struct {
+	Address   string
+	TokenPath string
+}
+
+// Matches reports whether a notification passes the filter
+func (f StreamFilter) Matches(notification *domain.Notification) bool {
+	if f.TokenPath != "" && notification.TokenPath != f.TokenPath {
+		return false
+	}
+	if f.Address != "" && !notification.Involves(f.Address) {
+		return false
+	}
+	return true
+}
+
+// StreamHub fans notifications out to WebSocket and SSE subscribers
+type StreamHub struct {
+	mu          sync.RWMutex
+	subscribers map[chan *domain.Notification]StreamFilter
+}
+
+// NewStreamHub creates a new stream hub
+func NewStreamHub() *StreamHub {
+	return &StreamHub{
+		subscribers: make(map[chan *domain.Notification]StreamFilter),
+	}
+}
+
+// Subscribe registers a subscriber and returns its channel
+func (h *StreamHub) Subscribe(filter StreamFilter) chan *domain.Notification {
+	ch := make(chan *domain.Notification, subscriberBuffer)
+	h.mu.Lock()
+	h.subscribers[ch] = filter
+	h.mu.Unlock()
+	return ch
+}
+
+// Unsubscribe removes a subscriber and closes its channel
+func (h *StreamHub) Unsubscribe(ch chan *domain.Notification) {
+	h.mu.Lock()
+	if _, ok := h.subscribers[ch]; ok {
+		delete(h.subscribers, ch)
+		close(ch)
+	}
+	h.mu.Unlock()
+}
+
+// Broadcast delivers a notification to every matching subscriber without blocking on slow ones
+func (h *StreamHub) Broadcast(notification *domain.Notification) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for ch, filter := range h.subscribers {
+		if !filter.Matches(notification) {
+			continue
+		}
+		select {
+		case ch <- notification:
+		default:
+			logger.Warn("subscriber is too slow, dropping notification", "kind", notification.Kind)
+		}
+	}
+}
+
+// StreamHandler serves real-time notifications over WebSocket and Server-Sent Events
+type StreamHandler struct {
+	hub      *StreamHub
+	upgrader websocket.Upgrader
+}
+
+// NewStreamHandler creates a new stream handler, WebSocket connections are accepted from the API origin
+// and allowedOrigins such as "https://app.example.com", "*" accepts any origin
+func NewStreamHandler(hub *StreamHub, allowedOrigins []string) *StreamHandler {
+	return &StreamHandler{
+		hub: hub,
+		upgrader: websocket.Upgrader{
+			CheckOrigin: checkOrigin(allowedOrigins),
+		},
+	}
+}
+
+// checkOrigin accepts clients without an Origin header (not browsers), same-origin pages and the allowed origins
+func checkOrigin(allowedOrigins []string) func(r *http.Request) bool {
+	allowed := make(map[string]bool, len(allowedOrigins))
+	for _, origin := range allowedOrigins {
+		allowed[strings.ToLower(strings.TrimRight(origin, "/"))] = true
+	}
+
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" || allowed["*"] || allowed[strings.ToLower(origin)] {
+			return true
+		}
+		u, err := url.Parse(origin)
+		return err == nil && strings.EqualFold(u.Host, r.Host)
+	}
+}
+
+// streamFilter reads the subscription filter from the query string
+func streamFilter(c *gin.Context) StreamFilter {
+	return StreamFilter{
+		Address:   c.Query("address"),
+		TokenPath: c.Query("token_path"),
+	}
+}
+
+// ServeWebSocket handles GET /stream/ws?address={address}&token_path={tokenPath}
+func (h *StreamHandler) ServeWebSocket(c *gin.Context) {
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		logger.WarnContext(c.Request.Context(), "websocket upgrade failed", "error", err)
+		return
+	}
+	defer conn.Close()
+
+	ch := h.hub.Subscribe(streamFilter(c))
+	defer h.hub.Unsubscribe(ch)
+
+	// The read loop only detects client disconnects, subscriptions are fixed by the query string
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(streamPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case notification, ok := <-ch:
+			if !ok {
+				return
+			}
+			if err := conn.WriteJSON(notification); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(10*time.Second)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// ServeSSE handles GET /stream/sse?address={address}&token_path={tokenPath}
+func (h *StreamHandler) ServeSSE(c *gin.Context) {
+	ch := h.hub.Subscribe(streamFilter(c))
+	defer h.hub.Unsubscribe(ch)
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	ticker := time.NewTicker(streamPingInterval)
+	defer ticker.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case notification, ok := <-ch:
+			if !ok {
+				return false
+			}
+			c.SSEvent(notification.Kind, notification)
+			return true
+		case <-ticker.C:
+			c.SSEvent("ping", gin.H{"time": time.Now().Unix()})
+			return true
+		}
+	})
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gn-indexer/internal/domain"
+	"gn-indexer/internal/repository"
+	"gn-indexer/internal/types"
+	"gorm.io/gorm"
+	"net/http"
+)
+
+// TokenHandler handles token metadata API requests
+type TokenHandler struct {
+	tokenRepo repository.TokenRepository
+}
+
+// NewTokenHandler creates a new token handler
+func NewTokenHandler(tokenRepo repository.TokenRepository) *TokenHandler {
+	return &TokenHandler{
+		tokenRepo: tokenRepo,
+	}
+}
+
+// GetTokens handles GET /tokens
+func (h *TokenHandler) GetTokens(c *gin.Context) {
+	tokens, err := h.tokenRepo.GetAll(c.Request.Context())
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, ErrCodeInternal, "failed to get tokens: "+err.Error())
+		return
+	}
+
+	infos := make([]types.TokenInfo, 0, len(tokens))
+	for i := range tokens {
+		infos = append(infos, toTokenInfo(&tokens[i]))
+	}
+
+	c.JSON(http.StatusOK, types.TokenListResponse{Tokens: infos})
+}
+
+// GetTokenMetadata handles GET /tokens/metadata?tokenPath={tokenPath}
+func (h *TokenHandler) GetTokenMetadata(c *gin.Context) {
+	tokenPath := c.Query("tokenPath")
+	if tokenPath == "" {
+		writeError(c, http.StatusBadRequest, ErrCodeInvalidRequest, "tokenPath parameter is required")
+		return
+	}
+
+	token, err := h.tokenRepo.GetByPath(c.Request.Context(), tokenPath)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			writeErrorDetails(c, http.StatusNotFound, ErrCodeNotFound, "token not found", map[string]interface{}{
+				"tokenPath": tokenPath,
+			})
+			return
+		}
+		writeError(c, http.StatusInternalServerError, ErrCodeInternal, "failed to get token: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, toTokenInfo(token))
+}
+
+// toTokenInfo converts a domain token into a response record
+func toTokenInfo(token *domain.Token) types.TokenInfo {
+	info := types.TokenInfo{
+		TokenPath: token.Path,
+		Symbol:    token.Symbol,
+		Name:      token.Name,
+		Decimals:  token.Decimals,
+	}
+	if token.TotalSupply != nil && token.TotalSupply.Int != nil {
+		info.TotalSupply = token.TotalSupply.String()
+	}
+	return info
+}
@@ -0,0 +1,224 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gn-indexer/internal/domain"
+	"gn-indexer/internal/repository"
+	"gn-indexer/internal/service"
+	"gn-indexer/internal/types"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// WebhookHandler handles webhook registration and delivery log requests
+type WebhookHandler struct {
+	webhookRepo repository.WebhookRepository
+}
+
+// NewWebhookHandler creates a new webhook handler
+func NewWebhookHandler(webhookRepo repository.WebhookRepository) *WebhookHandler {
+	return &WebhookHandler{
+		webhookRepo: webhookRepo,
+	}
+}
+
+// CreateWebhook handles POST /webhooks
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	var body types.WebhookRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		writeError(c, http.StatusBadRequest, ErrCodeInvalidRequest, "invalid request body: "+err.Error())
+		return
+	}
+
+	if err := checkWebhookURL(c.Request.Context(), body.URL); err != nil {
+		writeError(c, http.StatusBadRequest, ErrCodeInvalidRequest, err.Error())
+		return
+	}
+
+	webhook := &domain.Webhook{
+		URL:       body.URL,
+		Secret:    body.Secret,
+		Address:   domain.NormalizeAddress(body.Address),
+		TokenPath: body.TokenPath,
+		Active:    true,
+		APIKeyID:  requestOwner(c),
+	}
+
+	if body.MinAmount != "" {
+		minAmount, err := domain.NewU64FromString(body.MinAmount)
+		if err != nil || minAmount.Sign() < 0 {
+			writeError(c, http.StatusBadRequest, ErrCodeInvalidRequest, "invalid minAmount")
+			return
+		}
+		webhook.MinAmount = minAmount
+	}
+
+	if webhook.Secret == "" {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			writeError(c, http.StatusInternalServerError, ErrCodeInternal, "failed to generate secret: "+err.Error())
+			return
+		}
+		webhook.Secret = hex.EncodeToString(secret)
+	}
+
+	if err := h.webhookRepo.Create(c.Request.Context(), webhook); err != nil {
+		writeError(c, http.StatusInternalServerError, ErrCodeInternal, "failed to create webhook: "+err.Error())
+		return
+	}
+
+	// The secret is only shown once, integrators need it to verify signatures
+	response := toWebhookResponse(webhook)
+	response.Secret = webhook.Secret
+	c.JSON(http.StatusCreated, response)
+}
+
+// GetWebhooks handles GET /webhooks
+func (h *WebhookHandler) GetWebhooks(c *gin.Context) {
+	webhooks, err := h.webhookRepo.List(c.Request.Context(), requestOwner(c))
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, ErrCodeInternal, "failed to get webhooks: "+err.Error())
+		return
+	}
+
+	responses := make([]types.WebhookResponse, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		responses = append(responses, toWebhookResponse(webhook))
+	}
+
+	c.JSON(http.StatusOK, types.WebhookListResponse{Webhooks: responses})
+}
+
+// GetWebhook handles GET /webhooks/:id
+func (h *WebhookHandler) GetWebhook(c *gin.Context) {
+	id, ok := webhookID(c)
+	if !ok {
+		return
+	}
+
+	webhook, err := h.webhookRepo.GetOwned(c.Request.Context(), requestOwner(c), id)
+	if err != nil {
+		writeWebhookError(c, id, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toWebhookResponse(webhook))
+}
+
+// DeleteWebhook handles DELETE /webhooks/:id
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	id, ok := webhookID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.webhookRepo.Delete(c.Request.Context(), requestOwner(c), id); err != nil {
+		writeWebhookError(c, id, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetDeliveries handles GET /webhooks/:id/deliveries?limit={limit}
+func (h *WebhookHandler) GetDeliveries(c *gin.Context) {
+	id, ok := webhookID(c)
+	if !ok {
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 || limit > 500 {
+		limit = 50
+	}
+
+	if _, err := h.webhookRepo.GetOwned(c.Request.Context(), requestOwner(c), id); err != nil {
+		writeWebhookError(c, id, err)
+		return
+	}
+
+	deliveries, err := h.webhookRepo.GetDeliveries(c.Request.Context(), id, limit)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, ErrCodeInternal, "failed to get deliveries: "+err.Error())
+		return
+	}
+
+	records := make([]types.WebhookDeliveryRecord, 0, len(deliveries))
+	for _, d := range deliveries {
+		records = append(records, types.WebhookDeliveryRecord{
+			ID:             d.ID,
+			Status:         d.Status,
+			Attempts:       d.Attempts,
+			LastStatusCode: d.LastStatusCode,
+			LastError:      d.LastError,
+			NextAttemptAt:  d.NextAttemptAt,
+			CreatedAt:      d.CreatedAt,
+			DeliveredAt:    d.DeliveredAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, types.WebhookDeliveryResponse{Deliveries: records})
+}
+
+// checkWebhookURL accepts absolute http or https URLs whose host only resolves to public addresses,
+// so webhooks cannot reach the private network or cloud metadata endpoints
+func checkWebhookURL(ctx context.Context, rawURL string) error {
+	endpoint, err := url.Parse(rawURL)
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Hostname() == "" {
+		return errors.New("url must be an absolute http or https URL")
+	}
+
+	host := endpoint.Hostname()
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return fmt.Errorf("url host %s cannot be resolved", host)
+	}
+	for _, addr := range addrs {
+		if !service.PublicIP(addr.IP) {
+			return fmt.Errorf("url host %s is not a public address", host)
+		}
+	}
+	return nil
+}
+
+// webhookID parses the :id path parameter, writing a 400 response when it is invalid
+func webhookID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		writeError(c, http.StatusBadRequest, ErrCodeInvalidRequest, "invalid webhook id")
+		return 0, false
+	}
+	return id, true
+}
+
+// writeWebhookError maps repository errors to responses
+func writeWebhookError(c *gin.Context, id int64, err error) {
+	if errors.Is(err, repository.ErrWebhookNotFound) {
+		writeErrorDetails(c, http.StatusNotFound, ErrCodeNotFound, "webhook not found", map[string]interface{}{"id": id})
+		return
+	}
+	writeError(c, http.StatusInternalServerError, ErrCodeInternal, "failed to get webhook: "+err.Error())
+}
+
+// toWebhookResponse converts a webhook into its API representation without the secret
+func toWebhookResponse(webhook *domain.Webhook) types.WebhookResponse {
+	response := types.WebhookResponse{
+		ID:        webhook.ID,
+		URL:       webhook.URL,
+		Address:   webhook.Address,
+		TokenPath: webhook.TokenPath,
+		Active:    webhook.Active,
+		CreatedAt: webhook.CreatedAt,
+	}
+	if webhook.MinAmount != nil {
+		response.MinAmount = webhook.MinAmount.String()
+	}
+	return response
+}
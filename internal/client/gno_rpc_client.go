@@ -0,0 +1,168 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GnoRPCClient queries realm state through the gno.land tm2 JSON-RPC endpoint
+type GnoRPCClient struct {
+	Endpoint string
+	httpc    *http.Client
+}
+
+// rpcReq JSON-RPC request structure
+type rpcReq struct {
+	JSONRPC string                 `json:"jsonrpc"`
+	ID      int                    `json:"id"`
+	Method  string                 `json:"method"`
+	Params  map[string]interface{} `json:"params"`
+}
+
+// abciQueryResp JSON-RPC response structure for abci_query
+type abciQueryResp struct {
+	Result struct {
+		Response struct {
+			ResponseBase struct {
+				Error interface{} `json:"Error"`
+				Data  string      `json:"Data"`
+				Log   string      `json:"Log"`
+			} `json:"ResponseBase"`
+		} `json:"response"`
+	} `json:"result"`
+	Error *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Data    string `json:"data"`
+	} `json:"error"`
+}
+
+// qevalResult matches a single qeval result such as ("FOO" string) or (6 uint)
+var qevalResult = regexp.MustCompile(`^\((.*) ([^ ()]+)\)$`)
+
+// NewGnoRPCClient creates a new gno RPC client, timeout bounds each request
+func NewGnoRPCClient(endpoint string, timeout time.Duration) *GnoRPCClient {
+	return &GnoRPCClient{
+		Endpoint: strings.TrimRight(endpoint, "/"),
+		httpc:    &http.Client{Timeout: timeout},
+	}
+}
+
+// QEval evaluates a read-only expression in a realm via vm/qeval and returns the raw result
+// e.g. QEval(ctx, "gno.land/r/demo/foo20", "GetSymbol()") → `("FOO" string)`
+func (c *GnoRPCClient) QEval(ctx context.Context, pkgPath, expr string) (string, error) {
+	data := base64.StdEncoding.EncodeToString([]byte(pkgPath + "." + expr))
+	body, err := json.Marshal(rpcReq{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "abci_query",
+		Params: map[string]interface{}{
+			"path": "vm/qeval",
+			"data": data,
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("marshal rpc request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := c.httpc.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	raw, err := io.ReadAll(res.Body)
+	if err != nil {
+		return "", fmt.Errorf("read response body: %w", err)
+	}
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return "", fmt.Errorf("http %d from %s: %s", res.StatusCode, c.Endpoint, sample(raw, 600))
+	}
+
+	var r abciQueryResp
+	if err := json.Unmarshal(raw, &r); err != nil {
+		return "", fmt.Errorf("decode json: %w; body: %s", err, sample(raw, 600))
+	}
+	if r.Error != nil {
+		return "", fmt.Errorf("rpc error %d: %s %s", r.Error.Code, r.Error.Message, r.Error.Data)
+	}
+	if r.Result.Response.ResponseBase.Error != nil {
+		return "", fmt.Errorf("qeval %s.%s failed: %s", pkgPath, expr, r.Result.Response.ResponseBase.Log)
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(r.Result.Response.ResponseBase.Data)
+	if err != nil {
+		return "", fmt.Errorf("decode qeval data: %w", err)
+	}
+
+	return strings.TrimSpace(string(decoded)), nil
+}
+
+// QEvalString evaluates an expression returning a string
+func (c *GnoRPCClient) QEvalString(ctx context.Context, pkgPath, expr string) (string, error) {
+	value, err := c.qevalValue(ctx, pkgPath, expr)
+	if err != nil {
+		return "", err
+	}
+	unquoted, err := strconv.Unquote(value)
+	if err != nil {
+		return "", fmt.Errorf("qeval %s.%s returned non-string %s", pkgPath, expr, value)
+	}
+	return unquoted, nil
+}
+
+// QEvalNumber evaluates an expression returning an integer, kept as a decimal string
+func (c *GnoRPCClient) QEvalNumber(ctx context.Context, pkgPath, expr string) (string, error) {
+	value, err := c.qevalValue(ctx, pkgPath, expr)
+	if err != nil {
+		return "", err
+	}
+	if !isDecimal(value) {
+		return "", fmt.Errorf("qeval %s.%s returned non-integer %s", pkgPath, expr, value)
+	}
+	return value, nil
+}
+
+// qevalValue returns the value part of the first qeval result, dropping its type
+func (c *GnoRPCClient) qevalValue(ctx context.Context, pkgPath, expr string) (string, error) {
+	result, err := c.QEval(ctx, pkgPath, expr)
+	if err != nil {
+		return "", err
+	}
+
+	// Multiple return values are printed one per line, only the first is used
+	first := strings.SplitN(result, "\n", 2)[0]
+	m := qevalResult.FindStringSubmatch(first)
+	if len(m) != 3 {
+		return "", fmt.Errorf("unexpected qeval result: %s", first)
+	}
+	return m[1], nil
+}
+
+// isDecimal reports whether s is a non-negative base-10 integer
+func isDecimal(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -6,11 +6,16 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"gn-indexer/internal/metrics"
+	"gn-indexer/internal/tracing"
 	"io"
 	"mime"
 	"net/http"
 	"strings"
 	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // GraphQLClient handles GraphQL HTTP requests
@@ -31,16 +36,25 @@ type gqlResp[T any] struct {
 	Errors []interface{} `json:"errors"`
 }
 
-// NewGraphQLClient creates a new GraphQL client
-func NewGraphQLClient[T any](endpoint string) *GraphQLClient[T] {
+// NewGraphQLClient creates a new GraphQL client, timeout bounds each query
+func NewGraphQLClient[T any](endpoint string, timeout time.Duration) *GraphQLClient[T] {
 	return &GraphQLClient[T]{
 		Endpoint: endpoint,
-		httpc:    &http.Client{Timeout: 20 * time.Second},
+		httpc:    &http.Client{Timeout: timeout},
 	}
 }
 
-// Do executes a GraphQL query
-func (c *GraphQLClient[T]) Do(ctx context.Context, query string, vars map[string]interface{}, out *T) error {
+// Do executes a GraphQL query, its latency and failures are recorded per operation
+func (c *GraphQLClient[T]) Do(ctx context.Context, query string, vars map[string]interface{}, out *T) (err error) {
+	operation := metrics.GraphQLOperation(query)
+	ctx, span := tracing.StartKind(ctx, "graphql "+operation, trace.SpanKindClient,
+		attribute.String("graphql.operation.name", operation), attribute.String("server.address", c.Endpoint))
+	start := time.Now()
+	defer func() {
+		metrics.ObserveGraphQL(operation, start, err)
+		tracing.End(span, err)
+	}()
+
 	if out == nil {
 		return errors.New("out is nil")
 	}
@@ -57,6 +71,7 @@ func (c *GraphQLClient[T]) Do(ctx context.Context, query string, vars map[string
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
+	tracing.InjectHTTP(ctx, req.Header)
 
 	res, err := c.httpc.Do(req)
 	if err != nil {
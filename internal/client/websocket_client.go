@@ -4,8 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"gn-indexer/internal/logging"
+	"gn-indexer/internal/metrics"
 	"gn-indexer/internal/types"
-	"log"
+	"log/slog"
 	"sync"
 	"time"
 
@@ -47,18 +49,22 @@ type Subscription struct {
 
 // SubscriptionClient handles GraphQL subscriptions via WebSocket
 type SubscriptionClient struct {
-	Endpoint      string
-	conn          *websocket.Conn
-	nextID        int64
-	mu            sync.Mutex
-	subscriptions map[string]*Subscription
+	Endpoint       string
+	reconnectDelay time.Duration
+	conn           *websocket.Conn
+	nextID         int64
+	mu             sync.Mutex
+	subscriptions  map[string]*Subscription
+	logger         *slog.Logger
 }
 
-// NewSubscriptionClient creates a new subscription client
-func NewSubscriptionClient(endpoint string) *SubscriptionClient {
+// NewSubscriptionClient creates a new subscription client, a dropped connection is retried after reconnectDelay
+func NewSubscriptionClient(endpoint string, reconnectDelay time.Duration) *SubscriptionClient {
 	return &SubscriptionClient{
-		Endpoint:      endpoint,
-		subscriptions: make(map[string]*Subscription),
+		Endpoint:       endpoint,
+		reconnectDelay: reconnectDelay,
+		subscriptions:  make(map[string]*Subscription),
+		logger:         logging.For("websocket"),
 	}
 }
 
@@ -114,14 +120,12 @@ func (sc *SubscriptionClient) Connect(ctx context.Context) error {
 
 // Subscribe starts a persistent subscription
 func (sc *SubscriptionClient) Subscribe(ctx context.Context, query string, vars map[string]interface{}, handler func(types.BlocksData) error) error {
-	log.Printf("Subscribe: starting subscription with query: %s", query)
+	sc.logger.Debug("starting subscription", "query", query)
 
 	if err := sc.Connect(ctx); err != nil {
 		return fmt.Errorf("connect websocket: %w", err)
 	}
 
-	log.Printf("Subscribe: websocket connected successfully")
-
 	// generate unique ID for this subscription
 	subID := sc.generateID()
 
@@ -148,7 +152,8 @@ func (sc *SubscriptionClient) Subscribe(ctx context.Context, query string, vars
 		},
 	}
 
-	log.Printf("Subscribe: sending start message with ID %s: %+v", subID, startMsg)
+	logger := sc.logger.With(logging.KeySubscriptionID, subID)
+	logger.Debug("sending start message")
 
 	if err := sc.conn.WriteJSON(startMsg); err != nil {
 		// remove subscription on error
@@ -158,12 +163,10 @@ func (sc *SubscriptionClient) Subscribe(ctx context.Context, query string, vars
 		return fmt.Errorf("write start message: %w", err)
 	}
 
-	log.Printf("Subscribe: start message sent successfully")
-
 	// handle subscription responses in goroutine
 	go func() {
 		defer func() {
-			log.Printf("Subscribe: goroutine ending for subscription %s, closing connection", subID)
+			logger.Debug("subscription ended, closing connection")
 			// remove subscription
 			sc.mu.Lock()
 			delete(sc.subscriptions, subID)
@@ -171,12 +174,10 @@ func (sc *SubscriptionClient) Subscribe(ctx context.Context, query string, vars
 			sc.conn.Close()
 		}()
 
-		log.Printf("Subscribe: starting message handling loop for subscription %s", subID)
-
 		for {
 			select {
 			case <-ctx.Done():
-				log.Printf("Subscribe: context cancelled for subscription %s, stopping subscription", subID)
+				logger.Info("context cancelled, stopping subscription")
 				// send stop message before closing
 				stopMsg := gqlWSMessage{
 					Type: GQL_STOP,
@@ -185,92 +186,85 @@ func (sc *SubscriptionClient) Subscribe(ctx context.Context, query string, vars
 				sc.conn.WriteJSON(stopMsg)
 				return
 			default:
-				log.Printf("Subscribe: waiting for message for subscription %s...", subID)
-
 				var response gqlWSMessage
 				if err := sc.conn.ReadJSON(&response); err != nil {
-					log.Printf("Subscribe: read subscription response error for subscription %s: %v", subID, err)
+					logger.Warn("read subscription response failed", "error", err)
 
 					// Attempt to reconnect if connection is lost
 					if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-						log.Printf("Subscribe: connection closed for subscription %s, attempting to reconnect...", subID)
+						logger.Warn("connection closed, attempting to reconnect")
 
 						// Wait briefly before attempting to reconnect
-						time.Sleep(2 * time.Second)
+						time.Sleep(sc.reconnectDelay)
 
 						if err := sc.reconnect(ctx); err != nil {
-							log.Printf("Subscribe: reconnection failed for subscription %s: %v", subID, err)
+							metrics.WebSocketReconnects.WithLabelValues("failure").Inc()
+							logger.Error("reconnection failed", "error", err)
 							return
 						}
 
 						// Restart subscription after reconnection
 						if err := sc.resubscribe(subscription); err != nil {
-							log.Printf("Subscribe: resubscription failed for subscription %s: %v", subID, err)
+							metrics.WebSocketReconnects.WithLabelValues("failure").Inc()
+							logger.Error("resubscription failed", "error", err)
 							return
 						}
+						metrics.WebSocketReconnects.WithLabelValues("success").Inc()
 
-						log.Printf("Subscribe: reconnected and resubscribed successfully for subscription %s", subID)
+						logger.Info("reconnected and resubscribed")
 						continue
 					}
 
 					return
 				}
 
-				log.Printf("Subscribe: received message for subscription %s, type: %s, payload: %+v", subID, response.Type, response.Payload)
+				logger.Debug("received message", "type", response.Type)
 
 				// check if this message is for our subscription
 				if response.ID != subID {
-					log.Printf("Subscribe: message ID %s doesn't match subscription ID %s, skipping", response.ID, subID)
+					logger.Debug("message for another subscription, skipping", "message_id", response.ID)
 					continue
 				}
 
 				switch response.Type {
 				case GQL_DATA:
-					log.Printf("Subscribe: handling DATA message for subscription %s", subID)
 					// handle data message
 					if payload, ok := response.Payload["data"]; ok {
-						log.Printf("Subscribe: data payload found for subscription %s: %+v", subID, payload)
-
 						if data, ok := payload.(map[string]interface{}); ok {
 							// convert to BlocksData
 							jsonData, _ := json.Marshal(data)
-							log.Printf("Subscribe: marshaled data for subscription %s: %s", subID, string(jsonData))
 
 							var blocksData types.BlocksData
 							if err := json.Unmarshal(jsonData, &blocksData); err == nil {
-								log.Printf("Subscribe: unmarshaled successfully for subscription %s, block height: %d", subID, blocksData.GetBlocks.Height)
+								logger.Debug("received block", logging.KeyBlockHeight, blocksData.GetBlocks.Height)
+								metrics.SetNetworkHeight(blocksData.GetBlocks.Height)
 
 								if err := handler(blocksData); err != nil {
-									log.Printf("Subscribe: handler error for subscription %s: %v", subID, err)
-								} else {
-									log.Printf("Subscribe: handler executed successfully for subscription %s", subID)
+									logger.Error("handler failed", logging.KeyBlockHeight, blocksData.GetBlocks.Height, "error", err)
 								}
 							} else {
-								log.Printf("Subscribe: unmarshal error for subscription %s: %v", subID, err)
+								logger.Error("unmarshal block failed", "error", err)
 							}
 						} else {
-							log.Printf("Subscribe: payload data is not map[string]interface{} for subscription %s: %T", subID, payload)
+							logger.Warn("unexpected payload data type", "type", fmt.Sprintf("%T", payload))
 						}
 					} else {
-						log.Printf("Subscribe: no data payload found in response for subscription %s", subID)
+						logger.Warn("no data payload in response")
 					}
 				case GQL_ERROR:
-					log.Printf("Subscribe: handling ERROR message for subscription %s", subID)
 					if payload, ok := response.Payload["errors"]; ok {
-						log.Printf("Subscribe: subscription error for subscription %s: %+v", subID, payload)
+						logger.Error("subscription error", "errors", payload)
 						// Todo: re connecting logic
 					}
 				case GQL_COMPLETE:
-					log.Printf("Subscribe: handling COMPLETE message for subscription %s", subID)
-					log.Printf("Subscribe: subscription %s completed", subID)
+					logger.Info("subscription completed")
 					return
 				case GQL_CONNECTION_ERROR:
-					log.Printf("Subscribe: handling CONNECTION_ERROR message for subscription %s", subID)
 					// connection error handling
 					if payload, ok := response.Payload["message"]; ok {
-						log.Printf("Subscribe: connection error for subscription %s: %v", subID, payload)
+						logger.Error("connection error", "reason", payload)
 					} else {
-						log.Printf("Subscribe: connection error for subscription %s: unknown reason", subID)
+						logger.Error("connection error", "reason", "unknown")
 					}
 					stopMsg := gqlWSMessage{
 						Type: GQL_STOP,
@@ -279,28 +273,26 @@ func (sc *SubscriptionClient) Subscribe(ctx context.Context, query string, vars
 					_ = sc.conn.WriteJSON(stopMsg)
 					return
 				case GQL_CONNECTION_ACK:
-					log.Printf("Subscribe: received connection_ack for subscription %s (this should not happen after start)", subID)
+					logger.Debug("unexpected connection_ack after start")
 				default:
-					log.Printf("Subscribe: unknown message type %s for subscription %s", response.Type, subID)
+					logger.Warn("unknown message type", "type", response.Type)
 				}
 			}
 		}
 	}()
 
-	log.Printf("Subscribe: subscription %s started successfully", subID)
+	logger.Info("subscription started")
 	return nil
 }
 
 // SubscribeOnce starts a one-time subscription, receives one data message, then stops
 func (sc *SubscriptionClient) SubscribeOnce(ctx context.Context, query string, vars map[string]interface{}, handler func(types.BlocksData) error) error {
-	log.Printf("SubscribeOnce: starting one-time subscription with query: %s", query)
+	sc.logger.Debug("starting one-time subscription", "query", query)
 
 	if err := sc.Connect(ctx); err != nil {
 		return fmt.Errorf("connect websocket: %w", err)
 	}
 
-	log.Printf("SubscribeOnce: websocket connected successfully")
-
 	// generate unique ID for this subscription
 	subID := sc.generateID()
 
@@ -314,72 +306,62 @@ func (sc *SubscriptionClient) SubscribeOnce(ctx context.Context, query string, v
 		},
 	}
 
-	log.Printf("SubscribeOnce: sending start message with ID %s: %+v", subID, startMsg)
+	logger := sc.logger.With(logging.KeySubscriptionID, subID)
+	logger.Debug("sending start message")
 
 	if err := sc.conn.WriteJSON(startMsg); err != nil {
 		return fmt.Errorf("write start message: %w", err)
 	}
 
-	log.Printf("SubscribeOnce: start message sent successfully, waiting for data...")
-
 	// wait for data message
 	for {
 		select {
 		case <-ctx.Done():
-			log.Printf("SubscribeOnce: context cancelled for subscription %s", subID)
+			logger.Info("context cancelled")
 			sc.conn.Close()
 			return ctx.Err()
 		default:
 			var response gqlWSMessage
 			if err := sc.conn.ReadJSON(&response); err != nil {
-				log.Printf("SubscribeOnce: read subscription response error for subscription %s: %v", subID, err)
 				sc.conn.Close()
 				return fmt.Errorf("read subscription response: %w", err)
 			}
 
-			log.Printf("SubscribeOnce: received message for subscription %s, type: %s, payload: %+v", subID, response.Type, response.Payload)
+			logger.Debug("received message", "type", response.Type)
 
 			// check if this message is for our subscription
 			if response.ID != subID {
-				log.Printf("SubscribeOnce: message ID %s doesn't match subscription ID %s, skipping", response.ID, subID)
+				logger.Debug("message for another subscription, skipping", "message_id", response.ID)
 				continue
 			}
 
 			switch response.Type {
 			case GQL_DATA:
-				log.Printf("SubscribeOnce: handling DATA message for subscription %s", subID)
 				// handle data message
 				if payload, ok := response.Payload["data"]; ok {
-					log.Printf("SubscribeOnce: data payload found for subscription %s: %+v", subID, payload)
-
 					if data, ok := payload.(map[string]interface{}); ok {
 						// convert to BlocksData
 						jsonData, _ := json.Marshal(data)
-						log.Printf("SubscribeOnce: marshaled data for subscription %s: %s", subID, string(jsonData))
 
 						var blocksData types.BlocksData
 						if err := json.Unmarshal(jsonData, &blocksData); err == nil {
-							log.Printf("SubscribeOnce: unmarshaled successfully for subscription %s, block height: %d", subID, blocksData.GetBlocks.Height)
+							logger.Debug("received block", logging.KeyBlockHeight, blocksData.GetBlocks.Height)
+							metrics.SetNetworkHeight(blocksData.GetBlocks.Height)
 
 							if err := handler(blocksData); err != nil {
-								log.Printf("SubscribeOnce: handler error for subscription %s: %v", subID, err)
 								sc.conn.Close()
 								return fmt.Errorf("handler error: %w", err)
 							}
 
-							log.Printf("SubscribeOnce: handler executed successfully for subscription %s", subID)
 						} else {
-							log.Printf("SubscribeOnce: unmarshal error for subscription %s: %v", subID, err)
 							sc.conn.Close()
 							return fmt.Errorf("unmarshal error: %w", err)
 						}
 					} else {
-						log.Printf("SubscribeOnce: payload data is not map[string]interface{} for subscription %s: %T", subID, payload)
 						sc.conn.Close()
 						return fmt.Errorf("invalid payload data type: %T", payload)
 					}
 				} else {
-					log.Printf("SubscribeOnce: no data payload found in response for subscription %s", subID)
 					sc.conn.Close()
 					return fmt.Errorf("no data payload found")
 				}
@@ -391,37 +373,26 @@ func (sc *SubscriptionClient) SubscribeOnce(ctx context.Context, query string, v
 				}
 				sc.conn.WriteJSON(stopMsg)
 				sc.conn.Close()
-				log.Printf("SubscribeOnce: one-time subscription %s completed successfully", subID)
+				logger.Debug("one-time subscription completed")
 				return nil
 
 			case GQL_ERROR:
-				log.Printf("SubscribeOnce: handling ERROR message for subscription %s", subID)
-				if payload, ok := response.Payload["errors"]; ok {
-					log.Printf("SubscribeOnce: subscription error for subscription %s: %+v", subID, payload)
-				}
 				sc.conn.Close()
 				return fmt.Errorf("subscription error for subscription %s: %+v", subID, response.Payload)
 
 			case GQL_CONNECTION_ERROR:
-				log.Printf("SubscribeOnce: handling CONNECTION_ERROR message for subscription %s", subID)
-				if payload, ok := response.Payload["message"]; ok {
-					log.Printf("SubscribeOnce: connection error for subscription %s: %v", subID, payload)
-				} else {
-					log.Printf("SubscribeOnce: connection error for subscription %s: unknown reason", subID)
-				}
 				sc.conn.Close()
 				return fmt.Errorf("connection error for subscription %s: %+v", subID, response.Payload)
 
 			case GQL_COMPLETE:
-				log.Printf("SubscribeOnce: handling COMPLETE message for subscription %s", subID)
 				sc.conn.Close()
 				return fmt.Errorf("subscription %s completed without data", subID)
 
 			case GQL_CONNECTION_ACK:
-				log.Printf("SubscribeOnce: received connection_ack for subscription %s (this should not happen after start)", subID)
+				logger.Debug("unexpected connection_ack after start")
 
 			default:
-				log.Printf("SubscribeOnce: unknown message type %s for subscription %s", response.Type, subID)
+				logger.Warn("unknown message type", "type", response.Type)
 			}
 		}
 	}
@@ -429,7 +400,7 @@ func (sc *SubscriptionClient) SubscribeOnce(ctx context.Context, query string, v
 
 // reconnect attempts to reconnect to the websocket
 func (sc *SubscriptionClient) reconnect(ctx context.Context) error {
-	log.Printf("reconnect: attempting to reconnect...")
+	sc.logger.Info("reconnecting")
 
 	if sc.conn != nil {
 		sc.conn.Close()
@@ -441,7 +412,7 @@ func (sc *SubscriptionClient) reconnect(ctx context.Context) error {
 
 // resubscribe sends the subscription start message again
 func (sc *SubscriptionClient) resubscribe(subscription *Subscription) error {
-	log.Printf("resubscribe: sending start message again for subscription %s...", subscription.ID)
+	sc.logger.Debug("sending start message again", logging.KeySubscriptionID, subscription.ID)
 
 	startMsg := gqlWSMessage{
 		Type: GQL_START,
@@ -482,7 +453,7 @@ func (sc *SubscriptionClient) StopSubscription(subscriptionID string) error {
 	// mark subscription as inactive
 	subscription.Active = false
 
-	log.Printf("StopSubscription: subscription %s stopped successfully", subscriptionID)
+	sc.logger.Info("subscription stopped", logging.KeySubscriptionID, subscriptionID)
 	return nil
 }
 
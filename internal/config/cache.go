@@ -0,0 +1,55 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Response cache backends
+const (
+	CacheBackendMemory = "memory"
+	CacheBackendRedis  = "redis"
+	CacheBackendNone   = "none"
+)
+
+// CacheConfig holds balance API response cache configuration
+type CacheConfig struct {
+	Backend      string        `yaml:"backend" env:"CACHE_BACKEND"`             // memory, redis or none
+	Size         int           `yaml:"size" env:"CACHE_SIZE"`                   // in-memory capacity in responses
+	MaxStaleness time.Duration `yaml:"max_staleness" env:"CACHE_MAX_STALENESS"` // entries expire after this even if no invalidation arrives
+	RedisURL     string        `yaml:"redis_url" env:"REDIS_URL" secret:"url"`
+}
+
+// defaultCacheConfig caches up to 10000 responses in memory for 5 seconds
+func defaultCacheConfig() CacheConfig {
+	return CacheConfig{
+		Backend:      CacheBackendMemory,
+		Size:         10000,
+		MaxStaleness: 5 * time.Second,
+	}
+}
+
+// NewCacheConfig creates a cache config from CACHE_BACKEND, CACHE_SIZE, CACHE_MAX_STALENESS and REDIS_URL
+func NewCacheConfig() *CacheConfig {
+	cfg := defaultCacheConfig()
+	loadEnv(&cfg)
+	return &cfg
+}
+
+// Validate checks the backend is known and has what it needs
+func (c *CacheConfig) Validate() error {
+	switch c.Backend {
+	case CacheBackendMemory, CacheBackendNone:
+	case CacheBackendRedis:
+		if c.RedisURL == "" {
+			return errors.New("cache backend redis needs REDIS_URL")
+		}
+	default:
+		return fmt.Errorf("unknown cache backend %q", c.Backend)
+	}
+	if c.Size <= 0 || c.MaxStaleness <= 0 {
+		return errors.New("cache size and max staleness must be positive")
+	}
+	return nil
+}
@@ -1,30 +1,170 @@
 package config
 
 import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib" // registers the pgx driver replicas are opened with
 	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
-	"os"
+	"gorm.io/plugin/dbresolver"
 )
 
+// sqliteScheme selects the SQLite backend, the rest of the URL is the database file, e.g. sqlite://gn-indexer.db
+const sqliteScheme = "sqlite://"
+
+// sqliteParams enforces foreign keys like PostgreSQL does and waits for the file lock instead of failing at once
+const sqliteParams = "_foreign_keys=on&_busy_timeout=5000&_journal_mode=WAL"
+
+// postgresSearchPath resolves the unqualified table names to the indexer schema
+const postgresSearchPath = "indexer,public"
+
 // DatabaseConfig holds database configuration
 type DatabaseConfig struct {
-	DSN string
+	DSN               string        `yaml:"url" env:"DATABASE_URL" secret:"url"`
+	MaxOpenConns      int           `yaml:"max_open_conns" env:"DATABASE_MAX_OPEN_CONNS"`
+	MaxIdleConns      int           `yaml:"max_idle_conns" env:"DATABASE_MAX_IDLE_CONNS"`
+	ConnMaxLifetime   time.Duration `yaml:"conn_max_lifetime" env:"DATABASE_CONN_MAX_LIFETIME"`
+	ConnMaxIdleTime   time.Duration `yaml:"conn_max_idle_time" env:"DATABASE_CONN_MAX_IDLE_TIME"`
+	StatementTimeout  time.Duration `yaml:"statement_timeout" env:"DATABASE_STATEMENT_TIMEOUT"`    // server side limit of each statement, zero for none
+	PrepareStatements bool          `yaml:"prepare_statements" env:"DATABASE_PREPARE_STATEMENTS"`  // cache prepared statements per connection
+	ReplicaURLs       []string      `yaml:"replica_urls" env:"DATABASE_REPLICA_URLS" secret:"url"` // read replicas, only used by UseReplicas
+}
+
+// defaultDatabaseConfig keeps the pool small enough to run every binary against one local Postgres
+func defaultDatabaseConfig() DatabaseConfig {
+	return DatabaseConfig{
+		MaxOpenConns:      20,
+		MaxIdleConns:      10,
+		ConnMaxLifetime:   30 * time.Minute,
+		ConnMaxIdleTime:   5 * time.Minute,
+		PrepareStatements: true,
+	}
 }
 
-// NewDatabaseConfig creates a new database config
+// NewDatabaseConfig creates a database config from DATABASE_URL and the DATABASE_* pool settings, an empty URL is reported by Validate
 func NewDatabaseConfig() *DatabaseConfig {
-	dsn := os.Getenv("DATABASE_URL")
-	if dsn == "" {
-		panic("DATABASE_URL is empty")
+	cfg := defaultDatabaseConfig()
+	loadEnv(&cfg)
+	return &cfg
+}
+
+// Validate checks the database URL is set and the pool settings are usable
+func (c *DatabaseConfig) Validate() error {
+	var errs []error
+	if c.DSN == "" {
+		errs = append(errs, errors.New("database url is empty, set DATABASE_URL"))
+	}
+	if c.MaxOpenConns < 0 || c.MaxIdleConns < 0 || c.ConnMaxLifetime < 0 || c.ConnMaxIdleTime < 0 || c.StatementTimeout < 0 {
+		errs = append(errs, errors.New("database pool settings and statement timeout must not be negative"))
 	}
-	return &DatabaseConfig{DSN: dsn}
+	if c.MaxOpenConns > 0 && c.MaxIdleConns > c.MaxOpenConns {
+		errs = append(errs, fmt.Errorf("database max idle conns %d exceeds max open conns %d", c.MaxIdleConns, c.MaxOpenConns))
+	}
+	if c.IsSQLite() && len(c.ReplicaURLs) > 0 {
+		errs = append(errs, errors.New("database read replicas need PostgreSQL, unset DATABASE_REPLICA_URLS for SQLite"))
+	}
+	return errors.Join(errs...)
 }
 
-// Connect establishes database connection
+// IsSQLite reports whether the database URL selects the SQLite backend
+func (c *DatabaseConfig) IsSQLite() bool {
+	return strings.HasPrefix(c.DSN, sqliteScheme)
+}
+
+// Connect establishes the primary database connection with the configured pool and statement timeout.
+// A sqlite:// URL opens a SQLite file, which has no statement timeout, anything else connects to PostgreSQL.
 func (c *DatabaseConfig) Connect() (*gorm.DB, error) {
-	db, err := gorm.Open(postgres.Open(c.DSN), &gorm.Config{})
+	dialector := postgres.Open(c.withRuntimeParams(c.DSN))
+	if c.IsSQLite() {
+		dialector = sqlite.Open(withSQLiteParams(strings.TrimPrefix(c.DSN, sqliteScheme)))
+	}
+	db, err := gorm.Open(dialector, &gorm.Config{PrepareStmt: c.PrepareStatements})
 	if err != nil {
 		return nil, err
 	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	c.configurePool(sqlDB)
 	return db, nil
 }
+
+// UseReplicas routes reads of the given tables (models or table names) to the read replicas, writes and other tables stay on the primary.
+// It returns the replica connections, e.g. to monitor their lag, and none when no replica is configured.
+func (c *DatabaseConfig) UseReplicas(db *gorm.DB, tables ...interface{}) ([]*sql.DB, error) {
+	if len(c.ReplicaURLs) == 0 {
+		return nil, nil
+	}
+
+	replicas := make([]*sql.DB, 0, len(c.ReplicaURLs))
+	dialectors := make([]gorm.Dialector, 0, len(c.ReplicaURLs))
+	for i, dsn := range c.ReplicaURLs {
+		sqlDB, err := sql.Open("pgx", c.withRuntimeParams(dsn))
+		if err != nil {
+			return nil, fmt.Errorf("open replica %d: %w", i+1, err)
+		}
+		c.configurePool(sqlDB)
+		replicas = append(replicas, sqlDB)
+		dialectors = append(dialectors, postgres.New(postgres.Config{Conn: sqlDB}))
+	}
+
+	resolver := dbresolver.Register(dbresolver.Config{Replicas: dialectors, Policy: dbresolver.RandomPolicy{}}, tables...)
+	if err := db.Use(resolver); err != nil {
+		return nil, fmt.Errorf("register replicas: %w", err)
+	}
+	return replicas, nil
+}
+
+// configurePool applies the pool settings to a connection pool
+func (c *DatabaseConfig) configurePool(sqlDB *sql.DB) {
+	sqlDB.SetMaxOpenConns(c.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(c.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(c.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(c.ConnMaxIdleTime)
+}
+
+// withRuntimeParams adds the search_path, unless the DSN sets its own, and statement_timeout run-time parameters to a URL or keyword/value DSN
+func (c *DatabaseConfig) withRuntimeParams(dsn string) string {
+	var params [][2]string
+	if !strings.Contains(dsn, "search_path") {
+		params = append(params, [2]string{"search_path", postgresSearchPath})
+	}
+	if c.StatementTimeout > 0 {
+		params = append(params, [2]string{"statement_timeout", strconv.FormatInt(c.StatementTimeout.Milliseconds(), 10)})
+	}
+	if len(params) == 0 {
+		return dsn
+	}
+
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		u, err := url.Parse(dsn)
+		if err == nil {
+			query := u.Query()
+			for _, param := range params {
+				query.Set(param[0], param[1])
+			}
+			u.RawQuery = query.Encode()
+			return u.String()
+		}
+	}
+	for _, param := range params {
+		dsn += " " + param[0] + "=" + param[1]
+	}
+	return dsn
+}
+
+// withSQLiteParams adds the connection parameters to a SQLite file name
+func withSQLiteParams(path string) string {
+	if strings.Contains(path, "?") {
+		return path + "&" + sqliteParams
+	}
+	return path + "?" + sqliteParams
+}
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"errors"
+	"time"
+)
+
+// HealthConfig holds the readiness thresholds of the /readyz endpoints
+type HealthConfig struct {
+	MaxLagBlocks    int64         `yaml:"max_lag_blocks" env:"HEALTH_MAX_LAG_BLOCKS"`       // blocks the synced height may trail the network tip
+	MaxQueueBacklog int64         `yaml:"max_queue_backlog" env:"HEALTH_MAX_QUEUE_BACKLOG"` // messages the event processor may leave waiting in the queue
+	MaxReplicaLag   time.Duration `yaml:"max_replica_lag" env:"HEALTH_MAX_REPLICA_LAG"`     // time a read replica may trail the primary
+}
+
+// defaultHealthConfig tolerates a short lag and a full batch cycle of backlog
+func defaultHealthConfig() HealthConfig {
+	return HealthConfig{
+		MaxLagBlocks:    100,
+		MaxQueueBacklog: 10000,
+		MaxReplicaLag:   30 * time.Second,
+	}
+}
+
+// NewHealthConfig creates a health config from HEALTH_MAX_LAG_BLOCKS, HEALTH_MAX_QUEUE_BACKLOG and HEALTH_MAX_REPLICA_LAG
+func NewHealthConfig() *HealthConfig {
+	cfg := defaultHealthConfig()
+	loadEnv(&cfg)
+	return &cfg
+}
+
+// Validate checks the thresholds are not negative, zero disables a threshold
+func (c *HealthConfig) Validate() error {
+	if c.MaxLagBlocks < 0 || c.MaxQueueBacklog < 0 || c.MaxReplicaLag < 0 {
+		return errors.New("health thresholds must not be negative")
+	}
+	return nil
+}
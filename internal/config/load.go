@@ -0,0 +1,221 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// redactedValue replaces secrets in the effective configuration
+const redactedValue = "xxxxx"
+
+// Validator is a configuration checking its values before a service starts
+type Validator interface {
+	Validate() error
+}
+
+// Load fills cfg, which already holds the defaults, and validates it. The sources are applied in order of precedence:
+// the YAML file named by -config or CONFIG_FILE, then the environment variables in `env` tags, then flags set on the command line.
+func Load(cfg Validator, fs *flag.FlagSet, args []string) error {
+	path := fs.String("config", os.Getenv("CONFIG_FILE"), "YAML configuration file, overridden by environment variables and flags")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	// Flags are bound to cfg, keep what the command line set before the file and environment overwrite it
+	set := make(map[string]string)
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = f.Value.String()
+	})
+
+	if *path != "" {
+		if err := loadFile(cfg, *path); err != nil {
+			return err
+		}
+	}
+	if err := loadEnv(cfg); err != nil {
+		return err
+	}
+	for name, value := range set {
+		if err := fs.Set(name, value); err != nil {
+			return fmt.Errorf("flag -%s: %w", name, err)
+		}
+	}
+
+	return cfg.Validate()
+}
+
+// loadFile decodes a YAML file over cfg, unknown keys are rejected to catch typos
+func loadFile(cfg interface{}, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open config file: %w", err)
+	}
+	defer file.Close()
+
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil {
+		return fmt.Errorf("decode config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// loadEnv sets every field tagged `env` whose variable is set and not empty.
+// Invalid values are reported together and leave their field unchanged.
+func loadEnv(cfg interface{}) error {
+	var errs []error
+	walk(reflect.ValueOf(cfg).Elem(), "", func(field reflect.Value, tag reflect.StructField, key string) {
+		name := tag.Tag.Get("env")
+		if name == "" {
+			return
+		}
+		value, ok := os.LookupEnv(name)
+		if !ok || value == "" {
+			return
+		}
+		if err := setValue(field, value); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	})
+	return errors.Join(errs...)
+}
+
+// walk calls fn for each leaf field of a struct, key is the dotted path of YAML names
+func walk(v reflect.Value, prefix string, fn func(field reflect.Value, tag reflect.StructField, key string)) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		tag := t.Field(i)
+		if !tag.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(tag.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(tag.Name)
+		}
+		key := prefix + name
+
+		field := v.Field(i)
+		if field.Kind() == reflect.Struct && field.Type() != reflect.TypeOf(time.Duration(0)) {
+			walk(field, key+".", fn)
+			continue
+		}
+		fn(field, tag, key)
+	}
+}
+
+// setValue parses an environment value into a field
+func setValue(field reflect.Value, value string) error {
+	switch field.Interface().(type) {
+	case string:
+		field.SetString(value)
+	case bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", value)
+		}
+		field.SetBool(b)
+	case int, int64:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", value)
+		}
+		field.SetInt(n)
+	case float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", value)
+		}
+		field.SetFloat(f)
+	case time.Duration:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", value)
+		}
+		field.SetInt(int64(d))
+	case []string:
+		// values separated by commas, blank entries are skipped
+		var list []string
+		for _, entry := range strings.Split(value, ",") {
+			if entry = strings.TrimSpace(entry); entry != "" {
+				list = append(list, entry)
+			}
+		}
+		field.Set(reflect.ValueOf(list))
+	case map[string]string:
+		// key=value pairs separated by commas, malformed entries are skipped
+		m := make(map[string]string)
+		for _, entry := range strings.Split(value, ",") {
+			k, v, ok := strings.Cut(strings.TrimSpace(entry), "=")
+			if ok && strings.TrimSpace(k) != "" {
+				m[strings.TrimSpace(k)] = strings.TrimSpace(v)
+			}
+		}
+		field.Set(reflect.ValueOf(m))
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+	return nil
+}
+
+// LogAttrs returns the effective configuration as log attributes keyed by YAML path, e.g. sync.chunk_size.
+// Fields tagged `secret:"true"` are redacted, `secret:"url"` only hides the password of a URL.
+func LogAttrs(cfg interface{}) []any {
+	var attrs []any
+	walk(reflect.ValueOf(cfg).Elem(), "", func(field reflect.Value, tag reflect.StructField, key string) {
+		value := field.Interface()
+		if s, ok := value.(string); ok && s != "" {
+			switch tag.Tag.Get("secret") {
+			case "true":
+				value = redactedValue
+			case "url":
+				value = redactURL(s)
+			}
+		}
+		if list, ok := value.([]string); ok && tag.Tag.Get("secret") == "url" {
+			redacted := make([]string, len(list))
+			for i, s := range list {
+				redacted[i] = redactURL(s)
+			}
+			value = redacted
+		}
+		if d, ok := value.(time.Duration); ok {
+			value = d.String()
+		}
+		attrs = append(attrs, slog.Any(key, value))
+	})
+	return attrs
+}
+
+// dsnPassword matches the password of a keyword/value DSN such as "host=db password='p w'"
+var dsnPassword = regexp.MustCompile(`(?i)(\bpassword\s*=\s*)('(?:[^'\\]|\\.)*'|\S*)`)
+
+// redactURL hides the password of a URL or of a keyword/value DSN, values that do not parse are hidden entirely
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return redactedValue
+	}
+	if u.Scheme == "" {
+		return dsnPassword.ReplaceAllString(raw, "${1}"+redactedValue)
+	}
+	// libpq also reads the password from the query string
+	if query := u.Query(); query.Has("password") {
+		query.Set("password", redactedValue)
+		u.RawQuery = query.Encode()
+	}
+	return u.Redacted()
+}
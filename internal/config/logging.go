@@ -0,0 +1,30 @@
+package config
+
+// Log output formats
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+)
+
+// LogConfig holds structured logging configuration
+type LogConfig struct {
+	Level      string            `yaml:"level" env:"LOG_LEVEL"`       // default level: debug, info, warn or error
+	Format     string            `yaml:"format" env:"LOG_FORMAT"`     // text or json
+	Components map[string]string `yaml:"components" env:"LOG_LEVELS"` // level overrides by component, e.g. websocket=debug
+}
+
+// defaultLogConfig logs at info level as text
+func defaultLogConfig() LogConfig {
+	return LogConfig{
+		Level:      "info",
+		Format:     LogFormatText,
+		Components: make(map[string]string),
+	}
+}
+
+// NewLogConfig creates a log config from LOG_LEVEL, LOG_FORMAT and LOG_LEVELS ("websocket=debug,balance=warn")
+func NewLogConfig() *LogConfig {
+	cfg := defaultLogConfig()
+	loadEnv(&cfg)
+	return &cfg
+}
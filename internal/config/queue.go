@@ -0,0 +1,36 @@
+package config
+
+import "errors"
+
+// QueueConfig holds the SQS queue shared by the block syncer and the event processor
+type QueueConfig struct {
+	Name              string `yaml:"name" env:"SQS_QUEUE_NAME"`
+	EndpointURL       string `yaml:"endpoint_url" env:"LOCALSTACK_URL"` // LocalStack in development, empty for AWS
+	Region            string `yaml:"region" env:"AWS_DEFAULT_REGION"`
+	AccessKeyID       string `yaml:"access_key_id" env:"AWS_ACCESS_KEY_ID" secret:"true"`
+	SecretAccessKey   string `yaml:"secret_access_key" env:"AWS_SECRET_ACCESS_KEY" secret:"true"`
+	VisibilityTimeout int    `yaml:"visibility_timeout" env:"SQS_VISIBILITY_TIMEOUT"` // seconds a received message stays hidden
+}
+
+// defaultQueueConfig points at the LocalStack queue of docker-compose.yml
+func defaultQueueConfig() QueueConfig {
+	return QueueConfig{
+		Name:              "gn-token-events",
+		EndpointURL:       "http://localhost:4566",
+		Region:            "ap-northeast-2",
+		AccessKeyID:       "test",
+		SecretAccessKey:   "test",
+		VisibilityTimeout: 30,
+	}
+}
+
+// Validate checks the queue name and region are set
+func (c *QueueConfig) Validate() error {
+	if c.Name == "" || c.Region == "" {
+		return errors.New("queue name and region are required")
+	}
+	if c.VisibilityTimeout < 0 {
+		return errors.New("queue visibility timeout must not be negative")
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// BlockSyncerConfig is the configuration of cmd/block-syncer
+type BlockSyncerConfig struct {
+	Database    DatabaseConfig `yaml:"database"`
+	Queue       QueueConfig    `yaml:"queue"`
+	GraphQL     GraphQLConfig  `yaml:"graphql"`
+	Sync        SyncConfig     `yaml:"sync"`
+	Log         LogConfig      `yaml:"log"`
+	Tracing     TracingConfig  `yaml:"tracing"`
+	Health      HealthConfig   `yaml:"health"`
+	MetricsAddr string         `yaml:"metrics_addr"` // serves /metrics, /healthz and /readyz, empty to disable
+}
+
+// DefaultBlockSyncerConfig returns the block syncer defaults
+func DefaultBlockSyncerConfig() *BlockSyncerConfig {
+	return &BlockSyncerConfig{
+		Database:    defaultDatabaseConfig(),
+		Queue:       defaultQueueConfig(),
+		GraphQL:     defaultGraphQLConfig(),
+		Sync:        defaultSyncConfig(),
+		Log:         defaultLogConfig(),
+		Tracing:     defaultTracingConfig(),
+		Health:      defaultHealthConfig(),
+		MetricsAddr: ":9101",
+	}
+}
+
+// RegisterFlags binds the block syncer flags to the config
+func (c *BlockSyncerConfig) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.MetricsAddr, "metrics-addr", c.MetricsAddr, "address serving /metrics, /healthz and /readyz, empty to disable")
+	fs.IntVar(&c.Sync.ChunkSize, "chunk-size", c.Sync.ChunkSize, "blocks per range query of backfill and integrity runs")
+}
+
+// Validate checks every section
+func (c *BlockSyncerConfig) Validate() error {
+	return errors.Join(c.Database.Validate(), c.Queue.Validate(), c.GraphQL.Validate(), c.Sync.Validate(), c.Tracing.Validate(), c.Health.Validate())
+}
+
+// EventProcessorConfig is the configuration of cmd/event-processor
+type EventProcessorConfig struct {
+	Database          DatabaseConfig `yaml:"database"`
+	Queue             QueueConfig    `yaml:"queue"`
+	Cache             CacheConfig    `yaml:"cache"` // a redis cache is invalidated after balances change
+	Log               LogConfig      `yaml:"log"`
+	Tracing           TracingConfig  `yaml:"tracing"`
+	Health            HealthConfig   `yaml:"health"`
+	MetricsAddr       string         `yaml:"metrics_addr"`
+	BatchSize         int            `yaml:"batch_size"` // messages received per poll, SQS allows up to 10
+	MetadataInterval  time.Duration  `yaml:"metadata_interval"`
+	TokenMetadataFile string         `yaml:"token_metadata_file" env:"TOKEN_METADATA_FILE"`
+	GnoRPCEndpoint    string         `yaml:"gno_rpc_endpoint" env:"GNO_RPC_ENDPOINT"`
+	GnoRPCTimeout     time.Duration  `yaml:"gno_rpc_timeout" env:"GNO_RPC_TIMEOUT"`
+	WebhookTimeout    time.Duration  `yaml:"webhook_timeout" env:"WEBHOOK_TIMEOUT"`
+}
+
+// DefaultEventProcessorConfig returns the event processor defaults
+func DefaultEventProcessorConfig() *EventProcessorConfig {
+	return &EventProcessorConfig{
+		Database:         defaultDatabaseConfig(),
+		Queue:            defaultQueueConfig(),
+		Cache:            defaultCacheConfig(),
+		Log:              defaultLogConfig(),
+		Tracing:          defaultTracingConfig(),
+		Health:           defaultHealthConfig(),
+		MetricsAddr:      ":9102",
+		BatchSize:        10,
+		MetadataInterval: 10 * time.Minute,
+		GnoRPCTimeout:    10 * time.Second,
+		WebhookTimeout:   10 * time.Second,
+	}
+}
+
+// RegisterFlags binds the event processor flags to the config
+func (c *EventProcessorConfig) RegisterFlags(fs *flag.FlagSet) {
+	fs.IntVar(&c.BatchSize, "batch", c.BatchSize, "batch size for processing events")
+	fs.DurationVar(&c.MetadataInterval, "metadata-interval", c.MetadataInterval, "token metadata refresh interval")
+	fs.StringVar(&c.MetricsAddr, "metrics-addr", c.MetricsAddr, "address serving /metrics, /healthz and /readyz, empty to disable")
+}
+
+// Validate checks every section
+func (c *EventProcessorConfig) Validate() error {
+	var errs []error
+	if c.BatchSize < 1 || c.BatchSize > 10 {
+		errs = append(errs, fmt.Errorf("batch size %d is not between 1 and 10", c.BatchSize))
+	}
+	if c.MetadataInterval <= 0 || c.GnoRPCTimeout <= 0 || c.WebhookTimeout <= 0 {
+		errs = append(errs, errors.New("metadata interval, gno rpc timeout and webhook timeout must be positive"))
+	}
+	return errors.Join(append(errs, c.Database.Validate(), c.Queue.Validate(), c.Cache.Validate(), c.Tracing.Validate(), c.Health.Validate())...)
+}
+
+// BalanceAPIConfig is the configuration of cmd/balance-api
+type BalanceAPIConfig struct {
+	Database             DatabaseConfig `yaml:"database"`
+	Cache                CacheConfig    `yaml:"cache"`
+	Log                  LogConfig      `yaml:"log"`
+	Tracing              TracingConfig  `yaml:"tracing"`
+	Health               HealthConfig   `yaml:"health"`
+	Host                 string         `yaml:"host"`
+	Port                 string         `yaml:"port"`
+	SnapshotDir          string         `yaml:"snapshot_dir" env:"SNAPSHOT_DIR"`
+	SnapshotWorkers      int            `yaml:"snapshot_workers" env:"SNAPSHOT_WORKERS"`     // snapshot jobs exported at the same time
+	SnapshotRetention    time.Duration  `yaml:"snapshot_retention" env:"SNAPSHOT_RETENTION"` // exported files are deleted after this long
+	APIKeysRequired      bool           `yaml:"api_keys_required" env:"API_KEYS_REQUIRED"`
+	AdminToken           string         `yaml:"admin_token" env:"ADMIN_TOKEN" secret:"true"`         // enables the /admin endpoints issuing API keys
+	StreamAllowedOrigins []string       `yaml:"stream_allowed_origins" env:"STREAM_ALLOWED_ORIGINS"` // browser origins besides the API's own allowed on /stream/ws, "*" allows any
+}
+
+// DefaultBalanceAPIConfig returns the balance API defaults
+func DefaultBalanceAPIConfig() *BalanceAPIConfig {
+	// API queries are short, a runaway one is cancelled instead of holding a connection
+	database := defaultDatabaseConfig()
+	database.StatementTimeout = 10 * time.Second
+
+	return &BalanceAPIConfig{
+		Database:          database,
+		Cache:             defaultCacheConfig(),
+		Log:               defaultLogConfig(),
+		Tracing:           defaultTracingConfig(),
+		Health:            defaultHealthConfig(),
+		Host:              "127.0.0.1",
+		Port:              "8080",
+		SnapshotDir:       filepath.Join(os.TempDir(), "gn-snapshots"),
+		SnapshotWorkers:   2,
+		SnapshotRetention: 24 * time.Hour,
+	}
+}
+
+// RegisterFlags binds the balance API flags to the config
+func (c *BalanceAPIConfig) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.Port, "port", c.Port, "server port")
+	fs.StringVar(&c.Host, "host", c.Host, "server host")
+}
+
+// Validate checks every section
+func (c *BalanceAPIConfig) Validate() error {
+	var errs []error
+	if c.Port == "" {
+		errs = append(errs, errors.New("port is empty"))
+	}
+	if c.SnapshotDir == "" {
+		errs = append(errs, errors.New("snapshot dir is empty"))
+	}
+	if c.SnapshotWorkers < 1 || c.SnapshotRetention <= 0 {
+		errs = append(errs, errors.New("snapshot workers and snapshot retention must be positive"))
+	}
+	return errors.Join(append(errs, c.Database.Validate(), c.Cache.Validate(), c.Tracing.Validate(), c.Health.Validate())...)
+}
+
+// HolderSnapshotConfig is the configuration of cmd/holder-snapshot, the export itself is described by its flags
+type HolderSnapshotConfig struct {
+	Database DatabaseConfig `yaml:"database"`
+	Log      LogConfig      `yaml:"log"`
+}
+
+// DefaultHolderSnapshotConfig returns the holder snapshot defaults
+func DefaultHolderSnapshotConfig() *HolderSnapshotConfig {
+	return &HolderSnapshotConfig{Database: defaultDatabaseConfig(), Log: defaultLogConfig()}
+}
+
+// Validate checks every section
+func (c *HolderSnapshotConfig) Validate() error {
+	return c.Database.Validate()
+}
+
+// MigrateConfig is the configuration of cmd/migrate
+type MigrateConfig struct {
+	Database DatabaseConfig `yaml:"database"`
+	Log      LogConfig      `yaml:"log"`
+}
+
+// DefaultMigrateConfig returns the migrate defaults
+func DefaultMigrateConfig() *MigrateConfig {
+	return &MigrateConfig{Database: defaultDatabaseConfig(), Log: defaultLogConfig()}
+}
+
+// Validate checks every section
+func (c *MigrateConfig) Validate() error {
+	return c.Database.Validate()
+}
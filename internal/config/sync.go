@@ -0,0 +1,62 @@
+package config
+
+import (
+	"errors"
+	"time"
+)
+
+// GraphQLConfig holds the tx-indexer GraphQL endpoints the block syncer reads from
+type GraphQLConfig struct {
+	Endpoint       string        `yaml:"endpoint" env:"GRAPHQL_ENDPOINT"`
+	WSEndpoint     string        `yaml:"ws_endpoint" env:"GRAPHQL_WS_ENDPOINT"`
+	Timeout        time.Duration `yaml:"timeout" env:"GRAPHQL_TIMEOUT"`                    // per HTTP query
+	ReconnectDelay time.Duration `yaml:"reconnect_delay" env:"GRAPHQL_WS_RECONNECT_DELAY"` // wait before reconnecting a dropped subscription
+}
+
+// defaultGraphQLConfig has no endpoints, they depend on the network
+func defaultGraphQLConfig() GraphQLConfig {
+	return GraphQLConfig{
+		Timeout:        20 * time.Second,
+		ReconnectDelay: 2 * time.Second,
+	}
+}
+
+// Validate checks both endpoints are set
+func (c *GraphQLConfig) Validate() error {
+	if c.Endpoint == "" || c.WSEndpoint == "" {
+		return errors.New("graphql endpoints are empty, set GRAPHQL_ENDPOINT and GRAPHQL_WS_ENDPOINT")
+	}
+	if c.Timeout <= 0 || c.ReconnectDelay < 0 {
+		return errors.New("graphql timeout must be positive and reconnect delay not negative")
+	}
+	return nil
+}
+
+// SyncConfig tunes how blocks and transactions are fetched
+type SyncConfig struct {
+	ChunkSize     int           `yaml:"chunk_size" env:"SYNC_CHUNK_SIZE"`         // blocks per range query of backfill and integrity runs
+	TxIndexLimit  int           `yaml:"tx_index_limit" env:"SYNC_TX_INDEX_LIMIT"` // transactions fetched per block, the imax of the query
+	RetryAttempts int           `yaml:"retry_attempts" env:"SYNC_RETRY_ATTEMPTS"` // attempts at a realtime block before giving up
+	RetryDelay    time.Duration `yaml:"retry_delay" env:"SYNC_RETRY_DELAY"`       // wait between realtime attempts
+}
+
+// defaultSyncConfig stays under the tx-indexer query limits
+func defaultSyncConfig() SyncConfig {
+	return SyncConfig{
+		ChunkSize:     1000,
+		TxIndexLimit:  1000,
+		RetryAttempts: 3,
+		RetryDelay:    500 * time.Millisecond,
+	}
+}
+
+// Validate checks the sizes and attempts are positive
+func (c *SyncConfig) Validate() error {
+	if c.ChunkSize <= 0 || c.TxIndexLimit <= 0 || c.RetryAttempts <= 0 {
+		return errors.New("sync chunk size, tx index limit and retry attempts must be positive")
+	}
+	if c.RetryDelay < 0 {
+		return errors.New("sync retry delay must not be negative")
+	}
+	return nil
+}
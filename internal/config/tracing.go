@@ -0,0 +1,45 @@
+package config
+
+import "fmt"
+
+// Trace exporters
+const (
+	TracingExporterNone   = "none"
+	TracingExporterStdout = "stdout"
+	TracingExporterOTLP   = "otlp"
+)
+
+// TracingConfig holds OpenTelemetry tracing configuration
+type TracingConfig struct {
+	Exporter    string  `yaml:"exporter" env:"OTEL_TRACES_EXPORTER"`        // none, stdout or otlp
+	SampleRatio float64 `yaml:"sample_ratio" env:"OTEL_TRACES_SAMPLER_ARG"` // share of new traces recorded, spans continuing a remote trace follow its decision
+}
+
+// defaultTracingConfig records every trace but exports none
+func defaultTracingConfig() TracingConfig {
+	return TracingConfig{
+		Exporter:    TracingExporterNone,
+		SampleRatio: 1,
+	}
+}
+
+// NewTracingConfig creates a tracing config from OTEL_TRACES_EXPORTER and OTEL_TRACES_SAMPLER_ARG.
+// The OTLP exporter reads its endpoint and headers from the standard OTEL_EXPORTER_OTLP_* variables.
+func NewTracingConfig() *TracingConfig {
+	cfg := defaultTracingConfig()
+	loadEnv(&cfg)
+	return &cfg
+}
+
+// Validate checks the exporter is known and the sample ratio is a share
+func (c *TracingConfig) Validate() error {
+	switch c.Exporter {
+	case TracingExporterNone, TracingExporterStdout, TracingExporterOTLP, "":
+	default:
+		return fmt.Errorf("unknown trace exporter %q", c.Exporter)
+	}
+	if c.SampleRatio < 0 || c.SampleRatio > 1 {
+		return fmt.Errorf("trace sample ratio %v is not between 0 and 1", c.SampleRatio)
+	}
+	return nil
+}
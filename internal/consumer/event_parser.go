@@ -1,6 +1,7 @@
 package consumer
 
 import (
+	"errors"
 	"fmt"
 	"gn-indexer/internal/domain"
 	"strconv"
@@ -14,24 +15,38 @@ func NewEventParser() *EventParser {
 	return &EventParser{}
 }
 
-// ParseEventsFromTransaction parses all events from a transaction
+// ParseEventsFromTransaction parses all events from a transaction.
+// An event that fails to parse is left out and reported in the joined error, the other events are still returned.
 func (ep *EventParser) ParseEventsFromTransaction(tx *domain.Transaction) ([]domain.ParsedEvent, error) {
 	var events []domain.ParsedEvent
+	var errs []error
 
 	// Parse events from response_json
 	if tx.Response != nil {
 		for i, event := range tx.Response.Events {
-			if ep.IsTokenEvent(&event) {
-				parsedEvent, err := ep.ParseTokenEvent(&event, tx, i)
-				if err != nil {
-					return nil, fmt.Errorf("parse event %d: %w", i, err)
-				}
-				events = append(events, *parsedEvent)
+			var parsedEvent *domain.ParsedEvent
+			var err error
+			switch {
+			case ep.IsNFTEvent(&event):
+				parsedEvent, err = ep.ParseNFTEvent(&event, tx, i)
+			case ep.IsNFTApprovalEvent(&event):
+				parsedEvent, err = ep.ParseNFTApprovalEvent(&event, tx, i)
+			case ep.IsTokenEvent(&event):
+				parsedEvent, err = ep.ParseTokenEvent(&event, tx, i)
+			case ep.IsApprovalEvent(&event):
+				parsedEvent, err = ep.ParseApprovalEvent(&event, tx, i)
+			default:
+				continue
 			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("parse %s event %d: %w", event.Type, i, err))
+				continue
+			}
+			events = append(events, *parsedEvent)
 		}
 	}
 
-	return events, nil
+	return events, errors.Join(errs...)
 }
 
 // IsTokenEvent checks if an event is a token-related event
@@ -41,6 +56,11 @@ func (ep *EventParser) IsTokenEvent(event *domain.GnoEvent) bool {
 		return false
 	}
 
+	// GRC721 transfers share the type but carry a token id instead of a value
+	if ep.IsNFTEvent(event) {
+		return false
+	}
+
 	// Must have one of the token functions
 	switch event.Func {
 	case "Mint", "Burn", "Transfer":
@@ -50,18 +70,57 @@ func (ep *EventParser) IsTokenEvent(event *domain.GnoEvent) bool {
 	}
 }
 
+// IsNFTEvent checks if an event is a GRC721 Transfer event (keyed by token id)
+func (ep *EventParser) IsNFTEvent(event *domain.GnoEvent) bool {
+	if event.Type != "Transfer" {
+		return false
+	}
+	return nftTokenID(event) != ""
+}
+
+// nftTokenID returns the GRC721 token id attribute, realms use either "tokenId" or "tid"
+func nftTokenID(event *domain.GnoEvent) string {
+	for _, attr := range event.Attrs {
+		if attr.Key == "tokenId" || attr.Key == "tid" {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// IsApprovalEvent checks if an event is a GRC20 Approval event (owner, spender, value)
+func (ep *EventParser) IsApprovalEvent(event *domain.GnoEvent) bool {
+	return event.Type == "Approval" && hasAttr(event, "spender") && hasAttr(event, "value")
+}
+
+// IsNFTApprovalEvent checks if an event is a GRC721 Approval event (owner, to, token id)
+func (ep *EventParser) IsNFTApprovalEvent(event *domain.GnoEvent) bool {
+	return event.Type == "Approval" && nftTokenID(event) != ""
+}
+
+// hasAttr reports whether an event carries the attribute key
+func hasAttr(event *domain.GnoEvent, key string) bool {
+	for _, attr := range event.Attrs {
+		if attr.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
 // ParseTokenEvent parses a single token event
 func (ep *EventParser) ParseTokenEvent(event *domain.GnoEvent, tx *domain.Transaction, eventIndex int) (*domain.ParsedEvent, error) {
 	// Extract attributes
 	var fromAddr, toAddr string
 	var amount int64
+	var malformed []string
 
 	for _, attr := range event.Attrs {
 		switch attr.Key {
 		case "from":
-			fromAddr = attr.Value
+			fromAddr = parseAddressAttr(attr, &malformed)
 		case "to":
-			toAddr = attr.Value
+			toAddr = parseAddressAttr(attr, &malformed)
 		case "value":
 			if val, err := strconv.ParseInt(attr.Value, 10, 64); err == nil {
 				amount = val
@@ -75,18 +134,142 @@ func (ep *EventParser) ParseTokenEvent(event *domain.GnoEvent, tx *domain.Transa
 	eventType := ep.determineEventType(event.Func, fromAddr, toAddr)
 
 	return &domain.ParsedEvent{
-		Type:        event.Type,
-		Func:        eventType,
-		TokenPath:   event.PkgPath,
-		FromAddress: fromAddr,
-		ToAddress:   toAddr,
-		Amount:      amount,
-		TxHash:      tx.Hash,
-		BlockHeight: int64(tx.BlockHeight),
-		EventIndex:  eventIndex,
+		Type:           event.Type,
+		Func:           eventType,
+		TokenPath:      event.PkgPath,
+		FromAddress:    fromAddr,
+		ToAddress:      toAddr,
+		Amount:         amount,
+		TxHash:         tx.Hash,
+		BlockHeight:    int64(tx.BlockHeight),
+		EventIndex:     eventIndex,
+		MalformedAttrs: malformed,
 	}, nil
 }
 
+// ParseApprovalEvent parses a single Approval event (owner, spender, value)
+func (ep *EventParser) ParseApprovalEvent(event *domain.GnoEvent, tx *domain.Transaction, eventIndex int) (*domain.ParsedEvent, error) {
+	var owner, spender string
+	var amount int64
+	var malformed []string
+
+	for _, attr := range event.Attrs {
+		switch attr.Key {
+		case "owner":
+			owner = parseAddressAttr(attr, &malformed)
+		case "spender":
+			spender = parseAddressAttr(attr, &malformed)
+		case "value":
+			val, err := strconv.ParseInt(attr.Value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid amount value: %s", attr.Value)
+			}
+			amount = val
+		}
+	}
+
+	if owner == "" || spender == "" {
+		return nil, fmt.Errorf("approval event requires owner and spender")
+	}
+
+	return &domain.ParsedEvent{
+		Type:           event.Type,
+		Func:           domain.EventTypeApproval,
+		TokenPath:      event.PkgPath,
+		FromAddress:    owner,
+		ToAddress:      spender,
+		Amount:         amount,
+		TxHash:         tx.Hash,
+		BlockHeight:    int64(tx.BlockHeight),
+		EventIndex:     eventIndex,
+		MalformedAttrs: malformed,
+	}, nil
+}
+
+// ParseNFTEvent parses a single GRC721 Transfer event, classified by its from/to addresses
+func (ep *EventParser) ParseNFTEvent(event *domain.GnoEvent, tx *domain.Transaction, eventIndex int) (*domain.ParsedEvent, error) {
+	var fromAddr, toAddr string
+	var malformed []string
+	for _, attr := range event.Attrs {
+		switch attr.Key {
+		case "from":
+			fromAddr = parseAddressAttr(attr, &malformed)
+		case "to":
+			toAddr = parseAddressAttr(attr, &malformed)
+		}
+	}
+
+	var eventType domain.EventType
+	switch {
+	case fromAddr == "" && toAddr != "":
+		eventType = domain.EventTypeNFTMint
+	case fromAddr != "" && toAddr == "":
+		eventType = domain.EventTypeNFTBurn
+	case fromAddr != "" && toAddr != "":
+		eventType = domain.EventTypeNFTTransfer
+	default:
+		return nil, fmt.Errorf("nft event requires from or to address")
+	}
+
+	return &domain.ParsedEvent{
+		Type:           event.Type,
+		Func:           eventType,
+		TokenPath:      event.PkgPath,
+		FromAddress:    fromAddr,
+		ToAddress:      toAddr,
+		TokenID:        nftTokenID(event),
+		TxHash:         tx.Hash,
+		BlockHeight:    int64(tx.BlockHeight),
+		EventIndex:     eventIndex,
+		MalformedAttrs: malformed,
+	}, nil
+}
+
+// ParseNFTApprovalEvent parses a single GRC721 Approval event, FromAddress carries the owner and ToAddress the approved address
+func (ep *EventParser) ParseNFTApprovalEvent(event *domain.GnoEvent, tx *domain.Transaction, eventIndex int) (*domain.ParsedEvent, error) {
+	var owner, approved string
+	var malformed []string
+	for _, attr := range event.Attrs {
+		switch attr.Key {
+		case "owner":
+			owner = parseAddressAttr(attr, &malformed)
+		case "to", "approved":
+			approved = parseAddressAttr(attr, &malformed)
+		}
+	}
+
+	if owner == "" {
+		return nil, fmt.Errorf("nft approval event requires owner")
+	}
+
+	return &domain.ParsedEvent{
+		Type:           event.Type,
+		Func:           domain.EventTypeNFTApproval,
+		TokenPath:      event.PkgPath,
+		FromAddress:    owner,
+		ToAddress:      approved,
+		TokenID:        nftTokenID(event),
+		TxHash:         tx.Hash,
+		BlockHeight:    int64(tx.BlockHeight),
+		EventIndex:     eventIndex,
+		MalformedAttrs: malformed,
+	}, nil
+}
+
+// parseAddressAttr returns the canonical form of an address attr, empty values mean mint or burn.
+// Values that fail bech32 validation are kept as emitted and their key is added to malformed.
+func parseAddressAttr(attr domain.Attr, malformed *[]string) string {
+	if attr.Value == "" {
+		return ""
+	}
+	addr, err := domain.ParseAddress(attr.Value)
+	if err != nil {
+		*malformed = append(*malformed, attr.Key)
+		return attr.Value
+	}
+	return addr.String()
+}
+
 // determineEventType determines the event type based on function and addresses
 func (ep *EventParser) determineEventType(funcName, fromAddr, toAddr string) domain.EventType {
 	switch funcName {
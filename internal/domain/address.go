@@ -0,0 +1,122 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// AddressHRP is the bech32 human readable part of gno addresses
+const AddressHRP = "g"
+
+// addressLength is the byte length of the account key hash an address encodes
+const addressLength = 20
+
+// bech32Charset maps 5-bit values to bech32 characters
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+// ErrInvalidAddress is returned for strings that are not a valid gno address
+var ErrInvalidAddress = errors.New("invalid gno address")
+
+// Address is a validated gno bech32 address in its canonical lowercase form
+type Address string
+
+// ParseAddress validates a g1... address, including its bech32 checksum, and returns it lowercased.
+// All-uppercase input is accepted as bech32 allows it, mixed case is rejected.
+func ParseAddress(s string) (Address, error) {
+	lower := strings.ToLower(s)
+	if lower != s && strings.ToUpper(s) != s {
+		return "", fmt.Errorf("%w: mixed case", ErrInvalidAddress)
+	}
+
+	sep := strings.LastIndexByte(lower, '1')
+	if sep < 0 || lower[:sep] != AddressHRP {
+		return "", fmt.Errorf("%w: expected %s1 prefix", ErrInvalidAddress, AddressHRP)
+	}
+
+	data := make([]byte, 0, len(lower)-sep-1)
+	for _, c := range lower[sep+1:] {
+		v := strings.IndexRune(bech32Charset, c)
+		if v < 0 {
+			return "", fmt.Errorf("%w: invalid character %q", ErrInvalidAddress, c)
+		}
+		data = append(data, byte(v))
+	}
+	if len(data) < 6 {
+		return "", fmt.Errorf("%w: too short", ErrInvalidAddress)
+	}
+	if bech32Polymod(append(bech32HRPExpand(AddressHRP), data...)) != 1 {
+		return "", fmt.Errorf("%w: checksum mismatch", ErrInvalidAddress)
+	}
+
+	payload, err := convertBits(data[:len(data)-6], 5, 8)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrInvalidAddress, err)
+	}
+	if len(payload) != addressLength {
+		return "", fmt.Errorf("%w: expected %d bytes, got %d", ErrInvalidAddress, addressLength, len(payload))
+	}
+
+	return Address(lower), nil
+}
+
+// NormalizeAddress returns the canonical form of a valid address, other strings are returned unchanged
+func NormalizeAddress(s string) string {
+	if addr, err := ParseAddress(s); err == nil {
+		return addr.String()
+	}
+	return s
+}
+
+// String returns the address as stored and served
+func (a Address) String() string {
+	return string(a)
+}
+
+// bech32Polymod computes the BCH checksum over 5-bit values
+func bech32Polymod(values []byte) uint32 {
+	generator := [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (top>>uint(i))&1 == 1 {
+				chk ^= generator[i]
+			}
+		}
+	}
+	return chk
+}
+
+// bech32HRPExpand expands the human readable part for checksum computation
+func bech32HRPExpand(hrp string) []byte {
+	expanded := make([]byte, 0, len(hrp)*2+1)
+	for i := 0; i < len(hrp); i++ {
+		expanded = append(expanded, hrp[i]>>5)
+	}
+	expanded = append(expanded, 0)
+	for i := 0; i < len(hrp); i++ {
+		expanded = append(expanded, hrp[i]&31)
+	}
+	return expanded
+}
+
+// convertBits regroups 5-bit values into bytes, rejecting non-zero padding
+func convertBits(data []byte, from, to uint) ([]byte, error) {
+	var acc, bits uint
+	maxv := uint(1)<<to - 1
+	out := make([]byte, 0, len(data)*int(from)/int(to))
+	for _, v := range data {
+		acc = acc<<from | uint(v)
+		bits += from
+		for bits >= to {
+			bits -= to
+			out = append(out, byte(acc>>bits&maxv))
+		}
+	}
+	if bits >= from || (acc<<(to-bits))&maxv != 0 {
+		return nil, errors.New("invalid padding")
+	}
+	return out, nil
+}
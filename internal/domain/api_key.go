@@ -0,0 +1,39 @@
+package domain
+
+import "time"
+
+// APIKey is a partner credential of the balance API, only the SHA-256 hash of the key is stored
+type APIKey struct {
+	ID         int64      `json:"id" gorm:"primaryKey;column:id"`
+	Name       string     `json:"name" gorm:"column:name"`
+	KeyPrefix  string     `json:"key_prefix" gorm:"column:key_prefix"` // first characters of the key, to recognize it in listings
+	KeyHash    string     `json:"-" gorm:"column:key_hash"`
+	RateLimit  float64    `json:"rate_limit" gorm:"column:rate_limit"`   // sustained requests per second
+	Burst      int        `json:"burst" gorm:"column:burst"`             // token bucket size
+	DailyQuota int64      `json:"daily_quota" gorm:"column:daily_quota"` // requests per UTC day, 0 means unlimited
+	CreatedAt  time.Time  `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+	RevokedAt  *time.Time `json:"revoked_at" gorm:"column:revoked_at"`
+}
+
+// TableName returns the table name for APIKey
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// Revoked reports whether the key can no longer be used
+func (k *APIKey) Revoked() bool {
+	return k.RevokedAt != nil
+}
+
+// APIKeyUsage counts the requests of a key on one UTC day
+type APIKeyUsage struct {
+	APIKeyID int64     `json:"api_key_id" gorm:"primaryKey;column:api_key_id"`
+	Day      time.Time `json:"day" gorm:"primaryKey;column:day;type:date"`
+	Requests int64     `json:"requests" gorm:"column:requests"` // served requests, counted against the quota
+	Rejected int64     `json:"rejected" gorm:"column:rejected"` // requests refused by the rate limit or quota
+}
+
+// TableName returns the table name for APIKeyUsage
+func (APIKeyUsage) TableName() string {
+	return "api_key_usage"
+}
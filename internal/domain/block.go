@@ -16,5 +16,5 @@ type Block struct {
 
 // TableName returns the table name for Block
 func (Block) TableName() string {
-	return "indexer.blocks"
+	return "blocks"
 }
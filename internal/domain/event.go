@@ -8,8 +8,27 @@ const (
 	EventTypeMint     EventType = "MINT"
 	EventTypeBurn     EventType = "BURN"
 	EventTypeTransfer EventType = "TRANSFER"
+	EventTypeApproval EventType = "APPROVAL"
+
+	// GRC721 events are keyed by token id instead of value
+	EventTypeNFTMint     EventType = "NFT_MINT"
+	EventTypeNFTBurn     EventType = "NFT_BURN"
+	EventTypeNFTTransfer EventType = "NFT_TRANSFER"
+	EventTypeNFTApproval EventType = "NFT_APPROVAL" // does not move the token
 )
 
+// IsNFT reports whether the event type belongs to a GRC721 collection
+func (t EventType) IsNFT() bool {
+	switch t {
+	case EventTypeNFTMint, EventTypeNFTBurn, EventTypeNFTTransfer, EventTypeNFTApproval:
+		return true
+	default:
+		return false
+	}
+}
+
+// ParsedEvent is a token event extracted from a transaction.
+// For Approval events FromAddress carries the owner and ToAddress the spender (the approved address for GRC721).
 type ParsedEvent struct {
 	Type        string
 	Func        EventType
@@ -17,9 +36,19 @@ type ParsedEvent struct {
 	FromAddress string
 	ToAddress   string
 	Amount      int64
+	TokenID     string // GRC721 token id, empty for GRC20 events
 	TxHash      string
 	BlockHeight int64
 	EventIndex  int
+
+	// MalformedAttrs lists the address attrs that failed bech32 validation, their values are kept as emitted
+	MalformedAttrs []string
+
+	// CorrelationID ties the event to the logs of its block ingestion, it travels as a queue message attribute
+	CorrelationID string `json:"-"`
+
+	// TraceContext carries the trace of the ingestion across the queue, e.g. traceparent
+	TraceContext map[string]string `json:"-"`
 }
 
 // TxEvent represents a database event record
@@ -32,6 +61,11 @@ type TxEvent struct {
 	PkgPath    string `json:"pkg_path" gorm:"column:pkg_path"`
 }
 
+// TableName returns the table name for TxEvent
+func (TxEvent) TableName() string {
+	return "tx_events"
+}
+
 // TxEventAttr represents a database event attribute record
 type TxEventAttr struct {
 	ID        int64  `json:"id" gorm:"primaryKey;column:id"`
@@ -40,3 +74,8 @@ type TxEventAttr struct {
 	Key       string `json:"key" gorm:"column:key"`
 	Value     string `json:"value" gorm:"column:value"`
 }
+
+// TableName returns the table name for TxEventAttr
+func (TxEventAttr) TableName() string {
+	return "tx_event_attrs"
+}
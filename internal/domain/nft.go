@@ -0,0 +1,38 @@
+package domain
+
+import "time"
+
+// NFTOwner represents the current owner of a GRC721 token.
+// A burned token keeps its row with an empty owner so older events cannot revive it.
+type NFTOwner struct {
+	CollectionPath string    `json:"collection_path" gorm:"primaryKey;column:collection_path"`
+	TokenID        string    `json:"token_id" gorm:"primaryKey;column:token_id"`
+	Owner          string    `json:"owner" gorm:"column:owner"`
+	LastTxHash     string    `json:"last_tx_hash" gorm:"column:last_tx_hash"`
+	LastBlockH     int64     `json:"last_block_h" gorm:"column:last_block_h"`
+	LastEventIndex int       `json:"last_event_index" gorm:"column:last_event_index"`
+	UpdatedAt      time.Time `json:"updated_at" gorm:"column:updated_at"`
+}
+
+// TableName returns the table name for NFTOwner
+func (NFTOwner) TableName() string {
+	return "nft_owners"
+}
+
+// NFTTransfer represents a GRC721 mint, burn or transfer event
+type NFTTransfer struct {
+	ID             int64     `json:"id" gorm:"primaryKey;column:id"`
+	TxHash         string    `json:"tx_hash" gorm:"column:tx_hash"`
+	EventIndex     int       `json:"event_index" gorm:"column:event_index"`
+	CollectionPath string    `json:"collection_path" gorm:"column:collection_path"`
+	TokenID        string    `json:"token_id" gorm:"column:token_id"`
+	FromAddress    string    `json:"from_address" gorm:"column:from_address"`
+	ToAddress      string    `json:"to_address" gorm:"column:to_address"`
+	BlockHeight    int64     `json:"block_height" gorm:"column:block_height"`
+	CreatedAt      time.Time `json:"created_at" gorm:"column:created_at"`
+}
+
+// TableName returns the table name for NFTTransfer
+func (NFTTransfer) TableName() string {
+	return "nft_transfers"
+}
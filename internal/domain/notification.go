@@ -0,0 +1,30 @@
+package domain
+
+import "time"
+
+// Notification kinds pushed to real-time subscribers
+const (
+	NotificationTransfer = "transfer" // a mint, burn or transfer was applied
+	NotificationBalance  = "balance"  // the balance of an address changed
+)
+
+// Notification describes a change applied by the event processor.
+// Transfer notifications set FromAddress and ToAddress, balance notifications set Address and Balance.
+type Notification struct {
+	Kind        string    `json:"kind"`
+	TokenPath   string    `json:"tokenPath"`
+	Address     string    `json:"address,omitempty"`
+	FromAddress string    `json:"fromAddress,omitempty"`
+	ToAddress   string    `json:"toAddress,omitempty"`
+	Amount      string    `json:"amount,omitempty"`
+	Balance     string    `json:"balance,omitempty"`
+	EventType   EventType `json:"eventType,omitempty"`
+	TxHash      string    `json:"txHash,omitempty"`
+	BlockHeight int64     `json:"blockHeight,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// Involves reports whether the notification concerns an address
+func (n *Notification) Involves(address string) bool {
+	return n.Address == address || n.FromAddress == address || n.ToAddress == address
+}
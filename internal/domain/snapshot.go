@@ -0,0 +1,34 @@
+package domain
+
+import "time"
+
+// Snapshot job statuses
+const (
+	SnapshotStatusPending = "pending"
+	SnapshotStatusRunning = "running"
+	SnapshotStatusDone    = "done"
+	SnapshotStatusFailed  = "failed"
+	SnapshotStatusExpired = "expired" // done, the file was deleted after the retention period
+)
+
+// SnapshotJob represents an asynchronous token holder export
+type SnapshotJob struct {
+	ID         int64      `json:"id" gorm:"primaryKey;column:id"`
+	TokenPath  string     `json:"token_path" gorm:"column:token_path"`
+	APIKeyID   *int64     `json:"api_key_id" gorm:"column:api_key_id"` // key that submitted the job, nil without API keys
+	AtHeight   *int64     `json:"at_height" gorm:"column:at_height"`   // nil exports current balances
+	Format     string     `json:"format" gorm:"column:format"`
+	MinAmount  *U64       `json:"min_amount" gorm:"column:min_amount"`
+	Exclude    []string   `json:"exclude" gorm:"column:exclude;serializer:json"`
+	Status     string     `json:"status" gorm:"column:status"`
+	RowCount   int64      `json:"row_count" gorm:"column:row_count"`
+	FilePath   string     `json:"file_path" gorm:"column:file_path"`
+	Error      string     `json:"error" gorm:"column:error"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+	FinishedAt *time.Time `json:"finished_at" gorm:"column:finished_at"`
+}
+
+// TableName returns the table name for SnapshotJob
+func (SnapshotJob) TableName() string {
+	return "snapshot_jobs"
+}
@@ -1,17 +1,25 @@
 package domain
 
 import (
+	"context"
 	"database/sql/driver"
 	"fmt"
 	"math/big"
+	"strings"
 	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-// U64 represents a u64 domain type from PostgreSQL
+// U64 represents an unsigned amount, stored in the u64 domain on PostgreSQL and as text on SQLite
 type U64 struct {
 	*big.Int
 }
 
+// sqliteU64Digits is the width SQLite amounts are zero padded to, the number of digits of the largest u64
+const sqliteU64Digits = 20
+
 // NewU64 creates a new U64 from int64
 func NewU64(value int64) *U64 {
 	return &U64{big.NewInt(value)}
@@ -34,6 +42,22 @@ func (u U64) Value() (driver.Value, error) {
 	return u.String(), nil
 }
 
+// GormValue stores amounts on SQLite as zero padded text, so that comparing and ordering the text
+// matches the numbers without going through the floating point arithmetic of SQLite
+func (u U64) GormValue(ctx context.Context, db *gorm.DB) clause.Expr {
+	if u.Int == nil || db.Dialector.Name() != "sqlite" {
+		value, _ := u.Value()
+		return clause.Expr{SQL: "?", Vars: []interface{}{value}}
+	}
+
+	digits := u.Int.String()
+	if u.Int.Sign() < 0 || len(digits) > sqliteU64Digits {
+		db.AddError(fmt.Errorf("amount %s is out of the u64 range", digits))
+		return clause.Expr{SQL: "NULL"}
+	}
+	return clause.Expr{SQL: "?", Vars: []interface{}{strings.Repeat("0", sqliteU64Digits-len(digits)) + digits}}
+}
+
 // Scan implements sql.Scanner for database deserialization
 func (u *U64) Scan(value interface{}) error {
 	if value == nil {
@@ -76,24 +100,50 @@ func (u U64) String() string {
 	return u.Int.String()
 }
 
+// FormatDecimal renders the amount with the given number of decimals without losing precision,
+// trailing fractional zeros are trimmed (e.g. 1500000 with 6 decimals → "1.5")
+func (u U64) FormatDecimal(decimals int) string {
+	if u.Int == nil {
+		return "0"
+	}
+	if decimals <= 0 {
+		return u.Int.String()
+	}
+
+	digits := u.Int.String()
+	if len(digits) <= decimals {
+		digits = strings.Repeat("0", decimals-len(digits)+1) + digits
+	}
+
+	intPart := digits[:len(digits)-decimals]
+	fracPart := strings.TrimRight(digits[len(digits)-decimals:], "0")
+	if fracPart == "" {
+		return intPart
+	}
+	return intPart + "." + fracPart
+}
+
 // Token requests token information
 type Token struct {
-	Path      string    `json:"token_path" gorm:"primaryKey;column:token_path"`
-	Symbol    string    `json:"symbol"`
-	Decimals  int       `json:"decimals"`
-	CreatedAt time.Time `json:"created_at" gorm:"column:created_at"`
+	Path              string     `json:"token_path" gorm:"primaryKey;column:token_path"`
+	Symbol            string     `json:"symbol"`
+	Name              string     `json:"name" gorm:"column:name"`
+	Decimals          int        `json:"decimals"`
+	TotalSupply       *U64       `json:"total_supply" gorm:"column:total_supply;"`
+	MetadataUpdatedAt *time.Time `json:"metadata_updated_at" gorm:"column:metadata_updated_at"`
+	CreatedAt         time.Time  `json:"created_at" gorm:"column:created_at"`
 }
 
 // TableName returns the table name for Token
 func (Token) TableName() string {
-	return "indexer.tokens"
+	return "tokens"
 }
 
 // Balance represents account balance for a specific token
 type Balance struct {
 	Address    string    `json:"address" gorm:"primaryKey;column:address"`
 	TokenPath  string    `json:"token_path" gorm:"primaryKey;column:token_path"`
-	Amount     *U64      `json:"amount" gorm:"column:amount;type:numeric"`
+	Amount     *U64      `json:"amount" gorm:"column:amount;"`
 	LastTxHash string    `json:"last_tx_hash" gorm:"column:last_tx_hash"`
 	LastBlockH int64     `json:"last_block_h" gorm:"column:last_block_h"`
 	UpdatedAt  time.Time `json:"updated_at" gorm:"column:updated_at"`
@@ -101,7 +151,24 @@ type Balance struct {
 
 // TableName returns the table name for Balance
 func (Balance) TableName() string {
-	return "indexer.balances"
+	return "balances"
+}
+
+// Allowance represents the amount a spender may transfer on behalf of an owner
+type Allowance struct {
+	Owner          string    `json:"owner" gorm:"primaryKey;column:owner"`
+	Spender        string    `json:"spender" gorm:"primaryKey;column:spender"`
+	TokenPath      string    `json:"token_path" gorm:"primaryKey;column:token_path"`
+	Amount         *U64      `json:"amount" gorm:"column:amount;"`
+	LastTxHash     string    `json:"last_tx_hash" gorm:"column:last_tx_hash"`
+	LastBlockH     int64     `json:"last_block_h" gorm:"column:last_block_h"`
+	LastEventIndex int       `json:"last_event_index" gorm:"column:last_event_index"`
+	UpdatedAt      time.Time `json:"updated_at" gorm:"column:updated_at"`
+}
+
+// TableName returns the table name for Allowance
+func (Allowance) TableName() string {
+	return "allowances"
 }
 
 // Transfer represents a token transfer event
@@ -112,12 +179,29 @@ type Transfer struct {
 	TokenPath   string    `json:"token_path" gorm:"column:token_path"`
 	FromAddress string    `json:"from_address" gorm:"column:from_address"`
 	ToAddress   string    `json:"to_address" gorm:"column:to_address"`
-	Amount      *U64      `json:"amount" gorm:"column:amount;type:numeric"`
+	Amount      *U64      `json:"amount" gorm:"column:amount;"`
 	BlockHeight int64     `json:"block_height" gorm:"column:block_height"`
 	CreatedAt   time.Time `json:"created_at" gorm:"column:created_at"`
 }
 
 // TableName returns the table name for Transfer
 func (Transfer) TableName() string {
-	return "indexer.transfers"
+	return "transfers"
+}
+
+// BalanceHistory represents the signed change a token event made to the balance of an address
+type BalanceHistory struct {
+	ID          int64     `json:"id" gorm:"primaryKey;column:id"`
+	Address     string    `json:"address" gorm:"column:address"`
+	TokenPath   string    `json:"token_path" gorm:"column:token_path"`
+	Delta       int64     `json:"delta" gorm:"column:delta"`
+	TxHash      string    `json:"tx_hash" gorm:"column:tx_hash"`
+	EventIndex  int       `json:"event_index" gorm:"column:event_index"`
+	BlockHeight int64     `json:"block_height" gorm:"column:block_height"`
+	CreatedAt   time.Time `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+}
+
+// TableName returns the table name for BalanceHistory
+func (BalanceHistory) TableName() string {
+	return "balance_history"
 }
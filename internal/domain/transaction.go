@@ -50,5 +50,5 @@ type TransactionResponse struct {
 
 // TableName returns the table name for Transaction
 func (Transaction) TableName() string {
-	return "indexer.transactions"
+	return "transactions"
 }
@@ -0,0 +1,64 @@
+package domain
+
+import "time"
+
+// Webhook delivery statuses
+const (
+	DeliveryStatusPending   = "pending"
+	DeliveryStatusSucceeded = "succeeded"
+	DeliveryStatusFailed    = "failed"
+)
+
+// Webhook represents a registered endpoint notified of transfers matching its filters
+type Webhook struct {
+	ID        int64     `json:"id" gorm:"primaryKey;column:id"`
+	URL       string    `json:"url" gorm:"column:url"`
+	Secret    string    `json:"-" gorm:"column:secret"` // HMAC key for the payload signature
+	Address   string    `json:"address" gorm:"column:address"`
+	TokenPath string    `json:"token_path" gorm:"column:token_path"`
+	MinAmount *U64      `json:"min_amount" gorm:"column:min_amount"`
+	Active    bool      `json:"active" gorm:"column:active"`
+	APIKeyID  *int64    `json:"api_key_id" gorm:"column:api_key_id"` // key that registered the webhook, nil without API keys
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+}
+
+// TableName returns the table name for Webhook
+func (Webhook) TableName() string {
+	return "webhooks"
+}
+
+// Matches reports whether a transfer notification passes the webhook filters
+func (w *Webhook) Matches(notification *Notification) bool {
+	if w.TokenPath != "" && notification.TokenPath != w.TokenPath {
+		return false
+	}
+	if w.Address != "" && !notification.Involves(w.Address) {
+		return false
+	}
+	if w.MinAmount != nil && w.MinAmount.Int != nil {
+		amount, err := NewU64FromString(notification.Amount)
+		if err != nil || amount.Cmp(w.MinAmount.Int) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// WebhookDelivery represents one payload sent, or to be sent, to a webhook
+type WebhookDelivery struct {
+	ID             int64      `json:"id" gorm:"primaryKey;column:id"`
+	WebhookID      int64      `json:"webhook_id" gorm:"column:webhook_id"`
+	Payload        string     `json:"payload" gorm:"column:payload"`
+	Status         string     `json:"status" gorm:"column:status"`
+	Attempts       int        `json:"attempts" gorm:"column:attempts"`
+	LastStatusCode int        `json:"last_status_code" gorm:"column:last_status_code"`
+	LastError      string     `json:"last_error" gorm:"column:last_error"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at" gorm:"column:next_attempt_at"`
+	CreatedAt      time.Time  `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+	DeliveredAt    *time.Time `json:"delivered_at" gorm:"column:delivered_at"`
+}
+
+// TableName returns the table name for WebhookDelivery
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
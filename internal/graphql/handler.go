@@ -0,0 +1,52 @@
+package graphql
+
+import (
+	_ "embed"
+	"gn-indexer/internal/repository"
+	"net/http"
+
+	gql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+)
+
+//go:embed schema.graphql
+var schemaSDL string
+
+// maxQueryDepth bounds nested selections such as transfer → transaction → transfers → ...
+const maxQueryDepth = 8
+
+// Repositories are the data sources behind the GraphQL schema
+type Repositories struct {
+	Blocks       repository.BlockRepository
+	Transactions repository.TransactionRepository
+	Events       repository.EventRepository
+	EventAttrs   repository.EventAttrRepository
+	Transfers    repository.TransferRepository
+	Tokens       repository.TokenRepository
+	Balances     repository.BalanceRepository
+}
+
+// Handler serves GraphQL queries over HTTP
+type Handler struct {
+	repos Repositories
+	relay *relay.Handler
+}
+
+// NewHandler parses the schema and creates a new GraphQL handler
+func NewHandler(repos Repositories) (*Handler, error) {
+	schema, err := gql.ParseSchema(schemaSDL, &Resolver{repos: repos}, gql.MaxDepth(maxQueryDepth))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Handler{
+		repos: repos,
+		relay: &relay.Handler{Schema: schema},
+	}, nil
+}
+
+// ServeHTTP executes a query with a fresh set of batch loaders
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := withLoaders(r.Context(), newLoaders(h.repos))
+	h.relay.ServeHTTP(w, r.WithContext(ctx))
+}
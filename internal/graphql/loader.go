@@ -0,0 +1,190 @@
+package graphql
+
+import (
+	"context"
+	"gn-indexer/internal/domain"
+	"sync"
+)
+
+// batchLoader loads values by key for the lifetime of one request.
+// Resolvers that build a list register the keys their children will need up front,
+// the first Load then fetches every registered key in a single query and later loads hit the cache.
+type batchLoader[K comparable, V any] struct {
+	fetch func(ctx context.Context, keys []K) (map[K]V, error)
+
+	mu      sync.Mutex
+	pending map[K]struct{}
+	values  map[K]V
+	loaded  map[K]bool
+}
+
+// newBatchLoader creates a loader backed by a batch fetch function
+func newBatchLoader[K comparable, V any](fetch func(ctx context.Context, keys []K) (map[K]V, error)) *batchLoader[K, V] {
+	return &batchLoader[K, V]{
+		fetch:   fetch,
+		pending: make(map[K]struct{}),
+		values:  make(map[K]V),
+		loaded:  make(map[K]bool),
+	}
+}
+
+// Register queues keys for the next fetch
+func (l *batchLoader[K, V]) Register(keys ...K) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, key := range keys {
+		if !l.loaded[key] {
+			l.pending[key] = struct{}{}
+		}
+	}
+}
+
+// Prime caches a value that was already read by the parent resolver
+func (l *batchLoader[K, V]) Prime(key K, value V) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.values[key] = value
+	l.loaded[key] = true
+	delete(l.pending, key)
+}
+
+// Load returns the value for a key, fetching it together with every pending key when it is not cached.
+// Missing keys yield the zero value.
+func (l *batchLoader[K, V]) Load(ctx context.Context, key K) (V, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.loaded[key] {
+		return l.values[key], nil
+	}
+
+	l.pending[key] = struct{}{}
+	keys := make([]K, 0, len(l.pending))
+	for k := range l.pending {
+		keys = append(keys, k)
+	}
+
+	values, err := l.fetch(ctx, keys)
+	if err != nil {
+		var zero V
+		return zero, err
+	}
+
+	for _, k := range keys {
+		l.values[k] = values[k]
+		l.loaded[k] = true
+		delete(l.pending, k)
+	}
+	return l.values[key], nil
+}
+
+// loaders holds the per-request batch loaders
+type loaders struct {
+	repos Repositories
+
+	blocks       *batchLoader[int64, *domain.Block]
+	transactions *batchLoader[string, *domain.Transaction]
+	blockTxs     *batchLoader[int64, []domain.Transaction]
+	txEvents     *batchLoader[string, []domain.TxEvent]
+	eventAttrs   *batchLoader[int64, []domain.TxEventAttr]
+	txTransfers  *batchLoader[string, []domain.Transfer]
+	tokens       *batchLoader[string, *domain.Token]
+}
+
+// newLoaders creates a fresh set of loaders over the repositories
+func newLoaders(repos Repositories) *loaders {
+	return &loaders{
+		repos: repos,
+		blocks: newBatchLoader(func(ctx context.Context, heights []int64) (map[int64]*domain.Block, error) {
+			blocks, err := repos.Blocks.GetBlocksByHeights(ctx, heights)
+			if err != nil {
+				return nil, err
+			}
+			result := make(map[int64]*domain.Block, len(blocks))
+			for i := range blocks {
+				result[int64(blocks[i].Height)] = &blocks[i]
+			}
+			return result, nil
+		}),
+		transactions: newBatchLoader(func(ctx context.Context, hashes []string) (map[string]*domain.Transaction, error) {
+			txs, err := repos.Transactions.GetTransactionsByHashes(ctx, hashes)
+			if err != nil {
+				return nil, err
+			}
+			result := make(map[string]*domain.Transaction, len(txs))
+			for i := range txs {
+				result[txs[i].Hash] = &txs[i]
+			}
+			return result, nil
+		}),
+		blockTxs: newBatchLoader(func(ctx context.Context, heights []int64) (map[int64][]domain.Transaction, error) {
+			txs, err := repos.Transactions.GetTransactionsByBlockHeights(ctx, heights)
+			if err != nil {
+				return nil, err
+			}
+			result := make(map[int64][]domain.Transaction)
+			for _, tx := range txs {
+				result[int64(tx.BlockHeight)] = append(result[int64(tx.BlockHeight)], tx)
+			}
+			return result, nil
+		}),
+		txEvents: newBatchLoader(func(ctx context.Context, hashes []string) (map[string][]domain.TxEvent, error) {
+			events, err := repos.Events.GetByTxHashes(ctx, hashes)
+			if err != nil {
+				return nil, err
+			}
+			result := make(map[string][]domain.TxEvent)
+			for _, event := range events {
+				result[event.TxHash] = append(result[event.TxHash], event)
+			}
+			return result, nil
+		}),
+		eventAttrs: newBatchLoader(func(ctx context.Context, ids []int64) (map[int64][]domain.TxEventAttr, error) {
+			attrs, err := repos.EventAttrs.GetByEventIDs(ctx, ids)
+			if err != nil {
+				return nil, err
+			}
+			result := make(map[int64][]domain.TxEventAttr)
+			for _, attr := range attrs {
+				result[attr.EventID] = append(result[attr.EventID], attr)
+			}
+			return result, nil
+		}),
+		txTransfers: newBatchLoader(func(ctx context.Context, hashes []string) (map[string][]domain.Transfer, error) {
+			transfers, err := repos.Transfers.GetByTxHashes(ctx, hashes)
+			if err != nil {
+				return nil, err
+			}
+			result := make(map[string][]domain.Transfer)
+			for _, transfer := range transfers {
+				result[transfer.TxHash] = append(result[transfer.TxHash], transfer)
+			}
+			return result, nil
+		}),
+		tokens: newBatchLoader(func(ctx context.Context, paths []string) (map[string]*domain.Token, error) {
+			tokens, err := repos.Tokens.GetByPaths(ctx, paths)
+			if err != nil {
+				return nil, err
+			}
+			result := make(map[string]*domain.Token, len(tokens))
+			for i := range tokens {
+				result[tokens[i].Path] = &tokens[i]
+			}
+			return result, nil
+		}),
+	}
+}
+
+type loadersKey struct{}
+
+// withLoaders attaches request-scoped loaders to the context
+func withLoaders(ctx context.Context, l *loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, l)
+}
+
+// loadersFrom returns the loaders attached to the context
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
@@ -0,0 +1,544 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"gn-indexer/internal/domain"
+	"gn-indexer/internal/repository"
+	"strings"
+	"time"
+)
+
+const (
+	maxBlockLimit    = 100
+	maxTransferLimit = 100
+	maxBalanceLimit  = 500
+)
+
+// Resolver is the root query resolver
+type Resolver struct {
+	repos Repositories
+}
+
+// clampLimit applies the schema default when the limit is out of range
+func clampLimit(limit int32, fallback, max int) int {
+	if limit < 1 || int(limit) > max {
+		return fallback
+	}
+	return int(limit)
+}
+
+// Block resolves block(height)
+func (r *Resolver) Block(ctx context.Context, args struct{ Height Long }) (*blockResolver, error) {
+	block, err := loadersFrom(ctx).blocks.Load(ctx, int64(args.Height))
+	if err != nil || block == nil {
+		return nil, err
+	}
+	return newBlockResolvers(ctx, []domain.Block{*block})[0], nil
+}
+
+// LatestBlock resolves latestBlock
+func (r *Resolver) LatestBlock(ctx context.Context) (*blockResolver, error) {
+	blocks, err := r.repos.Blocks.ListBlocks(ctx, 0, 1)
+	if err != nil || len(blocks) == 0 {
+		return nil, err
+	}
+	return newBlockResolvers(ctx, blocks)[0], nil
+}
+
+// Blocks resolves blocks(before, limit)
+func (r *Resolver) Blocks(ctx context.Context, args struct {
+	Before *Long
+	Limit  int32
+}) ([]*blockResolver, error) {
+	var before int64
+	if args.Before != nil {
+		before = int64(*args.Before)
+	}
+
+	blocks, err := r.repos.Blocks.ListBlocks(ctx, before, clampLimit(args.Limit, 20, maxBlockLimit))
+	if err != nil {
+		return nil, err
+	}
+	return newBlockResolvers(ctx, blocks), nil
+}
+
+// Transaction resolves transaction(hash)
+func (r *Resolver) Transaction(ctx context.Context, args struct{ Hash string }) (*transactionResolver, error) {
+	tx, err := loadersFrom(ctx).transactions.Load(ctx, args.Hash)
+	if err != nil || tx == nil {
+		return nil, err
+	}
+	return newTransactionResolvers(ctx, []domain.Transaction{*tx})[0], nil
+}
+
+// TransferFilterInput is the transfers filter argument
+type TransferFilterInput struct {
+	Address      *string
+	Direction    *string
+	Counterparty *string
+	TokenPath    *string
+	FromHeight   *Long
+	ToHeight     *Long
+	MinAmount    *string
+	MaxAmount    *string
+}
+
+// Transfers resolves transfers(filter, cursor, limit)
+func (r *Resolver) Transfers(ctx context.Context, args struct {
+	Filter *TransferFilterInput
+	Cursor *string
+	Limit  int32
+}) (*transferConnectionResolver, error) {
+	filter, err := toTransferFilter(args.Filter)
+	if err != nil {
+		return nil, err
+	}
+	if args.Cursor != nil && *args.Cursor != "" {
+		if filter.Cursor, err = repository.DecodeTransferCursor(*args.Cursor); err != nil {
+			return nil, fmt.Errorf("invalid cursor")
+		}
+	}
+
+	// Fetch one extra row to know whether another page exists
+	limit := clampLimit(args.Limit, 20, maxTransferLimit)
+	filter.Limit = limit + 1
+
+	transfers, err := r.repos.Transfers.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	connection := &transferConnectionResolver{}
+	if len(transfers) > limit {
+		transfers = transfers[:limit]
+		next := repository.NewTransferCursor(&transfers[limit-1]).Encode()
+		connection.nextCursor = &next
+	}
+	connection.nodes = newTransferResolvers(ctx, transfers)
+	return connection, nil
+}
+
+// toTransferFilter converts the GraphQL filter into a repository filter
+func toTransferFilter(input *TransferFilterInput) (repository.TransferFilter, error) {
+	var filter repository.TransferFilter
+	if input == nil {
+		return filter, nil
+	}
+
+	var err error
+	if filter.Address, err = parseAddress("address", input.Address); err != nil {
+		return filter, err
+	}
+	if filter.Counterparty, err = parseAddress("counterparty", input.Counterparty); err != nil {
+		return filter, err
+	}
+	filter.TokenPath = stringValue(input.TokenPath)
+	if input.Direction != nil {
+		filter.Direction = strings.ToLower(*input.Direction)
+	}
+	if filter.Address == "" && (filter.Direction != "" || filter.Counterparty != "") {
+		return filter, fmt.Errorf("direction and counterparty require address")
+	}
+	if input.FromHeight != nil {
+		filter.FromHeight = int64(*input.FromHeight)
+	}
+	if input.ToHeight != nil {
+		filter.ToHeight = int64(*input.ToHeight)
+	}
+
+	if filter.MinAmount, err = parseAmount("minAmount", input.MinAmount); err != nil {
+		return filter, err
+	}
+	if filter.MaxAmount, err = parseAmount("maxAmount", input.MaxAmount); err != nil {
+		return filter, err
+	}
+	return filter, nil
+}
+
+// Token resolves token(path)
+func (r *Resolver) Token(ctx context.Context, args struct{ Path string }) (*tokenResolver, error) {
+	token, err := loadersFrom(ctx).tokens.Load(ctx, args.Path)
+	if err != nil || token == nil {
+		return nil, err
+	}
+	return &tokenResolver{token: token}, nil
+}
+
+// Tokens resolves tokens
+func (r *Resolver) Tokens(ctx context.Context) ([]*tokenResolver, error) {
+	tokens, err := r.repos.Tokens.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resolvers := make([]*tokenResolver, 0, len(tokens))
+	for i := range tokens {
+		loadersFrom(ctx).tokens.Prime(tokens[i].Path, &tokens[i])
+		resolvers = append(resolvers, &tokenResolver{token: &tokens[i]})
+	}
+	return resolvers, nil
+}
+
+// BalanceFilterInput is the balances filter argument
+type BalanceFilterInput struct {
+	Address     *string
+	TokenPath   *string
+	MinAmount   *string
+	ExcludeZero *bool
+}
+
+// Balances resolves balances(filter, sort, page, limit)
+func (r *Resolver) Balances(ctx context.Context, args struct {
+	Filter *BalanceFilterInput
+	Sort   string
+	Page   int32
+	Limit  int32
+}) (*balanceConnectionResolver, error) {
+	query := repository.BalanceQuery{Sort: repository.BalanceSortAmount}
+	if args.Sort == "ADDRESS" {
+		query.Sort = repository.BalanceSortAddress
+	}
+
+	if args.Filter != nil {
+		var err error
+		if query.Address, err = parseAddress("address", args.Filter.Address); err != nil {
+			return nil, err
+		}
+		query.TokenPath = stringValue(args.Filter.TokenPath)
+		query.ExcludeZero = args.Filter.ExcludeZero != nil && *args.Filter.ExcludeZero
+
+		if query.MinAmount, err = parseAmount("minAmount", args.Filter.MinAmount); err != nil {
+			return nil, err
+		}
+	}
+
+	return listBalances(ctx, r.repos, query, args.Page, args.Limit)
+}
+
+// listBalances runs a paginated balance query shared by balances and Token.holders
+func listBalances(ctx context.Context, repos Repositories, query repository.BalanceQuery, page, limit int32) (*balanceConnectionResolver, error) {
+	query.Limit = clampLimit(limit, 50, maxBalanceLimit)
+	if page > 1 {
+		query.Offset = (int(page) - 1) * query.Limit
+	}
+
+	balances, total, err := repos.Balances.ListBalances(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make([]*balanceResolver, 0, len(balances))
+	for _, balance := range balances {
+		loadersFrom(ctx).tokens.Register(balance.TokenPath)
+		nodes = append(nodes, &balanceResolver{balance: balance})
+	}
+	return &balanceConnectionResolver{nodes: nodes, total: Long(total)}, nil
+}
+
+// parseAmount parses an optional raw token amount argument
+func parseAmount(name string, value *string) (*domain.U64, error) {
+	if value == nil || *value == "" {
+		return nil, nil
+	}
+	amount, err := domain.NewU64FromString(*value)
+	if err != nil || amount.Sign() < 0 {
+		return nil, fmt.Errorf("invalid %s", name)
+	}
+	return amount, nil
+}
+
+// parseAddress validates an optional address argument and returns its canonical form
+func parseAddress(name string, value *string) (string, error) {
+	if value == nil || *value == "" {
+		return "", nil
+	}
+	addr, err := domain.ParseAddress(*value)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return addr.String(), nil
+}
+
+// amountString renders a possibly missing amount
+func amountString(amount *domain.U64) string {
+	if amount == nil {
+		return "0"
+	}
+	return amount.String()
+}
+
+// stringValue dereferences an optional string argument
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+// blockResolver resolves Block
+type blockResolver struct {
+	block *domain.Block
+}
+
+// newBlockResolvers wraps blocks and queues the batch lookups of their transactions
+func newBlockResolvers(ctx context.Context, blocks []domain.Block) []*blockResolver {
+	l := loadersFrom(ctx)
+	resolvers := make([]*blockResolver, 0, len(blocks))
+	for i := range blocks {
+		height := int64(blocks[i].Height)
+		l.blocks.Prime(height, &blocks[i])
+		l.blockTxs.Register(height)
+		resolvers = append(resolvers, &blockResolver{block: &blocks[i]})
+	}
+	return resolvers
+}
+
+func (b *blockResolver) Height() Long          { return Long(b.block.Height) }
+func (b *blockResolver) Hash() string          { return b.block.Hash }
+func (b *blockResolver) LastBlockHash() string { return b.block.LastBlockHash }
+func (b *blockResolver) Time() string          { return b.block.Time.UTC().Format(time.RFC3339) }
+func (b *blockResolver) NumTxs() int32         { return int32(b.block.NumTxs) }
+func (b *blockResolver) TotalTxs() int32       { return int32(b.block.TotalTxs) }
+
+// Transactions resolves Block.transactions
+func (b *blockResolver) Transactions(ctx context.Context) ([]*transactionResolver, error) {
+	txs, err := loadersFrom(ctx).blockTxs.Load(ctx, int64(b.block.Height))
+	if err != nil {
+		return nil, err
+	}
+	return newTransactionResolvers(ctx, txs), nil
+}
+
+// transactionResolver resolves Transaction
+type transactionResolver struct {
+	tx *domain.Transaction
+}
+
+// newTransactionResolvers wraps transactions and queues the batch lookups of their children
+func newTransactionResolvers(ctx context.Context, txs []domain.Transaction) []*transactionResolver {
+	l := loadersFrom(ctx)
+	resolvers := make([]*transactionResolver, 0, len(txs))
+	for i := range txs {
+		l.transactions.Prime(txs[i].Hash, &txs[i])
+		l.blocks.Register(int64(txs[i].BlockHeight))
+		l.txEvents.Register(txs[i].Hash)
+		l.txTransfers.Register(txs[i].Hash)
+		resolvers = append(resolvers, &transactionResolver{tx: &txs[i]})
+	}
+	return resolvers
+}
+
+func (t *transactionResolver) Hash() string      { return t.tx.Hash }
+func (t *transactionResolver) Index() int32      { return int32(t.tx.Index) }
+func (t *transactionResolver) Success() bool     { return t.tx.Success }
+func (t *transactionResolver) BlockHeight() Long { return Long(t.tx.BlockHeight) }
+func (t *transactionResolver) GasWanted() Long   { return Long(t.tx.GasWanted) }
+func (t *transactionResolver) GasUsed() Long     { return Long(t.tx.GasUsed) }
+func (t *transactionResolver) Memo() string      { return t.tx.Memo }
+
+// Block resolves Transaction.block
+func (t *transactionResolver) Block(ctx context.Context) (*blockResolver, error) {
+	return loadBlock(ctx, int64(t.tx.BlockHeight))
+}
+
+// Events resolves Transaction.events
+func (t *transactionResolver) Events(ctx context.Context) ([]*eventResolver, error) {
+	l := loadersFrom(ctx)
+	events, err := l.txEvents.Load(ctx, t.tx.Hash)
+	if err != nil {
+		return nil, err
+	}
+
+	resolvers := make([]*eventResolver, 0, len(events))
+	for i := range events {
+		l.eventAttrs.Register(events[i].ID)
+		resolvers = append(resolvers, &eventResolver{event: &events[i]})
+	}
+	return resolvers, nil
+}
+
+// Transfers resolves Transaction.transfers
+func (t *transactionResolver) Transfers(ctx context.Context) ([]*transferResolver, error) {
+	transfers, err := loadersFrom(ctx).txTransfers.Load(ctx, t.tx.Hash)
+	if err != nil {
+		return nil, err
+	}
+	return newTransferResolvers(ctx, transfers), nil
+}
+
+// loadBlock resolves a block reference through the block loader
+func loadBlock(ctx context.Context, height int64) (*blockResolver, error) {
+	l := loadersFrom(ctx)
+	block, err := l.blocks.Load(ctx, height)
+	if err != nil || block == nil {
+		return nil, err
+	}
+	l.blockTxs.Register(height)
+	return &blockResolver{block: block}, nil
+}
+
+// loadTransaction resolves a transaction reference through the transaction loader
+func loadTransaction(ctx context.Context, hash string) (*transactionResolver, error) {
+	l := loadersFrom(ctx)
+	tx, err := l.transactions.Load(ctx, hash)
+	if err != nil || tx == nil {
+		return nil, err
+	}
+	l.txEvents.Register(hash)
+	l.txTransfers.Register(hash)
+	return &transactionResolver{tx: tx}, nil
+}
+
+// eventResolver resolves Event
+type eventResolver struct {
+	event *domain.TxEvent
+}
+
+func (e *eventResolver) Index() int32    { return int32(e.event.EventIndex) }
+func (e *eventResolver) Type() string    { return e.event.Type }
+func (e *eventResolver) Func() string    { return e.event.Func }
+func (e *eventResolver) PkgPath() string { return e.event.PkgPath }
+
+// Attrs resolves Event.attrs
+func (e *eventResolver) Attrs(ctx context.Context) ([]*eventAttrResolver, error) {
+	attrs, err := loadersFrom(ctx).eventAttrs.Load(ctx, e.event.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	resolvers := make([]*eventAttrResolver, 0, len(attrs))
+	for i := range attrs {
+		resolvers = append(resolvers, &eventAttrResolver{attr: &attrs[i]})
+	}
+	return resolvers, nil
+}
+
+// Transaction resolves Event.transaction
+func (e *eventResolver) Transaction(ctx context.Context) (*transactionResolver, error) {
+	return loadTransaction(ctx, e.event.TxHash)
+}
+
+// eventAttrResolver resolves EventAttr
+type eventAttrResolver struct {
+	attr *domain.TxEventAttr
+}
+
+func (a *eventAttrResolver) Key() string   { return a.attr.Key }
+func (a *eventAttrResolver) Value() string { return a.attr.Value }
+
+// transferResolver resolves Transfer
+type transferResolver struct {
+	transfer *domain.Transfer
+}
+
+// newTransferResolvers wraps transfers and queues the batch lookups of their references
+func newTransferResolvers(ctx context.Context, transfers []domain.Transfer) []*transferResolver {
+	l := loadersFrom(ctx)
+	resolvers := make([]*transferResolver, 0, len(transfers))
+	for i := range transfers {
+		l.transactions.Register(transfers[i].TxHash)
+		l.blocks.Register(transfers[i].BlockHeight)
+		l.tokens.Register(transfers[i].TokenPath)
+		resolvers = append(resolvers, &transferResolver{transfer: &transfers[i]})
+	}
+	return resolvers
+}
+
+func (t *transferResolver) TxHash() string      { return t.transfer.TxHash }
+func (t *transferResolver) EventIndex() int32   { return int32(t.transfer.EventIndex) }
+func (t *transferResolver) TokenPath() string   { return t.transfer.TokenPath }
+func (t *transferResolver) FromAddress() string { return t.transfer.FromAddress }
+func (t *transferResolver) ToAddress() string   { return t.transfer.ToAddress }
+func (t *transferResolver) Amount() string      { return amountString(t.transfer.Amount) }
+func (t *transferResolver) BlockHeight() Long   { return Long(t.transfer.BlockHeight) }
+
+// Token resolves Transfer.token
+func (t *transferResolver) Token(ctx context.Context) (*tokenResolver, error) {
+	return loadToken(ctx, t.transfer.TokenPath)
+}
+
+// Transaction resolves Transfer.transaction
+func (t *transferResolver) Transaction(ctx context.Context) (*transactionResolver, error) {
+	return loadTransaction(ctx, t.transfer.TxHash)
+}
+
+// Block resolves Transfer.block
+func (t *transferResolver) Block(ctx context.Context) (*blockResolver, error) {
+	return loadBlock(ctx, t.transfer.BlockHeight)
+}
+
+// transferConnectionResolver resolves TransferConnection
+type transferConnectionResolver struct {
+	nodes      []*transferResolver
+	nextCursor *string
+}
+
+func (c *transferConnectionResolver) Nodes() []*transferResolver { return c.nodes }
+func (c *transferConnectionResolver) NextCursor() *string        { return c.nextCursor }
+
+// tokenResolver resolves Token
+type tokenResolver struct {
+	token *domain.Token
+}
+
+// loadToken resolves a token reference through the token loader
+func loadToken(ctx context.Context, path string) (*tokenResolver, error) {
+	token, err := loadersFrom(ctx).tokens.Load(ctx, path)
+	if err != nil || token == nil {
+		return nil, err
+	}
+	return &tokenResolver{token: token}, nil
+}
+
+func (t *tokenResolver) Path() string    { return t.token.Path }
+func (t *tokenResolver) Symbol() string  { return t.token.Symbol }
+func (t *tokenResolver) Name() string    { return t.token.Name }
+func (t *tokenResolver) Decimals() int32 { return int32(t.token.Decimals) }
+
+// TotalSupply resolves Token.totalSupply, null until metadata is fetched
+func (t *tokenResolver) TotalSupply() *string {
+	if t.token.TotalSupply == nil {
+		return nil
+	}
+	supply := t.token.TotalSupply.String()
+	return &supply
+}
+
+// Holders resolves Token.holders, non-zero balances ranked by amount
+func (t *tokenResolver) Holders(ctx context.Context, args struct {
+	Page  int32
+	Limit int32
+}) (*balanceConnectionResolver, error) {
+	query := repository.BalanceQuery{
+		TokenPath:   t.token.Path,
+		ExcludeZero: true,
+		Sort:        repository.BalanceSortAmount,
+	}
+	return listBalances(ctx, loadersFrom(ctx).repos, query, args.Page, args.Limit)
+}
+
+// balanceResolver resolves Balance
+type balanceResolver struct {
+	balance *domain.Balance
+}
+
+func (b *balanceResolver) Address() string       { return b.balance.Address }
+func (b *balanceResolver) TokenPath() string     { return b.balance.TokenPath }
+func (b *balanceResolver) Amount() string        { return amountString(b.balance.Amount) }
+func (b *balanceResolver) LastTxHash() string    { return b.balance.LastTxHash }
+func (b *balanceResolver) LastBlockHeight() Long { return Long(b.balance.LastBlockH) }
+
+// Token resolves Balance.token
+func (b *balanceResolver) Token(ctx context.Context) (*tokenResolver, error) {
+	return loadToken(ctx, b.balance.TokenPath)
+}
+
+// balanceConnectionResolver resolves BalanceConnection
+type balanceConnectionResolver struct {
+	nodes []*balanceResolver
+	total Long
+}
+
+func (c *balanceConnectionResolver) Nodes() []*balanceResolver { return c.nodes }
+func (c *balanceConnectionResolver) Total() Long               { return c.total }
@@ -0,0 +1,46 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// Long is the 64-bit integer scalar used for heights and gas values
+type Long int64
+
+// ImplementsGraphQLType maps Long to the Long scalar of the schema
+func (Long) ImplementsGraphQLType(name string) bool {
+	return name == "Long"
+}
+
+// UnmarshalGraphQL accepts integer literals, JSON numbers and numeric strings
+func (l *Long) UnmarshalGraphQL(input interface{}) error {
+	switch v := input.(type) {
+	case int32:
+		*l = Long(v)
+	case int64:
+		*l = Long(v)
+	case int:
+		*l = Long(v)
+	case float64:
+		if v != float64(int64(v)) {
+			return fmt.Errorf("Long must be an integer, got %v", v)
+		}
+		*l = Long(v)
+	case string:
+		parsed, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid Long %q", v)
+		}
+		*l = Long(parsed)
+	default:
+		return fmt.Errorf("cannot use %T as Long", input)
+	}
+	return nil
+}
+
+// MarshalJSON writes Long as a JSON number
+func (l Long) MarshalJSON() ([]byte, error) {
+	return json.Marshal(int64(l))
+}
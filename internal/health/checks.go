@@ -0,0 +1,77 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"gn-indexer/internal/repository"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Backlogger is a queue that can report how many messages are waiting, e.g. queue.SQSQueue
+type Backlogger interface {
+	Backlog(ctx context.Context) (int64, error)
+}
+
+// DatabaseCheck pings the database
+func DatabaseCheck(db *gorm.DB) Check {
+	return Check{Name: "database", Run: func(ctx context.Context) error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return fmt.Errorf("get sql db: %w", err)
+		}
+		return sqlDB.PingContext(ctx)
+	}}
+}
+
+// replicaLagQuery is how long ago a replica replayed the last transaction, zero once it replayed everything it received
+const replicaLagQuery = `SELECT COALESCE(CASE WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+	ELSE EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()) END, 0)`
+
+// ReplicaCheck measures how far a Postgres read replica trails its primary
+func ReplicaCheck(name string, db *sql.DB) Check {
+	return Check{Name: name, Lag: func(ctx context.Context) (time.Duration, error) {
+		var seconds float64
+		if err := db.QueryRowContext(ctx, replicaLagQuery).Scan(&seconds); err != nil {
+			return 0, fmt.Errorf("query replica lag: %w", err)
+		}
+		return time.Duration(seconds * float64(time.Second)), nil
+	}}
+}
+
+// QueueCheck asks the queue for its backlog, which fails when the queue is unreachable
+func QueueCheck(queue Backlogger) Check {
+	return Check{Name: "queue", Run: func(ctx context.Context) error {
+		_, err := queue.Backlog(ctx)
+		return err
+	}}
+}
+
+// QueueProgress is MetricsProgress with the backlog of the queue the service consumes
+func QueueProgress(queue Backlogger) ProgressFunc {
+	return func(ctx context.Context) Progress {
+		progress := MetricsProgress(ctx)
+		if backlog, err := queue.Backlog(ctx); err == nil {
+			progress.QueueBacklog = &backlog
+		}
+		return progress
+	}
+}
+
+// BlockProgress reports the last indexed block, for services reading what the block syncer stored
+func BlockProgress(blockRepo repository.BlockRepository) ProgressFunc {
+	return func(ctx context.Context) Progress {
+		var progress Progress
+		height, err := blockRepo.GetLastSyncedHeight(ctx)
+		if err != nil || height == 0 {
+			return progress
+		}
+		progress.ProcessedHeight = int64(height)
+		if block, err := blockRepo.GetBlockByHeight(ctx, height); err == nil {
+			progress.LastEventAt = block.Time
+		}
+		return progress
+	}
+}
@@ -0,0 +1,205 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gn-indexer/internal/config"
+	"gn-indexer/internal/metrics"
+	"gn-indexer/internal/types"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// checkTimeout bounds each dependency check so a hanging dependency cannot hang the probe
+const checkTimeout = 2 * time.Second
+
+// Report statuses
+const (
+	StatusOK          = "ok"
+	StatusUnavailable = "unavailable"
+	StatusFail        = "fail"
+)
+
+// Check probes a dependency, e.g. a database ping.
+// A check with Lag measures how far a read replica trails its primary instead, it fails beyond HealthConfig.MaxReplicaLag.
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) error
+	Lag  func(ctx context.Context) (time.Duration, error)
+}
+
+// Progress is how far a service got, zero values are unknown
+type Progress struct {
+	ProcessedHeight int64
+	NetworkHeight   int64
+	LastEventAt     time.Time
+	QueueBacklog    *int64
+}
+
+// ProgressFunc reports the progress of a service
+type ProgressFunc func(ctx context.Context) Progress
+
+// Checker builds the health report of a service from its dependency checks and progress.
+// The service is ready when every check passes and the lag and queue backlog stay within the configured limits.
+type Checker struct {
+	service  string
+	checks   []Check
+	progress ProgressFunc
+	config   *config.HealthConfig
+}
+
+// NewChecker creates a new checker, progress may be nil for services without sync progress
+func NewChecker(service string, cfg *config.HealthConfig, progress ProgressFunc, checks ...Check) *Checker {
+	return &Checker{
+		service:  service,
+		checks:   checks,
+		progress: progress,
+		config:   cfg,
+	}
+}
+
+// Report runs every check and returns the report and whether the service is ready
+func (c *Checker) Report(ctx context.Context) (*types.HealthResponse, bool) {
+	response := &types.HealthResponse{
+		Status:  StatusOK,
+		Service: c.service,
+		Checks:  make(map[string]types.HealthCheck, len(c.checks)),
+	}
+
+	for _, check := range c.checks {
+		checkCtx, cancel := context.WithTimeout(ctx, checkTimeout)
+		start := time.Now()
+		var lag *float64
+		var err error
+		if check.Lag != nil {
+			lag, err = c.replicaLag(checkCtx, check)
+		} else {
+			err = check.Run(checkCtx)
+		}
+		cancel()
+
+		result := types.HealthCheck{Status: StatusOK, LatencyMs: float64(time.Since(start).Microseconds()) / 1000, LagSeconds: lag}
+		if err != nil {
+			result.Status = StatusFail
+			result.Error = err.Error()
+			response.Reasons = append(response.Reasons, fmt.Sprintf("%s check failed", check.Name))
+		}
+		response.Checks[check.Name] = result
+	}
+
+	if c.progress != nil {
+		progress := c.progress(ctx)
+		response.Sync = syncStatus(progress)
+
+		lag := response.Sync.LagBlocks
+		if lag != nil && c.config.MaxLagBlocks > 0 && *lag > c.config.MaxLagBlocks {
+			response.Reasons = append(response.Reasons, fmt.Sprintf("lag of %d blocks exceeds %d", *lag, c.config.MaxLagBlocks))
+		}
+		backlog := progress.QueueBacklog
+		if backlog != nil && c.config.MaxQueueBacklog > 0 && *backlog > c.config.MaxQueueBacklog {
+			response.Reasons = append(response.Reasons, fmt.Sprintf("queue backlog of %d messages exceeds %d", *backlog, c.config.MaxQueueBacklog))
+		}
+	}
+
+	ready := len(response.Reasons) == 0
+	if !ready {
+		response.Status = StatusUnavailable
+	}
+	return response, ready
+}
+
+// replicaLag runs a lag check, the lag is reported even when it exceeds the limit
+func (c *Checker) replicaLag(ctx context.Context, check Check) (*float64, error) {
+	lag, err := check.Lag(ctx)
+	if err != nil {
+		return nil, err
+	}
+	seconds := lag.Seconds()
+	if c.config.MaxReplicaLag > 0 && lag > c.config.MaxReplicaLag {
+		return &seconds, fmt.Errorf("lag of %s exceeds %s", lag.Round(time.Millisecond), c.config.MaxReplicaLag)
+	}
+	return &seconds, nil
+}
+
+// syncStatus converts progress into its API representation, the lag is only known once the tip is
+func syncStatus(progress Progress) types.SyncStatus {
+	status := types.SyncStatus{
+		ProcessedHeight: progress.ProcessedHeight,
+		NetworkHeight:   progress.NetworkHeight,
+		QueueBacklog:    progress.QueueBacklog,
+	}
+	if progress.NetworkHeight > 0 {
+		lag := max(progress.NetworkHeight-progress.ProcessedHeight, 0)
+		status.LagBlocks = &lag
+	}
+	if !progress.LastEventAt.IsZero() {
+		at := progress.LastEventAt.UTC()
+		age := time.Since(at).Seconds()
+		status.LastEventAt = &at
+		status.LastEventAgeSeconds = &age
+	}
+	return status
+}
+
+// ServeHealthz reports liveness, it always answers 200 while the process serves requests
+func (c *Checker) ServeHealthz(w http.ResponseWriter, r *http.Request) {
+	response, _ := c.Report(r.Context())
+	writeJSON(w, http.StatusOK, response)
+}
+
+// ServeReadyz reports readiness, 503 when a dependency is down or the service lags too far behind
+func (c *Checker) ServeReadyz(w http.ResponseWriter, r *http.Request) {
+	response, ready := c.Report(r.Context())
+	status := http.StatusOK
+	if !ready {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, response)
+}
+
+// writeJSON writes a JSON response
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// MetricsProgress reports the heights and last event tracked by the metrics of this process
+func MetricsProgress(ctx context.Context) Progress {
+	synced, network := metrics.Heights()
+	eventHeight, eventAt := metrics.LastEvent()
+	progress := Progress{
+		ProcessedHeight: int64(synced),
+		NetworkHeight:   int64(network),
+		LastEventAt:     eventAt,
+	}
+	if synced == 0 {
+		progress.ProcessedHeight = eventHeight // the event processor only sees events
+	}
+	return progress
+}
+
+// Serve exposes /healthz, /readyz and /metrics on addr until the context is cancelled, for binaries without an HTTP server
+func Serve(ctx context.Context, addr string, checker *Checker) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	mux.HandleFunc("/healthz", checker.ServeHealthz)
+	mux.HandleFunc("/readyz", checker.ServeReadyz)
+	server := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	slog.Info("serving health and metrics", "addr", addr, "paths", "/healthz /readyz /metrics")
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"gn-indexer/internal/config"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Attribute keys shared by every component so logs can be joined across binaries
+const (
+	KeyComponent      = "component"
+	KeyCorrelationID  = "correlation_id"
+	KeyTraceID        = "trace_id"
+	KeyTxHash         = "tx_hash"
+	KeyBlockHeight    = "block_height"
+	KeyEventIndex     = "event_index"
+	KeySubscriptionID = "subscription_id"
+)
+
+// levels holds the configured level of each component, loggers keep a pointer so Setup applies to existing ones
+var levels = struct {
+	sync.Mutex
+	defaultLevel slog.LevelVar
+	components   map[string]*slog.LevelVar
+	configured   map[string]bool
+}{components: make(map[string]*slog.LevelVar), configured: make(map[string]bool)}
+
+// output is the handler every logger writes through, replaced by Setup
+var output = struct {
+	sync.RWMutex
+	handler slog.Handler
+}{handler: slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug})}
+
+// Setup configures the output format and the default and per-component levels, and routes the log package through slog
+func Setup(cfg *config.LogConfig) error {
+	return SetupWriter(cfg, os.Stderr)
+}
+
+// SetupWriter is Setup writing to w
+func SetupWriter(cfg *config.LogConfig, w io.Writer) error {
+	defaultLevel, err := ParseLevel(cfg.Level)
+	if err != nil {
+		return err
+	}
+
+	options := &slog.HandlerOptions{Level: slog.LevelDebug}
+	var handler slog.Handler
+	switch cfg.Format {
+	case config.LogFormatText, "":
+		handler = slog.NewTextHandler(w, options)
+	case config.LogFormatJSON:
+		handler = slog.NewJSONHandler(w, options)
+	default:
+		return fmt.Errorf("unknown log format %q", cfg.Format)
+	}
+
+	levels.Lock()
+	levels.defaultLevel.Set(defaultLevel)
+	for component := range levels.configured {
+		delete(levels.configured, component)
+	}
+	for component, name := range cfg.Components {
+		level, err := ParseLevel(name)
+		if err != nil {
+			levels.Unlock()
+			return fmt.Errorf("component %s: %w", component, err)
+		}
+		levelVar(component).Set(level)
+		levels.configured[component] = true
+	}
+	for component, level := range levels.components {
+		if !levels.configured[component] {
+			level.Set(defaultLevel)
+		}
+	}
+	levels.Unlock()
+
+	output.Lock()
+	output.handler = handler
+	output.Unlock()
+
+	slog.SetDefault(slog.New(&componentHandler{level: &levels.defaultLevel}))
+	return nil
+}
+
+// ParseLevel parses debug, info, warn or error
+func ParseLevel(name string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(strings.TrimSpace(name))); err != nil {
+		return 0, fmt.Errorf("invalid log level %q", name)
+	}
+	return level, nil
+}
+
+// levelVar returns the level of a component, callers hold levels
+func levelVar(component string) *slog.LevelVar {
+	level, ok := levels.components[component]
+	if !ok {
+		level = new(slog.LevelVar)
+		level.Set(levels.defaultLevel.Level())
+		levels.components[component] = level
+	}
+	return level
+}
+
+// For returns the logger of a component, filtered by its configured level
+func For(component string) *slog.Logger {
+	levels.Lock()
+	level := levelVar(component)
+	levels.Unlock()
+	return slog.New(&componentHandler{level: level}).With(KeyComponent, component)
+}
+
+// Fatal logs an error and exits, for startup failures in main
+func Fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
+
+// correlationKey is the context key of the correlation ID
+type correlationKey struct{}
+
+// NewCorrelationID returns a random ID tying together the logs of one unit of work
+func NewCorrelationID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// WithCorrelationID returns a context whose logs carry the correlation ID
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	if id == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, correlationKey{}, id)
+}
+
+// CorrelationID returns the correlation ID of a context, empty if none
+func CorrelationID(ctx context.Context) string {
+	id, _ := ctx.Value(correlationKey{}).(string)
+	return id
+}
+
+// componentHandler filters records by the component level and adds the correlation and trace IDs of the context.
+// Attributes and groups are replayed on the current output so loggers created before Setup follow it.
+type componentHandler struct {
+	level *slog.LevelVar
+	ops   []func(slog.Handler) slog.Handler
+}
+
+func (h *componentHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+func (h *componentHandler) Handle(ctx context.Context, record slog.Record) error {
+	if ctx != nil {
+		if id := CorrelationID(ctx); id != "" {
+			record.AddAttrs(slog.String(KeyCorrelationID, id))
+		}
+		if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+			record.AddAttrs(slog.String(KeyTraceID, span.TraceID().String()))
+		}
+	}
+
+	output.RLock()
+	handler := output.handler
+	output.RUnlock()
+	for _, op := range h.ops {
+		handler = op(handler)
+	}
+	return handler.Handle(ctx, record)
+}
+
+func (h *componentHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return h.with(func(handler slog.Handler) slog.Handler { return handler.WithAttrs(attrs) })
+}
+
+func (h *componentHandler) WithGroup(name string) slog.Handler {
+	return h.with(func(handler slog.Handler) slog.Handler { return handler.WithGroup(name) })
+}
+
+func (h *componentHandler) with(op func(slog.Handler) slog.Handler) slog.Handler {
+	ops := make([]func(slog.Handler) slog.Handler, len(h.ops), len(h.ops)+1)
+	copy(ops, h.ops)
+	return &componentHandler{level: h.level, ops: append(ops, op)}
+}
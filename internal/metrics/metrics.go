@@ -0,0 +1,235 @@
+package metrics
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace prefixes every metric of the indexer binaries
+const namespace = "gn_indexer"
+
+// Sync progress of the block syncer
+var (
+	SyncedHeight = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "synced_height",
+		Help:      "Height of the last block saved by the block syncer.",
+	})
+	NetworkHeight = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "network_height",
+		Help:      "Height of the latest block seen on the network subscription.",
+	})
+	SyncLag = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "sync_lag_blocks",
+		Help:      "Blocks between the network tip and the last synced block.",
+	})
+	BlocksSynced = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "blocks_synced_total",
+		Help:      "Blocks saved by the block syncer.",
+	})
+	TransactionsSynced = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transactions_synced_total",
+		Help:      "Transactions saved by the block syncer.",
+	})
+	EventsStored = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "events_stored_total",
+		Help:      "Raw transaction events saved by the block syncer.",
+	})
+	LastEventHeight = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "last_event_height",
+		Help:      "Highest block height of an event stored by the block syncer or applied by the event processor.",
+	})
+	LastEventTimestamp = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "last_event_timestamp_seconds",
+		Help:      "Unix time of the last event stored by the block syncer or applied by the event processor.",
+	})
+)
+
+// Upstream GraphQL indexer
+var (
+	GraphQLRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "graphql_request_duration_seconds",
+		Help:      "Latency of queries to the upstream GraphQL indexer.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation"})
+	GraphQLErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "graphql_errors_total",
+		Help:      "Failed queries to the upstream GraphQL indexer.",
+	}, []string{"operation"})
+	WebSocketReconnects = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "websocket_reconnects_total",
+		Help:      "Reconnections of the block subscription by result.",
+	}, []string{"result"})
+)
+
+// Event queue between the block syncer and the event processor
+var (
+	QueueMessagesSent = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "queue_messages_sent_total",
+		Help:      "Events sent to the queue.",
+	})
+	QueueMessagesReceived = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "queue_messages_received_total",
+		Help:      "Events received and acknowledged from the queue.",
+	})
+	QueueFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "queue_failures_total",
+		Help:      "Failed queue operations by operation (send, receive, decode, delete).",
+	}, []string{"operation"})
+)
+
+// Event processor
+var (
+	BalanceUpdateDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "balance_update_duration_seconds",
+		Help:      "Time to apply a token event to balances.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+	}, []string{"event_type", "result"})
+)
+
+// HTTP API
+var (
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of balance API requests by route.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+)
+
+// heights holds the values behind SyncLag and the last event gauges, gauges cannot be read back
+var heights struct {
+	sync.Mutex
+	synced, network int
+	event           int64
+	eventAt         time.Time
+}
+
+// SetSyncedHeight records the last synced block and updates the lag
+func SetSyncedHeight(height int) {
+	heights.Lock()
+	defer heights.Unlock()
+	if height <= heights.synced {
+		return // ranges may be re-synced, the progress never goes back
+	}
+	heights.synced = height
+	SyncedHeight.Set(float64(height))
+	updateLag()
+}
+
+// SetNetworkHeight records the network tip and updates the lag
+func SetNetworkHeight(height int) {
+	heights.Lock()
+	defer heights.Unlock()
+	if height <= heights.network {
+		return
+	}
+	heights.network = height
+	NetworkHeight.Set(float64(height))
+	updateLag()
+}
+
+// Heights returns the last synced block and the network tip, 0 when not known yet
+func Heights() (synced, network int) {
+	heights.Lock()
+	defer heights.Unlock()
+	return heights.synced, heights.network
+}
+
+// MarkEvent records that an event of a block was stored or applied now
+func MarkEvent(height int64) {
+	heights.Lock()
+	defer heights.Unlock()
+	heights.eventAt = time.Now()
+	LastEventTimestamp.Set(float64(heights.eventAt.Unix()))
+	if height > heights.event {
+		heights.event = height // queue delivery is unordered, keep the highest
+		LastEventHeight.Set(float64(height))
+	}
+}
+
+// LastEvent returns the highest event height and when the last event was handled, zero before the first one
+func LastEvent() (height int64, at time.Time) {
+	heights.Lock()
+	defer heights.Unlock()
+	return heights.event, heights.eventAt
+}
+
+// updateLag keeps the lag at zero until the tip is known, callers hold heights
+func updateLag() {
+	if heights.network == 0 || heights.synced > heights.network {
+		SyncLag.Set(0)
+		return
+	}
+	SyncLag.Set(float64(heights.network - heights.synced))
+}
+
+// graphqlOperation matches the first field selected by a query, e.g. getBlocks
+var graphqlOperation = regexp.MustCompile(`\{\s*([A-Za-z_][A-Za-z0-9_]*)`)
+
+// GraphQLOperation names a query by its first selected field for metric labels
+func GraphQLOperation(query string) string {
+	if m := graphqlOperation.FindStringSubmatch(query); m != nil {
+		return m[1]
+	}
+	return "unknown"
+}
+
+// ObserveGraphQL records the latency and the outcome of an upstream query
+func ObserveGraphQL(operation string, start time.Time, err error) {
+	GraphQLRequestDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+	if err != nil {
+		GraphQLErrors.WithLabelValues(operation).Inc()
+	}
+}
+
+// ObserveBalanceUpdate records the time taken to apply an event to balances
+func ObserveBalanceUpdate(eventType string, start time.Time, err error) {
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	BalanceUpdateDuration.WithLabelValues(eventType, result).Observe(time.Since(start).Seconds())
+}
+
+// Middleware records the latency of every request by matched route, unmatched requests share one label
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		HTTPRequestDuration.WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).
+			Observe(time.Since(start).Seconds())
+	}
+}
+
+// Handler serves the metrics in the Prometheus text format
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
@@ -0,0 +1,351 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"gn-indexer/db"
+	"gn-indexer/internal/logging"
+	"io/fs"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"gorm.io/gorm"
+)
+
+// lockID is the advisory lock serializing migration runs across processes
+const lockID = 7246150916
+
+// dialect holds the statements that differ between the supported databases
+type dialect struct {
+	versionTable string // records the applied version, the same table the migrate/migrate CLI uses so either can take over
+	tableExists  string // selects whether the table named by $1 exists
+	lock, unlock string // serialize migration runs, empty when the database needs no lock
+	reset        string // restores session settings a migration may change, empty when there are none
+}
+
+// dialects are keyed by gorm driver name
+var dialects = map[string]dialect{
+	"postgres": {
+		versionTable: "public.schema_migrations",
+		tableExists:  "SELECT to_regclass($1) IS NOT NULL",
+		lock:         "SELECT pg_advisory_lock($1)",
+		unlock:       "SELECT pg_advisory_unlock($1)",
+		reset:        "RESET search_path", // migrations set the search path, keep it from leaking into the next one
+	},
+	// SQLite locks the database file for each write transaction, a local database has no concurrent migration runs
+	"sqlite": {
+		versionTable: "schema_migrations",
+		tableExists:  "SELECT EXISTS (SELECT 1 FROM sqlite_master WHERE type = 'table' AND name = $1)",
+	},
+}
+
+// Schema check errors
+var (
+	ErrSchemaOutdated = errors.New("database schema is older than this binary, run migrate up")
+	ErrSchemaTooNew   = errors.New("database schema is newer than this binary, deploy a newer binary or run migrate down")
+	ErrSchemaDirty    = errors.New("database schema is dirty after a failed migration, fix it and run migrate force")
+)
+
+// fileName matches migration files such as 0001_init.up.sql
+var fileName = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+// Migration is one schema version
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status describes the schema of a database against the migrations
+type Status struct {
+	Current int  // applied version, 0 before the first migration
+	Dirty   bool // a migration stopped half way
+	Latest  int  // version of the newest migration
+	Pending []*Migration
+}
+
+// Migrator applies migrations to a database
+type Migrator struct {
+	db         *sql.DB
+	dialect    dialect
+	migrations []*Migration
+	logger     *slog.Logger
+}
+
+// NewMigrator creates a migrator for a database of the gorm driver ("postgres" or "sqlite") from the migration files of fsys,
+// versions must start at 1 without gaps
+func NewMigrator(sqlDB *sql.DB, driver string, fsys fs.FS) (*Migrator, error) {
+	dialect, ok := dialects[driver]
+	if !ok {
+		return nil, fmt.Errorf("unsupported database driver %q", driver)
+	}
+	migrations, err := load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{
+		db:         sqlDB,
+		dialect:    dialect,
+		migrations: migrations,
+		logger:     logging.For("migrate"),
+	}, nil
+}
+
+// ForDatabase creates a migrator with the migrations embedded in this binary for the driver of gormDB
+func ForDatabase(gormDB *gorm.DB) (*Migrator, error) {
+	sqlDB, err := gormDB.DB()
+	if err != nil {
+		return nil, fmt.Errorf("get sql db: %w", err)
+	}
+	driver := gormDB.Dialector.Name()
+	return NewMigrator(sqlDB, driver, db.Migrations(driver))
+}
+
+// CheckSchema fails unless the database is at the version of the migrations embedded in this binary
+func CheckSchema(ctx context.Context, gormDB *gorm.DB) error {
+	migrator, err := ForDatabase(gormDB)
+	if err != nil {
+		return err
+	}
+	return migrator.Check(ctx)
+}
+
+// load reads and orders the migration files
+func load(fsys fs.FS) ([]*Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("read migrations: %w", err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+		version, _ := strconv.Atoi(match[1])
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("read migration %s: %w", entry.Name(), err)
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]*Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		migrations = append(migrations, migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	for i, migration := range migrations {
+		if migration.Version != i+1 {
+			return nil, fmt.Errorf("migration version %d is missing", i+1)
+		}
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %04d_%s needs both an up and a down file", migration.Version, migration.Name)
+		}
+	}
+	return migrations, nil
+}
+
+// Latest returns the version of the newest migration
+func (m *Migrator) Latest() int {
+	return len(m.migrations)
+}
+
+// Status reports the applied version and the pending migrations
+func (m *Migrator) Status(ctx context.Context) (*Status, error) {
+	current, dirty, err := m.version(ctx, m.db)
+	if err != nil {
+		return nil, err
+	}
+	status := &Status{Current: current, Dirty: dirty, Latest: m.Latest()}
+	if current < len(m.migrations) {
+		status.Pending = m.migrations[current:]
+	}
+	return status, nil
+}
+
+// Check fails unless the database is at the latest version
+func (m *Migrator) Check(ctx context.Context) error {
+	status, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+	switch {
+	case status.Dirty:
+		return fmt.Errorf("%w: version %d", ErrSchemaDirty, status.Current)
+	case status.Current < status.Latest:
+		return fmt.Errorf("%w: version %d, expected %d", ErrSchemaOutdated, status.Current, status.Latest)
+	case status.Current > status.Latest:
+		return fmt.Errorf("%w: version %d, expected %d", ErrSchemaTooNew, status.Current, status.Latest)
+	}
+	return nil
+}
+
+// Up applies every pending migration and returns the applied ones
+func (m *Migrator) Up(ctx context.Context) ([]*Migration, error) {
+	var applied []*Migration
+	err := m.locked(ctx, false, func(conn *sql.Conn, current int) error {
+		if current > len(m.migrations) {
+			return fmt.Errorf("%w: version %d, expected %d", ErrSchemaTooNew, current, len(m.migrations))
+		}
+		for _, migration := range m.migrations[current:] {
+			if err := m.apply(ctx, conn, migration.Up, migration.Version); err != nil {
+				return fmt.Errorf("migrate up to %04d_%s: %w", migration.Version, migration.Name, err)
+			}
+			m.logger.Info("applied migration", "version", migration.Version, "name", migration.Name)
+			applied = append(applied, migration)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down reverts the last steps migrations and returns the reverted ones
+func (m *Migrator) Down(ctx context.Context, steps int) ([]*Migration, error) {
+	var reverted []*Migration
+	err := m.locked(ctx, false, func(conn *sql.Conn, current int) error {
+		if current > len(m.migrations) {
+			return fmt.Errorf("%w: version %d, expected %d", ErrSchemaTooNew, current, len(m.migrations))
+		}
+		for i := 0; i < steps && current > 0; i++ {
+			migration := m.migrations[current-1]
+			if err := m.apply(ctx, conn, migration.Down, current-1); err != nil {
+				return fmt.Errorf("migrate down from %04d_%s: %w", migration.Version, migration.Name, err)
+			}
+			m.logger.Info("reverted migration", "version", migration.Version, "name", migration.Name)
+			reverted = append(reverted, migration)
+			current--
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// Force records version as applied and clean without running anything, to recover from a dirty schema
+func (m *Migrator) Force(ctx context.Context, version int) error {
+	if version < 0 || version > len(m.migrations) {
+		return fmt.Errorf("version %d is not between 0 and %d", version, len(m.migrations))
+	}
+	return m.locked(ctx, true, func(conn *sql.Conn, current int) error {
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+		if err := m.setVersion(ctx, tx, version); err != nil {
+			return err
+		}
+		return tx.Commit()
+	})
+}
+
+// locked runs fn on one connection holding the migration lock with the current version, a dirty schema is refused unless allowDirty
+func (m *Migrator) locked(ctx context.Context, allowDirty bool, fn func(conn *sql.Conn, current int) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("get connection: %w", err)
+	}
+	defer conn.Close()
+
+	if m.dialect.lock != "" {
+		if _, err := conn.ExecContext(ctx, m.dialect.lock, lockID); err != nil {
+			return fmt.Errorf("acquire migration lock: %w", err)
+		}
+		defer conn.ExecContext(context.Background(), m.dialect.unlock, lockID)
+	}
+
+	if _, err := conn.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+m.dialect.versionTable+" (version BIGINT NOT NULL PRIMARY KEY, dirty BOOLEAN NOT NULL)"); err != nil {
+		return fmt.Errorf("create version table: %w", err)
+	}
+	current, dirty, err := m.version(ctx, conn)
+	if err != nil {
+		return err
+	}
+	if dirty && !allowDirty {
+		return fmt.Errorf("%w: version %d", ErrSchemaDirty, current)
+	}
+	return fn(conn, current)
+}
+
+// apply runs a migration file and records the version it leads to in one transaction
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, script string, version int) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return err
+	}
+	if err := m.setVersion(ctx, tx, version); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	if m.dialect.reset == "" {
+		return nil
+	}
+	_, err = conn.ExecContext(ctx, m.dialect.reset)
+	return err
+}
+
+// querier is a database, a connection or a transaction
+type querier interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// version reads the applied version, a database without the version table is at version 0
+func (m *Migrator) version(ctx context.Context, q querier) (int, bool, error) {
+	var exists bool
+	if err := q.QueryRowContext(ctx, m.dialect.tableExists, m.dialect.versionTable).Scan(&exists); err != nil {
+		return 0, false, fmt.Errorf("find version table: %w", err)
+	}
+	if !exists {
+		return 0, false, nil
+	}
+
+	var version int
+	var dirty bool
+	err := q.QueryRowContext(ctx, "SELECT version, dirty FROM "+m.dialect.versionTable+" LIMIT 1").Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("read schema version: %w", err)
+	}
+	return version, dirty, nil
+}
+
+// setVersion replaces the recorded version, version 0 leaves the table empty like migrate/migrate does
+func (m *Migrator) setVersion(ctx context.Context, tx *sql.Tx, version int) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM "+m.dialect.versionTable); err != nil {
+		return fmt.Errorf("clear schema version: %w", err)
+	}
+	if version == 0 {
+		return nil
+	}
+	if _, err := tx.ExecContext(ctx, "INSERT INTO "+m.dialect.versionTable+" (version, dirty) VALUES ($1, false)", version); err != nil {
+		return fmt.Errorf("record schema version: %w", err)
+	}
+	return nil
+}
@@ -4,11 +4,16 @@ import (
 	"context"
 	"fmt"
 	"gn-indexer/internal/client"
+	"gn-indexer/internal/logging"
+	"gn-indexer/internal/metrics"
+	"gn-indexer/internal/tracing"
 	"gn-indexer/internal/types"
-	"log"
+	"log/slog"
 
 	"gn-indexer/internal/domain"
 	"gn-indexer/internal/repository"
+
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // EventProcessor defines the interface for processing transactions
@@ -27,6 +32,11 @@ type Syncer struct {
 
 	// Use interface instead of concrete type to avoid circular import
 	eventProcessor EventProcessor
+
+	// transactions fetched per block
+	txIndexLimit int
+
+	logger *slog.Logger
 }
 
 func NewSyncer(
@@ -36,6 +46,7 @@ func NewSyncer(
 	blockRepo repository.BlockRepository,
 	transactionRepo repository.TransactionRepository,
 	eventProcessor EventProcessor,
+	txIndexLimit int,
 ) *Syncer {
 	syncer := &Syncer{
 		blockClient:     client,
@@ -44,13 +55,18 @@ func NewSyncer(
 		blockRepo:       blockRepo,
 		transactionRepo: transactionRepo,
 		eventProcessor:  eventProcessor,
+		txIndexLimit:    txIndexLimit,
+		logger:          logging.For("syncer"),
 	}
 
 	return syncer
 }
 
 // SyncBlocks synchronizes blocks within a height range
-func (s *Syncer) SyncBlocks(ctx context.Context, fromHeight, toHeight int) error {
+func (s *Syncer) SyncBlocks(ctx context.Context, fromHeight, toHeight int) (err error) {
+	ctx, span := tracing.Start(ctx, "Syncer.SyncBlocks", attribute.Int("from_height", fromHeight), attribute.Int("to_height", toHeight))
+	defer func() { tracing.End(span, err) }()
+
 	var bd types.BlocksDataArr
 	if err := s.blockClient.Do(ctx, QBlocks, map[string]interface{}{
 		"gt": fromHeight - 1, // fromHeight-1보다 큰 값 = fromHeight부터
@@ -61,43 +77,61 @@ func (s *Syncer) SyncBlocks(ctx context.Context, fromHeight, toHeight int) error
 
 	for _, block := range bd.GetBlocks {
 		if err := s.blockRepo.SaveBlock(ctx, block); err != nil {
-			log.Printf("failed to save block: %v", err)
+			s.logger.ErrorContext(ctx, "failed to save block", logging.KeyBlockHeight, block.Height, "error", err)
 			continue
 		}
+		metrics.BlocksSynced.Inc()
+		metrics.SetSyncedHeight(block.Height)
 	}
 	// 실제 저장된 블록의 높이 범위 계산
 	if len(bd.GetBlocks) > 0 {
 		minHeight := bd.GetBlocks[0].Height
 		maxHeight := bd.GetBlocks[len(bd.GetBlocks)-1].Height
-		log.Printf("synced %d blocks from height %d to %d", len(bd.GetBlocks), minHeight, maxHeight)
+		s.logger.InfoContext(ctx, "synced blocks", "count", len(bd.GetBlocks), "from_height", minHeight, "to_height", maxHeight)
 	} else {
-		log.Printf("synced 0 blocks (no blocks in range %d to %d)", fromHeight+1, toHeight)
+		s.logger.InfoContext(ctx, "no blocks in range", "from_height", fromHeight, "to_height", toHeight)
 	}
 	return nil
 }
 
 // SyncTxs synchronizes transactions within a height range
-func (s *Syncer) SyncTxs(ctx context.Context, fromHeight, toHeight int) error {
+func (s *Syncer) SyncTxs(ctx context.Context, fromHeight, toHeight int) (err error) {
+	ctx, span := tracing.Start(ctx, "Syncer.SyncTxs", attribute.Int("from_height", fromHeight), attribute.Int("to_height", toHeight))
+	defer func() { tracing.End(span, err) }()
+
 	var td types.TxsData
 	if err := s.txClient.Do(ctx, QTxs, map[string]interface{}{
 		"gt":   fromHeight - 1, // fromHeight-1보다 큰 값 = fromHeight부터
 		"lt":   toHeight + 1,   // toHeight+1보다 작은 값 = toHeight까지
-		"imax": 1000,
+		"imax": s.txIndexLimit,
 	}, &td); err != nil {
 		return fmt.Errorf("sync transactions: %w", err)
 	}
 
+	// Events of one block share a correlation ID from here to the event processor
+	correlationIDs := make(map[int]string)
 	for _, tx := range td.GetTransactions {
-		if err := s.transactionRepo.SaveTransaction(ctx, tx); err != nil {
-			log.Printf("failed to save transaction: %v", err)
+		txCtx := ctx
+		if logging.CorrelationID(ctx) == "" {
+			id, ok := correlationIDs[tx.BlockHeight]
+			if !ok {
+				id = logging.NewCorrelationID()
+				correlationIDs[tx.BlockHeight] = id
+			}
+			txCtx = logging.WithCorrelationID(ctx, id)
+		}
+
+		if err := s.transactionRepo.SaveTransaction(txCtx, tx); err != nil {
+			s.logger.ErrorContext(txCtx, "failed to save transaction", logging.KeyTxHash, tx.Hash, logging.KeyBlockHeight, tx.BlockHeight, "error", err)
 			continue
 		}
+		metrics.TransactionsSynced.Inc()
 
 		// Process transaction for events and send to SQS queue
 		if s.eventProcessor != nil {
 			// tx is already domain.Transaction type, convert to pointer
-			if err := s.eventProcessor.ProcessTransaction(ctx, &tx); err != nil {
-				log.Printf("failed to process transaction events: %v", err)
+			if err := s.eventProcessor.ProcessTransaction(txCtx, &tx); err != nil {
+				s.logger.ErrorContext(txCtx, "failed to process transaction events", logging.KeyTxHash, tx.Hash, logging.KeyBlockHeight, tx.BlockHeight, "error", err)
 				// Don't fail the sync if event processing fails
 			}
 		}
@@ -106,9 +140,9 @@ func (s *Syncer) SyncTxs(ctx context.Context, fromHeight, toHeight int) error {
 	if len(td.GetTransactions) > 0 {
 		minHeight := td.GetTransactions[0].BlockHeight
 		maxHeight := td.GetTransactions[len(td.GetTransactions)-1].BlockHeight
-		log.Printf("synced %d transactions from height %d to %d", len(td.GetTransactions), minHeight, maxHeight)
+		s.logger.InfoContext(ctx, "synced transactions", "count", len(td.GetTransactions), "from_height", minHeight, "to_height", maxHeight)
 	} else {
-		log.Printf("synced 0 transactions (no transactions in range %d to %d)", fromHeight+1, toHeight)
+		s.logger.DebugContext(ctx, "no transactions in range", "from_height", fromHeight, "to_height", toHeight)
 	}
 	return nil
 }
@@ -137,11 +171,20 @@ func (s *Syncer) StartRealtimeSync(ctx context.Context) error {
 }
 
 // HandleRealtimeBlock processes real-time block data
-func (s *Syncer) HandleRealtimeBlock(ctx context.Context, block domain.Block) error {
+func (s *Syncer) HandleRealtimeBlock(ctx context.Context, block domain.Block) (err error) {
+	ctx, span := tracing.Start(ctx, "Syncer.HandleRealtimeBlock", attribute.Int(logging.KeyBlockHeight, block.Height))
+	defer func() { tracing.End(span, err) }()
+
+	if logging.CorrelationID(ctx) == "" {
+		ctx = logging.WithCorrelationID(ctx, logging.NewCorrelationID())
+	}
+
 	// save block
 	if err := s.blockRepo.SaveBlock(ctx, block); err != nil {
 		return fmt.Errorf("save realtime block: %w", err)
 	}
+	metrics.BlocksSynced.Inc()
+	metrics.SetSyncedHeight(block.Height)
 
 	// transaction sync
 	if block.NumTxs > 0 {
@@ -150,7 +193,7 @@ func (s *Syncer) HandleRealtimeBlock(ctx context.Context, block domain.Block) er
 		}
 	}
 
-	log.Printf("realtime sync: block %d saved", block.Height)
+	s.logger.InfoContext(ctx, "realtime block saved", logging.KeyBlockHeight, block.Height, "txs", block.NumTxs)
 	return nil
 }
 
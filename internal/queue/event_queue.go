@@ -2,6 +2,7 @@ package queue
 
 import (
 	"context"
+	"gn-indexer/internal/config"
 	"gn-indexer/internal/domain"
 )
 
@@ -27,3 +28,16 @@ type QueueConfig struct {
 	MaxReceiveMessages int
 	VisibilityTimeout  int
 }
+
+// NewQueueConfig creates a queue config from the configured queue, receiving up to maxReceiveMessages per poll
+func NewQueueConfig(cfg *config.QueueConfig, maxReceiveMessages int) *QueueConfig {
+	return &QueueConfig{
+		QueueName:          cfg.Name,
+		EndpointURL:        cfg.EndpointURL,
+		Region:             cfg.Region,
+		AccessKeyID:        cfg.AccessKeyID,
+		SecretAccessKey:    cfg.SecretAccessKey,
+		MaxReceiveMessages: maxReceiveMessages,
+		VisibilityTimeout:  cfg.VisibilityTimeout,
+	}
+}
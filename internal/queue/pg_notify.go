@@ -0,0 +1,120 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"gn-indexer/internal/config"
+	"gn-indexer/internal/logging"
+	"log/slog"
+	"time"
+
+	"gn-indexer/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+	"gorm.io/gorm"
+)
+
+// NotificationChannel is the Postgres NOTIFY channel carrying balance and transfer notifications
+const NotificationChannel = "indexer_notifications"
+
+// listenRetryDelay is the wait before reconnecting a dropped LISTEN connection
+const listenRetryDelay = 5 * time.Second
+
+// PgNotifyPublisher publishes notifications with pg_notify so other processes can LISTEN for them
+type PgNotifyPublisher struct {
+	db      *gorm.DB
+	channel string
+}
+
+// NewPgNotifyPublisher creates a new pg_notify publisher
+func NewPgNotifyPublisher(db *gorm.DB, channel string) *PgNotifyPublisher {
+	return &PgNotifyPublisher{db: db, channel: channel}
+}
+
+// NewNotifyPublisherFor creates a pg_notify publisher for the configured database, nil on SQLite which has no NOTIFY
+func NewNotifyPublisherFor(dbConfig *config.DatabaseConfig, db *gorm.DB, channel string) *PgNotifyPublisher {
+	if dbConfig.IsSQLite() {
+		return nil
+	}
+	return NewPgNotifyPublisher(db, channel)
+}
+
+// Publish sends a notification on the channel
+func (p *PgNotifyPublisher) Publish(ctx context.Context, notification *domain.Notification) error {
+	payload, err := json.Marshal(notification)
+	if err != nil {
+		return fmt.Errorf("marshal notification: %w", err)
+	}
+
+	if err := p.db.WithContext(ctx).Exec("SELECT pg_notify(?, ?)", p.channel, string(payload)).Error; err != nil {
+		return fmt.Errorf("pg_notify: %w", err)
+	}
+	return nil
+}
+
+// PgListener receives notifications published by PgNotifyPublisher
+type PgListener struct {
+	dsn     string
+	channel string
+	handler func(*domain.Notification)
+	logger  *slog.Logger
+}
+
+// NewPgListener creates a new listener calling handler for every notification on the channel
+func NewPgListener(dsn, channel string, handler func(*domain.Notification)) *PgListener {
+	return &PgListener{dsn: dsn, channel: channel, handler: handler, logger: logging.For("notify")}
+}
+
+// NewListenerFor creates a listener for the configured database, nil on SQLite which has no LISTEN
+func NewListenerFor(dbConfig *config.DatabaseConfig, channel string, handler func(*domain.Notification)) *PgListener {
+	if dbConfig.IsSQLite() {
+		return nil
+	}
+	return NewPgListener(dbConfig.DSN, channel, handler)
+}
+
+// Start listens until the context is cancelled, reconnecting when the connection drops
+func (l *PgListener) Start(ctx context.Context) error {
+	for {
+		err := l.listen(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		l.logger.Warn("connection lost, reconnecting", "error", err, "retry_in", listenRetryDelay)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(listenRetryDelay):
+		}
+	}
+}
+
+// listen holds a dedicated connection and dispatches notifications until an error occurs
+func (l *PgListener) listen(ctx context.Context) error {
+	conn, err := pgx.Connect(ctx, l.dsn)
+	if err != nil {
+		return fmt.Errorf("connect: %w", err)
+	}
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{l.channel}.Sanitize()); err != nil {
+		return fmt.Errorf("listen %s: %w", l.channel, err)
+	}
+	l.logger.Info("listening", "channel", l.channel)
+
+	for {
+		pgNotification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		var notification domain.Notification
+		if err := json.Unmarshal([]byte(pgNotification.Payload), &notification); err != nil {
+			l.logger.Warn("invalid payload", "error", err)
+			continue
+		}
+		l.handler(&notification)
+	}
+}
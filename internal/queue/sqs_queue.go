@@ -4,23 +4,33 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"gn-indexer/internal/logging"
+	"log/slog"
+	"strconv"
 
 	"gn-indexer/internal/domain"
+	"gn-indexer/internal/metrics"
+	"gn-indexer/internal/tracing"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/sqs"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const SQSLongPollingSec = 20
 
+// correlationIDAttribute carries the correlation ID of the ingestion that produced an event
+const correlationIDAttribute = "CorrelationId"
+
 // SQSQueue implements EventQueue interface using AWS SQS
 type SQSQueue struct {
 	client   *sqs.SQS
 	queueURL string
 	config   *QueueConfig
+	logger   *slog.Logger
 }
 
 // NewSQSQueue creates a new SQS queue instance
@@ -45,20 +55,31 @@ func NewSQSQueue(config *QueueConfig) (*SQSQueue, error) {
 		return nil, fmt.Errorf("get queue URL: %w", err)
 	}
 
-	log.Printf("SQSQueue: connected to queue %s at %s", config.QueueName, queueURL)
+	logger := logging.For("queue")
+	logger.Info("connected to queue", "queue", config.QueueName, "url", queueURL)
 
 	return &SQSQueue{
 		client:   sqsClient,
 		queueURL: queueURL,
 		config:   config,
+		logger:   logger,
 	}, nil
 }
 
 // SendEvent sends a parsed event to the SQS queue
-func (q *SQSQueue) SendEvent(ctx context.Context, event *domain.ParsedEvent) error {
+func (q *SQSQueue) SendEvent(ctx context.Context, event *domain.ParsedEvent) (err error) {
+	ctx, span := tracing.StartKind(ctx, "SQSQueue.SendEvent", trace.SpanKindProducer,
+		attribute.String("messaging.destination.name", q.config.QueueName),
+		attribute.String("event.type", event.Type),
+		attribute.String(logging.KeyTxHash, event.TxHash),
+		attribute.Int(logging.KeyEventIndex, event.EventIndex),
+	)
+	defer func() { tracing.End(span, err) }()
+
 	// Convert event to JSON
 	eventJSON, err := json.Marshal(event)
 	if err != nil {
+		metrics.QueueFailures.WithLabelValues("send").Inc()
 		return fmt.Errorf("marshal event: %w", err)
 	}
 
@@ -77,19 +98,41 @@ func (q *SQSQueue) SendEvent(ctx context.Context, event *domain.ParsedEvent) err
 			},
 		},
 	}
+	if id := logging.CorrelationID(ctx); id != "" {
+		message.MessageAttributes[correlationIDAttribute] = &sqs.MessageAttributeValue{
+			DataType:    aws.String("String"),
+			StringValue: aws.String(id),
+		}
+	}
+	// The trace context continues in the event processor
+	for key, value := range tracing.Inject(ctx) {
+		message.MessageAttributes[key] = &sqs.MessageAttributeValue{
+			DataType:    aws.String("String"),
+			StringValue: aws.String(value),
+		}
+	}
 
 	// Send message
 	result, err := q.client.SendMessageWithContext(ctx, message)
 	if err != nil {
+		metrics.QueueFailures.WithLabelValues("send").Inc()
 		return fmt.Errorf("send message: %w", err)
 	}
+	metrics.QueueMessagesSent.Inc()
 
-	log.Printf("SQSQueue: sent event %s to queue, message ID: %s", event.Type, *result.MessageId)
+	q.logger.DebugContext(ctx, "sent event", "type", event.Type, logging.KeyTxHash, event.TxHash, logging.KeyEventIndex, event.EventIndex, "message_id", *result.MessageId)
 	return nil
 }
 
 // ReceiveEvents receives multiple events from the SQS queue
-func (q *SQSQueue) ReceiveEvents(ctx context.Context) ([]*domain.ParsedEvent, error) {
+func (q *SQSQueue) ReceiveEvents(ctx context.Context) (events []*domain.ParsedEvent, err error) {
+	ctx, span := tracing.StartKind(ctx, "SQSQueue.ReceiveEvents", trace.SpanKindConsumer,
+		attribute.String("messaging.destination.name", q.config.QueueName))
+	defer func() {
+		span.SetAttributes(attribute.Int("messaging.batch.message_count", len(events)))
+		tracing.End(span, err)
+	}()
+
 	// Prepare receive message input
 	input := &sqs.ReceiveMessageInput{
 		QueueUrl:            aws.String(q.queueURL),
@@ -104,6 +147,7 @@ func (q *SQSQueue) ReceiveEvents(ctx context.Context) ([]*domain.ParsedEvent, er
 	// Receive messages
 	result, err := q.client.ReceiveMessageWithContext(ctx, input)
 	if err != nil {
+		metrics.QueueFailures.WithLabelValues("receive").Inc()
 		return nil, fmt.Errorf("receive messages: %w", err)
 	}
 
@@ -112,19 +156,29 @@ func (q *SQSQueue) ReceiveEvents(ctx context.Context) ([]*domain.ParsedEvent, er
 		return nil, nil // No messages available
 	}
 
-	log.Printf("SQSQueue: received %d messages from queue", len(result.Messages))
+	q.logger.DebugContext(ctx, "received messages", "count", len(result.Messages))
 
 	// Process all messages
-	var events []*domain.ParsedEvent
 	for _, message := range result.Messages {
 		// Parse event from message body
 		var event domain.ParsedEvent
 		if err := json.Unmarshal([]byte(*message.Body), &event); err != nil {
-			log.Printf("SQSQueue: failed to unmarshal message %s: %v", *message.MessageId, err)
+			metrics.QueueFailures.WithLabelValues("decode").Inc()
+			q.logger.ErrorContext(ctx, "failed to unmarshal message", "message_id", *message.MessageId, "error", err)
 			continue // Skip invalid message
 		}
 
-		log.Printf("SQSQueue: parsed event %s from message ID: %s", event.Type, *message.MessageId)
+		if attr, ok := message.MessageAttributes[correlationIDAttribute]; ok && attr.StringValue != nil {
+			event.CorrelationID = *attr.StringValue
+		}
+		for _, key := range tracing.Fields() {
+			if attr, ok := message.MessageAttributes[key]; ok && attr.StringValue != nil {
+				if event.TraceContext == nil {
+					event.TraceContext = make(map[string]string)
+				}
+				event.TraceContext[key] = *attr.StringValue
+			}
+		}
 
 		// Delete message from queue (acknowledge)
 		_, err = q.client.DeleteMessage(&sqs.DeleteMessageInput{
@@ -132,21 +186,39 @@ func (q *SQSQueue) ReceiveEvents(ctx context.Context) ([]*domain.ParsedEvent, er
 			ReceiptHandle: message.ReceiptHandle,
 		})
 		if err != nil {
-			log.Printf("SQSQueue: failed to delete message %s: %v", *message.MessageId, err)
+			metrics.QueueFailures.WithLabelValues("delete").Inc()
+			q.logger.ErrorContext(ctx, "failed to delete message", "message_id", *message.MessageId, "error", err)
 			continue // Skip failed deletion
 		}
 
 		events = append(events, &event)
 	}
+	metrics.QueueMessagesReceived.Add(float64(len(events)))
 
-	log.Printf("SQSQueue: successfully processed %d events", len(events))
 	return events, nil
 }
 
+// Backlog returns the approximate number of messages waiting in the queue, which also proves the queue is reachable
+func (q *SQSQueue) Backlog(ctx context.Context) (int64, error) {
+	name := sqs.QueueAttributeNameApproximateNumberOfMessages
+	result, err := q.client.GetQueueAttributesWithContext(ctx, &sqs.GetQueueAttributesInput{
+		QueueUrl:       aws.String(q.queueURL),
+		AttributeNames: []*string{aws.String(name)},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("get queue attributes: %w", err)
+	}
+	value, ok := result.Attributes[name]
+	if !ok || value == nil {
+		return 0, fmt.Errorf("queue attribute %s missing", name)
+	}
+	return strconv.ParseInt(*value, 10, 64)
+}
+
 // Close closes the SQS connection
 func (q *SQSQueue) Close() error {
 	// SQS client doesn't need explicit closing
-	log.Printf("SQSQueue: connection closed")
+	q.logger.Info("connection closed")
 	return nil
 }
 
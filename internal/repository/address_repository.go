@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gn-indexer/internal/domain"
+	"math/big"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AddressRepository finds and rewrites addresses stored in a non-canonical case
+type AddressRepository interface {
+	ListNonCanonical(ctx context.Context) ([]string, error)
+	Rename(ctx context.Context, from, to string) error
+}
+
+type postgresAddressRepository struct {
+	db *gorm.DB
+}
+
+// NewAddressRepository creates a new address repository
+func NewAddressRepository(db *gorm.DB) AddressRepository {
+	return &postgresAddressRepository{db: db}
+}
+
+// addressColumns lists every table column that stores an account address
+var addressColumns = []struct{ table, column string }{
+	{"transfers", "from_address"},
+	{"transfers", "to_address"},
+	{"balances", "address"},
+	{"balance_history", "address"},
+	{"allowances", "owner"},
+	{"allowances", "spender"},
+	{"nft_transfers", "from_address"},
+	{"nft_transfers", "to_address"},
+	{"nft_owners", "owner"},
+	{"webhooks", "address"},
+}
+
+// ListNonCanonical returns the distinct stored addresses that contain uppercase characters
+func (r *postgresAddressRepository) ListNonCanonical(ctx context.Context) ([]string, error) {
+	query := ""
+	for i, c := range addressColumns {
+		if i > 0 {
+			query += " UNION "
+		}
+		query += fmt.Sprintf("SELECT %[2]s AS address FROM %[1]s WHERE %[2]s <> lower(%[2]s)", c.table, c.column)
+	}
+
+	var addresses []string
+	if err := r.db.WithContext(ctx).Raw(query + " ORDER BY address").Scan(&addresses).Error; err != nil {
+		return nil, fmt.Errorf("failed to list non-canonical addresses: %w", err)
+	}
+	return addresses, nil
+}
+
+// Rename rewrites an address everywhere in one transaction.
+// Balances of both spellings are added up and allowances keep the most recently updated value.
+func (r *postgresAddressRepository) Rename(ctx context.Context, from, to string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := mergeBalances(tx, from, to); err != nil {
+			return err
+		}
+
+		// Rows keyed by address are merged into the canonical spelling first
+		merges := []string{
+			`INSERT INTO allowances (owner, spender, token_path, amount, last_tx_hash, last_block_h, updated_at)
+			 SELECT @to, spender, token_path, amount, last_tx_hash, last_block_h, CURRENT_TIMESTAMP FROM allowances WHERE owner = @from
+			 ON CONFLICT (owner, spender, token_path) DO UPDATE SET
+			   amount = EXCLUDED.amount, last_tx_hash = EXCLUDED.last_tx_hash, last_block_h = EXCLUDED.last_block_h, updated_at = CURRENT_TIMESTAMP
+			 WHERE EXCLUDED.last_block_h > allowances.last_block_h`,
+			`DELETE FROM allowances WHERE owner = @from`,
+			`INSERT INTO allowances (owner, spender, token_path, amount, last_tx_hash, last_block_h, updated_at)
+			 SELECT owner, @to, token_path, amount, last_tx_hash, last_block_h, CURRENT_TIMESTAMP FROM allowances WHERE spender = @from
+			 ON CONFLICT (owner, spender, token_path) DO UPDATE SET
+			   amount = EXCLUDED.amount, last_tx_hash = EXCLUDED.last_tx_hash, last_block_h = EXCLUDED.last_block_h, updated_at = CURRENT_TIMESTAMP
+			 WHERE EXCLUDED.last_block_h > allowances.last_block_h`,
+			`DELETE FROM allowances WHERE spender = @from`,
+			// The same event cannot be recorded under both spellings, drop any duplicate before renaming
+			`DELETE FROM balance_history AS h WHERE h.address = @from AND EXISTS (
+			   SELECT 1 FROM balance_history c
+			   WHERE c.address = @to AND c.token_path = h.token_path AND c.tx_hash = h.tx_hash AND c.event_index = h.event_index)`,
+		}
+		args := map[string]interface{}{"from": from, "to": to}
+		for _, statement := range merges {
+			if err := tx.Exec(statement, args).Error; err != nil {
+				return fmt.Errorf("failed to merge address %s: %w", from, err)
+			}
+		}
+
+		for _, c := range addressColumns {
+			if c.table == "balances" || c.table == "allowances" {
+				continue
+			}
+			statement := fmt.Sprintf("UPDATE %[1]s SET %[2]s = @to WHERE %[2]s = @from", c.table, c.column)
+			if err := tx.Exec(statement, args).Error; err != nil {
+				return fmt.Errorf("failed to rename address in %s.%s: %w", c.table, c.column, err)
+			}
+		}
+		return nil
+	})
+}
+
+// mergeBalances moves the balances of from onto to, adding up tokens held under both spellings.
+// The sum is computed with big integers since amounts exceed the exact range of SQL arithmetic on SQLite.
+func mergeBalances(tx *gorm.DB, from, to string) error {
+	var moved []*domain.Balance
+	if err := tx.Where("address = ?", from).Find(&moved).Error; err != nil {
+		return fmt.Errorf("failed to merge address %s: %w", from, err)
+	}
+
+	now := time.Now()
+	for _, balance := range moved {
+		var existing domain.Balance
+		err := tx.Where("address = ? AND token_path = ?", to, balance.TokenPath).Take(&existing).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("failed to merge address %s: %w", from, err)
+		}
+
+		merged := *balance
+		merged.Address = to
+		merged.UpdatedAt = now
+		if err == nil {
+			merged.Amount = &domain.U64{Int: new(big.Int).Add(amountOf(existing.Amount), amountOf(balance.Amount))}
+			if existing.LastBlockH >= balance.LastBlockH {
+				merged.LastTxHash = existing.LastTxHash
+				merged.LastBlockH = existing.LastBlockH
+			}
+		}
+		if err := tx.Save(&merged).Error; err != nil {
+			return fmt.Errorf("failed to merge address %s: %w", from, err)
+		}
+	}
+
+	if err := tx.Where("address = ?", from).Delete(&domain.Balance{}).Error; err != nil {
+		return fmt.Errorf("failed to merge address %s: %w", from, err)
+	}
+	return nil
+}
+
+// amountOf returns the integer of an amount, zero when it is unset
+func amountOf(amount *domain.U64) *big.Int {
+	if amount == nil || amount.Int == nil {
+		return new(big.Int)
+	}
+	return amount.Int
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gn-indexer/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// ErrAllowanceNotFound is returned when an allowance is not found
+var ErrAllowanceNotFound = errors.New("allowance not found")
+
+// AllowanceRepository handles allowance data persistence
+type AllowanceRepository interface {
+	Save(ctx context.Context, allowance *domain.Allowance) error
+	GetAllowance(ctx context.Context, tokenPath, owner, spender string) (*domain.Allowance, error)
+	ListAllowances(ctx context.Context, query AllowanceQuery) ([]*domain.Allowance, int64, error)
+}
+
+// AllowanceQuery selects a page of allowances, zero values are ignored
+type AllowanceQuery struct {
+	Owner     string
+	Spender   string
+	TokenPath string
+	Offset    int
+	Limit     int
+}
+
+type postgresAllowanceRepository struct {
+	db *gorm.DB
+}
+
+// NewAllowanceRepository creates a new PostgreSQL allowance repository
+func NewAllowanceRepository(db *gorm.DB) AllowanceRepository {
+	return &postgresAllowanceRepository{db: db}
+}
+
+// Save inserts or overwrites the allowance for (owner, spender, token_path)
+func (r *postgresAllowanceRepository) Save(ctx context.Context, allowance *domain.Allowance) error {
+	if err := r.db.WithContext(ctx).Save(allowance).Error; err != nil {
+		return fmt.Errorf("failed to save allowance: %w", err)
+	}
+	return nil
+}
+
+// GetAllowance retrieves the allowance an owner granted to a spender for a token
+func (r *postgresAllowanceRepository) GetAllowance(ctx context.Context, tokenPath, owner, spender string) (*domain.Allowance, error) {
+	var allowance domain.Allowance
+	err := r.db.WithContext(ctx).
+		Where("token_path = ? AND owner = ? AND spender = ?", tokenPath, owner, spender).
+		First(&allowance).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAllowanceNotFound
+		}
+		return nil, fmt.Errorf("failed to get allowance: %w", err)
+	}
+
+	return &allowance, nil
+}
+
+// ListAllowances returns a page of allowances matching the query and the total number of matches.
+// Allowances granted by an owner are ordered by spender, those received by a spender by owner.
+func (r *postgresAllowanceRepository) ListAllowances(ctx context.Context, query AllowanceQuery) ([]*domain.Allowance, int64, error) {
+	filtered := r.db.WithContext(ctx).Model(&domain.Allowance{})
+	if query.Owner != "" {
+		filtered = filtered.Where("owner = ?", query.Owner)
+	}
+	if query.Spender != "" {
+		filtered = filtered.Where("spender = ?", query.Spender)
+	}
+	if query.TokenPath != "" {
+		filtered = filtered.Where("token_path = ?", query.TokenPath)
+	}
+
+	var total int64
+	if err := filtered.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count allowances: %w", err)
+	}
+
+	order := "token_path, owner, spender"
+	if query.Owner != "" {
+		order = "token_path, spender, owner"
+	}
+	page := filtered.Session(&gorm.Session{}).Order(order).Offset(query.Offset)
+	if query.Limit > 0 {
+		page = page.Limit(query.Limit)
+	}
+
+	var allowances []*domain.Allowance
+	if err := page.Find(&allowances).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list allowances: %w", err)
+	}
+
+	return allowances, total, nil
+}
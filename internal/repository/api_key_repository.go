@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gn-indexer/internal/domain"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrAPIKeyNotFound is returned when an API key is not found
+var ErrAPIKeyNotFound = errors.New("api key not found")
+
+// APIKeyRepository handles API keys and their daily usage counters
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *domain.APIKey) error
+	GetByID(ctx context.Context, id int64) (*domain.APIKey, error)
+	GetByHash(ctx context.Context, keyHash string) (*domain.APIKey, error)
+	List(ctx context.Context) ([]*domain.APIKey, error)
+	Revoke(ctx context.Context, id int64, at time.Time) error
+	AddUsage(ctx context.Context, keyID int64, day time.Time, requests, rejected int64) error
+	GetUsage(ctx context.Context, keyID int64, fromDay time.Time) ([]*domain.APIKeyUsage, error)
+}
+
+type postgresAPIKeyRepository struct {
+	db *gorm.DB
+}
+
+// NewAPIKeyRepository creates a new PostgreSQL API key repository
+func NewAPIKeyRepository(db *gorm.DB) APIKeyRepository {
+	return &postgresAPIKeyRepository{db: db}
+}
+
+// Create stores a new API key
+func (r *postgresAPIKeyRepository) Create(ctx context.Context, key *domain.APIKey) error {
+	if err := r.db.WithContext(ctx).Create(key).Error; err != nil {
+		return fmt.Errorf("failed to create api key: %w", err)
+	}
+	return nil
+}
+
+// GetByID retrieves an API key by id
+func (r *postgresAPIKeyRepository) GetByID(ctx context.Context, id int64) (*domain.APIKey, error) {
+	return r.first(ctx, "id = ?", id)
+}
+
+// GetByHash retrieves an API key by the hash of its secret
+func (r *postgresAPIKeyRepository) GetByHash(ctx context.Context, keyHash string) (*domain.APIKey, error) {
+	return r.first(ctx, "key_hash = ?", keyHash)
+}
+
+func (r *postgresAPIKeyRepository) first(ctx context.Context, query string, arg interface{}) (*domain.APIKey, error) {
+	var key domain.APIKey
+	err := r.db.WithContext(ctx).Where(query, arg).First(&key).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAPIKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to get api key: %w", err)
+	}
+	return &key, nil
+}
+
+// List retrieves all API keys, revoked ones included
+func (r *postgresAPIKeyRepository) List(ctx context.Context) ([]*domain.APIKey, error) {
+	var keys []*domain.APIKey
+	if err := r.db.WithContext(ctx).Order("id ASC").Find(&keys).Error; err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+	return keys, nil
+}
+
+// Revoke marks a key as revoked, revoking twice keeps the first time
+func (r *postgresAPIKeyRepository) Revoke(ctx context.Context, id int64, at time.Time) error {
+	result := r.db.WithContext(ctx).Model(&domain.APIKey{}).
+		Where("id = ?", id).
+		Update("revoked_at", gorm.Expr("COALESCE(revoked_at, ?)", at))
+	if result.Error != nil {
+		return fmt.Errorf("failed to revoke api key: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// AddUsage adds request counts to the usage row of a key and day
+func (r *postgresAPIKeyRepository) AddUsage(ctx context.Context, keyID int64, day time.Time, requests, rejected int64) error {
+	err := r.db.WithContext(ctx).Exec(`
+		INSERT INTO api_key_usage (api_key_id, day, requests, rejected) VALUES (?, ?, ?, ?)
+		ON CONFLICT (api_key_id, day) DO UPDATE SET
+		  requests = api_key_usage.requests + EXCLUDED.requests,
+		  rejected = api_key_usage.rejected + EXCLUDED.rejected`,
+		keyID, day.Format("2006-01-02"), requests, rejected).Error
+	if err != nil {
+		return fmt.Errorf("failed to add api key usage: %w", err)
+	}
+	return nil
+}
+
+// GetUsage retrieves the usage of a key from a day on, newest first
+func (r *postgresAPIKeyRepository) GetUsage(ctx context.Context, keyID int64, fromDay time.Time) ([]*domain.APIKeyUsage, error) {
+	var usage []*domain.APIKeyUsage
+	err := r.db.WithContext(ctx).
+		Where("api_key_id = ? AND day >= ?", keyID, fromDay.Format("2006-01-02")).
+		Order("day DESC").
+		Find(&usage).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get api key usage: %w", err)
+	}
+	return usage, nil
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"gn-indexer/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// BalanceHistoryRepository handles balance history persistence and point-in-time queries
+type BalanceHistoryRepository interface {
+	Record(ctx context.Context, history *domain.BalanceHistory) (bool, error)
+	GetBalanceAt(ctx context.Context, tokenPath, address string, height int64) (*domain.Balance, error)
+	ListBalancesAt(ctx context.Context, height int64, query BalanceQuery) ([]*domain.Balance, int64, error)
+	StreamBalancesAt(ctx context.Context, height int64, query BalanceQuery, fn func(*domain.Balance) error) error
+}
+
+type postgresBalanceHistoryRepository struct {
+	db *gorm.DB
+}
+
+// NewBalanceHistoryRepository creates a new PostgreSQL balance history repository
+func NewBalanceHistoryRepository(db *gorm.DB) BalanceHistoryRepository {
+	return &postgresBalanceHistoryRepository{db: db}
+}
+
+// Record stores the balance change of an event, it reports false when the event was already recorded
+func (r *postgresBalanceHistoryRepository) Record(ctx context.Context, history *domain.BalanceHistory) (bool, error) {
+	result := conn(ctx, r.db).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "token_path"}, {Name: "address"}, {Name: "tx_hash"}, {Name: "event_index"}},
+			DoNothing: true,
+		}).
+		Create(history)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to record balance history: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// GetBalanceAt returns the balance of an address as of the end of a block
+func (r *postgresBalanceHistoryRepository) GetBalanceAt(ctx context.Context, tokenPath, address string, height int64) (*domain.Balance, error) {
+	balances, _, err := r.ListBalancesAt(ctx, height, BalanceQuery{TokenPath: tokenPath, Address: address, Limit: 1})
+	if err != nil {
+		return nil, err
+	}
+	if len(balances) == 0 {
+		return nil, ErrBalanceNotFound
+	}
+	return balances[0], nil
+}
+
+// ListBalancesAt returns a page of balances as of the end of a block
+func (r *postgresBalanceHistoryRepository) ListBalancesAt(ctx context.Context, height int64, query BalanceQuery) ([]*domain.Balance, int64, error) {
+	filtered := r.filterBalancesAt(ctx, height, query)
+
+	var total int64
+	if err := filtered.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count historical balances: %w", err)
+	}
+
+	page := filtered.Session(&gorm.Session{}).
+		Select(historicalBalanceColumns).
+		Order(query.order()).
+		Offset(query.Offset)
+	if query.Limit > 0 {
+		page = page.Limit(query.Limit)
+	}
+
+	var balances []*domain.Balance
+	if err := page.Find(&balances).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list historical balances: %w", err)
+	}
+
+	return balances, total, nil
+}
+
+// StreamBalancesAt calls fn for every balance as of the end of a block without loading them all in memory
+func (r *postgresBalanceHistoryRepository) StreamBalancesAt(ctx context.Context, height int64, query BalanceQuery, fn func(*domain.Balance) error) error {
+	rows, err := r.filterBalancesAt(ctx, height, query).
+		Select(historicalBalanceColumns).
+		Order(query.order()).
+		Rows()
+	if err != nil {
+		return fmt.Errorf("failed to stream historical balances: %w", err)
+	}
+	defer rows.Close()
+
+	return scanBalanceRows(r.db, rows, fn)
+}
+
+// historicalBalanceColumns maps balance history rows onto domain.Balance columns
+const historicalBalanceColumns = "address, token_path, amount, tx_hash AS last_tx_hash, block_height AS last_block_h"
+
+// filterBalancesAt sums the balance changes per (token, address) up to a height and applies the query filters.
+// A sum does not depend on the order events were indexed in, the last transaction is the latest by chain position.
+func (r *postgresBalanceHistoryRepository) filterBalancesAt(ctx context.Context, height int64, query BalanceQuery) *gorm.DB {
+	amount := "GREATEST(SUM(delta) OVER (PARTITION BY token_path, address), 0)"
+	if isSQLite(r.db) {
+		// Zero padded like the amounts of the balances table, see domain.U64
+		amount = "printf('%020d', max(SUM(delta) OVER (PARTITION BY token_path, address), 0))"
+	}
+	latest := conn(ctx, r.db).
+		Model(&domain.BalanceHistory{}).
+		Select("address, token_path, "+amount+" AS amount, tx_hash, block_height, "+
+			"ROW_NUMBER() OVER (PARTITION BY token_path, address ORDER BY block_height DESC, event_index DESC, id DESC) AS rn").
+		Where("block_height <= ?", height)
+	if query.TokenPath != "" {
+		latest = latest.Where("token_path = ?", query.TokenPath)
+	}
+	if query.Address != "" {
+		latest = latest.Where("address = ?", query.Address)
+	}
+
+	return query.applyAmountFilters(conn(ctx, r.db).Table("(?) AS latest", latest).Where("rn = 1"))
+}
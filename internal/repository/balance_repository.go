@@ -2,7 +2,9 @@ package repository
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"fmt"
 	"gn-indexer/internal/domain"
 
 	"gorm.io/gorm"
@@ -19,6 +21,34 @@ type BalanceRepository interface {
 	GetBalancesByAddress(ctx context.Context, address string) ([]*domain.Balance, error)
 	GetBalancesByTokenAndAddress(ctx context.Context, tokenPath string) ([]*domain.Balance, error)
 	GetAllBalances(ctx context.Context) ([]*domain.Balance, error)
+	ListBalances(ctx context.Context, query BalanceQuery) ([]*domain.Balance, int64, error)
+	GetTokenHolderSummary(ctx context.Context, tokenPath string) (*TokenHolderSummary, error)
+	StreamBalances(ctx context.Context, query BalanceQuery, fn func(*domain.Balance) error) error
+}
+
+// Balance sort orders for ListBalances
+const (
+	BalanceSortAmount  = "amount"  // largest holders first
+	BalanceSortAddress = "address" // alphabetical
+)
+
+// BalanceQuery selects a page of balances, zero values are ignored
+type BalanceQuery struct {
+	TokenPath   string
+	Address     string
+	MinAmount   *domain.U64
+	ExcludeZero bool
+	Exclude     []string // addresses left out of the result
+	Sort        string   // BalanceSortAmount (default) or BalanceSortAddress
+	Offset      int
+	Limit       int
+}
+
+// TokenHolderSummary aggregates the non-zero balances of a token
+type TokenHolderSummary struct {
+	TokenPath   string
+	HolderCount int64
+	TotalSupply *domain.U64 // sum of all balances
 }
 
 // balanceRepository implements BalanceRepository
@@ -33,7 +63,7 @@ func NewBalanceRepository(db *gorm.DB) BalanceRepository {
 
 // Create creates a new balance record
 func (r *balanceRepository) Create(ctx context.Context, balance *domain.Balance) error {
-	result := r.db.WithContext(ctx).Create(balance)
+	result := conn(ctx, r.db).Create(balance)
 	if result.Error != nil {
 		return result.Error
 	}
@@ -42,7 +72,7 @@ func (r *balanceRepository) Create(ctx context.Context, balance *domain.Balance)
 
 // Update updates an existing balance record
 func (r *balanceRepository) Update(ctx context.Context, balance *domain.Balance) error {
-	result := r.db.WithContext(ctx).Save(balance)
+	result := conn(ctx, r.db).Save(balance)
 	if result.Error != nil {
 		return result.Error
 	}
@@ -52,7 +82,7 @@ func (r *balanceRepository) Update(ctx context.Context, balance *domain.Balance)
 // GetBalance gets a balance for a specific token and address
 func (r *balanceRepository) GetBalance(ctx context.Context, tokenPath, address string) (*domain.Balance, error) {
 	var balance domain.Balance
-	result := r.db.WithContext(ctx).
+	result := conn(ctx, r.db).
 		Where("token_path = ? AND address = ?", tokenPath, address).
 		First(&balance)
 
@@ -69,7 +99,7 @@ func (r *balanceRepository) GetBalance(ctx context.Context, tokenPath, address s
 // GetBalancesByAddress gets all balances for a specific address
 func (r *balanceRepository) GetBalancesByAddress(ctx context.Context, address string) ([]*domain.Balance, error) {
 	var balances []*domain.Balance
-	result := r.db.WithContext(ctx).
+	result := conn(ctx, r.db).
 		Where("address = ?", address).
 		Find(&balances)
 
@@ -83,7 +113,7 @@ func (r *balanceRepository) GetBalancesByAddress(ctx context.Context, address st
 // GetBalancesByTokenAndAddress gets all balances for a specific token
 func (r *balanceRepository) GetBalancesByTokenAndAddress(ctx context.Context, tokenPath string) ([]*domain.Balance, error) {
 	var balances []*domain.Balance
-	result := r.db.WithContext(ctx).
+	result := conn(ctx, r.db).
 		Where("token_path = ?", tokenPath).
 		Find(&balances)
 
@@ -97,7 +127,7 @@ func (r *balanceRepository) GetBalancesByTokenAndAddress(ctx context.Context, to
 // GetAllBalances gets all balances
 func (r *balanceRepository) GetAllBalances(ctx context.Context) ([]*domain.Balance, error) {
 	var balances []*domain.Balance
-	result := r.db.WithContext(ctx).Find(&balances)
+	result := conn(ctx, r.db).Find(&balances)
 
 	if result.Error != nil {
 		return nil, result.Error
@@ -105,3 +135,124 @@ func (r *balanceRepository) GetAllBalances(ctx context.Context) ([]*domain.Balan
 
 	return balances, nil
 }
+
+// ListBalances returns a sorted page of balances and the total number of matching rows
+func (r *balanceRepository) ListBalances(ctx context.Context, query BalanceQuery) ([]*domain.Balance, int64, error) {
+	filtered := r.filterBalances(ctx, query)
+
+	var total int64
+	if err := filtered.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count balances: %w", err)
+	}
+
+	page := filtered.Session(&gorm.Session{}).Order(query.order()).Offset(query.Offset)
+	if query.Limit > 0 {
+		page = page.Limit(query.Limit)
+	}
+
+	var balances []*domain.Balance
+	if err := page.Find(&balances).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list balances: %w", err)
+	}
+
+	return balances, total, nil
+}
+
+// StreamBalances calls fn for every matching balance in query order without loading them all in memory
+func (r *balanceRepository) StreamBalances(ctx context.Context, query BalanceQuery, fn func(*domain.Balance) error) error {
+	rows, err := r.filterBalances(ctx, query).Order(query.order()).Rows()
+	if err != nil {
+		return fmt.Errorf("failed to stream balances: %w", err)
+	}
+	defer rows.Close()
+
+	return scanBalanceRows(r.db, rows, fn)
+}
+
+// filterBalances applies the query filters to the balances table
+func (r *balanceRepository) filterBalances(ctx context.Context, query BalanceQuery) *gorm.DB {
+	filtered := conn(ctx, r.db).Model(&domain.Balance{})
+	if query.TokenPath != "" {
+		filtered = filtered.Where("token_path = ?", query.TokenPath)
+	}
+	if query.Address != "" {
+		filtered = filtered.Where("address = ?", query.Address)
+	}
+	return query.applyAmountFilters(filtered)
+}
+
+// applyAmountFilters applies the amount and exclusion filters shared by current and historical queries
+func (q BalanceQuery) applyAmountFilters(db *gorm.DB) *gorm.DB {
+	if q.MinAmount != nil {
+		db = db.Where("amount >= ?", q.MinAmount)
+	}
+	if q.ExcludeZero {
+		db = db.Where("amount > ?", domain.NewU64(0))
+	}
+	if len(q.Exclude) > 0 {
+		db = db.Where("address NOT IN ?", q.Exclude)
+	}
+	return db
+}
+
+// order returns the ORDER BY clause for the query sort
+func (q BalanceQuery) order() string {
+	if q.Sort == BalanceSortAddress {
+		return "address ASC, token_path ASC"
+	}
+	return "amount DESC, address ASC, token_path ASC"
+}
+
+// scanBalanceRows scans each row into a balance and passes it to fn
+func scanBalanceRows(db *gorm.DB, rows *sql.Rows, fn func(*domain.Balance) error) error {
+	for rows.Next() {
+		var balance domain.Balance
+		if err := db.ScanRows(rows, &balance); err != nil {
+			return fmt.Errorf("failed to scan balance: %w", err)
+		}
+		if err := fn(&balance); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// GetTokenHolderSummary counts the holders of a token and sums their balances
+func (r *balanceRepository) GetTokenHolderSummary(ctx context.Context, tokenPath string) (*TokenHolderSummary, error) {
+	if isSQLite(r.db) {
+		return r.sumTokenHolders(ctx, tokenPath)
+	}
+
+	var row struct {
+		HolderCount int64
+		TotalSupply domain.U64
+	}
+	err := conn(ctx, r.db).
+		Model(&domain.Balance{}).
+		Select("COUNT(*) AS holder_count, COALESCE(SUM(amount), 0) AS total_supply").
+		Where("token_path = ? AND amount > ?", tokenPath, domain.NewU64(0)).
+		Scan(&row).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize holders: %w", err)
+	}
+
+	return &TokenHolderSummary{
+		TokenPath:   tokenPath,
+		HolderCount: row.HolderCount,
+		TotalSupply: &row.TotalSupply,
+	}, nil
+}
+
+// sumTokenHolders summarizes the holders of a token in Go, SUM on SQLite turns large amounts into floats
+func (r *balanceRepository) sumTokenHolders(ctx context.Context, tokenPath string) (*TokenHolderSummary, error) {
+	summary := &TokenHolderSummary{TokenPath: tokenPath, TotalSupply: domain.NewU64(0)}
+	err := r.StreamBalances(ctx, BalanceQuery{TokenPath: tokenPath, ExcludeZero: true, Sort: BalanceSortAddress}, func(balance *domain.Balance) error {
+		summary.HolderCount++
+		summary.TotalSupply.Add(summary.TotalSupply.Int, amountOf(balance.Amount))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize holders: %w", err)
+	}
+	return summary, nil
+}
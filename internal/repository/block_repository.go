@@ -2,18 +2,26 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"gn-indexer/internal/domain"
+	"time"
 
 	"gorm.io/gorm"
 )
 
+// ErrBlockNotFound is returned when a block is not found
+var ErrBlockNotFound = errors.New("block not found")
+
 // BlockRepository handles block data persistence
 type BlockRepository interface {
 	SaveBlock(ctx context.Context, block domain.Block) error
 	GetLastSyncedHeight(ctx context.Context) (int, error)
 	GetBlockByHash(ctx context.Context, hash string) (*domain.Block, error)
 	GetBlockByHeight(ctx context.Context, height int) (*domain.Block, error)
+	GetHeightAtTime(ctx context.Context, at time.Time) (int64, error)
+	GetBlocksByHeights(ctx context.Context, heights []int64) ([]domain.Block, error)
+	ListBlocks(ctx context.Context, beforeHeight int64, limit int) ([]domain.Block, error)
 }
 
 type postgresBlockRepository struct {
@@ -53,6 +61,9 @@ func (r *postgresBlockRepository) GetBlockByHash(ctx context.Context, hash strin
 	var block domain.Block
 	err := r.db.WithContext(ctx).Where("hash = ?", hash).First(&block).Error
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBlockNotFound
+		}
 		return nil, fmt.Errorf("failed to get block by hash: %w", err)
 	}
 	return &block, nil
@@ -63,7 +74,47 @@ func (r *postgresBlockRepository) GetBlockByHeight(ctx context.Context, height i
 	var block domain.Block
 	err := r.db.WithContext(ctx).Where("height = ?", height).First(&block).Error
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBlockNotFound
+		}
 		return nil, fmt.Errorf("failed to get block by height: %w", err)
 	}
 	return &block, nil
 }
+
+// GetHeightAtTime returns the height of the last block produced at or before the given time, 0 if none
+func (r *postgresBlockRepository) GetHeightAtTime(ctx context.Context, at time.Time) (int64, error) {
+	var height int64
+	err := r.db.WithContext(ctx).Model(&domain.Block{}).Select("COALESCE(MAX(height), 0)").Where("time <= ?", at).Scan(&height).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to get block height at time: %w", err)
+	}
+	return height, nil
+}
+
+// GetBlocksByHeights retrieves the blocks at the given heights in one query
+func (r *postgresBlockRepository) GetBlocksByHeights(ctx context.Context, heights []int64) ([]domain.Block, error) {
+	var blocks []domain.Block
+	if len(heights) == 0 {
+		return blocks, nil
+	}
+	err := r.db.WithContext(ctx).Where("height IN ?", heights).Find(&blocks).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blocks by heights: %w", err)
+	}
+	return blocks, nil
+}
+
+// ListBlocks retrieves blocks below beforeHeight, newest first; beforeHeight 0 starts from the latest block
+func (r *postgresBlockRepository) ListBlocks(ctx context.Context, beforeHeight int64, limit int) ([]domain.Block, error) {
+	query := r.db.WithContext(ctx).Order("height DESC").Limit(limit)
+	if beforeHeight > 0 {
+		query = query.Where("height < ?", beforeHeight)
+	}
+
+	var blocks []domain.Block
+	if err := query.Find(&blocks).Error; err != nil {
+		return nil, fmt.Errorf("failed to list blocks: %w", err)
+	}
+	return blocks, nil
+}
@@ -0,0 +1,8 @@
+package repository
+
+import "gorm.io/gorm"
+
+// isSQLite reports whether db runs on SQLite, where arithmetic on amounts is done in Go
+func isSQLite(db *gorm.DB) bool {
+	return db.Dialector.Name() == "sqlite"
+}
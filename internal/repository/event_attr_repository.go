@@ -11,6 +11,7 @@ import (
 type EventAttrRepository interface {
 	Create(ctx context.Context, attr *domain.TxEventAttr) error
 	GetByEventID(ctx context.Context, eventID int64) ([]domain.TxEventAttr, error)
+	GetByEventIDs(ctx context.Context, eventIDs []int64) ([]domain.TxEventAttr, error)
 }
 
 type postgresEventAttrRepository struct {
@@ -26,7 +27,7 @@ func NewEventAttrRepository(db *gorm.DB) EventAttrRepository {
 func (r *postgresEventAttrRepository) Create(ctx context.Context, attr *domain.TxEventAttr) error {
 	// Check if attribute already exists
 	var count int64
-	err := r.db.WithContext(ctx).Model(&domain.TxEventAttr{}).
+	err := conn(ctx, r.db).Model(&domain.TxEventAttr{}).
 		Where("event_id = ? AND attr_index = ?", attr.EventID, attr.AttrIndex).
 		Count(&count).Error
 
@@ -39,7 +40,7 @@ func (r *postgresEventAttrRepository) Create(ctx context.Context, attr *domain.T
 	}
 
 	// new event attr save
-	return r.db.WithContext(ctx).Table("indexer.tx_event_attrs").Create(attr).Error
+	return conn(ctx, r.db).Table("tx_event_attrs").Create(attr).Error
 }
 
 // GetByEventID retrieves all attributes for an event
@@ -56,3 +57,16 @@ func (r *postgresEventAttrRepository) GetByEventID(ctx context.Context, eventID
 
 	return attrs, nil
 }
+
+// GetByEventIDs retrieves the attributes of several events in one query, ordered by event and index
+func (r *postgresEventAttrRepository) GetByEventIDs(ctx context.Context, eventIDs []int64) ([]domain.TxEventAttr, error) {
+	var attrs []domain.TxEventAttr
+	if len(eventIDs) == 0 {
+		return attrs, nil
+	}
+	err := r.db.WithContext(ctx).Where("event_id IN ?", eventIDs).Order("event_id, attr_index").Find(&attrs).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get event attrs by event ids: %w", err)
+	}
+	return attrs, nil
+}
@@ -12,6 +12,17 @@ type EventRepository interface {
 	Create(ctx context.Context, event *domain.TxEvent) error
 	GetByTxHash(ctx context.Context, txHash string) ([]domain.TxEvent, error)
 	GetByTxHashAndIndex(ctx context.Context, txHash string, eventIndex int) (*domain.TxEvent, error)
+	GetByTxHashes(ctx context.Context, txHashes []string) ([]domain.TxEvent, error)
+	List(ctx context.Context, filter EventFilter) ([]domain.TxEvent, error)
+}
+
+// EventFilter selects events for List, newest first; empty fields match everything
+type EventFilter struct {
+	Type     string
+	Func     string
+	PkgPath  string
+	BeforeID int64 // id of the last event of the previous page
+	Limit    int
 }
 
 type postgresEventRepository struct {
@@ -25,22 +36,24 @@ func NewEventRepository(db *gorm.DB) EventRepository {
 
 // Create saves an event to the tx_events table
 func (r *postgresEventRepository) Create(ctx context.Context, event *domain.TxEvent) error {
-	// Check if event already exists
-	var count int64
-	err := r.db.WithContext(ctx).Model(&domain.TxEvent{}).
+	// Check if event already exists, reusing its ID so attributes can still reference it
+	var existing []domain.TxEvent
+	err := conn(ctx, r.db).
 		Where("tx_hash = ? AND event_index = ?", event.TxHash, event.EventIndex).
-		Count(&count).Error
+		Limit(1).
+		Find(&existing).Error
 
 	if err != nil {
 		return fmt.Errorf("failed to check event existence: %w", err)
 	}
 
-	if count > 0 {
+	if len(existing) > 0 {
+		event.ID = existing[0].ID
 		return nil
 	}
 
 	// new event save
-	return r.db.WithContext(ctx).Table("indexer.tx_events").Create(event).Error
+	return conn(ctx, r.db).Table("tx_events").Create(event).Error
 }
 
 // GetByTxHash retrieves all events for a transaction
@@ -71,3 +84,40 @@ func (r *postgresEventRepository) GetByTxHashAndIndex(ctx context.Context, txHas
 
 	return &event, nil
 }
+
+// GetByTxHashes retrieves the events of several transactions in one query, ordered by transaction and index
+func (r *postgresEventRepository) GetByTxHashes(ctx context.Context, txHashes []string) ([]domain.TxEvent, error) {
+	var events []domain.TxEvent
+	if len(txHashes) == 0 {
+		return events, nil
+	}
+	err := r.db.WithContext(ctx).Where("tx_hash IN ?", txHashes).Order("tx_hash, event_index").Find(&events).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get events by tx hashes: %w", err)
+	}
+	return events, nil
+}
+
+// List retrieves events matching the filter in id descending order
+func (r *postgresEventRepository) List(ctx context.Context, filter EventFilter) ([]domain.TxEvent, error) {
+	query := r.db.WithContext(ctx).Model(&domain.TxEvent{})
+
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if filter.Func != "" {
+		query = query.Where("func = ?", filter.Func)
+	}
+	if filter.PkgPath != "" {
+		query = query.Where("pkg_path = ?", filter.PkgPath)
+	}
+	if filter.BeforeID > 0 {
+		query = query.Where("id < ?", filter.BeforeID)
+	}
+
+	var events []domain.TxEvent
+	if err := query.Order("id DESC").Limit(filter.Limit).Find(&events).Error; err != nil {
+		return nil, fmt.Errorf("failed to list events: %w", err)
+	}
+	return events, nil
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gn-indexer/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// ErrNFTNotFound is returned when an NFT owner record is not found
+var ErrNFTNotFound = errors.New("nft not found")
+
+// NFTRepository handles GRC721 ownership and transfer history persistence
+type NFTRepository interface {
+	SaveOwner(ctx context.Context, owner *domain.NFTOwner) error
+	GetOwner(ctx context.Context, collectionPath, tokenID string) (*domain.NFTOwner, error)
+	GetByOwner(ctx context.Context, owner string) ([]domain.NFTOwner, error)
+	CreateTransfer(ctx context.Context, transfer *domain.NFTTransfer) error
+	GetTransfersByToken(ctx context.Context, collectionPath, tokenID string) ([]domain.NFTTransfer, error)
+	GetTransfersByCollection(ctx context.Context, collectionPath string, limit int) ([]domain.NFTTransfer, error)
+}
+
+type postgresNFTRepository struct {
+	db *gorm.DB
+}
+
+// NewNFTRepository creates a new PostgreSQL NFT repository
+func NewNFTRepository(db *gorm.DB) NFTRepository {
+	return &postgresNFTRepository{db: db}
+}
+
+// SaveOwner inserts or overwrites the owner of (collection_path, token_id)
+func (r *postgresNFTRepository) SaveOwner(ctx context.Context, owner *domain.NFTOwner) error {
+	if err := r.db.WithContext(ctx).Save(owner).Error; err != nil {
+		return fmt.Errorf("failed to save nft owner: %w", err)
+	}
+	return nil
+}
+
+// GetOwner retrieves the ownership record of a single token
+func (r *postgresNFTRepository) GetOwner(ctx context.Context, collectionPath, tokenID string) (*domain.NFTOwner, error) {
+	var owner domain.NFTOwner
+	err := r.db.WithContext(ctx).
+		Where("collection_path = ? AND token_id = ?", collectionPath, tokenID).
+		First(&owner).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNFTNotFound
+		}
+		return nil, fmt.Errorf("failed to get nft owner: %w", err)
+	}
+
+	return &owner, nil
+}
+
+// GetByOwner retrieves all tokens currently held by an address
+func (r *postgresNFTRepository) GetByOwner(ctx context.Context, owner string) ([]domain.NFTOwner, error) {
+	var owners []domain.NFTOwner
+	err := r.db.WithContext(ctx).
+		Where("owner = ?", owner).
+		Order("collection_path, token_id").
+		Find(&owners).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get nfts by owner: %w", err)
+	}
+
+	return owners, nil
+}
+
+// CreateTransfer saves a GRC721 transfer record to the nft_transfers table
+func (r *postgresNFTRepository) CreateTransfer(ctx context.Context, transfer *domain.NFTTransfer) error {
+	// Check if transfer already exists
+	var count int64
+	err := r.db.WithContext(ctx).Model(&domain.NFTTransfer{}).
+		Where("tx_hash = ? AND event_index = ?", transfer.TxHash, transfer.EventIndex).
+		Count(&count).Error
+
+	if err != nil {
+		return fmt.Errorf("failed to check nft transfer existence: %w", err)
+	}
+
+	if count > 0 {
+		return nil
+	}
+
+	return r.db.WithContext(ctx).Create(transfer).Error
+}
+
+// GetTransfersByToken retrieves the provenance of a token, oldest first
+func (r *postgresNFTRepository) GetTransfersByToken(ctx context.Context, collectionPath, tokenID string) ([]domain.NFTTransfer, error) {
+	var transfers []domain.NFTTransfer
+	err := r.db.WithContext(ctx).
+		Where("collection_path = ? AND token_id = ?", collectionPath, tokenID).
+		Order("block_height ASC, event_index ASC").
+		Find(&transfers).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get nft transfers by token: %w", err)
+	}
+
+	return transfers, nil
+}
+
+// GetTransfersByCollection retrieves the latest transfers of a collection
+func (r *postgresNFTRepository) GetTransfersByCollection(ctx context.Context, collectionPath string, limit int) ([]domain.NFTTransfer, error) {
+	var transfers []domain.NFTTransfer
+	err := r.db.WithContext(ctx).
+		Where("collection_path = ?", collectionPath).
+		Order("block_height DESC, event_index DESC").
+		Limit(limit).
+		Find(&transfers).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get nft transfers by collection: %w", err)
+	}
+
+	return transfers, nil
+}
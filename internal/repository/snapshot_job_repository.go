@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gn-indexer/internal/domain"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrSnapshotJobNotFound is returned when a snapshot job is not found
+var ErrSnapshotJobNotFound = errors.New("snapshot job not found")
+
+// SnapshotJobRepository handles snapshot job persistence
+type SnapshotJobRepository interface {
+	Create(ctx context.Context, job *domain.SnapshotJob) error
+	Update(ctx context.Context, job *domain.SnapshotJob) error
+	// GetOwned retrieves a job by id if the API key submitted it
+	GetOwned(ctx context.Context, apiKeyID *int64, id int64) (*domain.SnapshotJob, error)
+	// ClaimPending marks the oldest pending job running and returns it, ErrSnapshotJobNotFound when none is pending
+	ClaimPending(ctx context.Context) (*domain.SnapshotJob, error)
+	// RequeueRunning returns the jobs left running by a stopped process to pending
+	RequeueRunning(ctx context.Context) (int64, error)
+	CountPending(ctx context.Context) (int64, error)
+	// ListFinishedBefore retrieves the done jobs finished before a time, whose files are due for deletion
+	ListFinishedBefore(ctx context.Context, before time.Time) ([]*domain.SnapshotJob, error)
+}
+
+type postgresSnapshotJobRepository struct {
+	db *gorm.DB
+}
+
+// NewSnapshotJobRepository creates a new PostgreSQL snapshot job repository
+func NewSnapshotJobRepository(db *gorm.DB) SnapshotJobRepository {
+	return &postgresSnapshotJobRepository{db: db}
+}
+
+// Create inserts a new snapshot job
+func (r *postgresSnapshotJobRepository) Create(ctx context.Context, job *domain.SnapshotJob) error {
+	if err := r.db.WithContext(ctx).Create(job).Error; err != nil {
+		return fmt.Errorf("failed to create snapshot job: %w", err)
+	}
+	return nil
+}
+
+// Update saves the status and result of a snapshot job
+func (r *postgresSnapshotJobRepository) Update(ctx context.Context, job *domain.SnapshotJob) error {
+	if err := r.db.WithContext(ctx).Save(job).Error; err != nil {
+		return fmt.Errorf("failed to update snapshot job: %w", err)
+	}
+	return nil
+}
+
+// GetOwned retrieves a snapshot job by id if the API key submitted it
+func (r *postgresSnapshotJobRepository) GetOwned(ctx context.Context, apiKeyID *int64, id int64) (*domain.SnapshotJob, error) {
+	var job domain.SnapshotJob
+	err := ownedBy(r.db.WithContext(ctx), apiKeyID).Where("id = ?", id).First(&job).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSnapshotJobNotFound
+		}
+		return nil, fmt.Errorf("failed to get snapshot job: %w", err)
+	}
+	return &job, nil
+}
+
+// ClaimPending marks the oldest pending job running and returns it
+func (r *postgresSnapshotJobRepository) ClaimPending(ctx context.Context) (*domain.SnapshotJob, error) {
+	// Workers skip the row another worker is claiming instead of waiting, SQLite has a single writer
+	lock := " FOR UPDATE SKIP LOCKED"
+	if isSQLite(r.db) {
+		lock = ""
+	}
+
+	var jobs []*domain.SnapshotJob
+	err := r.db.WithContext(ctx).Raw(`UPDATE snapshot_jobs SET status = ?
+		WHERE id IN (
+			SELECT id FROM snapshot_jobs
+			WHERE status = ?
+			ORDER BY id ASC
+			LIMIT 1`+lock+`
+		)
+		RETURNING *`,
+		domain.SnapshotStatusRunning, domain.SnapshotStatusPending,
+	).Scan(&jobs).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim snapshot job: %w", err)
+	}
+	if len(jobs) == 0 {
+		return nil, ErrSnapshotJobNotFound
+	}
+	return jobs[0], nil
+}
+
+// RequeueRunning returns running jobs to pending
+func (r *postgresSnapshotJobRepository) RequeueRunning(ctx context.Context) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Model(&domain.SnapshotJob{}).
+		Where("status = ?", domain.SnapshotStatusRunning).
+		Update("status", domain.SnapshotStatusPending)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to requeue snapshot jobs: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// CountPending counts the jobs waiting for a worker
+func (r *postgresSnapshotJobRepository) CountPending(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&domain.SnapshotJob{}).
+		Where("status = ?", domain.SnapshotStatusPending).
+		Count(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to count pending snapshot jobs: %w", err)
+	}
+	return count, nil
+}
+
+// ListFinishedBefore retrieves the done jobs finished before a time
+func (r *postgresSnapshotJobRepository) ListFinishedBefore(ctx context.Context, before time.Time) ([]*domain.SnapshotJob, error) {
+	var jobs []*domain.SnapshotJob
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND finished_at < ?", domain.SnapshotStatusDone, before).
+		Order("id ASC").
+		Find(&jobs).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list finished snapshot jobs: %w", err)
+	}
+	return jobs, nil
+}
@@ -12,6 +12,9 @@ type TokenRepository interface {
 	GetByPath(ctx context.Context, tokenPath string) (*domain.Token, error)
 	RegisterIfNotExists(ctx context.Context, tokenPath string) error
 	GetAll(ctx context.Context) ([]domain.Token, error)
+	GetPendingMetadata(ctx context.Context) ([]domain.Token, error)
+	UpdateMetadata(ctx context.Context, token *domain.Token) error
+	GetByPaths(ctx context.Context, tokenPaths []string) ([]domain.Token, error)
 }
 
 type postgresTokenRepository struct {
@@ -25,7 +28,7 @@ func NewTokenRepository(db *gorm.DB) TokenRepository {
 
 // Create saves a token to the tokens table
 func (r *postgresTokenRepository) Create(ctx context.Context, token *domain.Token) error {
-	return r.db.WithContext(ctx).Table("indexer.tokens").Create(token).Error
+	return r.db.WithContext(ctx).Table("tokens").Create(token).Error
 }
 
 // GetByPath retrieves a token by its path
@@ -58,11 +61,11 @@ func (r *postgresTokenRepository) RegisterIfNotExists(ctx context.Context, token
 		return nil
 	}
 
-	// Create new token with default values
+	// Create new token with default values, metadata is filled by TokenMetadataService
 	token := &domain.Token{
 		Path:     tokenPath,
-		Symbol:   "", // Todo: updatable
-		Decimals: 0,  // Todo: updatable
+		Symbol:   "",
+		Decimals: 0,
 	}
 
 	return r.Create(ctx, token)
@@ -74,3 +77,35 @@ func (r *postgresTokenRepository) GetAll(ctx context.Context) ([]domain.Token, e
 	err := r.db.WithContext(ctx).Find(&tokens).Error
 	return tokens, err
 }
+
+// GetPendingMetadata retrieves tokens whose metadata has never been resolved
+func (r *postgresTokenRepository) GetPendingMetadata(ctx context.Context) ([]domain.Token, error) {
+	var tokens []domain.Token
+	err := r.db.WithContext(ctx).
+		Where("metadata_updated_at IS NULL").
+		Find(&tokens).Error
+	return tokens, err
+}
+
+// UpdateMetadata updates symbol, name, decimals and total supply of a token
+func (r *postgresTokenRepository) UpdateMetadata(ctx context.Context, token *domain.Token) error {
+	return r.db.WithContext(ctx).Model(&domain.Token{}).
+		Where("token_path = ?", token.Path).
+		Updates(map[string]interface{}{
+			"symbol":              token.Symbol,
+			"name":                token.Name,
+			"decimals":            token.Decimals,
+			"total_supply":        token.TotalSupply,
+			"metadata_updated_at": token.MetadataUpdatedAt,
+		}).Error
+}
+
+// GetByPaths retrieves the tokens with the given paths in one query
+func (r *postgresTokenRepository) GetByPaths(ctx context.Context, tokenPaths []string) ([]domain.Token, error) {
+	var tokens []domain.Token
+	if len(tokenPaths) == 0 {
+		return tokens, nil
+	}
+	err := r.db.WithContext(ctx).Where("token_path IN ?", tokenPaths).Find(&tokens).Error
+	return tokens, err
+}
@@ -2,17 +2,78 @@ package repository
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"gn-indexer/internal/domain"
 	"gorm.io/gorm"
+	"strconv"
+	"strings"
 )
 
+// ErrTransactionNotFound is returned when a transaction is not found
+var ErrTransactionNotFound = errors.New("transaction not found")
+
 // TransactionRepository handles transaction data persistence
 type TransactionRepository interface {
 	SaveTransaction(ctx context.Context, tx domain.Transaction) error
 	GetTransactionByHash(ctx context.Context, hash string) (*domain.Transaction, error)
 	GetTransactionsByBlockHeight(ctx context.Context, blockHeight int) ([]domain.Transaction, error)
+	GetTransactionsByHashes(ctx context.Context, hashes []string) ([]domain.Transaction, error)
+	GetTransactionsByBlockHeights(ctx context.Context, heights []int64) ([]domain.Transaction, error)
+	List(ctx context.Context, filter TransactionFilter) ([]domain.Transaction, error)
+}
+
+// TransactionFilter selects transactions for List, newest first
+type TransactionFilter struct {
+	BlockHeight int64 // 0 means all blocks
+	Success     *bool
+	Cursor      *TransactionCursor // position of the last transaction of the previous page
+	Limit       int
+}
+
+// TransactionCursor is the keyset position of a transaction in (block_height, tx_index) order
+type TransactionCursor struct {
+	BlockHeight int64
+	Index       int
+}
+
+// NewTransactionCursor returns the cursor pointing at a transaction
+func NewTransactionCursor(tx *domain.Transaction) *TransactionCursor {
+	return &TransactionCursor{
+		BlockHeight: int64(tx.BlockHeight),
+		Index:       tx.Index,
+	}
+}
+
+// Encode returns the opaque string form of the cursor
+func (c *TransactionCursor) Encode() string {
+	raw := fmt.Sprintf("%d:%d", c.BlockHeight, c.Index)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeTransactionCursor parses a cursor produced by Encode
+func DecodeTransactionCursor(encoded string) (*TransactionCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor encoding: %w", err)
+	}
+
+	parts := strings.Split(string(raw), ":")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid cursor format")
+	}
+
+	var cursor TransactionCursor
+	if cursor.BlockHeight, err = strconv.ParseInt(parts[0], 10, 64); err != nil {
+		return nil, fmt.Errorf("invalid cursor block height: %w", err)
+	}
+	if cursor.Index, err = strconv.Atoi(parts[1]); err != nil {
+		return nil, fmt.Errorf("invalid cursor tx index: %w", err)
+	}
+
+	return &cursor, nil
 }
 
 type postgresTransactionRepository struct {
@@ -67,19 +128,57 @@ func (r *postgresTransactionRepository) SaveTransaction(ctx context.Context, tx
 		"response_json": responseJSON,
 	}
 
-	return r.db.WithContext(ctx).Table("indexer.transactions").Create(txRecord).Error
+	return r.db.WithContext(ctx).Table("transactions").Create(txRecord).Error
+}
+
+// transactionJSON holds the JSON columns that are not mapped on domain.Transaction
+type transactionJSON struct {
+	GasFee       []byte `gorm:"column:gas_fee"`
+	MessagesJSON []byte `gorm:"column:messages_json"`
+	ResponseJSON []byte `gorm:"column:response_json"`
 }
 
-// GetTransactionByHash retrieves a transaction by its hash
+// GetTransactionByHash retrieves a transaction by its hash with its gas fee, messages and response decoded
 func (r *postgresTransactionRepository) GetTransactionByHash(ctx context.Context, hash string) (*domain.Transaction, error) {
 	var tx domain.Transaction
 	err := r.db.WithContext(ctx).Where("hash = ?", hash).First(&tx).Error
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTransactionNotFound
+		}
 		return nil, fmt.Errorf("failed to get transaction by hash: %w", err)
 	}
+
+	var raw transactionJSON
+	err = r.db.WithContext(ctx).Model(&domain.Transaction{}).
+		Select("gas_fee, messages_json, response_json").
+		Where("hash = ?", hash).
+		Scan(&raw).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction payload: %w", err)
+	}
+
+	if err := decodeJSONColumn(raw.GasFee, &tx.GasFee); err != nil {
+		return nil, fmt.Errorf("failed to decode gas fee: %w", err)
+	}
+	if err := decodeJSONColumn(raw.MessagesJSON, &tx.Messages); err != nil {
+		return nil, fmt.Errorf("failed to decode messages: %w", err)
+	}
+	if err := decodeJSONColumn(raw.ResponseJSON, &tx.Response); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
 	return &tx, nil
 }
 
+// decodeJSONColumn unmarshals a nullable JSON column, leaving target untouched when it is empty
+func decodeJSONColumn(data []byte, target interface{}) error {
+	if len(data) == 0 || string(data) == "null" {
+		return nil
+	}
+	return json.Unmarshal(data, target)
+}
+
 // GetTransactionsByBlockHeight retrieves all transactions for a specific block
 func (r *postgresTransactionRepository) GetTransactionsByBlockHeight(ctx context.Context, blockHeight int) ([]domain.Transaction, error) {
 	var transactions []domain.Transaction
@@ -89,3 +188,51 @@ func (r *postgresTransactionRepository) GetTransactionsByBlockHeight(ctx context
 	}
 	return transactions, nil
 }
+
+// GetTransactionsByHashes retrieves the transactions with the given hashes in one query
+func (r *postgresTransactionRepository) GetTransactionsByHashes(ctx context.Context, hashes []string) ([]domain.Transaction, error) {
+	var transactions []domain.Transaction
+	if len(hashes) == 0 {
+		return transactions, nil
+	}
+	err := r.db.WithContext(ctx).Where("hash IN ?", hashes).Find(&transactions).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transactions by hashes: %w", err)
+	}
+	return transactions, nil
+}
+
+// GetTransactionsByBlockHeights retrieves the transactions of several blocks in one query, ordered by block and index
+func (r *postgresTransactionRepository) GetTransactionsByBlockHeights(ctx context.Context, heights []int64) ([]domain.Transaction, error) {
+	var transactions []domain.Transaction
+	if len(heights) == 0 {
+		return transactions, nil
+	}
+	err := r.db.WithContext(ctx).Where("block_height IN ?", heights).Order("block_height, tx_index").Find(&transactions).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transactions by block heights: %w", err)
+	}
+	return transactions, nil
+}
+
+// List retrieves transactions matching the filter in (block_height, tx_index) descending order
+func (r *postgresTransactionRepository) List(ctx context.Context, filter TransactionFilter) ([]domain.Transaction, error) {
+	query := r.db.WithContext(ctx).Model(&domain.Transaction{})
+
+	if filter.BlockHeight > 0 {
+		query = query.Where("block_height = ?", filter.BlockHeight)
+	}
+	if filter.Success != nil {
+		query = query.Where("success = ?", *filter.Success)
+	}
+	if filter.Cursor != nil {
+		query = query.Where("(block_height, tx_index) < (?, ?)", filter.Cursor.BlockHeight, filter.Cursor.Index)
+	}
+
+	var transactions []domain.Transaction
+	err := query.Order("block_height DESC, tx_index DESC").Limit(filter.Limit).Find(&transactions).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list transactions: %w", err)
+	}
+	return transactions, nil
+}
@@ -2,8 +2,12 @@ package repository
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
 	"gn-indexer/internal/domain"
+	"strconv"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -15,6 +19,79 @@ type TransferRepository interface {
 	GetByAddress(ctx context.Context, address string) ([]domain.Transfer, error)
 	GetByTokenPath(ctx context.Context, tokenPath string) ([]domain.Transfer, error)
 	GetAll(ctx context.Context) ([]domain.Transfer, error)
+	List(ctx context.Context, filter TransferFilter) ([]domain.Transfer, error)
+	GetByTxHashes(ctx context.Context, txHashes []string) ([]domain.Transfer, error)
+}
+
+// Transfer directions relative to TransferFilter.Address
+const (
+	TransferDirectionIn  = "in"
+	TransferDirectionOut = "out"
+)
+
+// TransferFilter narrows down a transfer history query, zero values are ignored
+type TransferFilter struct {
+	Address      string // matches either side unless Direction is set
+	Direction    string // TransferDirectionIn or TransferDirectionOut, requires Address
+	Counterparty string // the other side of a transfer involving Address
+	TokenPath    string
+	FromHeight   int64
+	ToHeight     int64
+	FromTime     *time.Time // mapped to heights through blocks.time
+	ToTime       *time.Time
+	MinAmount    *domain.U64
+	MaxAmount    *domain.U64
+	Cursor       *TransferCursor // position of the last transfer of the previous page
+	Limit        int
+}
+
+// TransferCursor is the keyset position of a transfer in (block_height, event_index, id) order.
+// id breaks ties between transfers of different transactions in the same block.
+type TransferCursor struct {
+	BlockHeight int64
+	EventIndex  int
+	ID          int64
+}
+
+// NewTransferCursor returns the cursor pointing at a transfer
+func NewTransferCursor(transfer *domain.Transfer) *TransferCursor {
+	return &TransferCursor{
+		BlockHeight: transfer.BlockHeight,
+		EventIndex:  transfer.EventIndex,
+		ID:          transfer.ID,
+	}
+}
+
+// Encode returns the opaque string form of the cursor
+func (c *TransferCursor) Encode() string {
+	raw := fmt.Sprintf("%d:%d:%d", c.BlockHeight, c.EventIndex, c.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeTransferCursor parses a cursor produced by Encode
+func DecodeTransferCursor(encoded string) (*TransferCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor encoding: %w", err)
+	}
+
+	parts := strings.Split(string(raw), ":")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("invalid cursor format")
+	}
+
+	var cursor TransferCursor
+	if cursor.BlockHeight, err = strconv.ParseInt(parts[0], 10, 64); err != nil {
+		return nil, fmt.Errorf("invalid cursor block height: %w", err)
+	}
+	if cursor.EventIndex, err = strconv.Atoi(parts[1]); err != nil {
+		return nil, fmt.Errorf("invalid cursor event index: %w", err)
+	}
+	if cursor.ID, err = strconv.ParseInt(parts[2], 10, 64); err != nil {
+		return nil, fmt.Errorf("invalid cursor id: %w", err)
+	}
+
+	return &cursor, nil
 }
 
 type postgresTransferRepository struct {
@@ -104,3 +181,89 @@ func (r *postgresTransferRepository) GetAll(ctx context.Context) ([]domain.Trans
 
 	return transfers, nil
 }
+
+// List retrieves transfers matching the filter, newest first, using keyset pagination
+func (r *postgresTransferRepository) List(ctx context.Context, filter TransferFilter) ([]domain.Transfer, error) {
+	query := r.db.WithContext(ctx).Model(&domain.Transfer{})
+
+	// Address, direction and counterparty
+	switch {
+	case filter.Address != "" && filter.Direction == TransferDirectionIn:
+		query = query.Where("to_address = ?", filter.Address)
+		if filter.Counterparty != "" {
+			query = query.Where("from_address = ?", filter.Counterparty)
+		}
+	case filter.Address != "" && filter.Direction == TransferDirectionOut:
+		query = query.Where("from_address = ?", filter.Address)
+		if filter.Counterparty != "" {
+			query = query.Where("to_address = ?", filter.Counterparty)
+		}
+	case filter.Address != "" && filter.Counterparty != "":
+		query = query.Where("(from_address = ? AND to_address = ?) OR (from_address = ? AND to_address = ?)",
+			filter.Address, filter.Counterparty, filter.Counterparty, filter.Address)
+	case filter.Address != "":
+		query = query.Where("from_address = ? OR to_address = ?", filter.Address, filter.Address)
+	}
+
+	if filter.TokenPath != "" {
+		query = query.Where("token_path = ?", filter.TokenPath)
+	}
+
+	// Block range
+	if filter.FromHeight > 0 {
+		query = query.Where("block_height >= ?", filter.FromHeight)
+	}
+	if filter.ToHeight > 0 {
+		query = query.Where("block_height <= ?", filter.ToHeight)
+	}
+
+	// Time range, resolved to the first/last block inside the range
+	if filter.FromTime != nil {
+		query = query.Where("block_height >= (SELECT MIN(height) FROM blocks WHERE time >= ?)", *filter.FromTime)
+	}
+	if filter.ToTime != nil {
+		query = query.Where("block_height <= (SELECT MAX(height) FROM blocks WHERE time <= ?)", *filter.ToTime)
+	}
+
+	// Amount range
+	if filter.MinAmount != nil {
+		query = query.Where("amount >= ?", filter.MinAmount)
+	}
+	if filter.MaxAmount != nil {
+		query = query.Where("amount <= ?", filter.MaxAmount)
+	}
+
+	// Keyset pagination continues strictly after the cursor
+	if filter.Cursor != nil {
+		query = query.Where("(block_height, event_index, id) < (?, ?, ?)",
+			filter.Cursor.BlockHeight, filter.Cursor.EventIndex, filter.Cursor.ID)
+	}
+
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	var transfers []domain.Transfer
+	err := query.
+		Order("block_height DESC, event_index DESC, id DESC").
+		Find(&transfers).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list transfers: %w", err)
+	}
+
+	return transfers, nil
+}
+
+// GetByTxHashes retrieves the transfers of several transactions in one query, ordered by transaction and event index
+func (r *postgresTransferRepository) GetByTxHashes(ctx context.Context, txHashes []string) ([]domain.Transfer, error) {
+	var transfers []domain.Transfer
+	if len(txHashes) == 0 {
+		return transfers, nil
+	}
+	err := r.db.WithContext(ctx).Where("tx_hash IN ?", txHashes).Order("tx_hash, event_index").Find(&transfers).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transfers by tx hashes: %w", err)
+	}
+	return transfers, nil
+}
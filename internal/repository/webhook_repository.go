@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gn-indexer/internal/domain"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrWebhookNotFound is returned when a webhook is not found
+var ErrWebhookNotFound = errors.New("webhook not found")
+
+// WebhookRepository handles webhook registrations and their delivery log.
+// Management methods take the ID of the API key that registered the webhooks, nil for those registered without a key.
+type WebhookRepository interface {
+	Create(ctx context.Context, webhook *domain.Webhook) error
+	Delete(ctx context.Context, apiKeyID *int64, id int64) error
+	GetByID(ctx context.Context, id int64) (*domain.Webhook, error)
+	GetOwned(ctx context.Context, apiKeyID *int64, id int64) (*domain.Webhook, error)
+	List(ctx context.Context, apiKeyID *int64) ([]*domain.Webhook, error)
+	ListActive(ctx context.Context) ([]*domain.Webhook, error)
+	CreateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error
+	UpdateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error
+	ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*domain.WebhookDelivery, error)
+	GetDeliveries(ctx context.Context, webhookID int64, limit int) ([]*domain.WebhookDelivery, error)
+}
+
+type postgresWebhookRepository struct {
+	db *gorm.DB
+}
+
+// NewWebhookRepository creates a new PostgreSQL webhook repository
+func NewWebhookRepository(db *gorm.DB) WebhookRepository {
+	return &postgresWebhookRepository{db: db}
+}
+
+// Create registers a new webhook
+func (r *postgresWebhookRepository) Create(ctx context.Context, webhook *domain.Webhook) error {
+	if err := conn(ctx, r.db).Create(webhook).Error; err != nil {
+		return fmt.Errorf("failed to create webhook: %w", err)
+	}
+	return nil
+}
+
+// Delete removes a webhook of an API key together with its delivery log
+func (r *postgresWebhookRepository) Delete(ctx context.Context, apiKeyID *int64, id int64) error {
+	result := ownedBy(conn(ctx, r.db), apiKeyID).Where("id = ?", id).Delete(&domain.Webhook{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete webhook: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrWebhookNotFound
+	}
+	return nil
+}
+
+// GetByID retrieves a webhook by id whoever registered it, for the delivery loop
+func (r *postgresWebhookRepository) GetByID(ctx context.Context, id int64) (*domain.Webhook, error) {
+	return r.get(conn(ctx, r.db), id)
+}
+
+// GetOwned retrieves a webhook by id if the API key registered it
+func (r *postgresWebhookRepository) GetOwned(ctx context.Context, apiKeyID *int64, id int64) (*domain.Webhook, error) {
+	return r.get(ownedBy(conn(ctx, r.db), apiKeyID), id)
+}
+
+// get retrieves a webhook by id within a scope
+func (r *postgresWebhookRepository) get(scope *gorm.DB, id int64) (*domain.Webhook, error) {
+	var webhook domain.Webhook
+	err := scope.Where("id = ?", id).First(&webhook).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWebhookNotFound
+		}
+		return nil, fmt.Errorf("failed to get webhook: %w", err)
+	}
+	return &webhook, nil
+}
+
+// List retrieves the webhooks of an API key
+func (r *postgresWebhookRepository) List(ctx context.Context, apiKeyID *int64) ([]*domain.Webhook, error) {
+	var webhooks []*domain.Webhook
+	if err := ownedBy(conn(ctx, r.db), apiKeyID).Order("id ASC").Find(&webhooks).Error; err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	return webhooks, nil
+}
+
+// ownedBy scopes a query to the rows, such as webhooks or snapshot jobs, created by an API key, or without one when apiKeyID is nil
+func ownedBy(db *gorm.DB, apiKeyID *int64) *gorm.DB {
+	if apiKeyID == nil {
+		return db.Where("api_key_id IS NULL")
+	}
+	return db.Where("api_key_id = ?", *apiKeyID)
+}
+
+// ListActive retrieves the webhooks that should receive notifications
+func (r *postgresWebhookRepository) ListActive(ctx context.Context) ([]*domain.Webhook, error) {
+	var webhooks []*domain.Webhook
+	if err := conn(ctx, r.db).Where("active = ?", true).Find(&webhooks).Error; err != nil {
+		return nil, fmt.Errorf("failed to list active webhooks: %w", err)
+	}
+	return webhooks, nil
+}
+
+// CreateDelivery queues a payload for a webhook
+func (r *postgresWebhookRepository) CreateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	if err := conn(ctx, r.db).Create(delivery).Error; err != nil {
+		return fmt.Errorf("failed to create webhook delivery: %w", err)
+	}
+	return nil
+}
+
+// UpdateDelivery saves the outcome of a delivery attempt
+func (r *postgresWebhookRepository) UpdateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	if err := conn(ctx, r.db).Save(delivery).Error; err != nil {
+		return fmt.Errorf("failed to update webhook delivery: %w", err)
+	}
+	return nil
+}
+
+// ClaimDueDeliveries claims pending deliveries whose next attempt is due, oldest first.
+// Claiming moves their next attempt past the lease in the same statement, so concurrent workers never
+// send a delivery twice and a delivery claimed by a worker that stopped is retried once the lease ends.
+func (r *postgresWebhookRepository) ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*domain.WebhookDelivery, error) {
+	// Workers skip the rows another worker is claiming instead of waiting, SQLite has a single writer
+	lock := " FOR UPDATE SKIP LOCKED"
+	if isSQLite(r.db) {
+		lock = ""
+	}
+
+	var deliveries []*domain.WebhookDelivery
+	err := conn(ctx, r.db).Raw(`UPDATE webhook_deliveries SET next_attempt_at = ?
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = ? AND next_attempt_at <= ?
+			ORDER BY next_attempt_at ASC, id ASC
+			LIMIT ?`+lock+`
+		)
+		RETURNING *`,
+		now.Add(lease), domain.DeliveryStatusPending, now, limit,
+	).Scan(&deliveries).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim due webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// GetDeliveries retrieves the most recent deliveries of a webhook
+func (r *postgresWebhookRepository) GetDeliveries(ctx context.Context, webhookID int64, limit int) ([]*domain.WebhookDelivery, error) {
+	var deliveries []*domain.WebhookDelivery
+	err := conn(ctx, r.db).
+		Where("webhook_id = ?", webhookID).
+		Order("id DESC").
+		Limit(limit).
+		Find(&deliveries).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"gn-indexer/internal/domain"
+	"gn-indexer/internal/logging"
+	"gn-indexer/internal/repository"
+	"log/slog"
+	"strings"
+)
+
+// AddressNormalizationResult summarizes a normalization pass
+type AddressNormalizationResult struct {
+	Renamed   int      // addresses rewritten to lowercase
+	Malformed []string // stored values that are not valid addresses, left untouched
+}
+
+// AddressNormalizationService rewrites addresses stored in uppercase to their canonical lowercase form
+type AddressNormalizationService struct {
+	addressRepo repository.AddressRepository
+	logger      *slog.Logger
+}
+
+// NewAddressNormalizationService creates a new address normalization service
+func NewAddressNormalizationService(addressRepo repository.AddressRepository) *AddressNormalizationService {
+	return &AddressNormalizationService{addressRepo: addressRepo, logger: logging.For("address-normalization")}
+}
+
+// NormalizeAddresses renames every valid non-canonical address, merging rows that collide with the lowercase form
+func (ans *AddressNormalizationService) NormalizeAddresses(ctx context.Context) (*AddressNormalizationResult, error) {
+	addresses, err := ans.addressRepo.ListNonCanonical(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list non-canonical addresses: %w", err)
+	}
+	ans.logger.InfoContext(ctx, "found non-canonical addresses", "count", len(addresses))
+
+	result := &AddressNormalizationResult{}
+	for _, stored := range addresses {
+		// Lowercasing first also repairs mixed case, which bech32 itself rejects
+		addr, err := domain.ParseAddress(strings.ToLower(stored))
+		if err != nil {
+			ans.logger.WarnContext(ctx, "skipping malformed address", "address", stored, "error", err)
+			result.Malformed = append(result.Malformed, stored)
+			continue
+		}
+
+		if err := ans.addressRepo.Rename(ctx, stored, addr.String()); err != nil {
+			return result, fmt.Errorf("rename %s: %w", stored, err)
+		}
+		result.Renamed++
+	}
+
+	ans.logger.InfoContext(ctx, "addresses normalized", "renamed", result.Renamed, "malformed", len(result.Malformed))
+	return result, nil
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gn-indexer/internal/domain"
+	"gn-indexer/internal/logging"
+	"gn-indexer/internal/repository"
+	"log/slog"
+	"time"
+)
+
+// AllowanceService handles allowance updates from Approval events
+type AllowanceService struct {
+	allowanceRepo repository.AllowanceRepository
+	logger        *slog.Logger
+}
+
+// NewAllowanceService creates a new allowance service
+func NewAllowanceService(allowanceRepo repository.AllowanceRepository) *AllowanceService {
+	return &AllowanceService{
+		allowanceRepo: allowanceRepo,
+		logger:        logging.For("allowance"),
+	}
+}
+
+// ProcessEvent applies an Approval event, which overwrites the current allowance.
+// Note: TransferFrom does not emit Approval, so spent allowance is not reflected here.
+func (as *AllowanceService) ProcessEvent(ctx context.Context, event *domain.ParsedEvent) error {
+	// Ignore approvals older than the one already stored (queue delivery is unordered)
+	current, err := as.allowanceRepo.GetAllowance(ctx, event.TokenPath, event.FromAddress, event.ToAddress)
+	if err != nil && !errors.Is(err, repository.ErrAllowanceNotFound) {
+		return fmt.Errorf("get current allowance: %w", err)
+	}
+	if current != nil && isStale(event, current.LastBlockH, current.LastEventIndex) {
+		as.logger.DebugContext(ctx, "stale approval, skipping",
+			logging.KeyTxHash, event.TxHash, logging.KeyBlockHeight, event.BlockHeight, logging.KeyEventIndex, event.EventIndex,
+			"stored_height", current.LastBlockH, "stored_event_index", current.LastEventIndex)
+		return nil
+	}
+
+	allowance := &domain.Allowance{
+		Owner:          event.FromAddress,
+		Spender:        event.ToAddress,
+		TokenPath:      event.TokenPath,
+		Amount:         domain.NewU64(event.Amount),
+		LastTxHash:     event.TxHash,
+		LastBlockH:     event.BlockHeight,
+		LastEventIndex: event.EventIndex,
+		UpdatedAt:      time.Now(),
+	}
+
+	if err := as.allowanceRepo.Save(ctx, allowance); err != nil {
+		return fmt.Errorf("save allowance: %w", err)
+	}
+
+	as.logger.DebugContext(ctx, "allowance set", "token_path", event.TokenPath, "owner", event.FromAddress, "spender", event.ToAddress, "amount", event.Amount)
+	return nil
+}
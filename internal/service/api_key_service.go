@@ -0,0 +1,355 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"gn-indexer/internal/domain"
+	"gn-indexer/internal/logging"
+	"gn-indexer/internal/repository"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const (
+	// apiKeyPrefix marks balance API keys so they are easy to spot in logs and secret scanners
+	apiKeyPrefix = "gnk_"
+	// apiKeyCacheTTL is how long an authenticated key is reused, revocations take effect within it
+	apiKeyCacheTTL = 30 * time.Second
+	// apiKeyFlushInterval is how often usage counters are written to the database
+	apiKeyFlushInterval = 10 * time.Second
+
+	// Limits of keys issued without explicit values
+	DefaultAPIKeyRateLimit = 10.0
+	DefaultAPIKeyBurst     = 20
+)
+
+var (
+	// ErrInvalidAPIKey is returned for unknown or revoked keys
+	ErrInvalidAPIKey = errors.New("invalid api key")
+	// ErrQuotaExceeded is returned once a key used up its daily quota
+	ErrQuotaExceeded = errors.New("daily quota exceeded")
+	// ErrInvalidAPIKeyRequest is returned when a key cannot be issued as requested
+	ErrInvalidAPIKeyRequest = errors.New("invalid api key request")
+)
+
+// APIKeyRequest describes a key to issue, zero limits take the defaults
+type APIKeyRequest struct {
+	Name       string
+	RateLimit  float64
+	Burst      int
+	DailyQuota int64
+}
+
+// cachedAPIKey is an authenticated key reused until expiresAt
+type cachedAPIKey struct {
+	key       *domain.APIKey
+	expiresAt time.Time
+}
+
+// usageKey identifies the counters of a key on one UTC day
+type usageKey struct {
+	keyID int64
+	day   string
+}
+
+// apiKeyUsage holds the persisted and not yet flushed counts of a key and day
+type apiKeyUsage struct {
+	day             time.Time
+	persisted       int64 // requests already in the database when the counter was loaded or flushed
+	pending         int64
+	pendingRejected int64
+}
+
+// APIKeyService issues and authenticates API keys and accounts their usage against daily quotas.
+// Usage is counted in memory and flushed periodically, so with several API instances the quota is approximate.
+type APIKeyService struct {
+	apiKeyRepo repository.APIKeyRepository
+
+	mu    sync.Mutex
+	keys  map[string]cachedAPIKey // by key hash
+	usage map[usageKey]*apiKeyUsage
+
+	logger *slog.Logger
+}
+
+// NewAPIKeyService creates a new API key service
+func NewAPIKeyService(apiKeyRepo repository.APIKeyRepository) *APIKeyService {
+	return &APIKeyService{
+		apiKeyRepo: apiKeyRepo,
+		keys:       make(map[string]cachedAPIKey),
+		usage:      make(map[usageKey]*apiKeyUsage),
+		logger:     logging.For("api-keys"),
+	}
+}
+
+// HashAPIKey returns the stored form of a key
+func HashAPIKey(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// Issue creates a key and returns its secret, which is not stored and cannot be shown again
+func (s *APIKeyService) Issue(ctx context.Context, req APIKeyRequest) (string, *domain.APIKey, error) {
+	if req.Name == "" {
+		return "", nil, fmt.Errorf("%w: name is required", ErrInvalidAPIKeyRequest)
+	}
+	if req.RateLimit < 0 || req.Burst < 0 || req.DailyQuota < 0 {
+		return "", nil, fmt.Errorf("%w: limits must not be negative", ErrInvalidAPIKeyRequest)
+	}
+
+	raw := make([]byte, 24)
+	if _, err := rand.Read(raw); err != nil {
+		return "", nil, fmt.Errorf("generate api key: %w", err)
+	}
+	secret := apiKeyPrefix + hex.EncodeToString(raw)
+
+	key := &domain.APIKey{
+		Name:       req.Name,
+		KeyPrefix:  secret[:len(apiKeyPrefix)+8],
+		KeyHash:    HashAPIKey(secret),
+		RateLimit:  req.RateLimit,
+		Burst:      req.Burst,
+		DailyQuota: req.DailyQuota,
+	}
+	if key.RateLimit == 0 {
+		key.RateLimit = DefaultAPIKeyRateLimit
+	}
+	if key.Burst == 0 {
+		key.Burst = DefaultAPIKeyBurst
+	}
+
+	if err := s.apiKeyRepo.Create(ctx, key); err != nil {
+		return "", nil, err
+	}
+	s.logger.InfoContext(ctx, "issued key", "key_id", key.ID, "prefix", key.KeyPrefix, "name", key.Name)
+	return secret, key, nil
+}
+
+// Revoke disables a key, other API instances stop accepting it within apiKeyCacheTTL
+func (s *APIKeyService) Revoke(ctx context.Context, id int64) error {
+	if err := s.apiKeyRepo.Revoke(ctx, id, time.Now()); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	for hash, cached := range s.keys {
+		if cached.key.ID == id {
+			delete(s.keys, hash)
+		}
+	}
+	s.mu.Unlock()
+
+	s.logger.InfoContext(ctx, "revoked key", "key_id", id)
+	return nil
+}
+
+// Get returns a key by id
+func (s *APIKeyService) Get(ctx context.Context, id int64) (*domain.APIKey, error) {
+	return s.apiKeyRepo.GetByID(ctx, id)
+}
+
+// List returns every key
+func (s *APIKeyService) List(ctx context.Context) ([]*domain.APIKey, error) {
+	return s.apiKeyRepo.List(ctx)
+}
+
+// GetUsage returns the daily usage of a key over the last days, counts not flushed yet included
+func (s *APIKeyService) GetUsage(ctx context.Context, id int64, days int) ([]*domain.APIKeyUsage, error) {
+	fromDay := utcDay(time.Now()).AddDate(0, 0, -(days - 1))
+	usage, err := s.apiKeyRepo.GetUsage(ctx, id, fromDay)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for k, counter := range s.usage {
+		if k.keyID != id || counter.day.Before(fromDay) || (counter.pending == 0 && counter.pendingRejected == 0) {
+			continue
+		}
+		row := findUsageDay(usage, counter.day)
+		if row == nil {
+			row = &domain.APIKeyUsage{APIKeyID: id, Day: counter.day}
+			usage = append([]*domain.APIKeyUsage{row}, usage...)
+		}
+		row.Requests += counter.pending
+		row.Rejected += counter.pendingRejected
+	}
+	return usage, nil
+}
+
+// findUsageDay returns the usage row of a day
+func findUsageDay(usage []*domain.APIKeyUsage, day time.Time) *domain.APIKeyUsage {
+	for _, row := range usage {
+		if row.Day.Format("2006-01-02") == day.Format("2006-01-02") {
+			return row
+		}
+	}
+	return nil
+}
+
+// Authenticate returns the active key matching a secret
+func (s *APIKeyService) Authenticate(ctx context.Context, secret string) (*domain.APIKey, error) {
+	if secret == "" {
+		return nil, ErrInvalidAPIKey
+	}
+	hash := HashAPIKey(secret)
+
+	s.mu.Lock()
+	cached, ok := s.keys[hash]
+	s.mu.Unlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached.key, nil
+	}
+
+	key, err := s.apiKeyRepo.GetByHash(ctx, hash)
+	if err != nil {
+		if errors.Is(err, repository.ErrAPIKeyNotFound) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+	if key.Revoked() {
+		return nil, ErrInvalidAPIKey
+	}
+
+	s.mu.Lock()
+	s.keys[hash] = cachedAPIKey{key: key, expiresAt: time.Now().Add(apiKeyCacheTTL)}
+	s.mu.Unlock()
+	return key, nil
+}
+
+// ConsumeQuota counts a request against the daily quota and returns the requests left, -1 when unlimited
+func (s *APIKeyService) ConsumeQuota(ctx context.Context, key *domain.APIKey) (int64, error) {
+	counter, err := s.counter(ctx, key.ID, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if key.DailyQuota > 0 && counter.persisted+counter.pending >= key.DailyQuota {
+		counter.pendingRejected++
+		return 0, ErrQuotaExceeded
+	}
+	counter.pending++
+	if key.DailyQuota == 0 {
+		return -1, nil
+	}
+	return key.DailyQuota - counter.persisted - counter.pending, nil
+}
+
+// RecordRejected counts a request refused before reaching the quota, e.g. by the rate limit
+func (s *APIKeyService) RecordRejected(ctx context.Context, key *domain.APIKey) {
+	counter, err := s.counter(ctx, key.ID, time.Now())
+	if err != nil {
+		s.logger.WarnContext(ctx, "count rejected request failed", "key_id", key.ID, "error", err)
+		return
+	}
+	s.mu.Lock()
+	counter.pendingRejected++
+	s.mu.Unlock()
+}
+
+// counter returns the usage counter of a key for the day of now, loading the persisted count on first use
+func (s *APIKeyService) counter(ctx context.Context, keyID int64, now time.Time) (*apiKeyUsage, error) {
+	day := utcDay(now)
+	k := usageKey{keyID: keyID, day: day.Format("2006-01-02")}
+
+	s.mu.Lock()
+	counter, ok := s.usage[k]
+	s.mu.Unlock()
+	if ok {
+		return counter, nil
+	}
+
+	usage, err := s.apiKeyRepo.GetUsage(ctx, keyID, day)
+	if err != nil {
+		return nil, fmt.Errorf("load usage: %w", err)
+	}
+	loaded := &apiKeyUsage{day: day}
+	if row := findUsageDay(usage, day); row != nil {
+		loaded.persisted = row.Requests
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// Another request may have loaded it meanwhile
+	if counter, ok := s.usage[k]; ok {
+		return counter, nil
+	}
+	s.usage[k] = loaded
+	return loaded, nil
+}
+
+// Flush writes pending usage counts and forgets the counters of past days
+func (s *APIKeyService) Flush(ctx context.Context) error {
+	type pendingUsage struct {
+		key                usageKey
+		day                time.Time
+		requests, rejected int64
+	}
+
+	s.mu.Lock()
+	var batch []pendingUsage
+	today := utcDay(time.Now())
+	for k, counter := range s.usage {
+		if counter.pending > 0 || counter.pendingRejected > 0 {
+			batch = append(batch, pendingUsage{key: k, day: counter.day, requests: counter.pending, rejected: counter.pendingRejected})
+			counter.persisted += counter.pending
+			counter.pending, counter.pendingRejected = 0, 0
+		} else if counter.day.Before(today) {
+			delete(s.usage, k)
+		}
+	}
+	s.mu.Unlock()
+
+	for i, usage := range batch {
+		if err := s.apiKeyRepo.AddUsage(ctx, usage.key.keyID, usage.day, usage.requests, usage.rejected); err != nil {
+			// Put the unwritten counts back so the next flush retries them
+			s.mu.Lock()
+			for _, failed := range batch[i:] {
+				if counter, ok := s.usage[failed.key]; ok {
+					counter.persisted -= failed.requests
+					counter.pending += failed.requests
+					counter.pendingRejected += failed.rejected
+				}
+			}
+			s.mu.Unlock()
+			return fmt.Errorf("flush usage of key %d: %w", usage.key.keyID, err)
+		}
+	}
+	return nil
+}
+
+// Start flushes usage counters until the context is cancelled, then flushes once more
+func (s *APIKeyService) Start(ctx context.Context) error {
+	s.logger.Info("starting usage flush loop")
+
+	ticker := time.NewTicker(apiKeyFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if err := s.Flush(context.Background()); err != nil {
+				s.logger.Error("final flush failed", "error", err)
+			}
+			return ctx.Err()
+		case <-ticker.C:
+			if err := s.Flush(ctx); err != nil {
+				s.logger.Error("flush failed", "error", err)
+			}
+		}
+	}
+}
+
+// utcDay truncates a time to the start of its UTC day
+func utcDay(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
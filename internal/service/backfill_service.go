@@ -4,22 +4,28 @@ import (
 	"context"
 	"fmt"
 	"gn-indexer/internal/client"
+	"gn-indexer/internal/config"
+	"gn-indexer/internal/logging"
 	"gn-indexer/internal/producer"
 	"gn-indexer/internal/types"
-	"log"
+	"log/slog"
 )
 
 // BackfillService handles backfilling of missing blockchain data
 type BackfillService struct {
-	syncer     *producer.Syncer
-	wsEndpoint string
+	syncer        *producer.Syncer
+	graphqlConfig *config.GraphQLConfig
+	chunkSize     int
+	logger        *slog.Logger
 }
 
 // NewBackfillService creates a new backfill service
-func NewBackfillService(syncer *producer.Syncer, wsEndpoint string) *BackfillService {
+func NewBackfillService(syncer *producer.Syncer, graphqlConfig *config.GraphQLConfig, chunkSize int) *BackfillService {
 	return &BackfillService{
-		syncer:     syncer,
-		wsEndpoint: wsEndpoint,
+		syncer:        syncer,
+		graphqlConfig: graphqlConfig,
+		chunkSize:     chunkSize,
+		logger:        logging.For("backfill"),
 	}
 }
 
@@ -31,10 +37,10 @@ func (bs *BackfillService) BackfillToLatest(ctx context.Context) error {
 		return fmt.Errorf("get last synced height: %w", err)
 	}
 
-	log.Printf("BackfillService: starting backfill from height %d", lastHeight)
+	bs.logger.Info("starting backfill", "last_height", lastHeight)
 
 	// Create a separate websocket client for backfill
-	backfillSubClient := client.NewSubscriptionClient(bs.wsEndpoint)
+	backfillSubClient := client.NewSubscriptionClient(bs.graphqlConfig.WSEndpoint, bs.graphqlConfig.ReconnectDelay)
 	defer backfillSubClient.Close()
 
 	// Get current block height from websocket (one-time subscription)
@@ -54,16 +60,16 @@ func (bs *BackfillService) BackfillToLatest(ctx context.Context) error {
 
 	// Check if backfill is needed
 	if lastHeight >= currentHeight {
-		log.Printf("BackfillService: no backfill needed (last=%d, current=%d)", lastHeight, currentHeight)
+		bs.logger.Info("no backfill needed", "last_height", lastHeight, "network_height", currentHeight)
 		return nil
 	}
 
 	// Sync missing blocks in smaller chunks to avoid GraphQL limits
 	lastHeightPlus1 := lastHeight + 1
-	log.Printf("BackfillService: backfilling from height %d to %d", lastHeightPlus1, currentHeight)
+	bs.logger.Info("backfilling", "from_height", lastHeightPlus1, "to_height", currentHeight)
 
-	// Use smaller chunks (e.g., 1000 blocks at a time)
-	const chunkSize = 1000
+	// Use smaller chunks (1000 blocks at a time by default)
+	chunkSize := bs.chunkSize
 
 	successCount := 0
 	totalChunks := 0
@@ -75,11 +81,10 @@ func (bs *BackfillService) BackfillToLatest(ctx context.Context) error {
 		}
 
 		totalChunks++
-		log.Printf("BackfillService: syncing chunk %d/%d (height %d to %d)",
-			totalChunks, (currentHeight-lastHeight)/chunkSize+1, from, to)
+		bs.logger.Debug("syncing chunk", "chunk", totalChunks, "chunks", (currentHeight-lastHeight)/chunkSize+1, "from_height", from, "to_height", to)
 
 		if err := bs.syncer.SyncRange(ctx, from, to); err != nil {
-			log.Printf("BackfillService: chunk %d-%d failed: %v", from, to, err)
+			bs.logger.Error("chunk failed", "from_height", from, "to_height", to, "error", err)
 			continue
 		}
 
@@ -87,8 +92,7 @@ func (bs *BackfillService) BackfillToLatest(ctx context.Context) error {
 	}
 
 	// Summary log
-	log.Printf("BackfillService: completed - %d/%d chunks successful, synced blocks %d to %d",
-		successCount, totalChunks, lastHeightPlus1, currentHeight)
+	bs.logger.Info("backfill completed", "succeeded_chunks", successCount, "chunks", totalChunks, "from_height", lastHeightPlus1, "to_height", currentHeight)
 
 	if successCount > 0 {
 		return nil
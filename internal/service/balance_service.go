@@ -4,90 +4,208 @@ import (
 	"context"
 	"fmt"
 	"gn-indexer/internal/domain"
+	"gn-indexer/internal/logging"
+	"gn-indexer/internal/metrics"
 	"gn-indexer/internal/repository"
-	"log"
+	"gn-indexer/internal/tracing"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
 )
 
+// Publisher delivers balance and transfer notifications to subscribers outside the event processor
+type Publisher interface {
+	Publish(ctx context.Context, notification *domain.Notification) error
+}
+
 // BalanceService handles balance calculation and updates
 type BalanceService struct {
+	transactor  repository.Transactor
 	balanceRepo repository.BalanceRepository
 	tokenRepo   repository.TokenRepository
+	historyRepo repository.BalanceHistoryRepository
+	outbox      Publisher
+	publishers  []Publisher
+	logger      *slog.Logger
 }
 
-// NewBalanceService creates a new balance service
+// NewBalanceService creates a new balance service, historyRepo may be nil to skip balance history.
+// The balances and history rows of an event are written in one transaction when transactor is not nil.
+// outbox, which may be nil, writes transfer notifications to the database in that same transaction and its
+// failure fails the event. Every publisher is notified of applied transfers and balance changes once they are committed.
 func NewBalanceService(
+	transactor repository.Transactor,
 	balanceRepo repository.BalanceRepository,
 	tokenRepo repository.TokenRepository,
+	historyRepo repository.BalanceHistoryRepository,
+	outbox Publisher,
+	publishers ...Publisher,
 ) *BalanceService {
 	return &BalanceService{
+		transactor:  transactor,
 		balanceRepo: balanceRepo,
 		tokenRepo:   tokenRepo,
+		historyRepo: historyRepo,
+		outbox:      outbox,
+		publishers:  publishers,
+		logger:      logging.For("balance"),
 	}
 }
 
 // ProcessEvent processes a parsed event and updates balances accordingly
 func (bs *BalanceService) ProcessEvent(ctx context.Context, event *domain.ParsedEvent) error {
-	log.Printf("BalanceService: processing event %s for token %s", event.Type, event.TokenPath)
-
-	switch event.Type {
-	case "MINT":
-		return bs.processMintEvent(ctx, event)
-	case "BURN":
-		return bs.processBurnEvent(ctx, event)
-	case "TRANSFER":
-		return bs.processTransferEvent(ctx, event)
+	ctx, span := tracing.Start(ctx, "BalanceService.ProcessEvent", attribute.String("event.kind", string(eventKind(event))))
+	start := time.Now()
+	var err error
+	defer func() { tracing.End(span, err) }()
+
+	var process func(ctx context.Context, event *domain.ParsedEvent) ([]*domain.Notification, error)
+	switch eventKind(event) {
+	case domain.EventTypeMint:
+		process = bs.processMintEvent
+	case domain.EventTypeBurn:
+		process = bs.processBurnEvent
+	case domain.EventTypeTransfer:
+		process = bs.processTransferEvent
 	default:
-		log.Printf("BalanceService: unknown event type %s, skipping", event.Type)
+		bs.logger.WarnContext(ctx, "unknown event type, skipping", "type", event.Type, logging.KeyTxHash, event.TxHash, logging.KeyEventIndex, event.EventIndex)
+		return nil
+	}
+
+	transfer := &domain.Notification{
+		Kind:        domain.NotificationTransfer,
+		TokenPath:   event.TokenPath,
+		FromAddress: event.FromAddress,
+		ToAddress:   event.ToAddress,
+		Amount:      strconv.FormatInt(event.Amount, 10),
+		EventType:   eventKind(event),
+		TxHash:      event.TxHash,
+		BlockHeight: event.BlockHeight,
+	}
+
+	var notifications []*domain.Notification
+	err = bs.inTransaction(ctx, func(ctx context.Context) error {
+		var processErr error
+		notifications, processErr = process(ctx, event)
+		if processErr != nil || notifications == nil || bs.outbox == nil {
+			return processErr
+		}
+		transfer.CreatedAt = time.Now()
+		if err := bs.outbox.Publish(ctx, transfer); err != nil {
+			return fmt.Errorf("queue transfer notification: %w", err)
+		}
+		return nil
+	})
+	metrics.ObserveBalanceUpdate(string(eventKind(event)), start, err)
+	if err != nil {
+		return err
+	}
+	if notifications == nil {
+		// Redelivered event, subscribers were told the first time
 		return nil
 	}
+
+	for _, notification := range notifications {
+		bs.publish(ctx, notification)
+	}
+	bs.publish(ctx, transfer)
+	return nil
 }
 
-// processMintEvent handles token mint events
-func (bs *BalanceService) processMintEvent(ctx context.Context, event *domain.ParsedEvent) error {
-	log.Printf("BalanceService: processing mint event for %s to %s", event.TokenPath, event.ToAddress)
+// inTransaction runs fn in a transaction, or directly without a transactor
+func (bs *BalanceService) inTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if bs.transactor == nil {
+		return fn(ctx)
+	}
+	return bs.transactor.Transaction(ctx, fn)
+}
+
+// publish sends a notification to every publisher, failures are logged and never fail event processing
+func (bs *BalanceService) publish(ctx context.Context, notification *domain.Notification) {
+	notification.CreatedAt = time.Now()
+	for _, publisher := range bs.publishers {
+		if err := publisher.Publish(ctx, notification); err != nil {
+			bs.logger.ErrorContext(ctx, "failed to publish notification", "kind", notification.Kind, logging.KeyTxHash, notification.TxHash, "error", err)
+		}
+	}
+}
 
+// eventKind returns the classified event type, falling back to Type when Func is not set
+func eventKind(event *domain.ParsedEvent) domain.EventType {
+	if event.Func != "" {
+		return event.Func
+	}
+	return domain.EventType(event.Type)
+}
+
+// processMintEvent handles token mint events
+func (bs *BalanceService) processMintEvent(ctx context.Context, event *domain.ParsedEvent) ([]*domain.Notification, error) {
 	// Mint: increase balance for 'to' address
-	if err := bs.updateBalance(ctx, event.TokenPath, event.ToAddress, event.Amount, true); err != nil {
-		return fmt.Errorf("update balance for mint: %w", err)
+	notification, err := bs.updateBalance(ctx, event, event.ToAddress, event.Amount)
+	if err != nil {
+		return nil, fmt.Errorf("update balance for mint: %w", err)
 	}
 
-	log.Printf("BalanceService: mint event processed successfully")
-	return nil
+	return collect(notification), nil
 }
 
 // processBurnEvent handles token burn events
-func (bs *BalanceService) processBurnEvent(ctx context.Context, event *domain.ParsedEvent) error {
-	log.Printf("BalanceService: processing burn event for %s from %s", event.TokenPath, event.FromAddress)
-
+func (bs *BalanceService) processBurnEvent(ctx context.Context, event *domain.ParsedEvent) ([]*domain.Notification, error) {
 	// Burn: decrease balance for 'from' address
-	if err := bs.updateBalance(ctx, event.TokenPath, event.FromAddress, event.Amount, false); err != nil {
-		return fmt.Errorf("update balance for burn: %w", err)
+	notification, err := bs.updateBalance(ctx, event, event.FromAddress, -event.Amount)
+	if err != nil {
+		return nil, fmt.Errorf("update balance for burn: %w", err)
 	}
 
-	log.Printf("BalanceService: burn event processed successfully")
-	return nil
+	return collect(notification), nil
 }
 
 // processTransferEvent handles token transfer events
-func (bs *BalanceService) processTransferEvent(ctx context.Context, event *domain.ParsedEvent) error {
-	log.Printf("BalanceService: processing transfer event for %s from %s to %s",
-		event.TokenPath, event.FromAddress, event.ToAddress)
+func (bs *BalanceService) processTransferEvent(ctx context.Context, event *domain.ParsedEvent) ([]*domain.Notification, error) {
+	// A transfer to the sender itself does not change any balance
+	if event.FromAddress == event.ToAddress {
+		return []*domain.Notification{}, nil
+	}
 
 	// Transfer: decrease balance for 'from' address and increase for 'to' address
-	if err := bs.updateBalance(ctx, event.TokenPath, event.FromAddress, event.Amount, false); err != nil {
-		return fmt.Errorf("update balance for transfer from: %w", err)
+	sent, err := bs.updateBalance(ctx, event, event.FromAddress, -event.Amount)
+	if err != nil {
+		return nil, fmt.Errorf("update balance for transfer from: %w", err)
 	}
 
-	if err := bs.updateBalance(ctx, event.TokenPath, event.ToAddress, event.Amount, true); err != nil {
-		return fmt.Errorf("update balance for transfer to: %w", err)
+	received, err := bs.updateBalance(ctx, event, event.ToAddress, event.Amount)
+	if err != nil {
+		return nil, fmt.Errorf("update balance for transfer to: %w", err)
 	}
 
-	log.Printf("BalanceService: transfer event processed successfully")
-	return nil
+	return collect(sent, received), nil
+}
+
+// collect returns the notifications of the balances an event changed, nil when none changed
+func collect(notifications ...*domain.Notification) []*domain.Notification {
+	var changed []*domain.Notification
+	for _, notification := range notifications {
+		if notification != nil {
+			changed = append(changed, notification)
+		}
+	}
+	return changed
 }
 
-// updateBalance updates the balance for a specific token and address
-func (bs *BalanceService) updateBalance(ctx context.Context, tokenPath, address string, amount int64, isIncrease bool) error {
+// updateBalance adds a signed delta to the balance of an address for the event token and records it in the balance history.
+// It returns the notification of the change, or nil when the history shows the event was already applied.
+func (bs *BalanceService) updateBalance(ctx context.Context, event *domain.ParsedEvent, address string, delta int64) (notification *domain.Notification, err error) {
+	ctx, span := tracing.Start(ctx, "BalanceService.updateBalance",
+		attribute.String("token.path", event.TokenPath),
+		attribute.String("address", address),
+		attribute.Int64("delta", delta),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	tokenPath := event.TokenPath
+
 	// Get current balance
 	currentBalance, err := bs.balanceRepo.GetBalance(ctx, tokenPath, address)
 	if err != nil {
@@ -99,36 +217,40 @@ func (bs *BalanceService) updateBalance(ctx context.Context, tokenPath, address
 				Amount:    domain.NewU64(0),
 			}
 		} else {
-			return fmt.Errorf("get current balance: %w", err)
+			return nil, fmt.Errorf("get current balance: %w", err)
 		}
 	}
 
 	// Calculate new balance
-	var newAmount int64
+	currentAmount := int64(0)
 	if currentBalance.Amount != nil {
-		if isIncrease {
-			newAmount = currentBalance.Amount.Int64() + amount
-		} else {
-			newAmount = currentBalance.Amount.Int64() - amount
-			// Ensure balance doesn't go negative
-			if newAmount < 0 {
-				log.Printf("BalanceService: warning - balance would go negative for %s %s, setting to 0", tokenPath, address)
-				newAmount = 0
-			}
-		}
-	} else {
-		if isIncrease {
-			newAmount = amount
-		} else {
-			newAmount = 0
-		}
+		currentAmount = currentBalance.Amount.Int64()
+	}
+	newAmount := currentAmount + delta
+	// Ensure balance doesn't go negative
+	if newAmount < 0 {
+		bs.logger.WarnContext(ctx, "balance would go negative, setting to 0",
+			"token_path", tokenPath, "address", address, logging.KeyTxHash, event.TxHash, logging.KeyEventIndex, event.EventIndex)
+		newAmount = 0
+	}
+
+	// The history records the change actually applied, so summing it gives the live balance even after a clamp
+	recorded, err := bs.recordHistory(ctx, event, address, newAmount-currentAmount)
+	if err != nil {
+		return nil, err
+	}
+	if !recorded {
+		bs.logger.DebugContext(ctx, "event already applied, skipping", "token_path", tokenPath, "address", address, logging.KeyTxHash, event.TxHash, logging.KeyEventIndex, event.EventIndex)
+		return nil, nil
 	}
 
 	// Update or create balance
 	balance := &domain.Balance{
-		TokenPath: tokenPath,
-		Address:   address,
-		Amount:    domain.NewU64(newAmount),
+		TokenPath:  tokenPath,
+		Address:    address,
+		Amount:     domain.NewU64(newAmount),
+		LastTxHash: event.TxHash,
+		LastBlockH: event.BlockHeight,
 	}
 
 	// Try to update first, if it fails (not found), create new
@@ -136,19 +258,46 @@ func (bs *BalanceService) updateBalance(ctx context.Context, tokenPath, address
 		if err == repository.ErrBalanceNotFound {
 			// Create new balance
 			if err := bs.balanceRepo.Create(ctx, balance); err != nil {
-				return fmt.Errorf("create balance: %w", err)
+				return nil, fmt.Errorf("create balance: %w", err)
 			}
-			log.Printf("BalanceService: created new balance for %s %s: %d", tokenPath, address, newAmount)
+			bs.logger.DebugContext(ctx, "created balance", "token_path", tokenPath, "address", address, "amount", newAmount)
 		} else {
-			return fmt.Errorf("update balance: %w", err)
+			return nil, fmt.Errorf("update balance: %w", err)
 		}
 	} else {
-		currentAmount := int64(0)
-		if currentBalance.Amount != nil {
-			currentAmount = currentBalance.Amount.Int64()
-		}
-		log.Printf("BalanceService: updated balance for %s %s: %d -> %d", tokenPath, address, currentAmount, newAmount)
+		bs.logger.DebugContext(ctx, "updated balance", "token_path", tokenPath, "address", address, "from", currentAmount, "to", newAmount)
 	}
 
-	return nil
+	return &domain.Notification{
+		Kind:        domain.NotificationBalance,
+		TokenPath:   tokenPath,
+		Address:     address,
+		Amount:      strconv.FormatInt(event.Amount, 10),
+		Balance:     balance.Amount.String(),
+		EventType:   eventKind(event),
+		TxHash:      event.TxHash,
+		BlockHeight: event.BlockHeight,
+	}, nil
+}
+
+// recordHistory appends the balance change to the balance history used by point-in-time queries.
+// It reports false when the event was already recorded, so a redelivered event is not applied twice.
+func (bs *BalanceService) recordHistory(ctx context.Context, event *domain.ParsedEvent, address string, delta int64) (bool, error) {
+	if bs.historyRepo == nil || event.TxHash == "" {
+		return true, nil
+	}
+
+	history := &domain.BalanceHistory{
+		Address:     address,
+		TokenPath:   event.TokenPath,
+		Delta:       delta,
+		TxHash:      event.TxHash,
+		EventIndex:  event.EventIndex,
+		BlockHeight: event.BlockHeight,
+	}
+	recorded, err := bs.historyRepo.Record(ctx, history)
+	if err != nil {
+		return false, fmt.Errorf("record balance history: %w", err)
+	}
+	return recorded, nil
 }
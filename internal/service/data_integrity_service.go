@@ -4,39 +4,43 @@ import (
 	"context"
 	"fmt"
 	"gn-indexer/internal/client"
+	"gn-indexer/internal/logging"
 	"gn-indexer/internal/producer"
 	"gn-indexer/internal/types"
-	"log"
+	"log/slog"
 )
 
 type DataIntegrityService struct {
 	syncer    *producer.Syncer
 	subClient *client.SubscriptionClient
+	chunkSize int64
+	logger    *slog.Logger
 }
 
-func NewDataIntegrityService(syncer *producer.Syncer, subClient *client.SubscriptionClient) *DataIntegrityService {
+func NewDataIntegrityService(syncer *producer.Syncer, subClient *client.SubscriptionClient, chunkSize int) *DataIntegrityService {
 	return &DataIntegrityService{
 		syncer:    syncer,
 		subClient: subClient,
+		chunkSize: int64(chunkSize),
+		logger:    logging.For("data-integrity"),
 	}
 }
 
 // SyncRange performs synchronization for a specific height range
 func (dis *DataIntegrityService) SyncRange(ctx context.Context, fromHeight, toHeight int64) error {
-	log.Printf("DataIntegrityService: syncing range %d to %d", fromHeight, toHeight)
+	dis.logger.Debug("syncing range", "from_height", fromHeight, "to_height", toHeight)
 
 	if err := dis.syncer.SyncRange(ctx, int(fromHeight), int(toHeight)); err != nil {
 		return fmt.Errorf("sync range failed: %w", err)
 	}
 
-	log.Printf("DataIntegrityService: range %d to %d completed", fromHeight, toHeight)
 	return nil
 }
 
 // CheckAndFixDataIntegrity performs data integrity check and fixes missing blocks/transactions
 // This will re-sync all blocks and transactions from height 1 to the current latest height from network
 func (dis *DataIntegrityService) CheckAndFixDataIntegrity(ctx context.Context) error {
-	log.Printf("DataIntegrityService: starting data integrity check and fix from height 1")
+	dis.logger.Info("starting data integrity check and fix from height 1")
 
 	// Get the latest block height from network using SubscribeOnce (same as backfill service)
 	var latestNetworkHeight int
@@ -52,18 +56,18 @@ func (dis *DataIntegrityService) CheckAndFixDataIntegrity(ctx context.Context) e
 		return fmt.Errorf("invalid network height: %d", latestNetworkHeight)
 	}
 
-	log.Printf("DataIntegrityService: latest network height: %d, will sync entire range from height 1 to %d", latestNetworkHeight, latestNetworkHeight)
+	dis.logger.Info("syncing entire range up to the network height", "network_height", latestNetworkHeight)
 	return dis.syncRangeWithChunks(ctx, 1, int64(latestNetworkHeight))
 }
 
 // syncRangeWithChunks processes blocks in chunks to avoid GraphQL limits
 func (dis *DataIntegrityService) syncRangeWithChunks(ctx context.Context, fromHeight, toHeight int64) error {
 	if fromHeight > toHeight {
-		log.Printf("DataIntegrityService: no range to sync (fromHeight %d > toHeight %d)", fromHeight, toHeight)
+		dis.logger.Info("no range to sync", "from_height", fromHeight, "to_height", toHeight)
 		return nil
 	}
 
-	const chunkSize = int64(1000)
+	chunkSize := dis.chunkSize
 
 	// Calculate total chunks
 	var totalChunks int64
@@ -76,7 +80,7 @@ func (dis *DataIntegrityService) syncRangeWithChunks(ctx context.Context, fromHe
 		}
 	}
 
-	log.Printf("DataIntegrityService: processing %d chunks from height %d to %d", totalChunks, fromHeight, toHeight)
+	dis.logger.Info("processing chunks", "chunks", totalChunks, "from_height", fromHeight, "to_height", toHeight)
 
 	successCount := int64(0)
 	currentChunk := int64(0)
@@ -88,20 +92,16 @@ func (dis *DataIntegrityService) syncRangeWithChunks(ctx context.Context, fromHe
 			to = toHeight
 		}
 
-		log.Printf("DataIntegrityService: syncing chunk %d/%d (height %d~%d)",
-			currentChunk, totalChunks, from, to)
-
 		if err := dis.SyncRange(ctx, from, to); err != nil {
-			log.Printf("DataIntegrityService: chunk %d-%d failed: %v", from, to, err)
+			dis.logger.Error("chunk failed", "from_height", from, "to_height", to, "error", err)
 			continue
 		}
 
 		successCount++
-		log.Printf("DataIntegrityService: chunk %d/%d completed successfully", currentChunk, totalChunks)
+		dis.logger.Info("chunk completed", "chunk", currentChunk, "chunks", totalChunks, "from_height", from, "to_height", to)
 	}
 
-	log.Printf("DataIntegrityService: completed - %d/%d chunks successful, synced blocks %d~%d",
-		successCount, totalChunks, fromHeight, toHeight)
+	dis.logger.Info("data integrity check completed", "succeeded_chunks", successCount, "chunks", totalChunks, "from_height", fromHeight, "to_height", toHeight)
 
 	if successCount > 0 {
 		return nil
@@ -112,7 +112,7 @@ func (dis *DataIntegrityService) syncRangeWithChunks(ctx context.Context, fromHe
 
 // SyncSpecificRange syncs a specific height range (for manual control)
 func (dis *DataIntegrityService) SyncSpecificRange(ctx context.Context, fromHeight, toHeight int64) error {
-	log.Printf("DataIntegrityService: syncing specific range %d to %d", fromHeight, toHeight)
+	dis.logger.Info("syncing specific range", "from_height", fromHeight, "to_height", toHeight)
 
 	if fromHeight < 1 {
 		return fmt.Errorf("fromHeight must be >= 1, got %d", fromHeight)
@@ -3,40 +3,55 @@ package service
 import (
 	"context"
 	"fmt"
+	"gn-indexer/internal/domain"
+	"gn-indexer/internal/logging"
+	"gn-indexer/internal/metrics"
 	"gn-indexer/internal/queue"
-	"log"
+	"gn-indexer/internal/tracing"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // EventProcessorService handles consuming events from queue and processing them
 type EventProcessorService struct {
-	eventQueue     queue.EventQueue
-	balanceService *BalanceService
+	eventQueue       queue.EventQueue
+	balanceService   *BalanceService
+	allowanceService *AllowanceService
+	nftService       *NFTService
+	logger           *slog.Logger
 }
 
 // NewEventProcessorService creates a new event processor service
 func NewEventProcessorService(
 	eventQueue queue.EventQueue,
 	balanceService *BalanceService,
+	allowanceService *AllowanceService,
+	nftService *NFTService,
 ) *EventProcessorService {
 	return &EventProcessorService{
-		eventQueue:     eventQueue,
-		balanceService: balanceService,
+		eventQueue:       eventQueue,
+		balanceService:   balanceService,
+		allowanceService: allowanceService,
+		nftService:       nftService,
+		logger:           logging.For("event-processor"),
 	}
 }
 
 // Start begins continuous processing events from the queue
 func (eps *EventProcessorService) Start(ctx context.Context) error {
-	log.Printf("EventProcessorService: starting continuous event processing")
+	eps.logger.Info("starting continuous event processing")
 
 	for {
 		select {
 		case <-ctx.Done():
-			log.Printf("EventProcessorService: context cancelled, stopping")
+			eps.logger.Info("context cancelled, stopping")
 			return ctx.Err()
 		default:
 			// Use SQS Long Polling to receive events
 			if err := eps.processBatch(ctx); err != nil {
-				log.Printf("EventProcessorService: error processing events: %v", err)
+				eps.logger.Error("error processing events", "error", err)
 				// Continue processing even if batch fails
 			}
 		}
@@ -45,7 +60,7 @@ func (eps *EventProcessorService) Start(ctx context.Context) error {
 
 // ProcessSingleBatch processes exactly one batch of events and returns the count
 func (eps *EventProcessorService) ProcessSingleBatch(ctx context.Context, batchSize int) (int, error) {
-	log.Printf("EventProcessorService: processing single batch with size: %d", batchSize)
+	eps.logger.Info("processing single batch", "batch_size", batchSize)
 
 	// Receive events from queue (SQS Long Polling handles the waiting)
 	events, err := eps.eventQueue.ReceiveEvents(ctx)
@@ -55,29 +70,12 @@ func (eps *EventProcessorService) ProcessSingleBatch(ctx context.Context, batchS
 
 	// If no events available, return 0 (this is normal)
 	if len(events) == 0 {
-		log.Printf("EventProcessorService: no events available in queue")
+		eps.logger.Info("no events available in queue")
 		return 0, nil
 	}
 
-	log.Printf("EventProcessorService: processing %d events", len(events))
-
-	// Process all events
-	processedCount := 0
-	for _, event := range events {
-		log.Printf("EventProcessorService: processing event %s for token %s", event.Type, event.TokenPath)
-
-		// Process the event
-		if err := eps.balanceService.ProcessEvent(ctx, event); err != nil {
-			log.Printf("EventProcessorService: error processing event %s: %v", event.Type, err)
-			// Continue processing other events even if one fails
-			continue
-		}
-
-		processedCount++
-		log.Printf("EventProcessorService: successfully processed event %s", event.Type)
-	}
-
-	log.Printf("EventProcessorService: batch processing completed, processed %d/%d events", processedCount, len(events))
+	processedCount := eps.processEvents(ctx, events)
+	eps.logger.Info("batch processing completed", "processed", processedCount, "received", len(events))
 	return processedCount, nil
 }
 
@@ -94,24 +92,49 @@ func (eps *EventProcessorService) processBatch(ctx context.Context) error {
 		return nil
 	}
 
-	log.Printf("EventProcessorService: processing %d events", len(events))
+	processedCount := eps.processEvents(ctx, events)
+	eps.logger.Debug("processing completed", "processed", processedCount, "received", len(events))
+	return nil
+}
 
-	// Process all events
+// processEvents processes a batch and returns how many events succeeded, failures are logged and skipped
+func (eps *EventProcessorService) processEvents(ctx context.Context, events []*domain.ParsedEvent) int {
 	processedCount := 0
 	for _, event := range events {
-		log.Printf("EventProcessorService: processing event %s for token %s", event.Type, event.TokenPath)
-
-		// Process the event
-		if err := eps.balanceService.ProcessEvent(ctx, event); err != nil {
-			log.Printf("EventProcessorService: error processing event %s: %v", event.Type, err)
+		// Continue the trace of the block ingestion that queued the event
+		eventCtx := logging.WithCorrelationID(tracing.Extract(ctx, event.TraceContext), event.CorrelationID)
+		eventCtx, span := tracing.StartKind(eventCtx, "EventProcessorService.processEvent", trace.SpanKindConsumer,
+			attribute.String("event.type", event.Type),
+			attribute.String("token.path", event.TokenPath),
+			attribute.String(logging.KeyTxHash, event.TxHash),
+			attribute.Int(logging.KeyEventIndex, event.EventIndex),
+			attribute.Int64(logging.KeyBlockHeight, event.BlockHeight),
+		)
+		logger := eps.logger.With(logging.KeyTxHash, event.TxHash, logging.KeyEventIndex, event.EventIndex, logging.KeyBlockHeight, event.BlockHeight)
+		logger.DebugContext(eventCtx, "processing event", "type", event.Type, "token_path", event.TokenPath)
+
+		err := eps.processEvent(eventCtx, event)
+		tracing.End(span, err)
+		if err != nil {
+			logger.ErrorContext(eventCtx, "error processing event", "type", event.Type, "error", err)
 			// Continue processing other events even if one fails
 			continue
 		}
-
+		metrics.MarkEvent(event.BlockHeight)
 		processedCount++
-		log.Printf("EventProcessorService: successfully processed event %s", event.Type)
 	}
+	return processedCount
+}
 
-	log.Printf("EventProcessorService: processing completed, processed %d/%d events", processedCount, len(events))
-	return nil
+// processEvent routes an event to the service that owns its state
+func (eps *EventProcessorService) processEvent(ctx context.Context, event *domain.ParsedEvent) error {
+	kind := eventKind(event)
+	switch {
+	case kind == domain.EventTypeApproval:
+		return eps.allowanceService.ProcessEvent(ctx, event)
+	case kind.IsNFT():
+		return eps.nftService.ProcessEvent(ctx, event)
+	default:
+		return eps.balanceService.ProcessEvent(ctx, event)
+	}
 }
@@ -5,66 +5,96 @@ import (
 	"fmt"
 	event_parsing "gn-indexer/internal/consumer"
 	"gn-indexer/internal/domain"
+	"gn-indexer/internal/logging"
+	"gn-indexer/internal/metrics"
 	"gn-indexer/internal/queue"
 	"gn-indexer/internal/repository"
-	"log"
+	"gn-indexer/internal/tracing"
+	"log/slog"
 	"time"
+
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // EventStorageService handles storing parsed events to database
 type EventStorageService struct {
+	transactor    repository.Transactor
 	eventRepo     repository.EventRepository
 	eventAttrRepo repository.EventAttrRepository
 	transferRepo  repository.TransferRepository
 	tokenRepo     repository.TokenRepository
+	nftRepo       repository.NFTRepository
 	eventParser   *event_parsing.EventParser
 	eventQueue    queue.EventQueue
+	logger        *slog.Logger
 }
 
-// NewEventStorageService creates a new event storage service
+// NewEventStorageService creates a new event storage service.
+// The raw events of a transaction and their attributes are written in one transaction of transactor.
 func NewEventStorageService(
+	transactor repository.Transactor,
 	eventRepo repository.EventRepository,
 	eventAttrRepo repository.EventAttrRepository,
 	transferRepo repository.TransferRepository,
 	tokenRepo repository.TokenRepository,
+	nftRepo repository.NFTRepository,
 	eventQueue queue.EventQueue,
 ) *EventStorageService {
 	return &EventStorageService{
+		transactor:    transactor,
 		eventRepo:     eventRepo,
 		eventAttrRepo: eventAttrRepo,
 		transferRepo:  transferRepo,
 		tokenRepo:     tokenRepo,
+		nftRepo:       nftRepo,
 		eventParser:   event_parsing.NewEventParser(),
 		eventQueue:    eventQueue,
+		logger:        logging.For("event-storage"),
 	}
 }
 
 // ProcessTransaction processes a transaction and stores its events
-func (ess *EventStorageService) ProcessTransaction(ctx context.Context, tx *domain.Transaction) error {
-	log.Printf("Processing transaction %s for events", tx.Hash)
+func (ess *EventStorageService) ProcessTransaction(ctx context.Context, tx *domain.Transaction) (err error) {
+	ctx, span := tracing.Start(ctx, "EventStorageService.ProcessTransaction",
+		attribute.String(logging.KeyTxHash, tx.Hash), attribute.Int(logging.KeyBlockHeight, tx.BlockHeight))
+	defer func() { tracing.End(span, err) }()
+
+	logger := ess.logger.With(logging.KeyTxHash, tx.Hash, logging.KeyBlockHeight, tx.BlockHeight)
+	logger.DebugContext(ctx, "processing transaction events")
+
+	// Store every raw event first so tx_events mirrors the chain
+	if err := ess.storeRawEvents(ctx, logger, tx); err != nil {
+		return fmt.Errorf("store raw events: %w", err)
+	}
 
 	// Parse events from transaction
 	parsedEvents, err := ess.eventParser.ParseEventsFromTransaction(tx)
 	if err != nil {
-		return fmt.Errorf("parse events: %w", err)
+		// A malformed event is skipped, the other events of the transaction are still indexed
+		logger.WarnContext(ctx, "skipping events that failed to parse", "error", err)
 	}
 
 	if len(parsedEvents) == 0 {
-		log.Printf("No token events found in transaction %s", tx.Hash)
+		logger.DebugContext(ctx, "no token events in transaction")
 		return nil
 	}
 
-	log.Printf("Found %d token events in transaction %s", len(parsedEvents), tx.Hash)
+	logger.DebugContext(ctx, "found token events", "count", len(parsedEvents))
 
 	// Process each event
 	for _, parsedEvent := range parsedEvents {
-		if err := ess.processSingleEvent(ctx, &parsedEvent, tx); err != nil {
+		if len(parsedEvent.MalformedAttrs) > 0 {
+			logger.WarnContext(ctx, "malformed address attrs, storing values as emitted",
+				logging.KeyEventIndex, parsedEvent.EventIndex, "attrs", parsedEvent.MalformedAttrs)
+		}
+
+		if err := ess.processSingleEvent(ctx, &parsedEvent); err != nil {
 			return fmt.Errorf("process event: %w", err)
 		}
 
 		// Send event to queue for balance calculation
 		if err := ess.eventQueue.SendEvent(ctx, &parsedEvent); err != nil {
-			log.Printf("Failed to send event to queue: %v", err)
+			logger.ErrorContext(ctx, "failed to send event to queue", logging.KeyEventIndex, parsedEvent.EventIndex, "error", err)
 			// Don't fail the transaction processing if queue fails
 		}
 	}
@@ -72,41 +102,90 @@ func (ess *EventStorageService) ProcessTransaction(ctx context.Context, tx *doma
 	return nil
 }
 
-// processSingleEvent processes a single parsed event
-func (ess *EventStorageService) processSingleEvent(ctx context.Context, event *domain.ParsedEvent, tx *domain.Transaction) error {
-	log.Printf("Processing event %s for transaction %s", event.Type, event.TxHash)
+// storeRawEvents stores every GnoEvent of a transaction with its original attributes.
+// They are written in one transaction, so a failure never leaves an event without some of its attributes.
+func (ess *EventStorageService) storeRawEvents(ctx context.Context, logger *slog.Logger, tx *domain.Transaction) error {
+	if tx.Response == nil || len(tx.Response.Events) == 0 {
+		return nil
+	}
 
-	// 1. Store event in tx_events table
-	txEvent := &domain.TxEvent{
-		TxHash:     event.TxHash,
-		EventIndex: event.EventIndex,
-		Type:       event.Type,
-		Func:       string(event.Func),
-		PkgPath:    event.TokenPath,
+	var storedCount int
+	err := ess.transactor.Transaction(ctx, func(ctx context.Context) error {
+		var err error
+		storedCount, err = ess.storeEvents(ctx, tx)
+		return err
+	})
+	if err != nil {
+		return err
 	}
 
-	if err := ess.eventRepo.Create(ctx, txEvent); err != nil {
-		return fmt.Errorf("create tx_event: %w", err)
+	metrics.EventsStored.Add(float64(storedCount))
+	if storedCount > 0 {
+		metrics.MarkEvent(int64(tx.BlockHeight))
 	}
 
-	log.Printf("Saved event to tx_events table with ID: %d", txEvent.ID)
+	logger.DebugContext(ctx, "saved raw events", "count", storedCount)
+	return nil
+}
 
-	// 2. Store event attributes in tx_event_attrs table
-	attrs := []domain.TxEventAttr{
-		{EventID: txEvent.ID, AttrIndex: 0, Key: "from", Value: event.FromAddress},
-		{EventID: txEvent.ID, AttrIndex: 1, Key: "to", Value: event.ToAddress},
-		{EventID: txEvent.ID, AttrIndex: 2, Key: "value", Value: fmt.Sprintf("%d", event.Amount)},
-	}
+// storeEvents writes the events and attributes of a transaction and returns how many events it stored
+func (ess *EventStorageService) storeEvents(ctx context.Context, tx *domain.Transaction) (int, error) {
+	storedCount := 0
+	for i, event := range tx.Response.Events {
+		// Non-GnoEvent union members decode as empty objects, keep their index but skip them
+		if event.Type == "" {
+			continue
+		}
 
-	for _, attr := range attrs {
-		if err := ess.eventAttrRepo.Create(ctx, &attr); err != nil {
-			return fmt.Errorf("create tx_event_attr: %w", err)
+		// 1. Store event in tx_events table
+		txEvent := &domain.TxEvent{
+			TxHash:     tx.Hash,
+			EventIndex: i,
+			Type:       event.Type,
+			Func:       event.Func,
+			PkgPath:    event.PkgPath,
 		}
+
+		if err := ess.eventRepo.Create(ctx, txEvent); err != nil {
+			return 0, fmt.Errorf("create tx_event %d: %w", i, err)
+		}
+
+		// 2. Store original attributes in tx_event_attrs table
+		for j, attr := range event.Attrs {
+			eventAttr := &domain.TxEventAttr{
+				EventID:   txEvent.ID,
+				AttrIndex: j,
+				Key:       attr.Key,
+				Value:     attr.Value,
+			}
+			if err := ess.eventAttrRepo.Create(ctx, eventAttr); err != nil {
+				return 0, fmt.Errorf("create tx_event_attr %d of event %d: %w", j, i, err)
+			}
+		}
+
+		storedCount++
+	}
+	return storedCount, nil
+}
+
+// processSingleEvent stores the derived records of a parsed token event
+func (ess *EventStorageService) processSingleEvent(ctx context.Context, event *domain.ParsedEvent) error {
+	// GRC721 events are recorded in their own history table
+	if event.Func.IsNFT() {
+		return ess.processNFTEvent(ctx, event)
 	}
 
-	log.Printf("Saved %d attributes to tx_event_attrs table", len(attrs))
+	// 1. Register token if new (transfers reference tokens)
+	if err := ess.tokenRepo.RegisterIfNotExists(ctx, event.TokenPath); err != nil {
+		return fmt.Errorf("register token: %w", err)
+	}
+
+	// Approvals only change allowances, there is no transfer to record
+	if event.Func == domain.EventTypeApproval {
+		return nil
+	}
 
-	// 3. Store transfer record
+	// 2. Store transfer record
 	transfer := &domain.Transfer{
 		TxHash:      event.TxHash,
 		EventIndex:  event.EventIndex,
@@ -122,13 +201,32 @@ func (ess *EventStorageService) processSingleEvent(ctx context.Context, event *d
 		return fmt.Errorf("create transfer: %w", err)
 	}
 
-	log.Printf("Saved transfer record to transfers table")
+	ess.logger.DebugContext(ctx, "saved transfer", logging.KeyTxHash, event.TxHash, logging.KeyEventIndex, event.EventIndex, "token_path", event.TokenPath)
+	return nil
+}
 
-	// 4. Register token if new
-	if err := ess.tokenRepo.RegisterIfNotExists(ctx, event.TokenPath); err != nil {
-		return fmt.Errorf("register token: %w", err)
+// processNFTEvent stores a GRC721 mint, burn or transfer record
+func (ess *EventStorageService) processNFTEvent(ctx context.Context, event *domain.ParsedEvent) error {
+	// Approvals do not move the token, the raw event in tx_events is their only record
+	if event.Func == domain.EventTypeNFTApproval {
+		return nil
+	}
+
+	transfer := &domain.NFTTransfer{
+		TxHash:         event.TxHash,
+		EventIndex:     event.EventIndex,
+		CollectionPath: event.TokenPath,
+		TokenID:        event.TokenID,
+		FromAddress:    event.FromAddress,
+		ToAddress:      event.ToAddress,
+		BlockHeight:    event.BlockHeight,
+		CreatedAt:      time.Now(),
+	}
+
+	if err := ess.nftRepo.CreateTransfer(ctx, transfer); err != nil {
+		return fmt.Errorf("create nft transfer: %w", err)
 	}
 
-	log.Printf("Successfully processed event %s for transaction %s", event.Type, event.TxHash)
+	ess.logger.DebugContext(ctx, "saved nft transfer", logging.KeyTxHash, event.TxHash, logging.KeyEventIndex, event.EventIndex, "collection", event.TokenPath, "token_id", event.TokenID)
 	return nil
 }
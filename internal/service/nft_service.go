@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gn-indexer/internal/domain"
+	"gn-indexer/internal/logging"
+	"gn-indexer/internal/repository"
+	"log/slog"
+	"time"
+)
+
+// NFTService handles GRC721 ownership updates
+type NFTService struct {
+	nftRepo repository.NFTRepository
+	logger  *slog.Logger
+}
+
+// NewNFTService creates a new NFT service
+func NewNFTService(nftRepo repository.NFTRepository) *NFTService {
+	return &NFTService{
+		nftRepo: nftRepo,
+		logger:  logging.For("nft"),
+	}
+}
+
+// ProcessEvent applies a GRC721 mint, burn or transfer to the nft_owners table, approvals leave the owner unchanged
+func (ns *NFTService) ProcessEvent(ctx context.Context, event *domain.ParsedEvent) error {
+	if event.Func == domain.EventTypeNFTApproval {
+		return nil
+	}
+
+	// Ignore events older than the stored ownership (queue delivery is unordered)
+	current, err := ns.nftRepo.GetOwner(ctx, event.TokenPath, event.TokenID)
+	if err != nil && !errors.Is(err, repository.ErrNFTNotFound) {
+		return fmt.Errorf("get current owner: %w", err)
+	}
+	if current != nil && isStale(event, current.LastBlockH, current.LastEventIndex) {
+		ns.logger.DebugContext(ctx, "stale event, skipping",
+			logging.KeyTxHash, event.TxHash, logging.KeyBlockHeight, event.BlockHeight, logging.KeyEventIndex, event.EventIndex,
+			"stored_height", current.LastBlockH, "stored_event_index", current.LastEventIndex)
+		return nil
+	}
+
+	owner := event.ToAddress
+	if event.Func == domain.EventTypeNFTBurn {
+		// Burned tokens keep their row without an owner
+		owner = ""
+	}
+
+	record := &domain.NFTOwner{
+		CollectionPath: event.TokenPath,
+		TokenID:        event.TokenID,
+		Owner:          owner,
+		LastTxHash:     event.TxHash,
+		LastBlockH:     event.BlockHeight,
+		LastEventIndex: event.EventIndex,
+		UpdatedAt:      time.Now(),
+	}
+
+	if err := ns.nftRepo.SaveOwner(ctx, record); err != nil {
+		return fmt.Errorf("save nft owner: %w", err)
+	}
+
+	ns.logger.DebugContext(ctx, "nft owner updated", "collection", event.TokenPath, "token_id", event.TokenID, "owner", owner)
+	return nil
+}
+
+// isStale reports whether an event comes before the stored (block height, event index) position on chain
+func isStale(event *domain.ParsedEvent, height int64, eventIndex int) bool {
+	if height != event.BlockHeight {
+		return height > event.BlockHeight
+	}
+	return eventIndex > event.EventIndex
+}
@@ -4,10 +4,12 @@ import (
 	"context"
 	"fmt"
 	"gn-indexer/internal/client"
+	"gn-indexer/internal/config"
 	"gn-indexer/internal/domain"
+	"gn-indexer/internal/logging"
 	"gn-indexer/internal/producer"
 	"gn-indexer/internal/types"
-	"log"
+	"log/slog"
 	"time"
 )
 
@@ -15,19 +17,23 @@ import (
 type RealtimeSyncService struct {
 	syncer    *producer.Syncer
 	subClient *client.SubscriptionClient
+	config    *config.SyncConfig
+	logger    *slog.Logger
 }
 
 // NewRealtimeSyncService creates a new realtime sync service
-func NewRealtimeSyncService(syncer *producer.Syncer, subClient *client.SubscriptionClient) *RealtimeSyncService {
+func NewRealtimeSyncService(syncer *producer.Syncer, subClient *client.SubscriptionClient, cfg *config.SyncConfig) *RealtimeSyncService {
 	return &RealtimeSyncService{
 		syncer:    syncer,
 		subClient: subClient,
+		config:    cfg,
+		logger:    logging.For("realtime-sync"),
 	}
 }
 
 // Start begins the real-time synchronization process
 func (rs *RealtimeSyncService) Start(ctx context.Context) error {
-	log.Printf("RealtimeSyncService: starting real-time sync")
+	rs.logger.Info("starting real-time sync")
 
 	// Check if context is already cancelled
 	if ctx.Err() != nil {
@@ -40,8 +46,6 @@ func (rs *RealtimeSyncService) Start(ctx context.Context) error {
 
 // startSubscription starts the websocket subscription
 func (rs *RealtimeSyncService) startSubscription(ctx context.Context) error {
-	log.Printf("RealtimeSyncService: starting subscription")
-
 	// Check if context is already cancelled
 	if ctx.Err() != nil {
 		return ctx.Err()
@@ -53,39 +57,36 @@ func (rs *RealtimeSyncService) startSubscription(ctx context.Context) error {
 		return fmt.Errorf("failed to start subscription: %w", err)
 	}
 
-	log.Printf("RealtimeSyncService: subscription started successfully")
 	return nil
 }
 
 // handleSubscriptionData processes incoming real-time block data
 func (rs *RealtimeSyncService) handleSubscriptionData(data types.BlocksData) error {
 	block := data.GetBlocks
-	log.Printf("RealtimeSyncService: processing block %d", block.Height)
+
+	// One correlation ID per block, kept across retries
+	ctx := logging.WithCorrelationID(context.Background(), logging.NewCorrelationID())
+	rs.logger.DebugContext(ctx, "processing block", logging.KeyBlockHeight, block.Height)
 
 	// Process real-time blocks with retry logic
-	if err := rs.processBlockWithRetry(block); err != nil {
-		log.Printf("RealtimeSyncService: block %d failed after retries: %v", block.Height, err)
+	if err := rs.processBlockWithRetry(ctx, block); err != nil {
+		rs.logger.ErrorContext(ctx, "block failed after retries", logging.KeyBlockHeight, block.Height, "error", err)
 		return fmt.Errorf("block %d processing failed: %w", block.Height, err)
 	}
-
-	log.Printf("RealtimeSyncService: block %d processed successfully", block.Height)
 	return nil
 }
 
 // processBlockWithRetry processes the block with retry logic
-func (rs *RealtimeSyncService) processBlockWithRetry(block domain.Block) error {
-	const maxRetries = 3
-	const retryDelay = time.Millisecond * 500
-
+func (rs *RealtimeSyncService) processBlockWithRetry(ctx context.Context, block domain.Block) error {
+	maxRetries := rs.config.RetryAttempts
 	for attempt := 1; attempt <= maxRetries; attempt++ {
-		if err := rs.processBlock(block); err != nil {
+		if err := rs.processBlock(ctx, block); err != nil {
 			if attempt == maxRetries {
 				return fmt.Errorf("failed after %d attempts: %w", maxRetries, err)
 			}
 
-			log.Printf("RealtimeSyncService: attempt %d failed for block %d: %v, retrying...",
-				attempt, block.Height, err)
-			time.Sleep(retryDelay)
+			rs.logger.WarnContext(ctx, "block attempt failed, retrying", logging.KeyBlockHeight, block.Height, "attempt", attempt, "error", err)
+			time.Sleep(rs.config.RetryDelay)
 			continue
 		}
 
@@ -96,6 +97,6 @@ func (rs *RealtimeSyncService) processBlockWithRetry(block domain.Block) error {
 }
 
 // processBlock processes a single block
-func (rs *RealtimeSyncService) processBlock(block domain.Block) error {
-	return rs.syncer.HandleRealtimeBlock(context.Background(), block)
+func (rs *RealtimeSyncService) processBlock(ctx context.Context, block domain.Block) error {
+	return rs.syncer.HandleRealtimeBlock(ctx, block)
 }
@@ -0,0 +1,424 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gn-indexer/internal/domain"
+	"gn-indexer/internal/logging"
+	"gn-indexer/internal/repository"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// Snapshot export formats
+const (
+	SnapshotFormatCSV     = "csv"
+	SnapshotFormatJSONL   = "jsonl"
+	SnapshotFormatParquet = "parquet"
+)
+
+const (
+	// snapshotMaxPending bounds the jobs waiting for a worker, further submissions are rejected
+	snapshotMaxPending = 100
+	// snapshotPollInterval is how often idle workers look for pending jobs nobody woke them for
+	snapshotPollInterval = 5 * time.Second
+	// snapshotCleanupInterval is how often files past the retention period are deleted
+	snapshotCleanupInterval = time.Hour
+)
+
+var (
+	// ErrInvalidSnapshotFormat is returned for an unsupported export format
+	ErrInvalidSnapshotFormat = errors.New("invalid snapshot format, expected csv, jsonl or parquet")
+	// ErrSnapshotQueueFull is returned when too many jobs are waiting for a worker
+	ErrSnapshotQueueFull = errors.New("too many snapshot jobs are queued")
+)
+
+// SnapshotRequest selects the holders to export
+type SnapshotRequest struct {
+	TokenPath string
+	AtHeight  *int64      // nil exports current balances
+	MinAmount *domain.U64 // holders below the threshold are skipped
+	Exclude   []string    // addresses left out, e.g. the treasury or burn addresses
+	Format    string
+	APIKeyID  *int64 // key submitting a job, nil without API keys
+}
+
+// SnapshotHolder is a single exported row
+type SnapshotHolder struct {
+	Address         string `json:"address" parquet:"address"`
+	TokenPath       string `json:"tokenPath" parquet:"token_path"`
+	Amount          string `json:"amount" parquet:"amount"`
+	LastBlockHeight int64  `json:"lastBlockHeight" parquet:"last_block_height"`
+}
+
+// SnapshotService exports token holders at a given height
+type SnapshotService struct {
+	balanceRepo repository.BalanceRepository
+	historyRepo repository.BalanceHistoryRepository
+	jobRepo     repository.SnapshotJobRepository
+	outputDir   string
+	wake        chan struct{}
+	logger      *slog.Logger
+}
+
+// NewSnapshotService creates a new snapshot service.
+// jobRepo and outputDir are only needed for asynchronous jobs, which run once Start is called.
+func NewSnapshotService(
+	balanceRepo repository.BalanceRepository,
+	historyRepo repository.BalanceHistoryRepository,
+	jobRepo repository.SnapshotJobRepository,
+	outputDir string,
+) *SnapshotService {
+	return &SnapshotService{
+		balanceRepo: balanceRepo,
+		historyRepo: historyRepo,
+		jobRepo:     jobRepo,
+		outputDir:   outputDir,
+		wake:        make(chan struct{}, 1),
+		logger:      logging.For("snapshot"),
+	}
+}
+
+// ValidSnapshotFormat reports whether format is a supported export format
+func ValidSnapshotFormat(format string) bool {
+	switch format {
+	case SnapshotFormatCSV, SnapshotFormatJSONL, SnapshotFormatParquet:
+		return true
+	}
+	return false
+}
+
+// Export streams the holders matching the request to w and returns the number of rows written
+func (ss *SnapshotService) Export(ctx context.Context, req SnapshotRequest, w io.Writer) (int64, error) {
+	writer, err := newSnapshotWriter(req.Format, w)
+	if err != nil {
+		return 0, err
+	}
+
+	query := repository.BalanceQuery{
+		TokenPath:   req.TokenPath,
+		MinAmount:   req.MinAmount,
+		ExcludeZero: true,
+		Exclude:     normalizeAddresses(req.Exclude),
+		Sort:        repository.BalanceSortAmount,
+	}
+
+	var rowCount int64
+	write := func(balance *domain.Balance) error {
+		rowCount++
+		return writer.Write(&SnapshotHolder{
+			Address:         balance.Address,
+			TokenPath:       balance.TokenPath,
+			Amount:          balance.Amount.String(),
+			LastBlockHeight: balance.LastBlockH,
+		})
+	}
+
+	if req.AtHeight != nil {
+		err = ss.historyRepo.StreamBalancesAt(ctx, *req.AtHeight, query, write)
+	} else {
+		err = ss.balanceRepo.StreamBalances(ctx, query, write)
+	}
+	if err != nil {
+		return rowCount, fmt.Errorf("export holders: %w", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		return rowCount, fmt.Errorf("flush snapshot: %w", err)
+	}
+	return rowCount, nil
+}
+
+// Submit records a snapshot job, a worker started by Start runs it
+func (ss *SnapshotService) Submit(ctx context.Context, req SnapshotRequest) (*domain.SnapshotJob, error) {
+	if !ValidSnapshotFormat(req.Format) {
+		return nil, ErrInvalidSnapshotFormat
+	}
+
+	pending, err := ss.jobRepo.CountPending(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if pending >= snapshotMaxPending {
+		return nil, ErrSnapshotQueueFull
+	}
+
+	job := &domain.SnapshotJob{
+		TokenPath: req.TokenPath,
+		APIKeyID:  req.APIKeyID,
+		AtHeight:  req.AtHeight,
+		Format:    req.Format,
+		MinAmount: req.MinAmount,
+		Exclude:   req.Exclude,
+		Status:    domain.SnapshotStatusPending,
+	}
+	if err := ss.jobRepo.Create(ctx, job); err != nil {
+		return nil, fmt.Errorf("create job: %w", err)
+	}
+
+	// Wake an idle worker, a busy pool picks the job up when a worker frees
+	select {
+	case ss.wake <- struct{}{}:
+	default:
+	}
+
+	return job, nil
+}
+
+// GetJob returns a snapshot job by id if the API key submitted it
+func (ss *SnapshotService) GetJob(ctx context.Context, apiKeyID *int64, id int64) (*domain.SnapshotJob, error) {
+	return ss.jobRepo.GetOwned(ctx, apiKeyID, id)
+}
+
+// Start runs pending jobs on a fixed number of workers and deletes files older than retention until the context is cancelled.
+// Jobs left running by a previous process are queued again first.
+func (ss *SnapshotService) Start(ctx context.Context, workers int, retention time.Duration) error {
+	requeued, err := ss.jobRepo.RequeueRunning(ctx)
+	if err != nil {
+		return err
+	}
+	ss.logger.Info("starting job workers", "workers", workers, "requeued", requeued)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ss.work(ctx)
+		}()
+	}
+
+	ticker := time.NewTicker(snapshotCleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		ss.Cleanup(ctx, time.Now().Add(-retention))
+		select {
+		case <-ctx.Done():
+			ss.logger.Info("context cancelled, stopping")
+			wg.Wait()
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// work runs pending jobs one at a time, waiting for a submission or the next poll when none is pending
+func (ss *SnapshotService) work(ctx context.Context) {
+	ticker := time.NewTicker(snapshotPollInterval)
+	defer ticker.Stop()
+
+	for {
+		for ss.RunNext(ctx) {
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ss.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunNext claims the oldest pending job and runs it, reporting whether there was one
+func (ss *SnapshotService) RunNext(ctx context.Context) bool {
+	job, err := ss.jobRepo.ClaimPending(ctx)
+	if err != nil {
+		if !errors.Is(err, repository.ErrSnapshotJobNotFound) && ctx.Err() == nil {
+			ss.logger.ErrorContext(ctx, "job claim failed", "error", err)
+		}
+		return false
+	}
+
+	ss.run(ctx, job)
+	return true
+}
+
+// run exports a claimed job to a file in the output directory and records the outcome
+func (ss *SnapshotService) run(ctx context.Context, job *domain.SnapshotJob) {
+	logger := ss.logger.With("job_id", job.ID, "token", job.TokenPath)
+	logger.InfoContext(ctx, "job started")
+
+	req := SnapshotRequest{
+		TokenPath: job.TokenPath,
+		AtHeight:  job.AtHeight,
+		MinAmount: job.MinAmount,
+		Exclude:   job.Exclude,
+		Format:    job.Format,
+	}
+	rowCount, filePath, err := ss.exportToFile(ctx, job, req)
+	if err != nil && ctx.Err() != nil {
+		// Stopped with the process, the job stays running and is queued again at the next start
+		logger.WarnContext(ctx, "job interrupted", "error", err)
+		return
+	}
+
+	finishedAt := time.Now()
+	job.FinishedAt = &finishedAt
+	job.RowCount = rowCount
+	if err != nil {
+		job.Status = domain.SnapshotStatusFailed
+		job.Error = err.Error()
+		logger.ErrorContext(ctx, "job failed", "error", err)
+	} else {
+		job.Status = domain.SnapshotStatusDone
+		job.FilePath = filePath
+		logger.InfoContext(ctx, "job exported", "holders", rowCount, "file", filePath)
+	}
+
+	if err := ss.jobRepo.Update(ctx, job); err != nil {
+		logger.ErrorContext(ctx, "job result update failed", "error", err)
+	}
+}
+
+// Cleanup expires the jobs finished before a time and deletes their files,
+// along with files of the output directory last written before it, such as partial exports of a crashed process
+func (ss *SnapshotService) Cleanup(ctx context.Context, before time.Time) {
+	jobs, err := ss.jobRepo.ListFinishedBefore(ctx, before)
+	if err != nil {
+		ss.logger.ErrorContext(ctx, "listing expired jobs failed", "error", err)
+		return
+	}
+	for _, job := range jobs {
+		if err := os.Remove(job.FilePath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			ss.logger.WarnContext(ctx, "snapshot file removal failed", "job_id", job.ID, "error", err)
+			continue
+		}
+		job.Status = domain.SnapshotStatusExpired
+		job.FilePath = ""
+		if err := ss.jobRepo.Update(ctx, job); err != nil {
+			ss.logger.ErrorContext(ctx, "job expiry update failed", "job_id", job.ID, "error", err)
+		}
+	}
+
+	entries, err := os.ReadDir(ss.outputDir)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			ss.logger.WarnContext(ctx, "reading output dir failed", "error", err)
+		}
+		return
+	}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || entry.IsDir() || !strings.HasPrefix(entry.Name(), "snapshot-") || !info.ModTime().Before(before) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(ss.outputDir, entry.Name())); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			ss.logger.WarnContext(ctx, "leftover snapshot file removal failed", "file", entry.Name(), "error", err)
+		}
+	}
+}
+
+// exportToFile writes the export to <outputDir>/snapshot-<id>.<format>, removing partial files on failure
+func (ss *SnapshotService) exportToFile(ctx context.Context, job *domain.SnapshotJob, req SnapshotRequest) (int64, string, error) {
+	if err := os.MkdirAll(ss.outputDir, 0o755); err != nil {
+		return 0, "", fmt.Errorf("create output dir: %w", err)
+	}
+
+	filePath := filepath.Join(ss.outputDir, "snapshot-"+strconv.FormatInt(job.ID, 10)+"."+job.Format)
+	file, err := os.Create(filePath)
+	if err != nil {
+		return 0, "", fmt.Errorf("create output file: %w", err)
+	}
+
+	rowCount, err := ss.Export(ctx, req, file)
+	if closeErr := file.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("close output file: %w", closeErr)
+	}
+	if err != nil {
+		os.Remove(filePath)
+		return rowCount, "", err
+	}
+	return rowCount, filePath, nil
+}
+
+// snapshotWriter encodes exported holders in a single format
+type snapshotWriter interface {
+	Write(holder *SnapshotHolder) error
+	Close() error
+}
+
+// newSnapshotWriter returns the writer for an export format
+func newSnapshotWriter(format string, w io.Writer) (snapshotWriter, error) {
+	switch format {
+	case SnapshotFormatCSV:
+		csvWriter := csv.NewWriter(w)
+		if err := csvWriter.Write([]string{"address", "token_path", "amount", "last_block_height"}); err != nil {
+			return nil, fmt.Errorf("write csv header: %w", err)
+		}
+		return &csvSnapshotWriter{w: csvWriter}, nil
+	case SnapshotFormatJSONL:
+		return &jsonlSnapshotWriter{enc: json.NewEncoder(w)}, nil
+	case SnapshotFormatParquet:
+		return &parquetSnapshotWriter{w: parquet.NewGenericWriter[SnapshotHolder](w)}, nil
+	default:
+		return nil, ErrInvalidSnapshotFormat
+	}
+}
+
+// csvSnapshotWriter writes one CSV record per holder
+type csvSnapshotWriter struct {
+	w *csv.Writer
+}
+
+func (cw *csvSnapshotWriter) Write(holder *SnapshotHolder) error {
+	return cw.w.Write([]string{
+		holder.Address,
+		holder.TokenPath,
+		holder.Amount,
+		strconv.FormatInt(holder.LastBlockHeight, 10),
+	})
+}
+
+func (cw *csvSnapshotWriter) Close() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+// jsonlSnapshotWriter writes one JSON object per line
+type jsonlSnapshotWriter struct {
+	enc *json.Encoder
+}
+
+func (jw *jsonlSnapshotWriter) Write(holder *SnapshotHolder) error {
+	return jw.enc.Encode(holder)
+}
+
+func (jw *jsonlSnapshotWriter) Close() error {
+	return nil
+}
+
+// parquetSnapshotWriter buffers rows into parquet row groups
+type parquetSnapshotWriter struct {
+	w *parquet.GenericWriter[SnapshotHolder]
+}
+
+func (pw *parquetSnapshotWriter) Write(holder *SnapshotHolder) error {
+	_, err := pw.w.Write([]SnapshotHolder{*holder})
+	return err
+}
+
+func (pw *parquetSnapshotWriter) Close() error {
+	return pw.w.Close()
+}
+
+// normalizeAddresses lowercases valid addresses so they match the stored canonical form
+func normalizeAddresses(addresses []string) []string {
+	normalized := make([]string, len(addresses))
+	for i, address := range addresses {
+		normalized[i] = domain.NormalizeAddress(address)
+	}
+	return normalized
+}
@@ -0,0 +1,274 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gn-indexer/internal/client"
+	"gn-indexer/internal/domain"
+	"gn-indexer/internal/logging"
+	"gn-indexer/internal/repository"
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+)
+
+// pendingMetadataInterval is how often tokens without metadata are retried
+const pendingMetadataInterval = 30 * time.Second
+
+// ErrMetadataNotFound is returned when a resolver has no metadata for a token
+var ErrMetadataNotFound = errors.New("token metadata not found")
+
+// TokenMetadata holds resolved GRC20 metadata, empty fields were not resolved
+type TokenMetadata struct {
+	Symbol      string `json:"symbol"`
+	Name        string `json:"name"`
+	Decimals    *int   `json:"decimals"` // nil when unknown, a token can have 0 decimals
+	TotalSupply string `json:"totalSupply"`
+}
+
+// MetadataResolver resolves metadata for a token path
+type MetadataResolver interface {
+	Resolve(ctx context.Context, tokenPath string) (*TokenMetadata, error)
+}
+
+// StaticMetadataResolver serves metadata from a JSON file keyed by token path
+type StaticMetadataResolver struct {
+	entries map[string]TokenMetadata
+}
+
+// LoadStaticMetadataResolver loads a JSON file like {"gno.land/r/demo/foo": {"symbol": "FOO", ...}}
+func LoadStaticMetadataResolver(path string) (*StaticMetadataResolver, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read metadata file: %w", err)
+	}
+
+	entries := make(map[string]TokenMetadata)
+	if err := json.Unmarshal(raw, &entries); err != nil {
+		return nil, fmt.Errorf("decode metadata file: %w", err)
+	}
+
+	return &StaticMetadataResolver{entries: entries}, nil
+}
+
+// Resolve returns the override entry for a token path
+func (r *StaticMetadataResolver) Resolve(ctx context.Context, tokenPath string) (*TokenMetadata, error) {
+	entry, ok := r.entries[tokenPath]
+	if !ok {
+		return nil, ErrMetadataNotFound
+	}
+	return &entry, nil
+}
+
+// RPCMetadataResolver reads metadata from the realm itself via vm/qeval
+type RPCMetadataResolver struct {
+	rpcClient *client.GnoRPCClient
+}
+
+// NewRPCMetadataResolver creates a new RPC metadata resolver
+func NewRPCMetadataResolver(rpcClient *client.GnoRPCClient) *RPCMetadataResolver {
+	return &RPCMetadataResolver{rpcClient: rpcClient}
+}
+
+// Resolve queries the GRC20 getters exposed by the token realm.
+// A realm may lack some getters, the fields that resolved are returned and the error only reports that none did.
+func (r *RPCMetadataResolver) Resolve(ctx context.Context, tokenPath string) (*TokenMetadata, error) {
+	var (
+		metadata TokenMetadata
+		errs     []error
+	)
+
+	symbol, err := r.rpcClient.QEvalString(ctx, tokenPath, "GetSymbol()")
+	if err != nil {
+		errs = append(errs, fmt.Errorf("query symbol: %w", err))
+	}
+	metadata.Symbol = symbol
+
+	name, err := r.rpcClient.QEvalString(ctx, tokenPath, "GetName()")
+	if err != nil {
+		errs = append(errs, fmt.Errorf("query name: %w", err))
+	}
+	metadata.Name = name
+
+	if decimalsStr, err := r.rpcClient.QEvalNumber(ctx, tokenPath, "GetDecimals()"); err != nil {
+		errs = append(errs, fmt.Errorf("query decimals: %w", err))
+	} else if decimals, err := strconv.Atoi(decimalsStr); err != nil {
+		errs = append(errs, fmt.Errorf("invalid decimals %s: %w", decimalsStr, err))
+	} else {
+		metadata.Decimals = &decimals
+	}
+
+	totalSupply, err := r.rpcClient.QEvalNumber(ctx, tokenPath, "TotalSupply()")
+	if err != nil {
+		errs = append(errs, fmt.Errorf("query total supply: %w", err))
+	}
+	metadata.TotalSupply = totalSupply
+
+	if len(errs) == 4 {
+		return nil, errors.Join(errs...)
+	}
+	return &metadata, nil
+}
+
+// TokenMetadataService fills token symbol, name, decimals and total supply
+type TokenMetadataService struct {
+	tokenRepo repository.TokenRepository
+	resolvers []MetadataResolver
+	logger    *slog.Logger
+}
+
+// NewTokenMetadataService creates a new token metadata service.
+// Resolvers are merged in order, so realm queries should come first and a static file last to fill what they miss.
+func NewTokenMetadataService(tokenRepo repository.TokenRepository, resolvers ...MetadataResolver) *TokenMetadataService {
+	return &TokenMetadataService{
+		tokenRepo: tokenRepo,
+		resolvers: resolvers,
+		logger:    logging.For("token-metadata"),
+	}
+}
+
+// Start resolves pending tokens frequently and refreshes every token on refreshInterval
+func (tms *TokenMetadataService) Start(ctx context.Context, refreshInterval time.Duration) error {
+	tms.logger.Info("starting", "refresh_interval", refreshInterval)
+
+	if err := tms.RefreshAll(ctx); err != nil {
+		tms.logger.Error("initial refresh failed", "error", err)
+	}
+
+	pendingTicker := time.NewTicker(pendingMetadataInterval)
+	defer pendingTicker.Stop()
+	refreshTicker := time.NewTicker(refreshInterval)
+	defer refreshTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			tms.logger.Info("context cancelled, stopping")
+			return ctx.Err()
+		case <-pendingTicker.C:
+			if err := tms.RefreshPending(ctx); err != nil {
+				tms.logger.Error("pending refresh failed", "error", err)
+			}
+		case <-refreshTicker.C:
+			if err := tms.RefreshAll(ctx); err != nil {
+				tms.logger.Error("refresh failed", "error", err)
+			}
+		}
+	}
+}
+
+// RefreshPending resolves tokens that have never been resolved
+func (tms *TokenMetadataService) RefreshPending(ctx context.Context) error {
+	tokens, err := tms.tokenRepo.GetPendingMetadata(ctx)
+	if err != nil {
+		return fmt.Errorf("get pending tokens: %w", err)
+	}
+	return tms.refreshTokens(ctx, tokens)
+}
+
+// RefreshAll re-resolves every known token, total supply changes with mint and burn
+func (tms *TokenMetadataService) RefreshAll(ctx context.Context) error {
+	tokens, err := tms.tokenRepo.GetAll(ctx)
+	if err != nil {
+		return fmt.Errorf("get tokens: %w", err)
+	}
+	return tms.refreshTokens(ctx, tokens)
+}
+
+// refreshTokens resolves a list of tokens, a single failure does not stop the others
+func (tms *TokenMetadataService) refreshTokens(ctx context.Context, tokens []domain.Token) error {
+	resolvedCount := 0
+	for i := range tokens {
+		if err := tms.RefreshToken(ctx, &tokens[i]); err != nil {
+			tms.logger.WarnContext(ctx, "token not resolved", "token", tokens[i].Path, "error", err)
+			continue
+		}
+		resolvedCount++
+	}
+
+	if len(tokens) > 0 {
+		tms.logger.InfoContext(ctx, "tokens resolved", "resolved", resolvedCount, "total", len(tokens))
+	}
+	return nil
+}
+
+// RefreshToken resolves and stores metadata for a single token
+func (tms *TokenMetadataService) RefreshToken(ctx context.Context, token *domain.Token) error {
+	metadata, err := tms.resolve(ctx, token.Path)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	token.Symbol = metadata.Symbol
+	token.Name = metadata.Name
+	if metadata.Decimals != nil {
+		token.Decimals = *metadata.Decimals
+	}
+	token.MetadataUpdatedAt = &now
+	token.TotalSupply = nil
+	if metadata.TotalSupply != "" {
+		totalSupply, err := domain.NewU64FromString(metadata.TotalSupply)
+		if err != nil {
+			return fmt.Errorf("parse total supply: %w", err)
+		}
+		token.TotalSupply = totalSupply
+	}
+
+	if err := tms.tokenRepo.UpdateMetadata(ctx, token); err != nil {
+		return fmt.Errorf("update metadata: %w", err)
+	}
+	return nil
+}
+
+// resolve merges the metadata of every resolver that knows the token,
+// earlier resolvers win and later ones only fill the fields still missing
+func (tms *TokenMetadataService) resolve(ctx context.Context, tokenPath string) (*TokenMetadata, error) {
+	var (
+		merged  *TokenMetadata
+		lastErr error
+	)
+	for _, resolver := range tms.resolvers {
+		metadata, err := resolver.Resolve(ctx, tokenPath)
+		if err != nil {
+			// Keep the failure of a resolver over "not found" from another one
+			if lastErr == nil || !errors.Is(err, ErrMetadataNotFound) {
+				lastErr = err
+			}
+			continue
+		}
+		if merged == nil {
+			resolved := *metadata
+			merged = &resolved
+			continue
+		}
+		merged.fillMissing(metadata)
+	}
+
+	if merged == nil {
+		if lastErr == nil {
+			lastErr = ErrMetadataNotFound
+		}
+		return nil, lastErr
+	}
+	return merged, nil
+}
+
+// fillMissing copies the fields of other that m lacks
+func (m *TokenMetadata) fillMissing(other *TokenMetadata) {
+	if m.Symbol == "" {
+		m.Symbol = other.Symbol
+	}
+	if m.Name == "" {
+		m.Name = other.Name
+	}
+	if m.Decimals == nil {
+		m.Decimals = other.Decimals
+	}
+	if m.TotalSupply == "" {
+		m.TotalSupply = other.TotalSupply
+	}
+}
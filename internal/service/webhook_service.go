@@ -0,0 +1,290 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gn-indexer/internal/domain"
+	"gn-indexer/internal/logging"
+	"gn-indexer/internal/repository"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Webhook request headers
+const (
+	WebhookSignatureHeader = "X-Indexer-Signature" // sha256=<hex HMAC of "<timestamp>.<body>">
+	WebhookTimestampHeader = "X-Indexer-Timestamp" // unix seconds, part of the signed message
+	WebhookDeliveryHeader  = "X-Indexer-Delivery"  // delivery id, stable across retries
+)
+
+const (
+	// webhookMaxAttempts is the number of attempts before a delivery is marked failed
+	webhookMaxAttempts = 8
+	// webhookBaseBackoff is the delay before the first retry, doubled on every attempt
+	webhookBaseBackoff = 10 * time.Second
+	// webhookCacheTTL is how long the active webhook list is reused between database reads
+	webhookCacheTTL = 30 * time.Second
+	// webhookPollInterval is how often due deliveries are sent
+	webhookPollInterval = time.Second
+	// webhookBatchSize is the maximum number of deliveries sent per poll
+	webhookBatchSize = 50
+)
+
+// ErrNonPublicAddress is returned when a webhook target resolves to a private, loopback or otherwise non-public address
+var ErrNonPublicAddress = errors.New("not a public address")
+
+// WebhookPayload is the JSON body posted to webhook endpoints
+type WebhookPayload struct {
+	WebhookID int64                `json:"webhookId"`
+	Event     *domain.Notification `json:"event"`
+}
+
+// WebhookService queues transfer notifications for matching webhooks and delivers them with retries
+type WebhookService struct {
+	webhookRepo repository.WebhookRepository
+	httpClient  *http.Client
+
+	mu          sync.Mutex
+	webhooks    []*domain.Webhook
+	refreshedAt time.Time
+	logger      *slog.Logger
+}
+
+// NewWebhookService creates a new webhook service delivering with client, see NewWebhookClient
+func NewWebhookService(webhookRepo repository.WebhookRepository, client *http.Client) *WebhookService {
+	return &WebhookService{
+		webhookRepo: webhookRepo,
+		httpClient:  client,
+		logger:      logging.For("webhook"),
+	}
+}
+
+// NewWebhookClient returns the delivery client, timeout bounds each delivery.
+// It connects only to public addresses, checked on the resolved address of every connection so
+// DNS rebinding cannot reach the private network, and treats redirects as failures.
+func NewWebhookClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !PublicIP(ip) {
+				return fmt.Errorf("%w: %s", ErrNonPublicAddress, host)
+			}
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// A proxy would be the dialed address and hide the target from the check
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		// A redirect could lead to an address the registration check rejected, 3xx counts as a failure
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+}
+
+// nonPublicNetworks are special-purpose ranges not covered by the net.IP predicates
+var nonPublicNetworks = func() []*net.IPNet {
+	var networks []*net.IPNet
+	for _, cidr := range []string{
+		"100.64.0.0/10",  // carrier-grade NAT
+		"192.0.0.0/24",   // IETF protocol assignments
+		"198.18.0.0/15",  // benchmarking
+		"64:ff9b::/96",   // NAT64, embeds IPv4 addresses
+		"64:ff9b:1::/48", // local-use NAT64
+		"fc00::/7",       // unique local addresses
+	} {
+		_, network, _ := net.ParseCIDR(cidr)
+		networks = append(networks, network)
+	}
+	return networks
+}()
+
+// PublicIP reports whether an address is routable on the internet, webhooks may only target public addresses
+func PublicIP(ip net.IP) bool {
+	if ip.IsUnspecified() || ip.IsLoopback() || ip.IsPrivate() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	for _, network := range nonPublicNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// SignWebhookPayload returns the signature header value for a payload
+func SignWebhookPayload(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Publish queues a delivery for every active webhook matching a transfer notification.
+// It is the outbox of the balance service and runs in the transaction of the balance change.
+func (ws *WebhookService) Publish(ctx context.Context, notification *domain.Notification) error {
+	if notification.Kind != domain.NotificationTransfer {
+		return nil
+	}
+
+	webhooks, err := ws.activeWebhooks(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, webhook := range webhooks {
+		if !webhook.Matches(notification) {
+			continue
+		}
+
+		payload, err := json.Marshal(WebhookPayload{WebhookID: webhook.ID, Event: notification})
+		if err != nil {
+			return fmt.Errorf("marshal webhook payload: %w", err)
+		}
+
+		delivery := &domain.WebhookDelivery{
+			WebhookID:     webhook.ID,
+			Payload:       string(payload),
+			Status:        domain.DeliveryStatusPending,
+			NextAttemptAt: time.Now(),
+		}
+		if err := ws.webhookRepo.CreateDelivery(ctx, delivery); err != nil {
+			return fmt.Errorf("queue delivery for webhook %d: %w", webhook.ID, err)
+		}
+	}
+	return nil
+}
+
+// activeWebhooks returns the cached active webhooks, reloading them after webhookCacheTTL
+func (ws *WebhookService) activeWebhooks(ctx context.Context) ([]*domain.Webhook, error) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	if ws.webhooks != nil && time.Since(ws.refreshedAt) < webhookCacheTTL {
+		return ws.webhooks, nil
+	}
+
+	webhooks, err := ws.webhookRepo.ListActive(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load webhooks: %w", err)
+	}
+	ws.webhooks = webhooks
+	ws.refreshedAt = time.Now()
+	return webhooks, nil
+}
+
+// Start sends due deliveries until the context is cancelled
+func (ws *WebhookService) Start(ctx context.Context) error {
+	ws.logger.Info("starting delivery loop")
+
+	ticker := time.NewTicker(webhookPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			ws.logger.Info("context cancelled, stopping")
+			return ctx.Err()
+		case <-ticker.C:
+			if err := ws.DeliverDue(ctx); err != nil {
+				ws.logger.Error("delivery failed", "error", err)
+			}
+		}
+	}
+}
+
+// DeliverDue claims and sends the pending deliveries whose next attempt is due
+func (ws *WebhookService) DeliverDue(ctx context.Context) error {
+	// The lease outlasts a batch of deliveries that all time out
+	lease := ws.httpClient.Timeout*webhookBatchSize + time.Minute
+	deliveries, err := ws.webhookRepo.ClaimDueDeliveries(ctx, time.Now(), lease, webhookBatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, delivery := range deliveries {
+		webhook, err := ws.webhookRepo.GetByID(ctx, delivery.WebhookID)
+		if err != nil {
+			ws.logger.Warn("delivery has no webhook", "delivery_id", delivery.ID, "error", err)
+			continue
+		}
+
+		ws.attempt(ctx, webhook, delivery)
+		if err := ws.webhookRepo.UpdateDelivery(ctx, delivery); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// attempt posts a delivery once and schedules the next retry on failure
+func (ws *WebhookService) attempt(ctx context.Context, webhook *domain.Webhook, delivery *domain.WebhookDelivery) {
+	delivery.Attempts++
+
+	statusCode, err := ws.send(ctx, webhook, delivery)
+	delivery.LastStatusCode = statusCode
+	if err == nil {
+		now := time.Now()
+		delivery.Status = domain.DeliveryStatusSucceeded
+		delivery.DeliveredAt = &now
+		delivery.LastError = ""
+		return
+	}
+
+	delivery.LastError = err.Error()
+	if delivery.Attempts >= webhookMaxAttempts {
+		delivery.Status = domain.DeliveryStatusFailed
+		ws.logger.WarnContext(ctx, "delivery failed, giving up",
+			"delivery_id", delivery.ID, "url", webhook.URL, "attempts", delivery.Attempts, "error", err)
+		return
+	}
+	delivery.NextAttemptAt = time.Now().Add(webhookBaseBackoff << (delivery.Attempts - 1))
+}
+
+// send posts the signed payload, any non-2xx response is an error
+func (ws *WebhookService) send(ctx context.Context, webhook *domain.Webhook, delivery *domain.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(WebhookDeliveryHeader, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(webhook.Secret, timestamp, body))
+
+	res, err := ws.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 4096))
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return res.StatusCode, fmt.Errorf("http %d", res.StatusCode)
+	}
+	return res.StatusCode, nil
+}
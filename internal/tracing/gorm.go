@@ -0,0 +1,73 @@
+package tracing
+
+import (
+	"errors"
+
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// gormSpanKey stores the span of a statement between its before and after callbacks
+const gormSpanKey = "tracing:span"
+
+// GormPlugin wraps every GORM statement in a client span named after its operation, e.g. db.create
+type GormPlugin struct{}
+
+// Name implements gorm.Plugin
+func (GormPlugin) Name() string {
+	return "tracing"
+}
+
+// Initialize registers the span callbacks around each GORM operation
+func (GormPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	return errors.Join(
+		cb.Create().Before("gorm:create").Register("tracing:before_create", beforeStatement("db.create")),
+		cb.Create().After("gorm:create").Register("tracing:after_create", afterStatement),
+		cb.Query().Before("gorm:query").Register("tracing:before_query", beforeStatement("db.query")),
+		cb.Query().After("gorm:query").Register("tracing:after_query", afterStatement),
+		cb.Update().Before("gorm:update").Register("tracing:before_update", beforeStatement("db.update")),
+		cb.Update().After("gorm:update").Register("tracing:after_update", afterStatement),
+		cb.Delete().Before("gorm:delete").Register("tracing:before_delete", beforeStatement("db.delete")),
+		cb.Delete().After("gorm:delete").Register("tracing:after_delete", afterStatement),
+		cb.Row().Before("gorm:row").Register("tracing:before_row", beforeStatement("db.row")),
+		cb.Row().After("gorm:row").Register("tracing:after_row", afterStatement),
+		cb.Raw().Before("gorm:raw").Register("tracing:before_raw", beforeStatement("db.raw")),
+		cb.Raw().After("gorm:raw").Register("tracing:after_raw", afterStatement),
+	)
+}
+
+// beforeStatement starts the span of a statement, nested statements become its children
+func beforeStatement(name string) func(*gorm.DB) {
+	return func(tx *gorm.DB) {
+		if tx.Statement.Context == nil {
+			return
+		}
+		ctx, span := StartKind(tx.Statement.Context, name, trace.SpanKindClient,
+			semconv.DBSystemKey.String(tx.Dialector.Name()),
+			semconv.DBCollectionName(tx.Statement.Table),
+		)
+		tx.Statement.Context = ctx
+		tx.InstanceSet(gormSpanKey, span)
+	}
+}
+
+// afterStatement ends the span of a statement, a missing record is not a failure
+func afterStatement(tx *gorm.DB) {
+	value, ok := tx.InstanceGet(gormSpanKey)
+	if !ok {
+		return
+	}
+	span := value.(trace.Span)
+	span.SetAttributes(
+		semconv.DBQueryText(tx.Statement.SQL.String()),
+		semconv.DBCollectionName(tx.Statement.Table),
+	)
+
+	err := tx.Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	End(span, err)
+}
@@ -0,0 +1,28 @@
+package types
+
+// SnapshotJobRequest represents the body of POST /snapshots
+type SnapshotJobRequest struct {
+	TokenPath string   `json:"tokenPath"`
+	AtHeight  *int64   `json:"atHeight,omitempty"`
+	AtTime    string   `json:"atTime,omitempty"` // RFC3339, mapped to the last block at or before it
+	MinAmount string   `json:"minAmount,omitempty"`
+	Exclude   []string `json:"exclude,omitempty"`
+	Format    string   `json:"format"` // csv, jsonl or parquet
+}
+
+// WebhookRequest represents the body of POST /webhooks
+type WebhookRequest struct {
+	URL       string `json:"url"`
+	Address   string `json:"address,omitempty"`
+	TokenPath string `json:"tokenPath,omitempty"`
+	MinAmount string `json:"minAmount,omitempty"`
+	Secret    string `json:"secret,omitempty"` // generated when empty
+}
+
+// APIKeyRequest represents the body of POST /admin/keys, zero limits take the server defaults
+type APIKeyRequest struct {
+	Name       string  `json:"name"`
+	RateLimit  float64 `json:"rateLimit,omitempty"`  // requests per second
+	Burst      int     `json:"burst,omitempty"`      // requests allowed at once
+	DailyQuota int64   `json:"dailyQuota,omitempty"` // requests per UTC day, 0 means unlimited
+}
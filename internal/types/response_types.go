@@ -1,6 +1,21 @@
 package types
 
-import "gn-indexer/internal/domain"
+import (
+	"gn-indexer/internal/domain"
+	"time"
+)
+
+// ErrorResponse is the envelope of every API error
+type ErrorResponse struct {
+	Error ErrorBody `json:"error"`
+}
+
+// ErrorBody describes an API error, Code is stable and meant for clients to branch on
+type ErrorBody struct {
+	Code    string                 `json:"code"`
+	Message string                 `json:"message"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
 
 // BlocksData represents block subscription response data (single block)
 type BlocksData struct {
@@ -19,18 +34,45 @@ type TxsData struct {
 
 // BalanceResponse represents the response for /tokens/balances endpoint
 type BalanceResponse struct {
-	Balances []TokenBalance `json:"balances"`
+	Balances   []TokenBalance `json:"balances"`
+	Pagination *Pagination    `json:"pagination,omitempty"`
+	AtHeight   *int64         `json:"atHeight,omitempty"` // set for historical queries
+}
+
+// Pagination describes the page returned by a page/limit endpoint
+type Pagination struct {
+	Page  int   `json:"page"`
+	Limit int   `json:"limit"`
+	Total int64 `json:"total"`
 }
 
 // TokenBalance represents a single token balance
 type TokenBalance struct {
 	TokenPath string `json:"tokenPath"`
 	Amount    int64  `json:"amount"`
+	DecimalAmount
+}
+
+// DecimalAmount holds the decimal-formatted amount returned when format=decimal is requested
+type DecimalAmount struct {
+	AmountDecimal string `json:"amountDecimal,omitempty"`
+	Symbol        string `json:"symbol,omitempty"`
+	Decimals      *int   `json:"decimals,omitempty"`
 }
 
 // AccountBalanceResponse represents the response for /tokens/{tokenPath}/balances endpoint
 type AccountBalanceResponse struct {
 	AccountBalances []AccountBalance `json:"accountBalances"`
+	Pagination      *Pagination      `json:"pagination,omitempty"`
+	Summary         *HolderSummary   `json:"summary,omitempty"`
+	AtHeight        *int64           `json:"atHeight,omitempty"` // set for historical queries
+}
+
+// HolderSummary represents the holder count and summed balances of a token
+type HolderSummary struct {
+	HolderCount int64  `json:"holderCount"`
+	TotalSupply string `json:"totalSupply"` // sum of all balances, in raw units
+	DecimalAmount
 }
 
 // AccountBalance represents a single account balance for a specific token
@@ -38,11 +80,15 @@ type AccountBalance struct {
 	Address   string `json:"address"`
 	TokenPath string `json:"tokenPath"`
 	Amount    int64  `json:"amount"`
+	Rank      int    `json:"rank,omitempty"` // position by amount, set when sort=amount
+	DecimalAmount
 }
 
 // TransferHistoryResponse represents the response for /tokens/transfer-history endpoint
 type TransferHistoryResponse struct {
-	Transfers []TransferRecord `json:"transfers"`
+	Transfers  []TransferRecord `json:"transfers"`
+	NextCursor string           `json:"nextCursor,omitempty"`
+	Next       string           `json:"next,omitempty"`
 }
 
 // TransferRecord represents a single transfer record
@@ -51,4 +97,236 @@ type TransferRecord struct {
 	ToAddress   string `json:"toAddress"`
 	TokenPath   string `json:"tokenPath"`
 	Amount      int64  `json:"amount"`
+	TxHash      string `json:"txHash"`
+	BlockHeight int64  `json:"blockHeight"`
+	DecimalAmount
+}
+
+// BlockRecord represents a single block
+type BlockRecord struct {
+	Height        int       `json:"height"`
+	Hash          string    `json:"hash"`
+	LastBlockHash string    `json:"lastBlockHash"`
+	Time          time.Time `json:"time"`
+	NumTxs        int       `json:"numTxs"`
+	TotalTxs      int       `json:"totalTxs"`
+}
+
+// BlockListResponse represents the response for /blocks endpoint
+type BlockListResponse struct {
+	Blocks     []BlockRecord `json:"blocks"`
+	NextCursor string        `json:"nextCursor,omitempty"`
+	Next       string        `json:"next,omitempty"`
+}
+
+// TransactionRecord represents a single transaction without its payload
+type TransactionRecord struct {
+	Hash        string `json:"hash"`
+	BlockHeight int    `json:"blockHeight"`
+	Index       int    `json:"index"`
+	Success     bool   `json:"success"`
+	GasWanted   int64  `json:"gasWanted"`
+	GasUsed     int64  `json:"gasUsed"`
+	Memo        string `json:"memo"`
+}
+
+// TransactionListResponse represents the response for /txs and /blocks/{height}/txs endpoints
+type TransactionListResponse struct {
+	Transactions []TransactionRecord `json:"transactions"`
+	NextCursor   string              `json:"nextCursor,omitempty"`
+	Next         string              `json:"next,omitempty"`
+}
+
+// TransactionDetailResponse represents the response for /txs/{hash} endpoint
+type TransactionDetailResponse struct {
+	TransactionRecord
+	GasFee   *domain.GasFee              `json:"gasFee"`
+	Messages []domain.TransactionMessage `json:"messages"`
+	Events   []EventRecord               `json:"events"`
+}
+
+// EventRecord represents a single transaction event with its attributes
+type EventRecord struct {
+	ID      int64             `json:"id"`
+	TxHash  string            `json:"txHash"`
+	Index   int               `json:"index"`
+	Type    string            `json:"type"`
+	Func    string            `json:"func"`
+	PkgPath string            `json:"pkgPath"`
+	Attrs   []EventAttrRecord `json:"attrs"`
+}
+
+// EventAttrRecord represents a single event attribute
+type EventAttrRecord struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// EventListResponse represents the response for /events endpoint
+type EventListResponse struct {
+	Events     []EventRecord `json:"events"`
+	NextCursor string        `json:"nextCursor,omitempty"`
+	Next       string        `json:"next,omitempty"`
+}
+
+// AllowanceResponse represents the response for /tokens/allowances endpoint
+type AllowanceResponse struct {
+	Allowances []AllowanceRecord `json:"allowances"`
+	Pagination *Pagination       `json:"pagination,omitempty"`
+}
+
+// AllowanceRecord represents a single allowance granted by an owner to a spender
+type AllowanceRecord struct {
+	Owner     string `json:"owner"`
+	Spender   string `json:"spender"`
+	TokenPath string `json:"tokenPath"`
+	Amount    int64  `json:"amount"`
+}
+
+// NFTOwnershipResponse represents the response for /nfts endpoint
+type NFTOwnershipResponse struct {
+	NFTs []NFTRecord `json:"nfts"`
+}
+
+// NFTRecord represents a single GRC721 token held by an address
+type NFTRecord struct {
+	CollectionPath string `json:"collectionPath"`
+	TokenID        string `json:"tokenId"`
+	Owner          string `json:"owner"`
+	LastBlockH     int64  `json:"lastBlockHeight"`
+}
+
+// NFTTransferResponse represents the response for /nfts/provenance and /nfts/transfers endpoints
+type NFTTransferResponse struct {
+	Transfers []NFTTransferRecord `json:"transfers"`
+}
+
+// NFTTransferRecord represents a single GRC721 mint, burn or transfer
+type NFTTransferRecord struct {
+	CollectionPath string `json:"collectionPath"`
+	TokenID        string `json:"tokenId"`
+	FromAddress    string `json:"fromAddress"`
+	ToAddress      string `json:"toAddress"`
+	TxHash         string `json:"txHash"`
+	BlockHeight    int64  `json:"blockHeight"`
+}
+
+// TokenListResponse represents the response for /tokens endpoint
+type TokenListResponse struct {
+	Tokens []TokenInfo `json:"tokens"`
+}
+
+// TokenInfo represents token metadata
+type TokenInfo struct {
+	TokenPath   string `json:"tokenPath"`
+	Symbol      string `json:"symbol"`
+	Name        string `json:"name"`
+	Decimals    int    `json:"decimals"`
+	TotalSupply string `json:"totalSupply,omitempty"`
+}
+
+// SnapshotJobResponse represents the state of a token holder snapshot job
+type SnapshotJobResponse struct {
+	ID          int64      `json:"id"`
+	TokenPath   string     `json:"tokenPath"`
+	AtHeight    *int64     `json:"atHeight,omitempty"`
+	Format      string     `json:"format"`
+	Status      string     `json:"status"`
+	RowCount    int64      `json:"rowCount"`
+	Error       string     `json:"error,omitempty"`
+	DownloadURL string     `json:"downloadUrl,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	FinishedAt  *time.Time `json:"finishedAt,omitempty"`
+}
+
+// WebhookResponse represents a registered webhook, Secret is only returned on creation
+type WebhookResponse struct {
+	ID        int64     `json:"id"`
+	URL       string    `json:"url"`
+	Address   string    `json:"address,omitempty"`
+	TokenPath string    `json:"tokenPath,omitempty"`
+	MinAmount string    `json:"minAmount,omitempty"`
+	Active    bool      `json:"active"`
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// WebhookListResponse represents the response for GET /webhooks
+type WebhookListResponse struct {
+	Webhooks []WebhookResponse `json:"webhooks"`
+}
+
+// WebhookDeliveryRecord represents a single entry of the webhook delivery log
+type WebhookDeliveryRecord struct {
+	ID             int64      `json:"id"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	LastStatusCode int        `json:"lastStatusCode,omitempty"`
+	LastError      string     `json:"lastError,omitempty"`
+	NextAttemptAt  time.Time  `json:"nextAttemptAt"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	DeliveredAt    *time.Time `json:"deliveredAt,omitempty"`
+}
+
+// WebhookDeliveryResponse represents the response for GET /webhooks/{id}/deliveries
+type WebhookDeliveryResponse struct {
+	Deliveries []WebhookDeliveryRecord `json:"deliveries"`
+}
+
+// APIKeyResponse represents an API key, Key is only returned on creation
+type APIKeyResponse struct {
+	ID         int64      `json:"id"`
+	Name       string     `json:"name"`
+	KeyPrefix  string     `json:"keyPrefix"`
+	RateLimit  float64    `json:"rateLimit"`
+	Burst      int        `json:"burst"`
+	DailyQuota int64      `json:"dailyQuota"`
+	Key        string     `json:"key,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+}
+
+// APIKeyListResponse represents the response for GET /admin/keys
+type APIKeyListResponse struct {
+	Keys []APIKeyResponse `json:"keys"`
+}
+
+// APIKeyUsageRecord represents the usage of a key on one UTC day
+type APIKeyUsageRecord struct {
+	Day      string `json:"day"` // YYYY-MM-DD
+	Requests int64  `json:"requests"`
+	Rejected int64  `json:"rejected"`
+}
+
+// APIKeyUsageResponse represents the response for GET /admin/keys/{id}/usage
+type APIKeyUsageResponse struct {
+	KeyID int64               `json:"keyId"`
+	Usage []APIKeyUsageRecord `json:"usage"`
+}
+
+// HealthResponse represents the response for /healthz and /readyz
+type HealthResponse struct {
+	Status  string                 `json:"status"` // ok or unavailable
+	Service string                 `json:"service"`
+	Checks  map[string]HealthCheck `json:"checks"`
+	Sync    SyncStatus             `json:"sync"`
+	Reasons []string               `json:"reasons,omitempty"` // why the service is not ready
+}
+
+// HealthCheck represents the result of one dependency check
+type HealthCheck struct {
+	Status     string   `json:"status"` // ok or fail
+	LatencyMs  float64  `json:"latencyMs"`
+	LagSeconds *float64 `json:"lagSeconds,omitempty"` // how far a read replica trails the primary
+	Error      string   `json:"error,omitempty"`
+}
+
+// SyncStatus represents the processing progress of a service
+type SyncStatus struct {
+	ProcessedHeight     int64      `json:"processedHeight"`
+	NetworkHeight       int64      `json:"networkHeight,omitempty"`
+	LagBlocks           *int64     `json:"lagBlocks,omitempty"`
+	LastEventAt         *time.Time `json:"lastEventAt,omitempty"`
+	LastEventAgeSeconds *float64   `json:"lastEventAgeSeconds,omitempty"`
+	QueueBacklog        *int64     `json:"queueBacklog,omitempty"`
 }
@@ -0,0 +1,151 @@
+package api_test
+
+import (
+	"context"
+	"gn-indexer/internal/api"
+	"gn-indexer/internal/domain"
+	"gn-indexer/internal/service"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockAPIKeyRepository is a mock implementation of APIKeyRepository
+type MockAPIKeyRepository struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyRepository) Create(ctx context.Context, key *domain.APIKey) error {
+	return m.Called(ctx, key).Error(0)
+}
+
+func (m *MockAPIKeyRepository) GetByID(ctx context.Context, id int64) (*domain.APIKey, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) GetByHash(ctx context.Context, keyHash string) (*domain.APIKey, error) {
+	args := m.Called(ctx, keyHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) List(ctx context.Context) ([]*domain.APIKey, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]*domain.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) Revoke(ctx context.Context, id int64, at time.Time) error {
+	return m.Called(ctx, id, at).Error(0)
+}
+
+func (m *MockAPIKeyRepository) AddUsage(ctx context.Context, keyID int64, day time.Time, requests, rejected int64) error {
+	return m.Called(ctx, keyID, day, requests, rejected).Error(0)
+}
+
+func (m *MockAPIKeyRepository) GetUsage(ctx context.Context, keyID int64, fromDay time.Time) ([]*domain.APIKeyUsage, error) {
+	args := m.Called(ctx, keyID, fromDay)
+	return args.Get(0).([]*domain.APIKeyUsage), args.Error(1)
+}
+
+// newAuthRouter serves /health and /tokens behind the API key middleware
+func newAuthRouter(apiKeyRepo *MockAPIKeyRepository) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(api.NewAPIKeyAuth(service.NewAPIKeyService(apiKeyRepo)).Middleware())
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.GET("/health", ok)
+	router.GET("/tokens", ok)
+	return router
+}
+
+func TestAPIKeyAuth_RequiresKey(t *testing.T) {
+	// Setup
+	apiKeyRepo := new(MockAPIKeyRepository)
+	router := newAuthRouter(apiKeyRepo)
+
+	// Mock expectations
+	apiKeyRepo.On("GetByHash", mock.Anything, service.HashAPIKey("gnk_wrong")).Return(nil, service.ErrInvalidAPIKey)
+
+	// Execute
+	health := httptest.NewRecorder()
+	router.ServeHTTP(health, httptest.NewRequest("GET", "/health", nil))
+	missing := httptest.NewRecorder()
+	router.ServeHTTP(missing, httptest.NewRequest("GET", "/tokens", nil))
+	wrong := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/tokens", nil)
+	req.Header.Set(api.APIKeyHeader, "gnk_wrong")
+	router.ServeHTTP(wrong, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, health.Code)
+	assert.Equal(t, http.StatusUnauthorized, missing.Code)
+	assert.Contains(t, missing.Body.String(), api.ErrCodeUnauthorized)
+	assert.Equal(t, http.StatusUnauthorized, wrong.Code)
+}
+
+func TestAPIKeyAuth_RateLimitsPerKey(t *testing.T) {
+	// Setup
+	apiKeyRepo := new(MockAPIKeyRepository)
+	router := newAuthRouter(apiKeyRepo)
+	key := &domain.APIKey{ID: 1, KeyHash: service.HashAPIKey("gnk_partner"), RateLimit: 0.5, Burst: 2, DailyQuota: 100}
+
+	// Mock expectations
+	apiKeyRepo.On("GetByHash", mock.Anything, key.KeyHash).Return(key, nil)
+	apiKeyRepo.On("GetUsage", mock.Anything, int64(1), mock.Anything).Return([]*domain.APIKeyUsage{}, nil)
+
+	// Execute
+	var responses []*httptest.ResponseRecorder
+	for i := 0; i < 3; i++ {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/tokens?api_key=gnk_partner", nil))
+		responses = append(responses, w)
+	}
+
+	// Assert
+	assert.Equal(t, http.StatusOK, responses[0].Code)
+	assert.Equal(t, "99", responses[0].Header().Get("X-Quota-Remaining"))
+	assert.Equal(t, http.StatusOK, responses[1].Code)
+	assert.Equal(t, http.StatusTooManyRequests, responses[2].Code)
+	assert.Contains(t, responses[2].Body.String(), api.ErrCodeRateLimited)
+	assert.Equal(t, "2", responses[2].Header().Get("Retry-After"))
+}
+
+func TestAPIKeyAuth_QueryKeyKeepsAddressNormalization(t *testing.T) {
+	// Setup
+	apiKeyRepo := new(MockAPIKeyRepository)
+	key := &domain.APIKey{ID: 1, KeyHash: service.HashAPIKey("gnk_partner"), RateLimit: 10, Burst: 10, DailyQuota: 100}
+	w := httptest.NewRecorder()
+	newTestServer().ServeHTTP(w, httptest.NewRequest("GET", "/openapi.json", nil))
+	validator, err := api.NewRequestValidator(w.Body.Bytes())
+	assert.NoError(t, err)
+
+	router := gin.New()
+	router.Use(api.NewAPIKeyAuth(service.NewAPIKeyService(apiKeyRepo)).Middleware())
+	router.Use(validator.Middleware())
+	router.GET("/tokens/balances", func(c *gin.Context) {
+		c.String(http.StatusOK, c.Query("address"))
+	})
+
+	// Mock expectations
+	apiKeyRepo.On("GetByHash", mock.Anything, key.KeyHash).Return(key, nil)
+	apiKeyRepo.On("GetUsage", mock.Anything, int64(1), mock.Anything).Return([]*domain.APIKeyUsage{}, nil)
+
+	// Execute
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/tokens/balances?address=G1JG8MTUTU9KHHFWC4NXMUHCPFTF0PAJDHFVSQF5&api_key=gnk_partner", nil))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "g1jg8mtutu9khhfwc4nxmuhcpftf0pajdhfvsqf5", w.Body.String())
+}
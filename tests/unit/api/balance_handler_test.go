@@ -0,0 +1,127 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"gn-indexer/internal/api"
+	"gn-indexer/internal/domain"
+	"gn-indexer/internal/repository"
+	"gn-indexer/internal/types"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockBalanceRepository mocks the balance queries used by the API
+type MockBalanceRepository struct {
+	mock.Mock
+}
+
+func (m *MockBalanceRepository) Create(ctx context.Context, balance *domain.Balance) error {
+	return m.Called(ctx, balance).Error(0)
+}
+
+func (m *MockBalanceRepository) Update(ctx context.Context, balance *domain.Balance) error {
+	return m.Called(ctx, balance).Error(0)
+}
+
+func (m *MockBalanceRepository) GetBalance(ctx context.Context, tokenPath, address string) (*domain.Balance, error) {
+	args := m.Called(ctx, tokenPath, address)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Balance), args.Error(1)
+}
+
+func (m *MockBalanceRepository) GetBalancesByAddress(ctx context.Context, address string) ([]*domain.Balance, error) {
+	args := m.Called(ctx, address)
+	return args.Get(0).([]*domain.Balance), args.Error(1)
+}
+
+func (m *MockBalanceRepository) GetBalancesByTokenAndAddress(ctx context.Context, tokenPath string) ([]*domain.Balance, error) {
+	args := m.Called(ctx, tokenPath)
+	return args.Get(0).([]*domain.Balance), args.Error(1)
+}
+
+func (m *MockBalanceRepository) GetAllBalances(ctx context.Context) ([]*domain.Balance, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]*domain.Balance), args.Error(1)
+}
+
+func (m *MockBalanceRepository) ListBalances(ctx context.Context, query repository.BalanceQuery) ([]*domain.Balance, int64, error) {
+	args := m.Called(ctx, query)
+	return args.Get(0).([]*domain.Balance), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockBalanceRepository) GetTokenHolderSummary(ctx context.Context, tokenPath string) (*repository.TokenHolderSummary, error) {
+	args := m.Called(ctx, tokenPath)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.TokenHolderSummary), args.Error(1)
+}
+
+func (m *MockBalanceRepository) StreamBalances(ctx context.Context, query repository.BalanceQuery, fn func(*domain.Balance) error) error {
+	args := m.Called(ctx, query)
+	for _, balance := range args.Get(0).([]*domain.Balance) {
+		if err := fn(balance); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
+func TestBalanceHandler_GetBalancesByAddressPaginates(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockBalanceRepo := new(MockBalanceRepository)
+	handler := api.NewBalanceHandler(mockBalanceRepo, nil, nil, nil, nil)
+	router := gin.New()
+	router.GET("/tokens/balances", handler.GetBalancesByAddress)
+
+	// Mock expectations
+	mockBalanceRepo.On("ListBalances", mock.Anything, repository.BalanceQuery{
+		Address:     "test-address",
+		MinAmount:   domain.NewU64(10),
+		ExcludeZero: true,
+		Sort:        repository.BalanceSortAmount,
+		Offset:      2,
+		Limit:       2,
+	}).Return([]*domain.Balance{
+		{TokenPath: "gno.land/r/demo/foo", Address: "test-address", Amount: domain.NewU64(30)},
+	}, int64(3), nil)
+
+	// Execute
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/tokens/balances?address=test-address&page=2&limit=2&min_amount=10&exclude_zero=true", nil)
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response types.BalanceResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Len(t, response.Balances, 1)
+	assert.Equal(t, int64(30), response.Balances[0].Amount)
+	assert.Equal(t, &types.Pagination{Page: 2, Limit: 2, Total: 3}, response.Pagination)
+	mockBalanceRepo.AssertExpectations(t)
+}
+
+func TestBalanceHandler_GetBalancesByAddressRejectsInvalidSort(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	handler := api.NewBalanceHandler(new(MockBalanceRepository), nil, nil, nil, nil)
+	router := gin.New()
+	router.GET("/tokens/balances", handler.GetBalancesByAddress)
+
+	// Execute
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/tokens/balances?sort=random", nil)
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
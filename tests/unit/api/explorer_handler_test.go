@@ -0,0 +1,122 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"gn-indexer/internal/api"
+	"gn-indexer/internal/domain"
+	"gn-indexer/internal/types"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockBlockRepository mocks the block queries used by the API
+type MockBlockRepository struct {
+	mock.Mock
+}
+
+func (m *MockBlockRepository) SaveBlock(ctx context.Context, block domain.Block) error {
+	args := m.Called(ctx, block)
+	return args.Error(0)
+}
+
+func (m *MockBlockRepository) GetLastSyncedHeight(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockBlockRepository) GetBlockByHash(ctx context.Context, hash string) (*domain.Block, error) {
+	args := m.Called(ctx, hash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Block), args.Error(1)
+}
+
+func (m *MockBlockRepository) GetBlockByHeight(ctx context.Context, height int) (*domain.Block, error) {
+	args := m.Called(ctx, height)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Block), args.Error(1)
+}
+
+func (m *MockBlockRepository) GetHeightAtTime(ctx context.Context, at time.Time) (int64, error) {
+	args := m.Called(ctx, at)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockBlockRepository) GetBlocksByHeights(ctx context.Context, heights []int64) ([]domain.Block, error) {
+	args := m.Called(ctx, heights)
+	return args.Get(0).([]domain.Block), args.Error(1)
+}
+
+func (m *MockBlockRepository) ListBlocks(ctx context.Context, beforeHeight int64, limit int) ([]domain.Block, error) {
+	args := m.Called(ctx, beforeHeight, limit)
+	return args.Get(0).([]domain.Block), args.Error(1)
+}
+
+func TestExplorerHandler_GetBlocksPaginates(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockBlockRepo := new(MockBlockRepository)
+	handler := api.NewExplorerHandler(mockBlockRepo, nil, nil, nil, nil, nil)
+	router := gin.New()
+	router.GET("/blocks", handler.GetBlocks)
+	router.GET("/blocks/latest", handler.GetLatestBlock)
+	router.GET("/blocks/:height", handler.GetBlock)
+
+	// Mock expectations
+	mockBlockRepo.On("ListBlocks", mock.Anything, int64(11), 3).Return([]domain.Block{
+		{Height: 10, Hash: "hash-10"},
+		{Height: 9, Hash: "hash-9"},
+		{Height: 8, Hash: "hash-8"},
+	}, nil)
+
+	// Execute
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/blocks?cursor=11&limit=2", nil)
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response types.BlockListResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Len(t, response.Blocks, 2)
+	assert.Equal(t, "9", response.NextCursor)
+	assert.Equal(t, "/blocks?cursor=9&limit=2", response.Next)
+	mockBlockRepo.AssertExpectations(t)
+}
+
+func TestExplorerHandler_GetLatestBlockIsNotAHeight(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockBlockRepo := new(MockBlockRepository)
+	handler := api.NewExplorerHandler(mockBlockRepo, nil, nil, nil, nil, nil)
+	router := gin.New()
+	router.GET("/blocks/latest", handler.GetLatestBlock)
+	router.GET("/blocks/:height", handler.GetBlock)
+
+	// Mock expectations
+	mockBlockRepo.On("ListBlocks", mock.Anything, int64(0), 1).Return([]domain.Block{{Height: 42, Hash: "hash-42"}}, nil)
+
+	// Execute
+	latest := httptest.NewRecorder()
+	router.ServeHTTP(latest, httptest.NewRequest("GET", "/blocks/latest", nil))
+	invalid := httptest.NewRecorder()
+	router.ServeHTTP(invalid, httptest.NewRequest("GET", "/blocks/abc", nil))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, latest.Code)
+	var block types.BlockRecord
+	assert.NoError(t, json.Unmarshal(latest.Body.Bytes(), &block))
+	assert.Equal(t, 42, block.Height)
+	assert.Equal(t, http.StatusBadRequest, invalid.Code)
+	mockBlockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,114 @@
+package api_test
+
+import (
+	"encoding/json"
+	"gn-indexer/internal/api"
+	"gn-indexer/internal/types"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestServer builds the full router without repositories, enough for routing and validation
+func newTestServer() *gin.Engine {
+	return api.NewServer(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, api.NewStreamHub(), nil, nil, http.NotFoundHandler(), nil, nil, api.AuthConfig{}).GetRouter()
+}
+
+func TestOpenAPI_DescribesEveryRoute(t *testing.T) {
+	// Setup
+	router := newTestServer()
+
+	// Execute
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/openapi.json", nil))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	var document struct {
+		Paths map[string]map[string]interface{} `json:"paths"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &document))
+
+	param := regexp.MustCompile(`:([A-Za-z0-9_]+)`)
+	for _, route := range router.Routes() {
+		path := param.ReplaceAllString(route.Path, "{$1}")
+		methods, ok := document.Paths[path]
+		if assert.True(t, ok, "route %s is not documented", path) {
+			assert.Contains(t, methods, map[string]string{"GET": "get", "POST": "post", "DELETE": "delete"}[route.Method], path)
+		}
+	}
+	assert.Contains(t, document.Paths, "/tokens/{tokenPath}/balances")
+}
+
+func TestOpenAPI_RejectsInvalidParameters(t *testing.T) {
+	// Setup
+	router := newTestServer()
+	cases := []struct {
+		url       string
+		parameter string
+	}{
+		{"/tokens/balances?address=not-an-address", "address"},
+		{"/tokens/balances?address=g1jg8mtutu9khhfwc4nxmuhcpftf0pajdhfvsqf6", "address"},
+		{"/tokens/balances?sort=random", "sort"},
+		{"/tokens/transfer-history?from_time=yesterday", "from_time"},
+		{"/tokens/gno.land/r/demo/foo/balances?limit=1000", "limit"},
+		{"/nfts", "owner"},
+		{"/blocks/abc", "height"},
+	}
+
+	for _, tc := range cases {
+		// Execute
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", tc.url, nil))
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, w.Code, tc.url)
+		var response types.ErrorResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response), tc.url)
+		assert.Equal(t, api.ErrCodeInvalidParameter, response.Error.Code, tc.url)
+		assert.Equal(t, tc.parameter, response.Error.Details["parameter"], tc.url)
+	}
+}
+
+func TestOpenAPI_ValidatesRequestBody(t *testing.T) {
+	// Setup
+	router := newTestServer()
+
+	// Execute
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/webhooks", strings.NewReader(`{"url": "ftp://example.com"}`)))
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var response types.ErrorResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, api.ErrCodeInvalidParameter, response.Error.Code)
+	assert.Equal(t, "url", response.Error.Details["field"])
+}
+
+func TestOpenAPI_NormalizesAddressQuery(t *testing.T) {
+	// Setup
+	w := httptest.NewRecorder()
+	newTestServer().ServeHTTP(w, httptest.NewRequest("GET", "/openapi.json", nil))
+	validator, err := api.NewRequestValidator(w.Body.Bytes())
+	assert.NoError(t, err)
+
+	router := gin.New()
+	router.Use(validator.Middleware())
+	router.GET("/tokens/balances", func(c *gin.Context) {
+		c.String(http.StatusOK, c.Query("address"))
+	})
+
+	// Execute
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/tokens/balances?address=G1JG8MTUTU9KHHFWC4NXMUHCPFTF0PAJDHFVSQF5&page=2", nil))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "g1jg8mtutu9khhfwc4nxmuhcpftf0pajdhfvsqf5", w.Body.String())
+}
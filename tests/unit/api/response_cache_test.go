@@ -0,0 +1,136 @@
+package api_test
+
+import (
+	"context"
+	"gn-indexer/internal/api"
+	"gn-indexer/internal/domain"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+const cachedAddress = "g1jg8mtutu9khhfwc4nxmuhcpftf0pajdhfvsqf5"
+
+// newCachedRouter serves /tokens/balances through the cache and counts handler calls
+func newCachedRouter(cache *api.ResponseCache, calls *int) *gin.Engine {
+	router := gin.New()
+	router.GET("/tokens/balances", cache.Handle(func(c *gin.Context) {
+		*calls++
+		c.JSON(http.StatusOK, gin.H{"address": c.Query("address"), "calls": *calls})
+	}))
+	return router
+}
+
+func TestResponseCache_ServesHitsAndNotModified(t *testing.T) {
+	// Setup
+	calls := 0
+	router := newCachedRouter(api.NewResponseCache(api.NewLRUCacheStore(10), time.Minute), &calls)
+	url := "/tokens/balances?address=" + cachedAddress
+
+	// Execute
+	first := httptest.NewRecorder()
+	router.ServeHTTP(first, httptest.NewRequest("GET", url, nil))
+	second := httptest.NewRecorder()
+	router.ServeHTTP(second, httptest.NewRequest("GET", url, nil))
+
+	conditional := httptest.NewRequest("GET", url, nil)
+	conditional.Header.Set("If-None-Match", first.Header().Get("ETag"))
+	third := httptest.NewRecorder()
+	router.ServeHTTP(third, conditional)
+
+	// Assert
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, "MISS", first.Header().Get("X-Cache"))
+	assert.Equal(t, "HIT", second.Header().Get("X-Cache"))
+	assert.NotEmpty(t, first.Header().Get("ETag"))
+	assert.Equal(t, first.Body.String(), second.Body.String())
+	assert.Equal(t, "application/json; charset=utf-8", second.Header().Get("Content-Type"))
+	assert.Equal(t, http.StatusNotModified, third.Code)
+	assert.Empty(t, third.Body.String())
+}
+
+func TestResponseCache_InvalidatesOnBalanceChange(t *testing.T) {
+	// Setup
+	calls := 0
+	cache := api.NewResponseCache(api.NewLRUCacheStore(10), time.Minute)
+	router := newCachedRouter(cache, &calls)
+	url := "/tokens/balances?address=" + cachedAddress
+
+	// Execute
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", url, nil))
+	assert.NoError(t, cache.Publish(context.Background(), &domain.Notification{
+		Kind:      domain.NotificationBalance,
+		TokenPath: "gno.land/r/demo/foo",
+		Address:   cachedAddress,
+	}))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", url, nil))
+
+	// Assert
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, "MISS", w.Header().Get("X-Cache"))
+}
+
+func TestResponseCache_ExpiresAfterMaxStaleness(t *testing.T) {
+	// Setup
+	calls := 0
+	router := newCachedRouter(api.NewResponseCache(api.NewLRUCacheStore(10), 10*time.Millisecond), &calls)
+	url := "/tokens/balances?address=" + cachedAddress
+
+	// Execute
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", url, nil))
+	time.Sleep(20 * time.Millisecond)
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", url, nil))
+
+	// Assert
+	assert.Equal(t, 2, calls)
+}
+
+func TestLRUCacheStore_EvictsLeastRecentlyUsed(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	store := api.NewLRUCacheStore(2)
+	response := &api.CachedResponse{Body: []byte("{}")}
+
+	// Execute
+	assert.NoError(t, store.Set(ctx, "a", response, []string{"all"}, time.Minute))
+	assert.NoError(t, store.Set(ctx, "b", response, []string{"all"}, time.Minute))
+	_, _, _ = store.Get(ctx, "a")
+	assert.NoError(t, store.Set(ctx, "c", response, []string{"all"}, time.Minute))
+
+	// Assert
+	_, okA, _ := store.Get(ctx, "a")
+	_, okB, _ := store.Get(ctx, "b")
+	assert.True(t, okA)
+	assert.False(t, okB)
+	assert.Equal(t, 2, store.Len())
+}
+
+func TestRedisCacheStore_SetGetInvalidate(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	store, err := api.NewRedisCacheStore("redis://" + server.Addr())
+	assert.NoError(t, err)
+	defer store.Close()
+	response := &api.CachedResponse{ContentType: "application/json", ETag: `"abc"`, Body: []byte(`{"ok":true}`)}
+
+	// Execute
+	assert.NoError(t, store.Set(ctx, "balances", response, []string{"address:" + cachedAddress}, time.Minute))
+	cached, ok, err := store.Get(ctx, "balances")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, response, cached)
+
+	assert.NoError(t, store.Invalidate(ctx, "address:"+cachedAddress))
+	_, ok, err = store.Get(ctx, "balances")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
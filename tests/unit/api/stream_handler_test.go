@@ -0,0 +1,50 @@
+package api_test
+
+import (
+	"gn-indexer/internal/api"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamHandler_ChecksWebSocketOrigin(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/stream/ws", api.NewStreamHandler(api.NewStreamHub(), []string{"https://app.example.com/"}).ServeWebSocket)
+	server := httptest.NewServer(router)
+	defer server.Close()
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/stream/ws"
+	cases := map[string]bool{
+		"":                        true,
+		server.URL:                true,
+		"https://app.example.com": true,
+		"https://evil.example":    false,
+	}
+
+	for origin, accepted := range cases {
+		header := http.Header{}
+		if origin != "" {
+			header.Set("Origin", origin)
+		}
+
+		// Execute
+		conn, res, err := websocket.DefaultDialer.Dial(wsURL, header)
+		if conn != nil {
+			conn.Close()
+		}
+
+		// Assert
+		if accepted {
+			assert.NoError(t, err, origin)
+		} else {
+			assert.ErrorIs(t, err, websocket.ErrBadHandshake, origin)
+			assert.Equal(t, http.StatusForbidden, res.StatusCode, origin)
+		}
+	}
+}
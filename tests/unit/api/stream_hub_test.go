@@ -0,0 +1,34 @@
+package api_test
+
+import (
+	"gn-indexer/internal/api"
+	"gn-indexer/internal/domain"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamHub_BroadcastMatchesFilter(t *testing.T) {
+	// Setup
+	hub := api.NewStreamHub()
+	addressSub := hub.Subscribe(api.StreamFilter{Address: "to-address"})
+	tokenSub := hub.Subscribe(api.StreamFilter{TokenPath: "other-token"})
+	defer hub.Unsubscribe(addressSub)
+	defer hub.Unsubscribe(tokenSub)
+
+	notification := &domain.Notification{
+		Kind:        domain.NotificationTransfer,
+		TokenPath:   "test-token",
+		FromAddress: "from-address",
+		ToAddress:   "to-address",
+		Amount:      "50",
+	}
+
+	// Execute
+	hub.Broadcast(notification)
+
+	// Assert
+	assert.Len(t, addressSub, 1)
+	assert.Equal(t, notification, <-addressSub)
+	assert.Len(t, tokenSub, 0)
+}
@@ -0,0 +1,39 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookHandler_RejectsNonPublicTargets(t *testing.T) {
+	// Setup
+	router := newTestServer()
+	urls := []string{
+		"http://169.254.169.254/latest/meta-data",
+		"http://127.0.0.1:8080/hook",
+		"http://localhost/hook",
+		"http://10.0.0.5/hook",
+		"http://192.168.1.1/hook",
+		"http://[::1]/hook",
+		"http://[fe80::1]/hook",
+		"http://0.0.0.0/hook",
+		"http://100.64.0.1/hook",
+		"http://[fd00::1]/hook",
+		"ftp://example.com/hook",
+	}
+
+	for _, url := range urls {
+		// Execute
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/webhooks", strings.NewReader(`{"url":"`+url+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, w.Code, url)
+	}
+}
@@ -0,0 +1,243 @@
+package consumer_test
+
+import (
+	"gn-indexer/internal/consumer"
+	"gn-indexer/internal/domain"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventParser_ParseTransferAndApproval(t *testing.T) {
+	// Setup
+	parser := consumer.NewEventParser()
+	tx := &domain.Transaction{
+		Hash:        "test-tx",
+		BlockHeight: 1000,
+		Response: &domain.TransactionResponse{
+			Events: []domain.GnoEvent{
+				{
+					Type:    "Transfer",
+					Func:    "Transfer",
+					PkgPath: "gno.land/r/demo/foo",
+					Attrs: []domain.Attr{
+						{Key: "from", Value: "from-address"},
+						{Key: "to", Value: "to-address"},
+						{Key: "value", Value: "50"},
+					},
+				},
+				{
+					Type:    "Approval",
+					Func:    "Approve",
+					PkgPath: "gno.land/r/demo/foo",
+					Attrs: []domain.Attr{
+						{Key: "owner", Value: "owner-address"},
+						{Key: "spender", Value: "spender-address"},
+						{Key: "value", Value: "300"},
+					},
+				},
+				{
+					Type:    "register",
+					PkgPath: "gno.land/r/demo/grc20reg",
+				},
+			},
+		},
+	}
+
+	// Execute
+	events, err := parser.ParseEventsFromTransaction(tx)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, events, 2)
+
+	assert.Equal(t, domain.EventTypeTransfer, events[0].Func)
+	assert.Equal(t, int64(50), events[0].Amount)
+	assert.Equal(t, 0, events[0].EventIndex)
+
+	assert.Equal(t, domain.EventTypeApproval, events[1].Func)
+	assert.Equal(t, "owner-address", events[1].FromAddress)
+	assert.Equal(t, "spender-address", events[1].ToAddress)
+	assert.Equal(t, int64(300), events[1].Amount)
+	assert.Equal(t, int64(1000), events[1].BlockHeight)
+	assert.Equal(t, 1, events[1].EventIndex)
+}
+
+func TestEventParser_ApprovalRequiresOwnerAndSpender(t *testing.T) {
+	// Setup
+	parser := consumer.NewEventParser()
+	event := &domain.GnoEvent{
+		Type: "Approval",
+		Attrs: []domain.Attr{
+			{Key: "owner", Value: "owner-address"},
+			{Key: "value", Value: "1"},
+		},
+	}
+
+	// Execute
+	_, err := parser.ParseApprovalEvent(event, &domain.Transaction{Hash: "test-tx"}, 0)
+
+	// Assert
+	assert.Error(t, err)
+}
+
+func TestEventParser_ParseGRC721Events(t *testing.T) {
+	// Setup
+	parser := consumer.NewEventParser()
+	tx := &domain.Transaction{
+		Hash:        "nft-tx",
+		BlockHeight: 2000,
+		Response: &domain.TransactionResponse{
+			Events: []domain.GnoEvent{
+				{
+					Type:    "Transfer",
+					Func:    "Mint",
+					PkgPath: "gno.land/r/demo/nft",
+					Attrs: []domain.Attr{
+						{Key: "from", Value: ""},
+						{Key: "to", Value: "owner-address"},
+						{Key: "tokenId", Value: "1"},
+					},
+				},
+				{
+					Type:    "Transfer",
+					Func:    "TransferFrom",
+					PkgPath: "gno.land/r/demo/nft",
+					Attrs: []domain.Attr{
+						{Key: "from", Value: "owner-address"},
+						{Key: "to", Value: "new-owner"},
+						{Key: "tid", Value: "1"},
+					},
+				},
+				{
+					Type:    "Transfer",
+					Func:    "Burn",
+					PkgPath: "gno.land/r/demo/nft",
+					Attrs: []domain.Attr{
+						{Key: "from", Value: "new-owner"},
+						{Key: "to", Value: ""},
+						{Key: "tokenId", Value: "1"},
+					},
+				},
+			},
+		},
+	}
+
+	// Execute
+	events, err := parser.ParseEventsFromTransaction(tx)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, events, 3)
+	assert.Equal(t, domain.EventTypeNFTMint, events[0].Func)
+	assert.Equal(t, domain.EventTypeNFTTransfer, events[1].Func)
+	assert.Equal(t, domain.EventTypeNFTBurn, events[2].Func)
+	for _, event := range events {
+		assert.Equal(t, "1", event.TokenID)
+		assert.Equal(t, int64(0), event.Amount)
+	}
+}
+
+func TestEventParser_FlagsMalformedAddresses(t *testing.T) {
+	// Setup
+	parser := consumer.NewEventParser()
+	tx := &domain.Transaction{
+		Hash:        "address-tx",
+		BlockHeight: 3000,
+		Response: &domain.TransactionResponse{
+			Events: []domain.GnoEvent{
+				{
+					Type:    "Transfer",
+					Func:    "Transfer",
+					PkgPath: "gno.land/r/demo/foo",
+					Attrs: []domain.Attr{
+						{Key: "from", Value: "G1JG8MTUTU9KHHFWC4NXMUHCPFTF0PAJDHFVSQF5"},
+						{Key: "to", Value: "g1jg8mtutu9khhfwc4nxmuhcpftf0pajdhfvsqf6"},
+						{Key: "value", Value: "10"},
+					},
+				},
+			},
+		},
+	}
+
+	// Execute
+	events, err := parser.ParseEventsFromTransaction(tx)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, events, 1)
+	assert.Equal(t, "g1jg8mtutu9khhfwc4nxmuhcpftf0pajdhfvsqf5", events[0].FromAddress)
+	assert.Equal(t, "g1jg8mtutu9khhfwc4nxmuhcpftf0pajdhfvsqf6", events[0].ToAddress)
+	assert.Equal(t, []string{"to"}, events[0].MalformedAttrs)
+}
+
+func TestEventParser_MixedGRC20AndGRC721Approvals(t *testing.T) {
+	// Setup
+	parser := consumer.NewEventParser()
+	tx := &domain.Transaction{
+		Hash:        "mixed-tx",
+		BlockHeight: 3000,
+		Response: &domain.TransactionResponse{
+			Events: []domain.GnoEvent{
+				{
+					Type:    "Approval",
+					Func:    "Approve",
+					PkgPath: "gno.land/r/demo/nft",
+					Attrs: []domain.Attr{
+						{Key: "owner", Value: "owner-address"},
+						{Key: "to", Value: "operator-address"},
+						{Key: "tokenId", Value: "7"},
+					},
+				},
+				{
+					Type:    "Transfer",
+					Func:    "Transfer",
+					PkgPath: "gno.land/r/demo/foo",
+					Attrs: []domain.Attr{
+						{Key: "from", Value: "from-address"},
+						{Key: "to", Value: "to-address"},
+						{Key: "value", Value: "50"},
+					},
+				},
+				{
+					Type:    "Approval",
+					Func:    "Approve",
+					PkgPath: "gno.land/r/demo/foo",
+					Attrs: []domain.Attr{
+						{Key: "owner", Value: "owner-address"},
+						{Key: "spender", Value: "spender-address"},
+						{Key: "value", Value: "not-a-number"},
+					},
+				},
+				{
+					Type:    "Transfer",
+					Func:    "TransferFrom",
+					PkgPath: "gno.land/r/demo/nft",
+					Attrs: []domain.Attr{
+						{Key: "from", Value: "owner-address"},
+						{Key: "to", Value: "new-owner"},
+						{Key: "tokenId", Value: "7"},
+					},
+				},
+			},
+		},
+	}
+
+	// Execute
+	events, err := parser.ParseEventsFromTransaction(tx)
+
+	// Assert
+	assert.ErrorContains(t, err, "parse Approval event 2")
+	assert.Len(t, events, 3)
+
+	assert.Equal(t, domain.EventTypeNFTApproval, events[0].Func)
+	assert.Equal(t, "owner-address", events[0].FromAddress)
+	assert.Equal(t, "operator-address", events[0].ToAddress)
+	assert.Equal(t, "7", events[0].TokenID)
+
+	assert.Equal(t, domain.EventTypeTransfer, events[1].Func)
+	assert.Equal(t, int64(50), events[1].Amount)
+
+	assert.Equal(t, domain.EventTypeNFTTransfer, events[2].Func)
+	assert.Equal(t, 3, events[2].EventIndex)
+}
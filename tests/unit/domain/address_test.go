@@ -0,0 +1,44 @@
+package domain_test
+
+import (
+	"gn-indexer/internal/domain"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAddress(t *testing.T) {
+	// Setup
+	cases := []struct {
+		input    string
+		expected string
+		valid    bool
+	}{
+		{"g1jg8mtutu9khhfwc4nxmuhcpftf0pajdhfvsqf5", "g1jg8mtutu9khhfwc4nxmuhcpftf0pajdhfvsqf5", true},
+		{"G1JG8MTUTU9KHHFWC4NXMUHCPFTF0PAJDHFVSQF5", "g1jg8mtutu9khhfwc4nxmuhcpftf0pajdhfvsqf5", true},
+		{"g1Jg8mtutu9khhfwc4nxmuhcpftf0pajdhfvsqf5", "", false}, // mixed case
+		{"g1jg8mtutu9khhfwc4nxmuhcpftf0pajdhfvsqf6", "", false}, // checksum
+		{"cosmos1jg8mtutu9khhfwc4nxmuhcpftf0pajdhfvsqf5", "", false},
+		{"g1alice", "", false},
+		{"", "", false},
+	}
+
+	for _, tc := range cases {
+		// Execute
+		addr, err := domain.ParseAddress(tc.input)
+
+		// Assert
+		if tc.valid {
+			assert.NoError(t, err, tc.input)
+			assert.Equal(t, tc.expected, addr.String(), tc.input)
+		} else {
+			assert.ErrorIs(t, err, domain.ErrInvalidAddress, tc.input)
+		}
+	}
+}
+
+func TestNormalizeAddress_KeepsInvalidValues(t *testing.T) {
+	// Execute & Assert
+	assert.Equal(t, "g1jg8mtutu9khhfwc4nxmuhcpftf0pajdhfvsqf5", domain.NormalizeAddress("G1JG8MTUTU9KHHFWC4NXMUHCPFTF0PAJDHFVSQF5"))
+	assert.Equal(t, "Not-An-Address", domain.NormalizeAddress("Not-An-Address"))
+}
@@ -0,0 +1,164 @@
+package graphql_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"gn-indexer/internal/domain"
+	"gn-indexer/internal/graphql"
+	"gn-indexer/internal/repository"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockTransferRepository is a mock implementation of TransferRepository
+type MockTransferRepository struct {
+	mock.Mock
+}
+
+func (m *MockTransferRepository) Create(ctx context.Context, transfer *domain.Transfer) error {
+	args := m.Called(ctx, transfer)
+	return args.Error(0)
+}
+
+func (m *MockTransferRepository) GetByTxHash(ctx context.Context, txHash string) ([]domain.Transfer, error) {
+	args := m.Called(ctx, txHash)
+	return args.Get(0).([]domain.Transfer), args.Error(1)
+}
+
+func (m *MockTransferRepository) GetByAddress(ctx context.Context, address string) ([]domain.Transfer, error) {
+	args := m.Called(ctx, address)
+	return args.Get(0).([]domain.Transfer), args.Error(1)
+}
+
+func (m *MockTransferRepository) GetByTokenPath(ctx context.Context, tokenPath string) ([]domain.Transfer, error) {
+	args := m.Called(ctx, tokenPath)
+	return args.Get(0).([]domain.Transfer), args.Error(1)
+}
+
+func (m *MockTransferRepository) GetAll(ctx context.Context) ([]domain.Transfer, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]domain.Transfer), args.Error(1)
+}
+
+func (m *MockTransferRepository) List(ctx context.Context, filter repository.TransferFilter) ([]domain.Transfer, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]domain.Transfer), args.Error(1)
+}
+
+func (m *MockTransferRepository) GetByTxHashes(ctx context.Context, txHashes []string) ([]domain.Transfer, error) {
+	args := m.Called(ctx, txHashes)
+	return args.Get(0).([]domain.Transfer), args.Error(1)
+}
+
+// MockTransactionRepository is a mock implementation of TransactionRepository
+type MockTransactionRepository struct {
+	mock.Mock
+}
+
+func (m *MockTransactionRepository) SaveTransaction(ctx context.Context, tx domain.Transaction) error {
+	args := m.Called(ctx, tx)
+	return args.Error(0)
+}
+
+func (m *MockTransactionRepository) GetTransactionByHash(ctx context.Context, hash string) (*domain.Transaction, error) {
+	args := m.Called(ctx, hash)
+	return args.Get(0).(*domain.Transaction), args.Error(1)
+}
+
+func (m *MockTransactionRepository) GetTransactionsByBlockHeight(ctx context.Context, blockHeight int) ([]domain.Transaction, error) {
+	args := m.Called(ctx, blockHeight)
+	return args.Get(0).([]domain.Transaction), args.Error(1)
+}
+
+func (m *MockTransactionRepository) GetTransactionsByHashes(ctx context.Context, hashes []string) ([]domain.Transaction, error) {
+	args := m.Called(ctx, hashes)
+	return args.Get(0).([]domain.Transaction), args.Error(1)
+}
+
+func (m *MockTransactionRepository) GetTransactionsByBlockHeights(ctx context.Context, heights []int64) ([]domain.Transaction, error) {
+	args := m.Called(ctx, heights)
+	return args.Get(0).([]domain.Transaction), args.Error(1)
+}
+
+func (m *MockTransactionRepository) List(ctx context.Context, filter repository.TransactionFilter) ([]domain.Transaction, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]domain.Transaction), args.Error(1)
+}
+
+// runQuery posts a query to the handler and decodes the response
+func runQuery(t *testing.T, handler http.Handler, query string) map[string]interface{} {
+	body, _ := json.Marshal(map[string]string{"query": query})
+	req := httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	var response map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	return response
+}
+
+func TestTransfers_BatchesNestedTransactions(t *testing.T) {
+	// Setup
+	mockTransferRepo := new(MockTransferRepository)
+	mockTransactionRepo := new(MockTransactionRepository)
+	handler, err := graphql.NewHandler(graphql.Repositories{
+		Transfers:    mockTransferRepo,
+		Transactions: mockTransactionRepo,
+	})
+	assert.NoError(t, err)
+
+	transfers := []domain.Transfer{
+		{ID: 3, TxHash: "tx-b", EventIndex: 0, Amount: domain.NewU64(30), BlockHeight: 12},
+		{ID: 2, TxHash: "tx-a", EventIndex: 1, Amount: domain.NewU64(20), BlockHeight: 11},
+		{ID: 1, TxHash: "tx-a", EventIndex: 0, Amount: domain.NewU64(10), BlockHeight: 11},
+	}
+
+	// Mock expectations
+	mockTransferRepo.On("List", mock.Anything, mock.MatchedBy(func(filter repository.TransferFilter) bool {
+		return filter.Address == "g1jg8mtutu9khhfwc4nxmuhcpftf0pajdhfvsqf5" && filter.Limit == 3
+	})).Return(transfers, nil)
+	mockTransactionRepo.On("GetTransactionsByHashes", mock.Anything, mock.MatchedBy(func(hashes []string) bool {
+		return assert.ElementsMatch(t, []string{"tx-a", "tx-b"}, hashes)
+	})).Return([]domain.Transaction{
+		{Hash: "tx-a", BlockHeight: 11, GasUsed: 5_000_000_000},
+		{Hash: "tx-b", BlockHeight: 12},
+	}, nil).Once()
+
+	// Execute
+	response := runQuery(t, handler, `{
+		transfers(filter: {address: "g1jg8mtutu9khhfwc4nxmuhcpftf0pajdhfvsqf5"}, limit: 2) {
+			nodes { amount transaction { hash gasUsed } }
+			nextCursor
+		}
+	}`)
+
+	// Assert
+	assert.Nil(t, response["errors"])
+	connection := response["data"].(map[string]interface{})["transfers"].(map[string]interface{})
+	nodes := connection["nodes"].([]interface{})
+	assert.Len(t, nodes, 2)
+	assert.Equal(t, "30", nodes[0].(map[string]interface{})["amount"])
+	tx := nodes[1].(map[string]interface{})["transaction"].(map[string]interface{})
+	assert.Equal(t, "tx-a", tx["hash"])
+	assert.Equal(t, float64(5_000_000_000), tx["gasUsed"])
+	assert.NotEmpty(t, connection["nextCursor"])
+	mockTransferRepo.AssertExpectations(t)
+	mockTransactionRepo.AssertExpectations(t)
+}
+
+func TestTransfers_DirectionRequiresAddress(t *testing.T) {
+	// Setup
+	handler, err := graphql.NewHandler(graphql.Repositories{Transfers: new(MockTransferRepository)})
+	assert.NoError(t, err)
+
+	// Execute
+	response := runQuery(t, handler, `{ transfers(filter: {direction: IN}) { nodes { txHash } } }`)
+
+	// Assert
+	assert.NotNil(t, response["errors"])
+}
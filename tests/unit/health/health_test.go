@@ -0,0 +1,115 @@
+package health_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"gn-indexer/internal/config"
+	"gn-indexer/internal/health"
+	"gn-indexer/internal/types"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// okCheck is a dependency that is always reachable
+var okCheck = health.Check{Name: "database", Run: func(ctx context.Context) error { return nil }}
+
+// fixedProgress reports the same progress on every call
+func fixedProgress(progress health.Progress) health.ProgressFunc {
+	return func(ctx context.Context) health.Progress { return progress }
+}
+
+func TestChecker_ReadyWithinLag(t *testing.T) {
+	// Setup
+	lastEvent := time.Now().Add(-30 * time.Second)
+	checker := health.NewChecker("block-syncer", &config.HealthConfig{MaxLagBlocks: 10},
+		fixedProgress(health.Progress{ProcessedHeight: 95, NetworkHeight: 100, LastEventAt: lastEvent}), okCheck)
+
+	// Execute
+	report, ready := checker.Report(context.Background())
+
+	// Assert
+	assert.True(t, ready)
+	assert.Equal(t, health.StatusOK, report.Status)
+	assert.Equal(t, health.StatusOK, report.Checks["database"].Status)
+	assert.Equal(t, int64(5), *report.Sync.LagBlocks)
+	assert.InDelta(t, 30, *report.Sync.LastEventAgeSeconds, 1)
+	assert.Empty(t, report.Reasons)
+}
+
+func TestChecker_NotReadyWhenLaggingOrFailing(t *testing.T) {
+	// Setup
+	backlog := int64(500)
+	failing := health.Check{Name: "queue", Run: func(ctx context.Context) error { return errors.New("connection refused") }}
+	checker := health.NewChecker("event-processor", &config.HealthConfig{MaxLagBlocks: 10, MaxQueueBacklog: 100},
+		fixedProgress(health.Progress{ProcessedHeight: 50, NetworkHeight: 100, QueueBacklog: &backlog}), okCheck, failing)
+
+	// Execute
+	report, ready := checker.Report(context.Background())
+
+	// Assert
+	assert.False(t, ready)
+	assert.Equal(t, health.StatusUnavailable, report.Status)
+	assert.Equal(t, health.StatusFail, report.Checks["queue"].Status)
+	assert.Equal(t, "connection refused", report.Checks["queue"].Error)
+	assert.Len(t, report.Reasons, 3)
+}
+
+func TestChecker_UnknownTipIsNotLag(t *testing.T) {
+	// Setup
+	checker := health.NewChecker("block-syncer", &config.HealthConfig{MaxLagBlocks: 10},
+		fixedProgress(health.Progress{ProcessedHeight: 50}), okCheck)
+
+	// Execute
+	report, ready := checker.Report(context.Background())
+
+	// Assert
+	assert.True(t, ready)
+	assert.Nil(t, report.Sync.LagBlocks)
+	assert.Nil(t, report.Sync.LastEventAt)
+}
+
+func TestChecker_Endpoints(t *testing.T) {
+	// Setup
+	checker := health.NewChecker("block-syncer", &config.HealthConfig{MaxLagBlocks: 10},
+		fixedProgress(health.Progress{ProcessedHeight: 50, NetworkHeight: 100}), okCheck)
+
+	// Execute
+	healthz := httptest.NewRecorder()
+	checker.ServeHealthz(healthz, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	readyz := httptest.NewRecorder()
+	checker.ServeReadyz(readyz, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, healthz.Code)
+	assert.Equal(t, http.StatusServiceUnavailable, readyz.Code)
+
+	var report types.HealthResponse
+	assert.NoError(t, json.Unmarshal(readyz.Body.Bytes(), &report))
+	assert.Equal(t, "block-syncer", report.Service)
+	assert.Equal(t, []string{"lag of 50 blocks exceeds 10"}, report.Reasons)
+}
+
+func TestChecker_ReplicaLag(t *testing.T) {
+	// Setup
+	replica := func(lag time.Duration) health.Check {
+		return health.Check{Name: "replica-1", Lag: func(ctx context.Context) (time.Duration, error) { return lag, nil }}
+	}
+	cfg := &config.HealthConfig{MaxReplicaLag: 30 * time.Second}
+
+	// Execute
+	inSync, inSyncReady := health.NewChecker("balance-api", cfg, nil, okCheck, replica(2*time.Second)).Report(context.Background())
+	behind, behindReady := health.NewChecker("balance-api", cfg, nil, okCheck, replica(time.Minute)).Report(context.Background())
+
+	// Assert
+	assert.True(t, inSyncReady)
+	assert.Equal(t, 2.0, *inSync.Checks["replica-1"].LagSeconds)
+	assert.False(t, behindReady)
+	assert.Equal(t, health.StatusFail, behind.Checks["replica-1"].Status)
+	assert.Equal(t, 60.0, *behind.Checks["replica-1"].LagSeconds)
+	assert.Equal(t, "lag of 1m0s exceeds 30s", behind.Checks["replica-1"].Error)
+}
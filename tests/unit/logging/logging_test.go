@@ -0,0 +1,82 @@
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"gn-indexer/internal/config"
+	"gn-indexer/internal/logging"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComponentLevels(t *testing.T) {
+	// Setup
+	var buf bytes.Buffer
+	quiet := logging.For("test-quiet") // created before Setup, must still follow it
+	err := logging.SetupWriter(&config.LogConfig{
+		Level:      "warn",
+		Format:     config.LogFormatText,
+		Components: map[string]string{"test-verbose": "debug"},
+	}, &buf)
+	assert.NoError(t, err)
+	verbose := logging.For("test-verbose")
+
+	// Execute
+	quiet.Info("quiet info")
+	quiet.Warn("quiet warn")
+	verbose.Debug("verbose debug")
+
+	// Assert
+	out := buf.String()
+	assert.NotContains(t, out, "quiet info")
+	assert.Contains(t, out, "quiet warn")
+	assert.Contains(t, out, "component=test-quiet")
+	assert.Contains(t, out, "verbose debug")
+}
+
+func TestJSONOutputWithCorrelationID(t *testing.T) {
+	// Setup
+	var buf bytes.Buffer
+	err := logging.SetupWriter(&config.LogConfig{Level: "info", Format: config.LogFormatJSON}, &buf)
+	assert.NoError(t, err)
+	ctx := logging.WithCorrelationID(context.Background(), "abc123")
+
+	// Execute
+	logging.For("balance").With(logging.KeyTxHash, "0xhash").InfoContext(ctx, "balance updated", logging.KeyBlockHeight, 42)
+
+	// Assert
+	var record map[string]interface{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "balance updated", record["msg"])
+	assert.Equal(t, "balance", record[logging.KeyComponent])
+	assert.Equal(t, "abc123", record[logging.KeyCorrelationID])
+	assert.Equal(t, "0xhash", record[logging.KeyTxHash])
+	assert.Equal(t, float64(42), record[logging.KeyBlockHeight])
+}
+
+func TestSetupRejectsInvalidConfig(t *testing.T) {
+	// Execute
+	levelErr := logging.SetupWriter(&config.LogConfig{Level: "loud"}, &bytes.Buffer{})
+	formatErr := logging.SetupWriter(&config.LogConfig{Level: "info", Format: "xml"}, &bytes.Buffer{})
+
+	// Assert
+	assert.Error(t, levelErr)
+	assert.Error(t, formatErr)
+}
+
+func TestNewLogConfig(t *testing.T) {
+	// Setup
+	t.Setenv("LOG_LEVEL", "debug")
+	t.Setenv("LOG_FORMAT", "json")
+	t.Setenv("LOG_LEVELS", " websocket=debug, balance = warn ,invalid")
+
+	// Execute
+	cfg := config.NewLogConfig()
+
+	// Assert
+	assert.Equal(t, "debug", cfg.Level)
+	assert.Equal(t, config.LogFormatJSON, cfg.Format)
+	assert.Equal(t, map[string]string{"websocket": "debug", "balance": "warn"}, cfg.Components)
+}
@@ -0,0 +1,55 @@
+package metrics_test
+
+import (
+	"gn-indexer/internal/metrics"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyncLag(t *testing.T) {
+	// Execute
+	metrics.SetSyncedHeight(90)
+	lagBeforeTip := testutil.ToFloat64(metrics.SyncLag)
+	metrics.SetNetworkHeight(100)
+	lag := testutil.ToFloat64(metrics.SyncLag)
+	metrics.SetSyncedHeight(80) // re-syncing an older range keeps the progress
+	lagAfterResync := testutil.ToFloat64(metrics.SyncLag)
+
+	// Assert
+	assert.Equal(t, 0.0, lagBeforeTip)
+	assert.Equal(t, 10.0, lag)
+	assert.Equal(t, 10.0, lagAfterResync)
+	assert.Equal(t, 90.0, testutil.ToFloat64(metrics.SyncedHeight))
+}
+
+func TestGraphQLOperation(t *testing.T) {
+	assert.Equal(t, "getBlocks", metrics.GraphQLOperation("query($gt:Int!){\n  getBlocks(where:{}){ height }\n}"))
+	assert.Equal(t, "getTransactions", metrics.GraphQLOperation("{ getTransactions { hash } }"))
+	assert.Equal(t, "unknown", metrics.GraphQLOperation(""))
+}
+
+func TestMiddleware_LabelsByRoute(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(metrics.Middleware())
+	router.GET("/blocks/:height", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
+
+	// Execute
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/blocks/1", nil))
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/blocks/2", nil))
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/missing", nil))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `gn_indexer_http_request_duration_seconds_count{method="GET",route="/blocks/:height",status="200"} 2`)
+	assert.Contains(t, w.Body.String(), `gn_indexer_http_request_duration_seconds_count{method="GET",route="unmatched",status="404"} 1`)
+}
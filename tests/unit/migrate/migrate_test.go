@@ -0,0 +1,83 @@
+package migrate_test
+
+import (
+	"context"
+	"gn-indexer/db"
+	"gn-indexer/internal/config"
+	"gn-indexer/internal/migrate"
+	"io/fs"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewMigrator_EmbeddedMigrations(t *testing.T) {
+	// Setup
+	files, err := fs.Glob(db.Migrations("postgres"), "*.up.sql")
+	assert.NoError(t, err)
+
+	// Execute
+	migrator, err := migrate.NewMigrator(nil, "postgres", db.Migrations("postgres"))
+	sqliteMigrator, sqliteErr := migrate.NewMigrator(nil, "sqlite", db.Migrations("sqlite"))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, len(files), migrator.Latest())
+	assert.NoError(t, sqliteErr)
+	assert.Equal(t, migrator.Latest(), sqliteMigrator.Latest())
+}
+
+func TestNewMigrator_RejectsIncompleteMigrations(t *testing.T) {
+	// Setup
+	gap := fstest.MapFS{
+		"0001_init.up.sql":    {Data: []byte("CREATE TABLE a (id INT);")},
+		"0001_init.down.sql":  {Data: []byte("DROP TABLE a;")},
+		"0003_later.up.sql":   {Data: []byte("CREATE TABLE c (id INT);")},
+		"0003_later.down.sql": {Data: []byte("DROP TABLE c;")},
+		"README.md":           {Data: []byte("ignored")},
+	}
+	missingDown := fstest.MapFS{
+		"0001_init.up.sql": {Data: []byte("CREATE TABLE a (id INT);")},
+	}
+
+	// Execute
+	_, gapErr := migrate.NewMigrator(nil, "postgres", gap)
+	_, missingDownErr := migrate.NewMigrator(nil, "postgres", missingDown)
+
+	// Assert
+	assert.EqualError(t, gapErr, "migration version 2 is missing")
+	assert.EqualError(t, missingDownErr, "migration 0001_init needs both an up and a down file")
+}
+
+func TestMigrator_SQLiteUpAndDown(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	cfg := config.DefaultMigrateConfig().Database
+	cfg.DSN = "sqlite://" + filepath.Join(t.TempDir(), "indexer.db")
+	gormDB, err := cfg.Connect()
+	assert.NoError(t, err)
+	migrator, err := migrate.ForDatabase(gormDB)
+	assert.NoError(t, err)
+
+	// Execute
+	outdatedErr := migrator.Check(ctx)
+	applied, upErr := migrator.Up(ctx)
+	upStatus, _ := migrator.Status(ctx)
+	checkErr := migrate.CheckSchema(ctx, gormDB)
+	reverted, downErr := migrator.Down(ctx, migrator.Latest())
+	downStatus, _ := migrator.Status(ctx)
+
+	// Assert
+	assert.ErrorIs(t, outdatedErr, migrate.ErrSchemaOutdated)
+	assert.NoError(t, upErr)
+	assert.Len(t, applied, migrator.Latest())
+	assert.Equal(t, migrator.Latest(), upStatus.Current)
+	assert.Empty(t, upStatus.Pending)
+	assert.NoError(t, checkErr)
+	assert.NoError(t, downErr)
+	assert.Len(t, reverted, migrator.Latest())
+	assert.Equal(t, 0, downStatus.Current)
+	assert.Len(t, downStatus.Pending, migrator.Latest())
+}
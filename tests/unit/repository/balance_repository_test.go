@@ -3,6 +3,7 @@ package repository_test
 import (
 	"context"
 	"gn-indexer/internal/domain"
+	"gn-indexer/internal/repository"
 	"testing"
 	"time"
 
@@ -57,6 +58,32 @@ func (m *MockBalanceRepository) GetAllBalances(ctx context.Context) ([]*domain.B
 	return args.Get(0).([]*domain.Balance), args.Error(1)
 }
 
+func (m *MockBalanceRepository) ListBalances(ctx context.Context, query repository.BalanceQuery) ([]*domain.Balance, int64, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*domain.Balance), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockBalanceRepository) GetTokenHolderSummary(ctx context.Context, tokenPath string) (*repository.TokenHolderSummary, error) {
+	args := m.Called(ctx, tokenPath)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.TokenHolderSummary), args.Error(1)
+}
+
+func (m *MockBalanceRepository) StreamBalances(ctx context.Context, query repository.BalanceQuery, fn func(*domain.Balance) error) error {
+	args := m.Called(ctx, query)
+	for _, balance := range args.Get(0).([]*domain.Balance) {
+		if err := fn(balance); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
 func TestBalanceRepository_Create(t *testing.T) {
 	// Setup
 	mockRepo := new(MockBalanceRepository)
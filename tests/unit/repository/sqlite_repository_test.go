@@ -0,0 +1,359 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gn-indexer/internal/config"
+	"gn-indexer/internal/domain"
+	"gn-indexer/internal/migrate"
+	"gn-indexer/internal/repository"
+	"gn-indexer/internal/service"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// newSQLiteDB opens a migrated SQLite database in a temporary directory
+func newSQLiteDB(t *testing.T) *gorm.DB {
+	cfg := config.DefaultMigrateConfig().Database
+	cfg.DSN = "sqlite://" + filepath.Join(t.TempDir(), "indexer.db")
+	db, err := cfg.Connect()
+	assert.NoError(t, err)
+	migrator, err := migrate.ForDatabase(db)
+	assert.NoError(t, err)
+	_, err = migrator.Up(context.Background())
+	assert.NoError(t, err)
+	return db
+}
+
+// u64 parses an amount for a test
+func u64(t *testing.T, value string) *domain.U64 {
+	amount, err := domain.NewU64FromString(value)
+	assert.NoError(t, err)
+	return amount
+}
+
+func TestBalanceRepository_SQLiteKeepsU64Precision(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	db := newSQLiteDB(t)
+	tokenPath := "gno.land/r/demo/foo"
+	assert.NoError(t, repository.NewTokenRepository(db).Create(ctx, &domain.Token{Path: tokenPath, Symbol: "FOO", Decimals: 6}))
+	balanceRepo := repository.NewBalanceRepository(db)
+	for address, amount := range map[string]string{
+		"g1max":   "18446744073709551615",
+		"g1large": "9007199254740993", // not exact as a float64
+		"g1small": "10",
+		"g1zero":  "0",
+	} {
+		balance := &domain.Balance{Address: address, TokenPath: tokenPath, Amount: u64(t, amount), UpdatedAt: time.Now()}
+		assert.NoError(t, balanceRepo.Create(ctx, balance))
+	}
+
+	// Execute
+	balances, total, listErr := balanceRepo.ListBalances(ctx, repository.BalanceQuery{TokenPath: tokenPath, MinAmount: domain.NewU64(11)})
+	summary, summaryErr := balanceRepo.GetTokenHolderSummary(ctx, tokenPath)
+
+	// Assert
+	assert.NoError(t, listErr)
+	assert.Equal(t, int64(2), total)
+	if assert.Len(t, balances, 2) {
+		assert.Equal(t, "g1max", balances[0].Address)
+		assert.Equal(t, "18446744073709551615", balances[0].Amount.String())
+		assert.Equal(t, "g1large", balances[1].Address)
+		assert.Equal(t, "9007199254740993", balances[1].Amount.String())
+	}
+	assert.NoError(t, summaryErr)
+	assert.Equal(t, int64(3), summary.HolderCount)
+	assert.Equal(t, "18455751272964292618", summary.TotalSupply.String())
+}
+
+func TestAddressRepository_SQLiteRenameAddsBalances(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	db := newSQLiteDB(t)
+	tokenPath := "gno.land/r/demo/foo"
+	assert.NoError(t, repository.NewTokenRepository(db).Create(ctx, &domain.Token{Path: tokenPath, Symbol: "FOO", Decimals: 6}))
+	balanceRepo := repository.NewBalanceRepository(db)
+	assert.NoError(t, balanceRepo.Create(ctx, &domain.Balance{Address: "G1ABC", TokenPath: tokenPath, Amount: u64(t, "9007199254740993"), LastTxHash: "tx7", LastBlockH: 7}))
+	assert.NoError(t, balanceRepo.Create(ctx, &domain.Balance{Address: "g1abc", TokenPath: tokenPath, Amount: u64(t, "1"), LastTxHash: "tx5", LastBlockH: 5}))
+	addressRepo := repository.NewAddressRepository(db)
+
+	// Execute
+	nonCanonical, listErr := addressRepo.ListNonCanonical(ctx)
+	renameErr := addressRepo.Rename(ctx, "G1ABC", "g1abc")
+	merged, getErr := balanceRepo.GetBalance(ctx, tokenPath, "g1abc")
+	_, oldErr := balanceRepo.GetBalance(ctx, tokenPath, "G1ABC")
+
+	// Assert
+	assert.NoError(t, listErr)
+	assert.Equal(t, []string{"G1ABC"}, nonCanonical)
+	assert.NoError(t, renameErr)
+	assert.NoError(t, getErr)
+	assert.Equal(t, "9007199254740994", merged.Amount.String())
+	assert.Equal(t, "tx7", merged.LastTxHash)
+	assert.Equal(t, int64(7), merged.LastBlockH)
+	assert.ErrorIs(t, oldErr, repository.ErrBalanceNotFound)
+}
+
+func TestBalanceHistoryRepository_SQLiteSumsOutOfOrderDeltas(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	db := newSQLiteDB(t)
+	tokenPath := "gno.land/r/demo/foo"
+	assert.NoError(t, repository.NewTokenRepository(db).Create(ctx, &domain.Token{Path: tokenPath, Symbol: "FOO", Decimals: 6}))
+	historyRepo := repository.NewBalanceHistoryRepository(db)
+	// Indexed in a different order than they happened on chain
+	for _, history := range []*domain.BalanceHistory{
+		{Address: "g1abc", TokenPath: tokenPath, Delta: -30, TxHash: "tx3", EventIndex: 1, BlockHeight: 20},
+		{Address: "g1abc", TokenPath: tokenPath, Delta: 100, TxHash: "tx1", EventIndex: 0, BlockHeight: 10},
+		{Address: "g1abc", TokenPath: tokenPath, Delta: 5, TxHash: "tx2", EventIndex: 0, BlockHeight: 20},
+		{Address: "g1abc", TokenPath: tokenPath, Delta: 1000, TxHash: "tx4", EventIndex: 0, BlockHeight: 30},
+	} {
+		recorded, err := historyRepo.Record(ctx, history)
+		assert.NoError(t, err)
+		assert.True(t, recorded)
+	}
+
+	// Execute
+	replayed, replayErr := historyRepo.Record(ctx, &domain.BalanceHistory{Address: "g1abc", TokenPath: tokenPath, Delta: 100, TxHash: "tx1", EventIndex: 0, BlockHeight: 10})
+	atTen, tenErr := historyRepo.GetBalanceAt(ctx, tokenPath, "g1abc", 10)
+	atTwenty, twentyErr := historyRepo.GetBalanceAt(ctx, tokenPath, "g1abc", 20)
+	_, beforeErr := historyRepo.GetBalanceAt(ctx, tokenPath, "g1abc", 9)
+
+	// Assert
+	assert.NoError(t, replayErr)
+	assert.False(t, replayed)
+	assert.NoError(t, tenErr)
+	assert.Equal(t, "100", atTen.Amount.String())
+	assert.NoError(t, twentyErr)
+	assert.Equal(t, "75", atTwenty.Amount.String())
+	assert.Equal(t, "tx3", atTwenty.LastTxHash)
+	assert.Equal(t, int64(20), atTwenty.LastBlockH)
+	assert.ErrorIs(t, beforeErr, repository.ErrBalanceNotFound)
+}
+
+func TestBalanceHistoryRepository_SQLiteMatchesLiveBalanceAfterOverdraft(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	db := newSQLiteDB(t)
+	tokenPath := "gno.land/r/demo/foo"
+	tokenRepo := repository.NewTokenRepository(db)
+	assert.NoError(t, tokenRepo.Create(ctx, &domain.Token{Path: tokenPath, Symbol: "FOO", Decimals: 6}))
+	balanceRepo := repository.NewBalanceRepository(db)
+	historyRepo := repository.NewBalanceHistoryRepository(db)
+	balanceService := service.NewBalanceService(repository.NewTransactor(db), balanceRepo, tokenRepo, historyRepo, nil)
+
+	// Execute
+	// The burn overdraws the balance, which is clamped to 0 before the second mint
+	for i, event := range []*domain.ParsedEvent{
+		{Type: "MINT", TokenPath: tokenPath, ToAddress: "g1abc", Amount: 50},
+		{Type: "BURN", TokenPath: tokenPath, FromAddress: "g1abc", Amount: 100},
+		{Type: "MINT", TokenPath: tokenPath, ToAddress: "g1abc", Amount: 20},
+	} {
+		event.TxHash = fmt.Sprintf("tx%d", i)
+		event.BlockHeight = int64(10 + i)
+		assert.NoError(t, balanceService.ProcessEvent(ctx, event))
+	}
+	live, liveErr := balanceRepo.GetBalance(ctx, tokenPath, "g1abc")
+	historical, historyErr := historyRepo.GetBalanceAt(ctx, tokenPath, "g1abc", 12)
+
+	// Assert
+	assert.NoError(t, liveErr)
+	assert.Equal(t, "20", live.Amount.String())
+	assert.NoError(t, historyErr)
+	assert.Equal(t, live.Amount.String(), historical.Amount.String())
+}
+
+func TestTransactor_SQLiteRollsBackBalanceAndHistory(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	db := newSQLiteDB(t)
+	tokenPath := "gno.land/r/demo/foo"
+	assert.NoError(t, repository.NewTokenRepository(db).Create(ctx, &domain.Token{Path: tokenPath, Symbol: "FOO", Decimals: 6}))
+	balanceRepo := repository.NewBalanceRepository(db)
+	historyRepo := repository.NewBalanceHistoryRepository(db)
+	failure := errors.New("failed after writing")
+
+	// Execute
+	err := repository.NewTransactor(db).Transaction(ctx, func(ctx context.Context) error {
+		if _, err := historyRepo.Record(ctx, &domain.BalanceHistory{Address: "g1abc", TokenPath: tokenPath, Delta: 100, TxHash: "tx1", BlockHeight: 10}); err != nil {
+			return err
+		}
+		if err := balanceRepo.Create(ctx, &domain.Balance{Address: "g1abc", TokenPath: tokenPath, Amount: u64(t, "100"), LastTxHash: "tx1", LastBlockH: 10}); err != nil {
+			return err
+		}
+		return failure
+	})
+	_, balanceErr := balanceRepo.GetBalance(ctx, tokenPath, "g1abc")
+	_, historyErr := historyRepo.GetBalanceAt(ctx, tokenPath, "g1abc", 10)
+
+	// Assert
+	assert.ErrorIs(t, err, failure)
+	assert.ErrorIs(t, balanceErr, repository.ErrBalanceNotFound)
+	assert.ErrorIs(t, historyErr, repository.ErrBalanceNotFound)
+}
+
+func TestAllowanceRepository_SQLiteFiltersAndPaginates(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	db := newSQLiteDB(t)
+	tokenRepo := repository.NewTokenRepository(db)
+	for _, tokenPath := range []string{"gno.land/r/demo/foo", "gno.land/r/demo/bar"} {
+		assert.NoError(t, tokenRepo.Create(ctx, &domain.Token{Path: tokenPath, Symbol: "TKN", Decimals: 6}))
+	}
+	allowanceRepo := repository.NewAllowanceRepository(db)
+	for _, allowance := range []*domain.Allowance{
+		{Owner: "g1owner", Spender: "g1alice", TokenPath: "gno.land/r/demo/foo", Amount: u64(t, "1")},
+		{Owner: "g1owner", Spender: "g1bob", TokenPath: "gno.land/r/demo/foo", Amount: u64(t, "2")},
+		{Owner: "g1owner", Spender: "g1carol", TokenPath: "gno.land/r/demo/foo", Amount: u64(t, "3")},
+		{Owner: "g1owner", Spender: "g1alice", TokenPath: "gno.land/r/demo/bar", Amount: u64(t, "4")},
+		{Owner: "g1other", Spender: "g1alice", TokenPath: "gno.land/r/demo/foo", Amount: u64(t, "5")},
+	} {
+		assert.NoError(t, allowanceRepo.Save(ctx, allowance))
+	}
+
+	// Execute
+	page, total, pageErr := allowanceRepo.ListAllowances(ctx, repository.AllowanceQuery{Owner: "g1owner", TokenPath: "gno.land/r/demo/foo", Offset: 1, Limit: 1})
+	pair, pairTotal, pairErr := allowanceRepo.ListAllowances(ctx, repository.AllowanceQuery{Owner: "g1owner", Spender: "g1alice"})
+	received, receivedTotal, receivedErr := allowanceRepo.ListAllowances(ctx, repository.AllowanceQuery{Spender: "g1alice", TokenPath: "gno.land/r/demo/foo"})
+
+	// Assert
+	assert.NoError(t, pageErr)
+	assert.Equal(t, int64(3), total)
+	if assert.Len(t, page, 1) {
+		assert.Equal(t, "g1bob", page[0].Spender)
+	}
+	assert.NoError(t, pairErr)
+	assert.Equal(t, int64(2), pairTotal)
+	assert.Len(t, pair, 2)
+	assert.NoError(t, receivedErr)
+	assert.Equal(t, int64(2), receivedTotal)
+	if assert.Len(t, received, 2) {
+		assert.Equal(t, "g1other", received[0].Owner)
+		assert.Equal(t, "g1owner", received[1].Owner)
+	}
+}
+
+func TestWebhookRepository_SQLiteScopesToAPIKey(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	db := newSQLiteDB(t)
+	keyRepo := repository.NewAPIKeyRepository(db)
+	partnerA := &domain.APIKey{Name: "a", KeyPrefix: "a", KeyHash: "hash-a", RateLimit: 1, Burst: 1}
+	partnerB := &domain.APIKey{Name: "b", KeyPrefix: "b", KeyHash: "hash-b", RateLimit: 1, Burst: 1}
+	assert.NoError(t, keyRepo.Create(ctx, partnerA))
+	assert.NoError(t, keyRepo.Create(ctx, partnerB))
+	webhookRepo := repository.NewWebhookRepository(db)
+	webhook := &domain.Webhook{URL: "https://a.example.com/hook", Secret: "s", Active: true, APIKeyID: &partnerA.ID}
+	assert.NoError(t, webhookRepo.Create(ctx, webhook))
+
+	// Execute
+	listedByB, listErr := webhookRepo.List(ctx, &partnerB.ID)
+	listedWithoutKey, anonymousErr := webhookRepo.List(ctx, nil)
+	_, getErr := webhookRepo.GetOwned(ctx, &partnerB.ID, webhook.ID)
+	deleteErr := webhookRepo.Delete(ctx, &partnerB.ID, webhook.ID)
+	owned, ownedErr := webhookRepo.GetOwned(ctx, &partnerA.ID, webhook.ID)
+
+	// Assert
+	assert.NoError(t, listErr)
+	assert.Empty(t, listedByB)
+	assert.NoError(t, anonymousErr)
+	assert.Empty(t, listedWithoutKey)
+	assert.ErrorIs(t, getErr, repository.ErrWebhookNotFound)
+	assert.ErrorIs(t, deleteErr, repository.ErrWebhookNotFound)
+	assert.NoError(t, ownedErr)
+	assert.Equal(t, webhook.URL, owned.URL)
+}
+
+func TestWebhookRepository_SQLiteClaimsDeliveriesOnce(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	db := newSQLiteDB(t)
+	webhookRepo := repository.NewWebhookRepository(db)
+	webhook := &domain.Webhook{URL: "https://example.com/hook", Secret: "s", Active: true}
+	assert.NoError(t, webhookRepo.Create(ctx, webhook))
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		delivery := &domain.WebhookDelivery{WebhookID: webhook.ID, Payload: "{}", Status: domain.DeliveryStatusPending, NextAttemptAt: now.Add(-time.Minute)}
+		assert.NoError(t, webhookRepo.CreateDelivery(ctx, delivery))
+	}
+
+	// Execute
+	first, firstErr := webhookRepo.ClaimDueDeliveries(ctx, now, time.Minute, 2)
+	second, secondErr := webhookRepo.ClaimDueDeliveries(ctx, now, time.Minute, 2)
+	third, thirdErr := webhookRepo.ClaimDueDeliveries(ctx, now, time.Minute, 2)
+	afterLease, afterLeaseErr := webhookRepo.ClaimDueDeliveries(ctx, now.Add(2*time.Minute), time.Minute, 10)
+
+	// Assert
+	assert.NoError(t, firstErr)
+	assert.Len(t, first, 2)
+	assert.NoError(t, secondErr)
+	if assert.Len(t, second, 1) {
+		assert.NotContains(t, []int64{first[0].ID, first[1].ID}, second[0].ID)
+	}
+	assert.NoError(t, thirdErr)
+	assert.Empty(t, third)
+	assert.NoError(t, afterLeaseErr)
+	assert.Len(t, afterLease, 3)
+}
+
+func TestSnapshotJobRepository_SQLiteClaimsAndRequeuesJobs(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	db := newSQLiteDB(t)
+	jobRepo := repository.NewSnapshotJobRepository(db)
+	for _, tokenPath := range []string{"gno.land/r/demo/foo", "gno.land/r/demo/bar"} {
+		job := &domain.SnapshotJob{TokenPath: tokenPath, Format: "csv", MinAmount: u64(t, "10"), Exclude: []string{"g1treasury"}, Status: domain.SnapshotStatusPending}
+		assert.NoError(t, jobRepo.Create(ctx, job))
+	}
+
+	// Execute
+	pending, countErr := jobRepo.CountPending(ctx)
+	first, firstErr := jobRepo.ClaimPending(ctx)
+	second, secondErr := jobRepo.ClaimPending(ctx)
+	_, emptyErr := jobRepo.ClaimPending(ctx)
+	requeued, requeueErr := jobRepo.RequeueRunning(ctx)
+	again, againErr := jobRepo.ClaimPending(ctx)
+
+	// Assert
+	assert.NoError(t, countErr)
+	assert.Equal(t, int64(2), pending)
+	assert.NoError(t, firstErr)
+	assert.Equal(t, "gno.land/r/demo/foo", first.TokenPath)
+	assert.Equal(t, domain.SnapshotStatusRunning, first.Status)
+	assert.Equal(t, "10", first.MinAmount.String())
+	assert.Equal(t, []string{"g1treasury"}, first.Exclude)
+	assert.NoError(t, secondErr)
+	assert.Equal(t, "gno.land/r/demo/bar", second.TokenPath)
+	assert.ErrorIs(t, emptyErr, repository.ErrSnapshotJobNotFound)
+	assert.NoError(t, requeueErr)
+	assert.Equal(t, int64(2), requeued)
+	assert.NoError(t, againErr)
+	assert.Equal(t, first.ID, again.ID)
+}
+
+func TestSnapshotJobRepository_SQLiteScopesToAPIKey(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	db := newSQLiteDB(t)
+	jobRepo := repository.NewSnapshotJobRepository(db)
+	owner, other := int64(1), int64(2)
+	job := &domain.SnapshotJob{TokenPath: "gno.land/r/demo/foo", APIKeyID: &owner, Format: "csv", Status: domain.SnapshotStatusPending}
+	assert.NoError(t, jobRepo.Create(ctx, job))
+
+	// Execute
+	owned, ownedErr := jobRepo.GetOwned(ctx, &owner, job.ID)
+	_, otherErr := jobRepo.GetOwned(ctx, &other, job.ID)
+	_, anonymousErr := jobRepo.GetOwned(ctx, nil, job.ID)
+
+	// Assert
+	assert.NoError(t, ownedErr)
+	assert.Equal(t, job.ID, owned.ID)
+	assert.ErrorIs(t, otherErr, repository.ErrSnapshotJobNotFound)
+	assert.ErrorIs(t, anonymousErr, repository.ErrSnapshotJobNotFound)
+}
@@ -0,0 +1,32 @@
+package repository_test
+
+import (
+	"gn-indexer/internal/domain"
+	"gn-indexer/internal/repository"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransferCursor_EncodeDecode(t *testing.T) {
+	// Setup
+	transfer := &domain.Transfer{ID: 42, BlockHeight: 1000, EventIndex: 3}
+
+	// Execute
+	encoded := repository.NewTransferCursor(transfer).Encode()
+	decoded, err := repository.DecodeTransferCursor(encoded)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1000), decoded.BlockHeight)
+	assert.Equal(t, 3, decoded.EventIndex)
+	assert.Equal(t, int64(42), decoded.ID)
+}
+
+func TestTransferCursor_DecodeInvalid(t *testing.T) {
+	// Execute
+	_, err := repository.DecodeTransferCursor("not-a-cursor")
+
+	// Assert
+	assert.Error(t, err)
+}
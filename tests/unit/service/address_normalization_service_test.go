@@ -0,0 +1,49 @@
+package service_test
+
+import (
+	"context"
+	"gn-indexer/internal/service"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockAddressRepository is a mock implementation of AddressRepository
+type MockAddressRepository struct {
+	mock.Mock
+}
+
+func (m *MockAddressRepository) ListNonCanonical(ctx context.Context) ([]string, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockAddressRepository) Rename(ctx context.Context, from, to string) error {
+	return m.Called(ctx, from, to).Error(0)
+}
+
+func TestAddressNormalizationService_RenamesValidAddresses(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	addressRepo := new(MockAddressRepository)
+	svc := service.NewAddressNormalizationService(addressRepo)
+
+	// Mock expectations
+	addressRepo.On("ListNonCanonical", ctx).Return([]string{
+		"G1JG8MTUTU9KHHFWC4NXMUHCPFTF0PAJDHFVSQF5",
+		"g1Us8428u2a5satrlxzagqqa5m6vmuze025anjlj",
+		"Not-An-Address",
+	}, nil)
+	addressRepo.On("Rename", ctx, "G1JG8MTUTU9KHHFWC4NXMUHCPFTF0PAJDHFVSQF5", "g1jg8mtutu9khhfwc4nxmuhcpftf0pajdhfvsqf5").Return(nil)
+	addressRepo.On("Rename", ctx, "g1Us8428u2a5satrlxzagqqa5m6vmuze025anjlj", "g1us8428u2a5satrlxzagqqa5m6vmuze025anjlj").Return(nil)
+
+	// Execute
+	result, err := svc.NormalizeAddresses(ctx)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.Renamed)
+	assert.Equal(t, []string{"Not-An-Address"}, result.Malformed)
+	addressRepo.AssertExpectations(t)
+}
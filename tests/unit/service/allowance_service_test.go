@@ -0,0 +1,67 @@
+package service_test
+
+import (
+	"context"
+	"gn-indexer/internal/domain"
+	"gn-indexer/internal/repository"
+	"gn-indexer/internal/service"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockAllowanceRepository is a mock implementation of AllowanceRepository
+type MockAllowanceRepository struct {
+	mock.Mock
+}
+
+func (m *MockAllowanceRepository) Save(ctx context.Context, allowance *domain.Allowance) error {
+	args := m.Called(ctx, allowance)
+	return args.Error(0)
+}
+
+func (m *MockAllowanceRepository) GetAllowance(ctx context.Context, tokenPath, owner, spender string) (*domain.Allowance, error) {
+	args := m.Called(ctx, tokenPath, owner, spender)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Allowance), args.Error(1)
+}
+
+func (m *MockAllowanceRepository) ListAllowances(ctx context.Context, query repository.AllowanceQuery) ([]*domain.Allowance, int64, error) {
+	args := m.Called(ctx, query)
+	return args.Get(0).([]*domain.Allowance), args.Get(1).(int64), args.Error(2)
+}
+
+func TestAllowanceService_OrdersApprovalsWithinABlock(t *testing.T) {
+	// Setup
+	mockAllowanceRepo := new(MockAllowanceRepository)
+	allowanceService := service.NewAllowanceService(mockAllowanceRepo)
+	ctx := context.Background()
+	stored := &domain.Allowance{Owner: "g1owner", Spender: "g1spender", TokenPath: "gno.land/r/demo/foo", Amount: domain.NewU64(50), LastTxHash: "tx2", LastBlockH: 100, LastEventIndex: 2}
+	earlier := &domain.ParsedEvent{
+		Func: domain.EventTypeApproval, TokenPath: "gno.land/r/demo/foo", FromAddress: "g1owner", ToAddress: "g1spender",
+		Amount: 10, TxHash: "tx1", BlockHeight: 100, EventIndex: 1,
+	}
+	later := &domain.ParsedEvent{
+		Func: domain.EventTypeApproval, TokenPath: "gno.land/r/demo/foo", FromAddress: "g1owner", ToAddress: "g1spender",
+		Amount: 70, TxHash: "tx3", BlockHeight: 100, EventIndex: 4,
+	}
+
+	// Mock expectations
+	mockAllowanceRepo.On("GetAllowance", ctx, "gno.land/r/demo/foo", "g1owner", "g1spender").Return(stored, nil)
+	mockAllowanceRepo.On("Save", ctx, mock.MatchedBy(func(allowance *domain.Allowance) bool {
+		return allowance.Amount.String() == "70" && allowance.LastBlockH == 100 && allowance.LastEventIndex == 4
+	})).Return(nil).Once()
+
+	// Execute
+	earlierErr := allowanceService.ProcessEvent(ctx, earlier)
+	laterErr := allowanceService.ProcessEvent(ctx, later)
+
+	// Assert
+	assert.NoError(t, earlierErr)
+	assert.NoError(t, laterErr)
+	mockAllowanceRepo.AssertExpectations(t)
+	mockAllowanceRepo.AssertNumberOfCalls(t, "Save", 1)
+}
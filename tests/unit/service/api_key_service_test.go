@@ -0,0 +1,140 @@
+package service_test
+
+import (
+	"context"
+	"gn-indexer/internal/domain"
+	"gn-indexer/internal/repository"
+	"gn-indexer/internal/service"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockAPIKeyRepository is a mock implementation of APIKeyRepository
+type MockAPIKeyRepository struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyRepository) Create(ctx context.Context, key *domain.APIKey) error {
+	return m.Called(ctx, key).Error(0)
+}
+
+func (m *MockAPIKeyRepository) GetByID(ctx context.Context, id int64) (*domain.APIKey, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) GetByHash(ctx context.Context, keyHash string) (*domain.APIKey, error) {
+	args := m.Called(ctx, keyHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) List(ctx context.Context) ([]*domain.APIKey, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]*domain.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) Revoke(ctx context.Context, id int64, at time.Time) error {
+	return m.Called(ctx, id, at).Error(0)
+}
+
+func (m *MockAPIKeyRepository) AddUsage(ctx context.Context, keyID int64, day time.Time, requests, rejected int64) error {
+	return m.Called(ctx, keyID, day, requests, rejected).Error(0)
+}
+
+func (m *MockAPIKeyRepository) GetUsage(ctx context.Context, keyID int64, fromDay time.Time) ([]*domain.APIKeyUsage, error) {
+	args := m.Called(ctx, keyID, fromDay)
+	return args.Get(0).([]*domain.APIKeyUsage), args.Error(1)
+}
+
+func TestAPIKeyService_IssueAndAuthenticate(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	apiKeyRepo := new(MockAPIKeyRepository)
+	svc := service.NewAPIKeyService(apiKeyRepo)
+
+	// Mock expectations
+	apiKeyRepo.On("Create", ctx, mock.AnythingOfType("*domain.APIKey")).Return(nil)
+
+	// Execute
+	secret, key, err := svc.Issue(ctx, service.APIKeyRequest{Name: "partner"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(secret, key.KeyPrefix))
+	assert.Equal(t, service.HashAPIKey(secret), key.KeyHash)
+	assert.NotContains(t, key.KeyHash, secret)
+	assert.Equal(t, service.DefaultAPIKeyRateLimit, key.RateLimit)
+	assert.Equal(t, service.DefaultAPIKeyBurst, key.Burst)
+
+	// The key is looked up once, then served from the cache
+	apiKeyRepo.On("GetByHash", ctx, key.KeyHash).Return(key, nil).Once()
+	apiKeyRepo.On("GetByHash", ctx, service.HashAPIKey("gnk_unknown")).Return(nil, repository.ErrAPIKeyNotFound)
+
+	authenticated, err := svc.Authenticate(ctx, secret)
+	assert.NoError(t, err)
+	assert.Equal(t, key, authenticated)
+	_, err = svc.Authenticate(ctx, secret)
+	assert.NoError(t, err)
+
+	_, err = svc.Authenticate(ctx, "gnk_unknown")
+	assert.ErrorIs(t, err, service.ErrInvalidAPIKey)
+	apiKeyRepo.AssertExpectations(t)
+}
+
+func TestAPIKeyService_RejectsRevokedKey(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	apiKeyRepo := new(MockAPIKeyRepository)
+	svc := service.NewAPIKeyService(apiKeyRepo)
+	revokedAt := time.Now()
+	key := &domain.APIKey{ID: 1, KeyHash: service.HashAPIKey("gnk_revoked"), RevokedAt: &revokedAt}
+
+	// Mock expectations
+	apiKeyRepo.On("GetByHash", ctx, key.KeyHash).Return(key, nil)
+
+	// Execute
+	_, err := svc.Authenticate(ctx, "gnk_revoked")
+
+	// Assert
+	assert.ErrorIs(t, err, service.ErrInvalidAPIKey)
+}
+
+func TestAPIKeyService_EnforcesDailyQuotaAndFlushesUsage(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	apiKeyRepo := new(MockAPIKeyRepository)
+	svc := service.NewAPIKeyService(apiKeyRepo)
+	key := &domain.APIKey{ID: 7, DailyQuota: 5}
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	// Mock expectations
+	apiKeyRepo.On("GetUsage", ctx, int64(7), today).Return([]*domain.APIKeyUsage{
+		{APIKeyID: 7, Day: today, Requests: 3},
+	}, nil).Once()
+	apiKeyRepo.On("AddUsage", ctx, int64(7), today, int64(2), int64(1)).Return(nil).Once()
+
+	// Execute
+	first, err1 := svc.ConsumeQuota(ctx, key)
+	second, err2 := svc.ConsumeQuota(ctx, key)
+	_, err3 := svc.ConsumeQuota(ctx, key)
+	flushErr := svc.Flush(ctx)
+
+	// Assert
+	assert.NoError(t, err1)
+	assert.Equal(t, int64(1), first)
+	assert.NoError(t, err2)
+	assert.Equal(t, int64(0), second)
+	assert.ErrorIs(t, err3, service.ErrQuotaExceeded)
+	assert.NoError(t, flushErr)
+	apiKeyRepo.AssertExpectations(t)
+}
@@ -2,6 +2,7 @@ package service_test
 
 import (
 	"context"
+	"errors"
 	"gn-indexer/internal/domain"
 	"gn-indexer/internal/repository"
 	"gn-indexer/internal/service"
@@ -59,6 +60,32 @@ func (m *MockBalanceRepository) GetAllBalances(ctx context.Context) ([]*domain.B
 	return args.Get(0).([]*domain.Balance), args.Error(1)
 }
 
+func (m *MockBalanceRepository) ListBalances(ctx context.Context, query repository.BalanceQuery) ([]*domain.Balance, int64, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*domain.Balance), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockBalanceRepository) GetTokenHolderSummary(ctx context.Context, tokenPath string) (*repository.TokenHolderSummary, error) {
+	args := m.Called(ctx, tokenPath)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.TokenHolderSummary), args.Error(1)
+}
+
+func (m *MockBalanceRepository) StreamBalances(ctx context.Context, query repository.BalanceQuery, fn func(*domain.Balance) error) error {
+	args := m.Called(ctx, query)
+	for _, balance := range args.Get(0).([]*domain.Balance) {
+		if err := fn(balance); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
 type MockTokenRepository struct {
 	mock.Mock
 }
@@ -89,11 +116,75 @@ func (m *MockTokenRepository) GetAll(ctx context.Context) ([]domain.Token, error
 	return args.Get(0).([]domain.Token), args.Error(1)
 }
 
+func (m *MockTokenRepository) GetPendingMetadata(ctx context.Context) ([]domain.Token, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Token), args.Error(1)
+}
+
+func (m *MockTokenRepository) UpdateMetadata(ctx context.Context, token *domain.Token) error {
+	args := m.Called(ctx, token)
+	return args.Error(0)
+}
+
+func (m *MockTokenRepository) GetByPaths(ctx context.Context, tokenPaths []string) ([]domain.Token, error) {
+	args := m.Called(ctx, tokenPaths)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Token), args.Error(1)
+}
+
+// MockBalanceHistoryRepository is a mock implementation of BalanceHistoryRepository
+type MockBalanceHistoryRepository struct {
+	mock.Mock
+}
+
+func (m *MockBalanceHistoryRepository) Record(ctx context.Context, history *domain.BalanceHistory) (bool, error) {
+	args := m.Called(ctx, history)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockBalanceHistoryRepository) GetBalanceAt(ctx context.Context, tokenPath, address string, height int64) (*domain.Balance, error) {
+	args := m.Called(ctx, tokenPath, address, height)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Balance), args.Error(1)
+}
+
+func (m *MockBalanceHistoryRepository) ListBalancesAt(ctx context.Context, height int64, query repository.BalanceQuery) ([]*domain.Balance, int64, error) {
+	args := m.Called(ctx, height, query)
+	return args.Get(0).([]*domain.Balance), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockBalanceHistoryRepository) StreamBalancesAt(ctx context.Context, height int64, query repository.BalanceQuery, fn func(*domain.Balance) error) error {
+	args := m.Called(ctx, height, query)
+	for _, balance := range args.Get(0).([]*domain.Balance) {
+		if err := fn(balance); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
+// MockPublisher is a mock implementation of service.Publisher
+type MockPublisher struct {
+	mock.Mock
+}
+
+func (m *MockPublisher) Publish(ctx context.Context, notification *domain.Notification) error {
+	args := m.Called(ctx, notification)
+	return args.Error(0)
+}
+
 func TestBalanceService_ProcessMintEvent(t *testing.T) {
 	// Setup
 	mockBalanceRepo := new(MockBalanceRepository)
 	mockTokenRepo := new(MockTokenRepository)
-	balanceService := service.NewBalanceService(mockBalanceRepo, mockTokenRepo)
+	balanceService := service.NewBalanceService(nil, mockBalanceRepo, mockTokenRepo, nil, nil)
 	ctx := context.Background()
 
 	event := &domain.ParsedEvent{
@@ -121,7 +212,7 @@ func TestBalanceService_ProcessBurnEvent(t *testing.T) {
 	// Setup
 	mockBalanceRepo := new(MockBalanceRepository)
 	mockTokenRepo := new(MockTokenRepository)
-	balanceService := service.NewBalanceService(mockBalanceRepo, mockTokenRepo)
+	balanceService := service.NewBalanceService(nil, mockBalanceRepo, mockTokenRepo, nil, nil)
 	ctx := context.Background()
 
 	event := &domain.ParsedEvent{
@@ -157,7 +248,7 @@ func TestBalanceService_ProcessTransferEvent(t *testing.T) {
 	// Setup
 	mockBalanceRepo := new(MockBalanceRepository)
 	mockTokenRepo := new(MockTokenRepository)
-	balanceService := service.NewBalanceService(mockBalanceRepo, mockTokenRepo)
+	balanceService := service.NewBalanceService(nil, mockBalanceRepo, mockTokenRepo, nil, nil)
 	ctx := context.Background()
 
 	event := &domain.ParsedEvent{
@@ -193,3 +284,233 @@ func TestBalanceService_ProcessTransferEvent(t *testing.T) {
 	assert.NoError(t, err)
 	mockBalanceRepo.AssertExpectations(t)
 }
+
+func TestBalanceService_RecordsBalanceHistory(t *testing.T) {
+	// Setup
+	mockBalanceRepo := new(MockBalanceRepository)
+	mockTokenRepo := new(MockTokenRepository)
+	mockHistoryRepo := new(MockBalanceHistoryRepository)
+	balanceService := service.NewBalanceService(nil, mockBalanceRepo, mockTokenRepo, mockHistoryRepo, nil)
+	ctx := context.Background()
+
+	event := &domain.ParsedEvent{
+		Type:        "MINT",
+		TokenPath:   "test-token",
+		ToAddress:   "test-address",
+		Amount:      100,
+		TxHash:      "test-tx",
+		BlockHeight: 1000,
+		EventIndex:  2,
+	}
+
+	// Mock expectations
+	mockBalanceRepo.On("GetBalance", ctx, "test-token", "test-address").Return(&domain.Balance{
+		TokenPath: "test-token",
+		Address:   "test-address",
+		Amount:    domain.NewU64(20),
+	}, nil)
+	mockBalanceRepo.On("Update", ctx, mock.MatchedBy(func(balance *domain.Balance) bool {
+		return balance.LastTxHash == "test-tx" && balance.LastBlockH == 1000
+	})).Return(nil)
+	mockHistoryRepo.On("Record", ctx, mock.MatchedBy(func(history *domain.BalanceHistory) bool {
+		return history.Address == "test-address" &&
+			history.Delta == 100 &&
+			history.TxHash == "test-tx" &&
+			history.EventIndex == 2 &&
+			history.BlockHeight == 1000
+	})).Return(true, nil)
+
+	// Execute
+	err := balanceService.ProcessEvent(ctx, event)
+
+	// Assert
+	assert.NoError(t, err)
+	mockBalanceRepo.AssertExpectations(t)
+	mockHistoryRepo.AssertExpectations(t)
+}
+
+func TestBalanceService_RecordsAppliedDeltaOfOverdraft(t *testing.T) {
+	// Setup
+	mockBalanceRepo := new(MockBalanceRepository)
+	mockTokenRepo := new(MockTokenRepository)
+	mockHistoryRepo := new(MockBalanceHistoryRepository)
+	balanceService := service.NewBalanceService(nil, mockBalanceRepo, mockTokenRepo, mockHistoryRepo, nil)
+	ctx := context.Background()
+
+	event := &domain.ParsedEvent{
+		Type:        "BURN",
+		TokenPath:   "test-token",
+		FromAddress: "test-address",
+		Amount:      100,
+		TxHash:      "test-tx",
+		BlockHeight: 1000,
+	}
+
+	// Mock expectations
+	mockBalanceRepo.On("GetBalance", ctx, "test-token", "test-address").Return(&domain.Balance{
+		TokenPath: "test-token",
+		Address:   "test-address",
+		Amount:    domain.NewU64(30),
+	}, nil)
+	mockBalanceRepo.On("Update", ctx, mock.MatchedBy(func(balance *domain.Balance) bool {
+		return balance.Amount.Int64() == 0
+	})).Return(nil)
+	mockHistoryRepo.On("Record", ctx, mock.MatchedBy(func(history *domain.BalanceHistory) bool {
+		return history.Delta == -30
+	})).Return(true, nil)
+
+	// Execute
+	err := balanceService.ProcessEvent(ctx, event)
+
+	// Assert
+	assert.NoError(t, err)
+	mockBalanceRepo.AssertExpectations(t)
+	mockHistoryRepo.AssertExpectations(t)
+}
+
+func TestBalanceService_SkipsRedeliveredEvent(t *testing.T) {
+	// Setup
+	mockBalanceRepo := new(MockBalanceRepository)
+	mockTokenRepo := new(MockTokenRepository)
+	mockHistoryRepo := new(MockBalanceHistoryRepository)
+	mockPublisher := new(MockPublisher)
+	balanceService := service.NewBalanceService(nil, mockBalanceRepo, mockTokenRepo, mockHistoryRepo, nil, mockPublisher)
+	ctx := context.Background()
+
+	event := &domain.ParsedEvent{
+		Type:        "TRANSFER",
+		TokenPath:   "test-token",
+		FromAddress: "from-address",
+		ToAddress:   "to-address",
+		Amount:      100,
+		TxHash:      "test-tx",
+		BlockHeight: 1000,
+	}
+
+	// Mock expectations
+	mockBalanceRepo.On("GetBalance", ctx, "test-token", mock.Anything).Return(&domain.Balance{Amount: domain.NewU64(100)}, nil)
+	mockHistoryRepo.On("Record", ctx, mock.AnythingOfType("*domain.BalanceHistory")).Return(false, nil)
+
+	// Execute
+	err := balanceService.ProcessEvent(ctx, event)
+
+	// Assert
+	assert.NoError(t, err)
+	mockHistoryRepo.AssertNumberOfCalls(t, "Record", 2)
+	mockBalanceRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	mockBalanceRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	mockPublisher.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+}
+
+// failingTransactor runs fn like a transaction and reports a commit failure
+type failingTransactor struct {
+	err error
+}
+
+func (t *failingTransactor) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if err := fn(ctx); err != nil {
+		return err
+	}
+	return t.err
+}
+
+func TestBalanceService_PublishesNothingWhenCommitFails(t *testing.T) {
+	// Setup
+	mockBalanceRepo := new(MockBalanceRepository)
+	mockTokenRepo := new(MockTokenRepository)
+	mockHistoryRepo := new(MockBalanceHistoryRepository)
+	mockPublisher := new(MockPublisher)
+	transactor := &failingTransactor{err: errors.New("commit failed")}
+	balanceService := service.NewBalanceService(transactor, mockBalanceRepo, mockTokenRepo, mockHistoryRepo, nil, mockPublisher)
+	ctx := context.Background()
+
+	event := &domain.ParsedEvent{
+		Type:        "MINT",
+		TokenPath:   "test-token",
+		ToAddress:   "test-address",
+		Amount:      100,
+		TxHash:      "test-tx",
+		BlockHeight: 1000,
+	}
+
+	// Mock expectations
+	mockHistoryRepo.On("Record", ctx, mock.AnythingOfType("*domain.BalanceHistory")).Return(true, nil)
+	mockBalanceRepo.On("GetBalance", ctx, "test-token", "test-address").Return(nil, repository.ErrBalanceNotFound)
+	mockBalanceRepo.On("Update", ctx, mock.AnythingOfType("*domain.Balance")).Return(nil)
+
+	// Execute
+	err := balanceService.ProcessEvent(ctx, event)
+
+	// Assert
+	assert.ErrorContains(t, err, "commit failed")
+	mockPublisher.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+}
+
+func TestBalanceService_PublishesNotifications(t *testing.T) {
+	// Setup
+	mockBalanceRepo := new(MockBalanceRepository)
+	mockTokenRepo := new(MockTokenRepository)
+	mockPublisher := new(MockPublisher)
+	balanceService := service.NewBalanceService(nil, mockBalanceRepo, mockTokenRepo, nil, nil, mockPublisher)
+	ctx := context.Background()
+
+	event := &domain.ParsedEvent{
+		Type:      "MINT",
+		TokenPath: "test-token",
+		ToAddress: "test-address",
+		Amount:    100,
+		TxHash:    "test-tx",
+	}
+
+	// Mock expectations
+	mockBalanceRepo.On("GetBalance", ctx, "test-token", "test-address").Return(nil, repository.ErrBalanceNotFound)
+	mockBalanceRepo.On("Update", ctx, mock.AnythingOfType("*domain.Balance")).Return(repository.ErrBalanceNotFound)
+	mockBalanceRepo.On("Create", ctx, mock.AnythingOfType("*domain.Balance")).Return(nil)
+	mockPublisher.On("Publish", ctx, mock.MatchedBy(func(n *domain.Notification) bool {
+		return n.Kind == domain.NotificationBalance && n.Address == "test-address" && n.Balance == "100"
+	})).Return(nil).Once()
+	mockPublisher.On("Publish", ctx, mock.MatchedBy(func(n *domain.Notification) bool {
+		return n.Kind == domain.NotificationTransfer && n.ToAddress == "test-address" && n.Amount == "100"
+	})).Return(errors.New("publisher unavailable")).Once()
+
+	// Execute
+	err := balanceService.ProcessEvent(ctx, event)
+
+	// Assert
+	assert.NoError(t, err, "publish failures must not fail event processing")
+	mockPublisher.AssertExpectations(t)
+}
+
+func TestBalanceService_OutboxFailureFailsEvent(t *testing.T) {
+	// Setup
+	mockBalanceRepo := new(MockBalanceRepository)
+	mockTokenRepo := new(MockTokenRepository)
+	mockOutbox := new(MockPublisher)
+	mockPublisher := new(MockPublisher)
+	transactor := &failingTransactor{}
+	balanceService := service.NewBalanceService(transactor, mockBalanceRepo, mockTokenRepo, nil, mockOutbox, mockPublisher)
+	ctx := context.Background()
+
+	event := &domain.ParsedEvent{
+		Type:      "MINT",
+		TokenPath: "test-token",
+		ToAddress: "test-address",
+		Amount:    100,
+		TxHash:    "test-tx",
+	}
+
+	// Mock expectations
+	mockBalanceRepo.On("GetBalance", ctx, "test-token", "test-address").Return(nil, repository.ErrBalanceNotFound)
+	mockBalanceRepo.On("Update", ctx, mock.AnythingOfType("*domain.Balance")).Return(nil)
+	mockOutbox.On("Publish", ctx, mock.MatchedBy(func(n *domain.Notification) bool {
+		return n.Kind == domain.NotificationTransfer && n.ToAddress == "test-address"
+	})).Return(errors.New("database unavailable")).Once()
+
+	// Execute
+	err := balanceService.ProcessEvent(ctx, event)
+
+	// Assert
+	assert.ErrorContains(t, err, "queue transfer notification")
+	mockOutbox.AssertExpectations(t)
+	mockPublisher.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,151 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"gn-indexer/internal/config"
+	"gn-indexer/internal/domain"
+	"gn-indexer/internal/migrate"
+	"gn-indexer/internal/repository"
+	"gn-indexer/internal/service"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// newEventStorageDB opens a migrated SQLite database holding the block and transaction of tx
+func newEventStorageDB(t *testing.T, tx *domain.Transaction) *gorm.DB {
+	cfg := config.DefaultMigrateConfig().Database
+	cfg.DSN = "sqlite://" + filepath.Join(t.TempDir(), "indexer.db")
+	db, err := cfg.Connect()
+	assert.NoError(t, err)
+	migrator, err := migrate.ForDatabase(db)
+	assert.NoError(t, err)
+	_, err = migrator.Up(context.Background())
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+	block := domain.Block{Hash: "block-1", Height: tx.BlockHeight, Time: time.Now(), NumTxs: 1, TotalTxs: 1}
+	assert.NoError(t, repository.NewBlockRepository(db).SaveBlock(ctx, block))
+	assert.NoError(t, repository.NewTransactionRepository(db).SaveTransaction(ctx, *tx))
+	return db
+}
+
+// rawEventsTx is a transaction with two raw events around an empty union member, none of them a token event
+func rawEventsTx() *domain.Transaction {
+	return &domain.Transaction{
+		Hash:        "tx-1",
+		BlockHeight: 1,
+		Success:     true,
+		Response: &domain.TransactionResponse{Events: []domain.GnoEvent{
+			{Type: "ProposalCreated", Func: "Propose", PkgPath: "gno.land/r/gov/dao", Attrs: []domain.Attr{
+				{Key: "id", Value: "7"},
+				{Key: "title", Value: "Raise quota"},
+				{Key: "id", Value: "7"}, // duplicated keys are kept as emitted
+			}},
+			{},
+			{Type: "Voted", Func: "Vote", PkgPath: "gno.land/r/gov/dao", Attrs: []domain.Attr{
+				{Key: "choice", Value: ""},
+			}},
+		}},
+	}
+}
+
+// storedAttrs returns the key=value pairs of the stored attributes of each event index
+func storedAttrs(t *testing.T, db *gorm.DB, txHash string) map[int][]string {
+	ctx := context.Background()
+	events, err := repository.NewEventRepository(db).GetByTxHash(ctx, txHash)
+	assert.NoError(t, err)
+
+	stored := make(map[int][]string)
+	for _, event := range events {
+		attrs, err := repository.NewEventAttrRepository(db).GetByEventID(ctx, event.ID)
+		assert.NoError(t, err)
+		pairs := []string{}
+		for _, attr := range attrs {
+			pairs = append(pairs, attr.Key+"="+attr.Value)
+		}
+		stored[event.EventIndex] = pairs
+	}
+	return stored
+}
+
+func TestEventStorageService_StoresRawEventsOnceAcrossRedelivery(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	tx := rawEventsTx()
+	db := newEventStorageDB(t, tx)
+	eventStorageService := service.NewEventStorageService(
+		repository.NewTransactor(db),
+		repository.NewEventRepository(db),
+		repository.NewEventAttrRepository(db),
+		repository.NewTransferRepository(db),
+		repository.NewTokenRepository(db),
+		repository.NewNFTRepository(db),
+		nil,
+	)
+
+	// Execute
+	err := eventStorageService.ProcessTransaction(ctx, tx)
+	redeliveryErr := eventStorageService.ProcessTransaction(ctx, tx)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, redeliveryErr)
+	assert.Equal(t, map[int][]string{
+		0: {"id=7", "title=Raise quota", "id=7"},
+		2: {"choice="},
+	}, storedAttrs(t, db, tx.Hash))
+	var attrCount int64
+	assert.NoError(t, db.Model(&domain.TxEventAttr{}).Count(&attrCount).Error)
+	assert.Equal(t, int64(4), attrCount)
+}
+
+// failingEventAttrRepository fails to store the attribute at failIndex
+type failingEventAttrRepository struct {
+	repository.EventAttrRepository
+	failIndex int
+}
+
+func (r *failingEventAttrRepository) Create(ctx context.Context, attr *domain.TxEventAttr) error {
+	if attr.AttrIndex == r.failIndex {
+		return errors.New("connection reset")
+	}
+	return r.EventAttrRepository.Create(ctx, attr)
+}
+
+func TestEventStorageService_RollsBackRawEventsWhenAnAttrFails(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	tx := rawEventsTx()
+	db := newEventStorageDB(t, tx)
+	newService := func(eventAttrRepo repository.EventAttrRepository) *service.EventStorageService {
+		return service.NewEventStorageService(
+			repository.NewTransactor(db),
+			repository.NewEventRepository(db),
+			eventAttrRepo,
+			repository.NewTransferRepository(db),
+			repository.NewTokenRepository(db),
+			repository.NewNFTRepository(db),
+			nil,
+		)
+	}
+	failingService := newService(&failingEventAttrRepository{EventAttrRepository: repository.NewEventAttrRepository(db), failIndex: 2})
+
+	// Execute
+	err := failingService.ProcessTransaction(ctx, tx)
+	afterFailure := storedAttrs(t, db, tx.Hash)
+	retryErr := newService(repository.NewEventAttrRepository(db)).ProcessTransaction(ctx, tx)
+
+	// Assert
+	assert.ErrorContains(t, err, "connection reset")
+	assert.Empty(t, afterFailure)
+	assert.NoError(t, retryErr)
+	assert.Equal(t, map[int][]string{
+		0: {"id=7", "title=Raise quota", "id=7"},
+		2: {"choice="},
+	}, storedAttrs(t, db, tx.Hash))
+}
@@ -0,0 +1,81 @@
+package service_test
+
+import (
+	"context"
+	"gn-indexer/internal/domain"
+	"gn-indexer/internal/service"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockNFTRepository is a mock implementation of NFTRepository
+type MockNFTRepository struct {
+	mock.Mock
+}
+
+func (m *MockNFTRepository) SaveOwner(ctx context.Context, owner *domain.NFTOwner) error {
+	args := m.Called(ctx, owner)
+	return args.Error(0)
+}
+
+func (m *MockNFTRepository) GetOwner(ctx context.Context, collectionPath, tokenID string) (*domain.NFTOwner, error) {
+	args := m.Called(ctx, collectionPath, tokenID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.NFTOwner), args.Error(1)
+}
+
+func (m *MockNFTRepository) GetByOwner(ctx context.Context, owner string) ([]domain.NFTOwner, error) {
+	args := m.Called(ctx, owner)
+	return args.Get(0).([]domain.NFTOwner), args.Error(1)
+}
+
+func (m *MockNFTRepository) CreateTransfer(ctx context.Context, transfer *domain.NFTTransfer) error {
+	args := m.Called(ctx, transfer)
+	return args.Error(0)
+}
+
+func (m *MockNFTRepository) GetTransfersByToken(ctx context.Context, collectionPath, tokenID string) ([]domain.NFTTransfer, error) {
+	args := m.Called(ctx, collectionPath, tokenID)
+	return args.Get(0).([]domain.NFTTransfer), args.Error(1)
+}
+
+func (m *MockNFTRepository) GetTransfersByCollection(ctx context.Context, collectionPath string, limit int) ([]domain.NFTTransfer, error) {
+	args := m.Called(ctx, collectionPath, limit)
+	return args.Get(0).([]domain.NFTTransfer), args.Error(1)
+}
+
+func TestNFTService_OrdersEventsWithinABlock(t *testing.T) {
+	// Setup
+	mockNFTRepo := new(MockNFTRepository)
+	nftService := service.NewNFTService(mockNFTRepo)
+	ctx := context.Background()
+	stored := &domain.NFTOwner{CollectionPath: "gno.land/r/demo/nft", TokenID: "1", Owner: "g1second", LastTxHash: "tx1", LastBlockH: 100, LastEventIndex: 3}
+	earlier := &domain.ParsedEvent{
+		Func: domain.EventTypeNFTTransfer, TokenPath: "gno.land/r/demo/nft", TokenID: "1",
+		FromAddress: "g1first", ToAddress: "g1second", TxHash: "tx1", BlockHeight: 100, EventIndex: 1,
+	}
+	later := &domain.ParsedEvent{
+		Func: domain.EventTypeNFTTransfer, TokenPath: "gno.land/r/demo/nft", TokenID: "1",
+		FromAddress: "g1second", ToAddress: "g1third", TxHash: "tx1", BlockHeight: 100, EventIndex: 5,
+	}
+
+	// Mock expectations
+	mockNFTRepo.On("GetOwner", ctx, "gno.land/r/demo/nft", "1").Return(stored, nil)
+	mockNFTRepo.On("SaveOwner", ctx, mock.MatchedBy(func(owner *domain.NFTOwner) bool {
+		return owner.Owner == "g1third" && owner.LastBlockH == 100 && owner.LastEventIndex == 5
+	})).Return(nil).Once()
+
+	// Execute
+	earlierErr := nftService.ProcessEvent(ctx, earlier)
+	laterErr := nftService.ProcessEvent(ctx, later)
+
+	// Assert
+	assert.NoError(t, earlierErr)
+	assert.NoError(t, laterErr)
+	mockNFTRepo.AssertExpectations(t)
+	mockNFTRepo.AssertNumberOfCalls(t, "SaveOwner", 1)
+}
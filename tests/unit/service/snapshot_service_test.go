@@ -0,0 +1,195 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"gn-indexer/internal/domain"
+	"gn-indexer/internal/repository"
+	"gn-indexer/internal/service"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockSnapshotJobRepository is a mock implementation of SnapshotJobRepository
+type MockSnapshotJobRepository struct {
+	mock.Mock
+}
+
+func (m *MockSnapshotJobRepository) Create(ctx context.Context, job *domain.SnapshotJob) error {
+	return m.Called(ctx, job).Error(0)
+}
+
+func (m *MockSnapshotJobRepository) Update(ctx context.Context, job *domain.SnapshotJob) error {
+	return m.Called(ctx, job).Error(0)
+}
+
+func (m *MockSnapshotJobRepository) GetOwned(ctx context.Context, apiKeyID *int64, id int64) (*domain.SnapshotJob, error) {
+	args := m.Called(ctx, apiKeyID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.SnapshotJob), args.Error(1)
+}
+
+func (m *MockSnapshotJobRepository) ClaimPending(ctx context.Context) (*domain.SnapshotJob, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.SnapshotJob), args.Error(1)
+}
+
+func (m *MockSnapshotJobRepository) RequeueRunning(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockSnapshotJobRepository) CountPending(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockSnapshotJobRepository) ListFinishedBefore(ctx context.Context, before time.Time) ([]*domain.SnapshotJob, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).([]*domain.SnapshotJob), args.Error(1)
+}
+
+func TestSnapshotService_ExportCSVAtHeight(t *testing.T) {
+	// Setup
+	mockBalanceRepo := new(MockBalanceRepository)
+	mockHistoryRepo := new(MockBalanceHistoryRepository)
+	snapshotService := service.NewSnapshotService(mockBalanceRepo, mockHistoryRepo, nil, "")
+	ctx := context.Background()
+	height := int64(1500)
+
+	req := service.SnapshotRequest{
+		TokenPath: "test-token",
+		AtHeight:  &height,
+		MinAmount: domain.NewU64(10),
+		Exclude:   []string{"treasury-address"},
+		Format:    service.SnapshotFormatCSV,
+	}
+
+	// Mock expectations
+	mockHistoryRepo.On("StreamBalancesAt", ctx, height, repository.BalanceQuery{
+		TokenPath:   "test-token",
+		MinAmount:   domain.NewU64(10),
+		ExcludeZero: true,
+		Exclude:     []string{"treasury-address"},
+		Sort:        repository.BalanceSortAmount,
+	}).Return([]*domain.Balance{
+		{Address: "address-1", TokenPath: "test-token", Amount: domain.NewU64(500), LastBlockH: 1200},
+		{Address: "address-2", TokenPath: "test-token", Amount: domain.NewU64(20), LastBlockH: 1400},
+	}, nil)
+
+	// Execute
+	var out bytes.Buffer
+	rowCount, err := snapshotService.Export(ctx, req, &out)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), rowCount)
+	assert.Equal(t, "address,token_path,amount,last_block_height\n"+
+		"address-1,test-token,500,1200\n"+
+		"address-2,test-token,20,1400\n", out.String())
+	mockHistoryRepo.AssertExpectations(t)
+	mockBalanceRepo.AssertNotCalled(t, "StreamBalances")
+}
+
+func TestSnapshotService_ExportRejectsUnknownFormat(t *testing.T) {
+	// Setup
+	snapshotService := service.NewSnapshotService(new(MockBalanceRepository), nil, nil, "")
+
+	// Execute
+	_, err := snapshotService.Export(context.Background(), service.SnapshotRequest{TokenPath: "test-token", Format: "xml"}, &bytes.Buffer{})
+
+	// Assert
+	assert.ErrorIs(t, err, service.ErrInvalidSnapshotFormat)
+}
+
+func TestSnapshotService_SubmitRejectsFullQueue(t *testing.T) {
+	// Setup
+	mockJobRepo := new(MockSnapshotJobRepository)
+	snapshotService := service.NewSnapshotService(nil, nil, mockJobRepo, t.TempDir())
+	ctx := context.Background()
+
+	// Mock expectations
+	mockJobRepo.On("CountPending", ctx).Return(int64(100), nil)
+
+	// Execute
+	job, err := snapshotService.Submit(ctx, service.SnapshotRequest{TokenPath: "test-token", Format: service.SnapshotFormatCSV})
+
+	// Assert
+	assert.ErrorIs(t, err, service.ErrSnapshotQueueFull)
+	assert.Nil(t, job)
+	mockJobRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestSnapshotService_RunNextExportsClaimedJob(t *testing.T) {
+	// Setup
+	mockBalanceRepo := new(MockBalanceRepository)
+	mockJobRepo := new(MockSnapshotJobRepository)
+	outputDir := t.TempDir()
+	snapshotService := service.NewSnapshotService(mockBalanceRepo, nil, mockJobRepo, outputDir)
+	ctx := context.Background()
+	job := &domain.SnapshotJob{ID: 7, TokenPath: "test-token", Format: service.SnapshotFormatJSONL, Status: domain.SnapshotStatusRunning}
+
+	// Mock expectations
+	mockJobRepo.On("ClaimPending", ctx).Return(job, nil).Once()
+	mockJobRepo.On("ClaimPending", ctx).Return(nil, repository.ErrSnapshotJobNotFound)
+	mockBalanceRepo.On("StreamBalances", ctx, mock.Anything).Return([]*domain.Balance{
+		{Address: "address-1", TokenPath: "test-token", Amount: domain.NewU64(500), LastBlockH: 1200},
+	}, nil)
+	mockJobRepo.On("Update", ctx, job).Return(nil)
+
+	// Execute
+	ran := snapshotService.RunNext(ctx)
+	idle := snapshotService.RunNext(ctx)
+
+	// Assert
+	assert.True(t, ran)
+	assert.False(t, idle)
+	assert.Equal(t, domain.SnapshotStatusDone, job.Status)
+	assert.Equal(t, int64(1), job.RowCount)
+	assert.Equal(t, filepath.Join(outputDir, "snapshot-7.jsonl"), job.FilePath)
+	assert.FileExists(t, job.FilePath)
+}
+
+func TestSnapshotService_CleanupDeletesExpiredFiles(t *testing.T) {
+	// Setup
+	mockJobRepo := new(MockSnapshotJobRepository)
+	outputDir := t.TempDir()
+	snapshotService := service.NewSnapshotService(nil, nil, mockJobRepo, outputDir)
+	ctx := context.Background()
+	cutoff := time.Now().Add(-time.Hour)
+
+	expired := filepath.Join(outputDir, "snapshot-1.csv")
+	leftover := filepath.Join(outputDir, "snapshot-2.csv")
+	fresh := filepath.Join(outputDir, "snapshot-3.csv")
+	for _, path := range []string{expired, leftover, fresh} {
+		assert.NoError(t, os.WriteFile(path, []byte("address\n"), 0o644))
+	}
+	old := cutoff.Add(-time.Hour)
+	assert.NoError(t, os.Chtimes(leftover, old, old))
+	job := &domain.SnapshotJob{ID: 1, Status: domain.SnapshotStatusDone, FilePath: expired}
+
+	// Mock expectations
+	mockJobRepo.On("ListFinishedBefore", ctx, cutoff).Return([]*domain.SnapshotJob{job}, nil)
+	mockJobRepo.On("Update", ctx, job).Return(nil)
+
+	// Execute
+	snapshotService.Cleanup(ctx, cutoff)
+
+	// Assert
+	assert.Equal(t, domain.SnapshotStatusExpired, job.Status)
+	assert.Empty(t, job.FilePath)
+	assert.NoFileExists(t, expired)
+	assert.NoFileExists(t, leftover)
+	assert.FileExists(t, fresh)
+	mockJobRepo.AssertExpectations(t)
+}
@@ -0,0 +1,216 @@
+package service_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"gn-indexer/internal/client"
+	"gn-indexer/internal/domain"
+	"gn-indexer/internal/service"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// newQEvalServer mocks the gno RPC abci_query endpoint with fixed qeval results
+func newQEvalServer(results map[string]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Params struct {
+				Data string `json:"data"`
+			} `json:"params"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		query, _ := base64.StdEncoding.DecodeString(req.Params.Data)
+		expr := string(query)[strings.LastIndex(string(query), ".")+1:]
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"result": map[string]interface{}{
+				"response": map[string]interface{}{
+					"ResponseBase": map[string]interface{}{
+						"Data": base64.StdEncoding.EncodeToString([]byte(results[expr])),
+					},
+				},
+			},
+		})
+	}))
+}
+
+func TestRPCMetadataResolver_Resolve(t *testing.T) {
+	// Setup
+	server := newQEvalServer(map[string]string{
+		"GetSymbol()":   `("FOO" string)`,
+		"GetName()":     `("Foo Token" string)`,
+		"GetDecimals()": `(6 uint)`,
+		"TotalSupply()": `(100000000000000000000 uint64)`,
+	})
+	defer server.Close()
+
+	resolver := service.NewRPCMetadataResolver(client.NewGnoRPCClient(server.URL, 10*time.Second))
+
+	// Execute
+	metadata, err := resolver.Resolve(context.Background(), "gno.land/r/demo/foo")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "FOO", metadata.Symbol)
+	assert.Equal(t, "Foo Token", metadata.Name)
+	assert.Equal(t, 6, *metadata.Decimals)
+	assert.Equal(t, "100000000000000000000", metadata.TotalSupply)
+}
+
+func TestTokenMetadataService_RefreshPending(t *testing.T) {
+	// Setup
+	mockTokenRepo := new(MockTokenRepository)
+	server := newQEvalServer(map[string]string{
+		"GetSymbol()":   `("BAR" string)`,
+		"GetName()":     `("Bar" string)`,
+		"GetDecimals()": `(6 uint)`,
+		"TotalSupply()": `(1000 uint64)`,
+	})
+	defer server.Close()
+
+	metadataService := service.NewTokenMetadataService(
+		mockTokenRepo,
+		service.NewRPCMetadataResolver(client.NewGnoRPCClient(server.URL, 10*time.Second)),
+	)
+	ctx := context.Background()
+
+	// Mock expectations
+	mockTokenRepo.On("GetPendingMetadata", ctx).Return([]domain.Token{{Path: "gno.land/r/demo/bar"}}, nil)
+	mockTokenRepo.On("UpdateMetadata", ctx, mock.MatchedBy(func(token *domain.Token) bool {
+		return token.Path == "gno.land/r/demo/bar" &&
+			token.Symbol == "BAR" &&
+			token.Decimals == 6 &&
+			token.TotalSupply.String() == "1000" &&
+			token.MetadataUpdatedAt != nil
+	})).Return(nil)
+
+	// Execute
+	err := metadataService.RefreshPending(ctx)
+
+	// Assert
+	assert.NoError(t, err)
+	mockTokenRepo.AssertExpectations(t)
+}
+
+func TestRPCMetadataResolver_ResolveKeepsFieldsOfPartialRealm(t *testing.T) {
+	// Setup
+	partial := newQEvalServer(map[string]string{
+		"GetSymbol()":   `("FOO" string)`,
+		"GetDecimals()": `(0 uint)`,
+	})
+	defer partial.Close()
+	empty := newQEvalServer(map[string]string{})
+	defer empty.Close()
+
+	partialResolver := service.NewRPCMetadataResolver(client.NewGnoRPCClient(partial.URL, 10*time.Second))
+	emptyResolver := service.NewRPCMetadataResolver(client.NewGnoRPCClient(empty.URL, 10*time.Second))
+
+	// Execute
+	metadata, err := partialResolver.Resolve(context.Background(), "gno.land/r/demo/foo")
+	_, emptyErr := emptyResolver.Resolve(context.Background(), "gno.land/r/demo/foo")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "FOO", metadata.Symbol)
+	assert.Empty(t, metadata.Name)
+	assert.Equal(t, 0, *metadata.Decimals)
+	assert.Empty(t, metadata.TotalSupply)
+	assert.Error(t, emptyErr)
+}
+
+// decimals returns a pointer to n, for explicit decimals in metadata literals
+func decimals(n int) *int {
+	return &n
+}
+
+// fixedMetadataResolver resolves every token to the same metadata, or fails with err
+type fixedMetadataResolver struct {
+	metadata *service.TokenMetadata
+	err      error
+}
+
+func (r *fixedMetadataResolver) Resolve(ctx context.Context, tokenPath string) (*service.TokenMetadata, error) {
+	return r.metadata, r.err
+}
+
+func TestTokenMetadataService_StaticFileOnlyFillsMissingFields(t *testing.T) {
+	// Setup
+	mockTokenRepo := new(MockTokenRepository)
+	realm := &fixedMetadataResolver{metadata: &service.TokenMetadata{Symbol: "FOO", Decimals: decimals(6), TotalSupply: "2000"}}
+	static := &fixedMetadataResolver{metadata: &service.TokenMetadata{Symbol: "OLD", Name: "Foo Token", Decimals: decimals(2), TotalSupply: "1000"}}
+	metadataService := service.NewTokenMetadataService(mockTokenRepo, realm, static)
+	ctx := context.Background()
+
+	// Mock expectations
+	mockTokenRepo.On("UpdateMetadata", ctx, mock.MatchedBy(func(token *domain.Token) bool {
+		return token.Symbol == "FOO" &&
+			token.Name == "Foo Token" &&
+			token.Decimals == 6 &&
+			token.TotalSupply.String() == "2000"
+	})).Return(nil)
+
+	// Execute
+	err := metadataService.RefreshToken(ctx, &domain.Token{Path: "gno.land/r/demo/foo"})
+
+	// Assert
+	assert.NoError(t, err)
+	mockTokenRepo.AssertExpectations(t)
+}
+
+func TestTokenMetadataService_StaticFileWhenRealmFails(t *testing.T) {
+	// Setup
+	mockTokenRepo := new(MockTokenRepository)
+	realm := &fixedMetadataResolver{err: errors.New("rpc unavailable")}
+	static := &fixedMetadataResolver{metadata: &service.TokenMetadata{Symbol: "FOO", Decimals: decimals(6)}}
+	missing := &fixedMetadataResolver{err: service.ErrMetadataNotFound}
+	metadataService := service.NewTokenMetadataService(mockTokenRepo, realm, static)
+	failingService := service.NewTokenMetadataService(mockTokenRepo, realm, missing)
+	ctx := context.Background()
+
+	// Mock expectations
+	mockTokenRepo.On("UpdateMetadata", ctx, mock.MatchedBy(func(token *domain.Token) bool {
+		return token.Symbol == "FOO" && token.Decimals == 6
+	})).Return(nil).Once()
+
+	// Execute
+	err := metadataService.RefreshToken(ctx, &domain.Token{Path: "gno.land/r/demo/foo"})
+	failingErr := failingService.RefreshToken(ctx, &domain.Token{Path: "gno.land/r/demo/foo"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.ErrorContains(t, failingErr, "rpc unavailable")
+	mockTokenRepo.AssertExpectations(t)
+}
+
+func TestTokenMetadataService_ExplicitZeroDecimalsAreKept(t *testing.T) {
+	// Setup
+	mockTokenRepo := new(MockTokenRepository)
+	realm := &fixedMetadataResolver{metadata: &service.TokenMetadata{Symbol: "FOO", Decimals: decimals(0)}}
+	static := &fixedMetadataResolver{metadata: &service.TokenMetadata{Name: "Foo Token", Decimals: decimals(6), TotalSupply: "1000"}}
+	metadataService := service.NewTokenMetadataService(mockTokenRepo, realm, static)
+	ctx := context.Background()
+
+	// Mock expectations
+	mockTokenRepo.On("UpdateMetadata", ctx, mock.MatchedBy(func(token *domain.Token) bool {
+		return token.Symbol == "FOO" &&
+			token.Name == "Foo Token" &&
+			token.Decimals == 0 &&
+			token.TotalSupply.String() == "1000"
+	})).Return(nil)
+
+	// Execute
+	err := metadataService.RefreshToken(ctx, &domain.Token{Path: "gno.land/r/demo/foo", Decimals: 6})
+
+	// Assert
+	assert.NoError(t, err)
+	mockTokenRepo.AssertExpectations(t)
+}
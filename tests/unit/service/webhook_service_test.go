@@ -0,0 +1,229 @@
+package service_test
+
+import (
+	"context"
+	"gn-indexer/internal/domain"
+	"gn-indexer/internal/service"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockWebhookRepository is a mock implementation of WebhookRepository
+type MockWebhookRepository struct {
+	mock.Mock
+}
+
+func (m *MockWebhookRepository) Create(ctx context.Context, webhook *domain.Webhook) error {
+	return m.Called(ctx, webhook).Error(0)
+}
+
+func (m *MockWebhookRepository) Delete(ctx context.Context, apiKeyID *int64, id int64) error {
+	return m.Called(ctx, apiKeyID, id).Error(0)
+}
+
+func (m *MockWebhookRepository) GetByID(ctx context.Context, id int64) (*domain.Webhook, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookRepository) GetOwned(ctx context.Context, apiKeyID *int64, id int64) (*domain.Webhook, error) {
+	args := m.Called(ctx, apiKeyID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookRepository) List(ctx context.Context, apiKeyID *int64) ([]*domain.Webhook, error) {
+	args := m.Called(ctx, apiKeyID)
+	return args.Get(0).([]*domain.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookRepository) ListActive(ctx context.Context) ([]*domain.Webhook, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]*domain.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookRepository) CreateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	return m.Called(ctx, delivery).Error(0)
+}
+
+func (m *MockWebhookRepository) UpdateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	return m.Called(ctx, delivery).Error(0)
+}
+
+func (m *MockWebhookRepository) ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*domain.WebhookDelivery, error) {
+	args := m.Called(ctx, now, lease, limit)
+	return args.Get(0).([]*domain.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookRepository) GetDeliveries(ctx context.Context, webhookID int64, limit int) ([]*domain.WebhookDelivery, error) {
+	args := m.Called(ctx, webhookID, limit)
+	return args.Get(0).([]*domain.WebhookDelivery), args.Error(1)
+}
+
+func TestWebhookService_PublishQueuesMatchingWebhooks(t *testing.T) {
+	// Setup
+	mockWebhookRepo := new(MockWebhookRepository)
+	webhookService := service.NewWebhookService(mockWebhookRepo, service.NewWebhookClient(10*time.Second))
+	ctx := context.Background()
+
+	notification := &domain.Notification{
+		Kind:      domain.NotificationTransfer,
+		TokenPath: "test-token",
+		ToAddress: "watched-address",
+		Amount:    "500",
+	}
+
+	// Mock expectations
+	mockWebhookRepo.On("ListActive", ctx).Return([]*domain.Webhook{
+		{ID: 1, Address: "watched-address", MinAmount: domain.NewU64(100)},
+		{ID: 2, Address: "watched-address", MinAmount: domain.NewU64(1000)},
+		{ID: 3, TokenPath: "other-token"},
+	}, nil)
+	mockWebhookRepo.On("CreateDelivery", ctx, mock.MatchedBy(func(delivery *domain.WebhookDelivery) bool {
+		return delivery.WebhookID == 1 && delivery.Status == domain.DeliveryStatusPending
+	})).Return(nil).Once()
+
+	// Execute
+	err := webhookService.Publish(ctx, notification)
+
+	// Assert
+	assert.NoError(t, err)
+	mockWebhookRepo.AssertExpectations(t)
+}
+
+func TestWebhookService_DeliverDueSignsPayload(t *testing.T) {
+	// Setup
+	var signature, timestamp, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, _ := io.ReadAll(r.Body)
+		body = string(raw)
+		signature = r.Header.Get(service.WebhookSignatureHeader)
+		timestamp = r.Header.Get(service.WebhookTimestampHeader)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	mockWebhookRepo := new(MockWebhookRepository)
+	webhookService := service.NewWebhookService(mockWebhookRepo, server.Client())
+	ctx := context.Background()
+	delivery := &domain.WebhookDelivery{ID: 7, WebhookID: 1, Payload: `{"webhookId":1}`, Status: domain.DeliveryStatusPending}
+
+	// Mock expectations
+	mockWebhookRepo.On("ClaimDueDeliveries", ctx, mock.Anything, mock.Anything, mock.Anything).Return([]*domain.WebhookDelivery{delivery}, nil)
+	mockWebhookRepo.On("GetByID", ctx, int64(1)).Return(&domain.Webhook{ID: 1, URL: server.URL, Secret: "test-secret"}, nil)
+	mockWebhookRepo.On("UpdateDelivery", ctx, delivery).Return(nil)
+
+	// Execute
+	err := webhookService.DeliverDue(ctx)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, domain.DeliveryStatusSucceeded, delivery.Status)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Equal(t, `{"webhookId":1}`, body)
+	ts, _ := strconv.ParseInt(timestamp, 10, 64)
+	assert.Equal(t, service.SignWebhookPayload("test-secret", ts, []byte(body)), signature)
+}
+
+func TestWebhookService_DeliveryRefusesNonPublicTargets(t *testing.T) {
+	// Setup
+	var hits int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	mockWebhookRepo := new(MockWebhookRepository)
+	webhookService := service.NewWebhookService(mockWebhookRepo, service.NewWebhookClient(10*time.Second))
+	ctx := context.Background()
+	delivery := &domain.WebhookDelivery{ID: 7, WebhookID: 1, Payload: `{"webhookId":1}`, Status: domain.DeliveryStatusPending}
+
+	// Mock expectations
+	mockWebhookRepo.On("ClaimDueDeliveries", ctx, mock.Anything, mock.Anything, mock.Anything).Return([]*domain.WebhookDelivery{delivery}, nil)
+	mockWebhookRepo.On("GetByID", ctx, int64(1)).Return(&domain.Webhook{ID: 1, URL: server.URL, Secret: "test-secret"}, nil)
+	mockWebhookRepo.On("UpdateDelivery", ctx, delivery).Return(nil)
+
+	// Execute
+	err := webhookService.DeliverDue(ctx)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, domain.DeliveryStatusPending, delivery.Status)
+	assert.Contains(t, delivery.LastError, service.ErrNonPublicAddress.Error())
+	assert.Equal(t, 0, hits)
+}
+
+func TestWebhookService_DeliveryDoesNotFollowRedirects(t *testing.T) {
+	// Setup
+	var hits int
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer target.Close()
+	redirect := httptest.NewServer(http.RedirectHandler(target.URL, http.StatusFound))
+	defer redirect.Close()
+
+	mockWebhookRepo := new(MockWebhookRepository)
+	client := service.NewWebhookClient(10 * time.Second)
+	// The test servers listen on loopback, only the redirect policy of the delivery client is kept
+	client.Transport = redirect.Client().Transport
+	webhookService := service.NewWebhookService(mockWebhookRepo, client)
+	ctx := context.Background()
+	delivery := &domain.WebhookDelivery{ID: 7, WebhookID: 1, Payload: `{"webhookId":1}`, Status: domain.DeliveryStatusPending}
+
+	// Mock expectations
+	mockWebhookRepo.On("ClaimDueDeliveries", ctx, mock.Anything, mock.Anything, mock.Anything).Return([]*domain.WebhookDelivery{delivery}, nil)
+	mockWebhookRepo.On("GetByID", ctx, int64(1)).Return(&domain.Webhook{ID: 1, URL: redirect.URL, Secret: "test-secret"}, nil)
+	mockWebhookRepo.On("UpdateDelivery", ctx, delivery).Return(nil)
+
+	// Execute
+	err := webhookService.DeliverDue(ctx)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, domain.DeliveryStatusPending, delivery.Status)
+	assert.Equal(t, http.StatusFound, delivery.LastStatusCode)
+	assert.Equal(t, 0, hits)
+}
+
+func TestPublicIP(t *testing.T) {
+	// Setup
+	cases := map[string]bool{
+		"93.184.216.34":      true,
+		"2606:2800:220:1::":  true,
+		"127.0.0.1":          false,
+		"10.1.2.3":           false,
+		"169.254.169.254":    false,
+		"100.64.0.1":         false,
+		"192.0.0.170":        false,
+		"198.18.0.1":         false,
+		"::ffff:127.0.0.1":   false,
+		"64:ff9b::a9fe:a9fe": false,
+		"64:ff9b:1::1":       false,
+		"fd00:ec2::254":      false,
+		"::":                 false,
+	}
+
+	for address, public := range cases {
+		// Execute
+		result := service.PublicIP(net.ParseIP(address))
+
+		// Assert
+		assert.Equal(t, public, result, address)
+	}
+}
@@ -0,0 +1,84 @@
+package tracing_test
+
+import (
+	"context"
+	"gn-indexer/internal/config"
+	"gn-indexer/internal/tracing"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// newRecorder installs the propagator and a tracer provider recording every span
+func newRecorder(t *testing.T) *tracetest.SpanRecorder {
+	_, err := tracing.Setup(context.Background(), &config.TracingConfig{Exporter: config.TracingExporterNone}, "test")
+	assert.NoError(t, err)
+
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	return recorder
+}
+
+func TestTraceContextRoundTrip(t *testing.T) {
+	// Setup
+	recorder := newRecorder(t)
+	ctx, producer := tracing.Start(context.Background(), "producer")
+
+	// Execute
+	carrier := tracing.Inject(ctx)
+	consumerCtx := tracing.Extract(context.Background(), carrier)
+	_, consumer := tracing.Start(consumerCtx, "consumer")
+	consumer.End()
+	producer.End()
+
+	// Assert
+	assert.Contains(t, carrier, "traceparent")
+	assert.Contains(t, tracing.Fields(), "traceparent")
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+	assert.Equal(t, producer.SpanContext().TraceID(), spans[0].SpanContext().TraceID())
+	assert.Equal(t, producer.SpanContext().SpanID(), spans[0].Parent().SpanID())
+}
+
+func TestMiddlewareContinuesRemoteTrace(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	recorder := newRecorder(t)
+	router := gin.New()
+	router.Use(tracing.Middleware())
+	router.GET("/tokens/:path", func(c *gin.Context) {
+		c.Status(http.StatusInternalServerError)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/tokens/foo", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	w := httptest.NewRecorder()
+
+	// Execute
+	router.ServeHTTP(w, req)
+
+	// Assert
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	span := spans[0]
+	assert.Equal(t, "GET /tokens/:path", span.Name())
+	assert.Equal(t, trace.SpanKindServer, span.SpanKind())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", span.Parent().SpanID().String())
+	assert.Equal(t, "Error", span.Status().Code.String())
+}
+
+func TestSetupRejectsUnknownExporter(t *testing.T) {
+	// Execute
+	_, err := tracing.Setup(context.Background(), &config.TracingConfig{Exporter: "zipkin"}, "test")
+
+	// Assert
+	assert.Error(t, err)
+}
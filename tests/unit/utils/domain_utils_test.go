@@ -80,3 +80,27 @@ func TestU64_Scan(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Nil(t, u64.Int)
 }
+
+func TestU64_FormatDecimal(t *testing.T) {
+	// Test decimal formatting with token decimals
+	u64 := domain.NewU64(1500000)
+	assert.Equal(t, "1.5", u64.FormatDecimal(6))
+	assert.Equal(t, "1500000", u64.FormatDecimal(0))
+
+	// Test amounts smaller than one unit
+	small := domain.NewU64(42)
+	assert.Equal(t, "0.000042", small.FormatDecimal(6))
+
+	// Test whole amounts
+	whole := domain.NewU64(3000000)
+	assert.Equal(t, "3", whole.FormatDecimal(6))
+
+	// Test values beyond int64 keep full precision
+	large, err := domain.NewU64FromString("123456789012345678901234567890")
+	assert.NoError(t, err)
+	assert.Equal(t, "123456789012.34567890123456789", large.FormatDecimal(18))
+
+	// Test zero value
+	zero := &domain.U64{}
+	assert.Equal(t, "0", zero.FormatDecimal(6))
+}